@@ -1,25 +1,59 @@
 package api
 
 import (
-	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
 	db "simple-bank/db/sqlc"
 	"simple-bank/token"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
 )
 
+type accountResponse struct {
+	Number           string    `json:"number"`
+	Owner            string    `json:"owner"`
+	Balance          int64     `json:"balance"`
+	AvailableBalance int64     `json:"available_balance"`
+	Currency         string    `json:"currency"`
+	Product          string    `json:"product"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// getAccountResponse exposes an account by its public number, keeping the internal ID private
+func getAccountResponse(account db.Account) accountResponse {
+	return accountResponse{
+		Number:           account.Number,
+		Owner:            account.Owner,
+		Balance:          account.Balance,
+		AvailableBalance: account.AvailableBalance(),
+		Currency:         account.Currency,
+		Product:          account.Product,
+		CreatedAt:        account.CreatedAt,
+	}
+}
+
+// CreateAccountRequest opens an account of Product, checking unless given. An owner
+// holds at most one account per currency and product.
 type CreateAccountRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
+	Product  string `json:"product" binding:"omitempty,max=30"`
 }
 
 func (server *Server) CreateAccount(ctx *gin.Context) {
 	var req CreateAccountRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorHandler(err))
+		errorResponse(ctx, err)
+		return
+	}
+
+	if req.Product == "" {
+		req.Product = db.ProductChecking
+	}
+	product, valid := server.getAccountProduct(ctx, req.Product, req.Currency)
+	if !valid {
 		return
 	}
 
@@ -28,53 +62,166 @@ func (server *Server) CreateAccount(ctx *gin.Context) {
 		Owner:    authPayload.Username,
 		Balance:  0,
 		Currency: req.Currency,
+		Product:  product.Code,
 	}
 
-	account, err := server.store.CreateAccount(ctx, args)
+	account, err := db.CreateAccountWithNewNumber(ctx, server.store, args)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "unique_violation", "foreign_key_violation":
-				ctx.JSON(http.StatusForbidden, errorHandler(err))
-				return
-			}
-		}
-		ctx.JSON(http.StatusInternalServerError, errorHandler(err))
+		errorResponse(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, account)
+	ctx.JSON(http.StatusCreated, getAccountResponse(account))
 }
 
 type GetAccountRequest struct {
-	ID int64 `uri:"id" binding:"required"`
+	Number string `uri:"id" binding:"required,account_number"`
 }
 
 func (server *Server) GetAccount(ctx *gin.Context) {
 	var req GetAccountRequest
 
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorHandler(err))
+		errorResponse(ctx, err)
+		return
+	}
+
+	account, valid := server.getUserAccount(ctx, req.Number)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getAccountResponse(account))
+}
+
+type accountBalanceRequest struct {
+	GetAccountRequest
+	At time.Time `form:"at" binding:"required"`
+}
+
+type accountBalanceResponse struct {
+	Number   string    `json:"number"`
+	Balance  int64     `json:"balance"`
+	Currency string    `json:"currency"`
+	At       time.Time `json:"at"`
+}
+
+// GetAccountBalance reports the balance of an account at a past instant, including
+// every entry booked up to and at that instant
+func (server *Server) GetAccountBalance(ctx *gin.Context) {
+	var req accountBalanceRequest
+
+	if err := ctx.ShouldBindUri(&req.GetAccountRequest); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	if req.At.After(time.Now()) {
+		errorResponse(ctx, ErrValidationFailed.withDetail("at must not be in the future"))
+		return
+	}
+
+	account, valid := server.getUserAccount(ctx, req.Number)
+	if !valid {
 		return
 	}
 
-	account, err := server.store.GetAccount(ctx, req.ID)
+	balance, err := server.store.GetBalanceAt(ctx, db.GetBalanceAtParams{
+		AccountID: account.ID,
+		At:        req.At,
+	})
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorHandler(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorHandler(err))
+		errorResponse(ctx, err)
 		return
 	}
+
+	ctx.JSON(http.StatusOK, accountBalanceResponse{
+		Number:   account.Number,
+		Balance:  balance,
+		Currency: account.Currency,
+		At:       req.At,
+	})
+}
+
+// getUserAccount loads an account the authenticated user is an active member of, in
+// any role. Other accounts are reported exactly like missing ones, so account numbers
+// can't be probed.
+func (server *Server) getUserAccount(ctx *gin.Context, number string) (db.Account, bool) {
+	account, _, valid := server.getMemberAccount(ctx, number)
+	return account, valid
+}
+
+// getSpendingAccount loads an account the authenticated user may send amount from in a
+// single transfer: viewers are denied and spenders are held to their spend limit.
+func (server *Server) getSpendingAccount(ctx *gin.Context, number string, amount int64) (db.Account, bool) {
+	account, member, valid := server.getMemberAccount(ctx, number)
+	if !valid {
+		return account, false
+	}
+
+	if apiErr := spendError(member, amount, number); apiErr != nil {
+		errorResponse(ctx, apiErr)
+		return db.Account{}, false
+	}
+
+	return account, true
+}
+
+// spendError explains why member can't send amount from the account, nil when they can
+func spendError(member db.AccountMember, amount int64, number string) *apiError {
+	switch {
+	case member.CanSpend(amount):
+		return nil
+	case member.Role == db.MemberSpender:
+		return ErrSpendLimit.withDetail(fmt.Sprintf("%d is above the spend limit of %d on account [%s]", amount, member.SpendLimit, number))
+	default:
+		return ErrPermissionDenied.withDetail(fmt.Sprintf("a %s can't send from account [%s]", member.Role, number))
+	}
+}
+
+// getMemberAccount loads an account along with the authenticated user's active
+// membership of it
+func (server *Server) getMemberAccount(ctx *gin.Context, number string) (db.Account, db.AccountMember, bool) {
+	account, err := server.store.GetAccountByNumber(ctx, number)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = accountNotFound(number)
+		}
+		errorResponse(ctx, err)
+		return account, db.AccountMember{}, false
+	}
+
+	member, err := server.userMember(ctx, account)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = accountNotFound(number)
+		}
+		errorResponse(ctx, err)
+		return db.Account{}, member, false
+	}
+
+	return account, member, true
+}
+
+// userMember loads the active membership of the authenticated user in account, failing
+// with db.ErrRecordNotFound when they have none
+func (server *Server) userMember(ctx *gin.Context, account db.Account) (db.AccountMember, error) {
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if authPayload.Username != account.Owner {
-		err := errors.New("account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorHandler(err))
-		return
+	if authPayload.Username == account.Owner {
+		return db.OwnerMember(account), nil
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	return server.store.GetActiveAccountMember(ctx, db.GetActiveAccountMemberParams{
+		AccountID: account.ID,
+		Username:  authPayload.Username,
+	})
+}
+
+func accountNotFound(number string) *apiError {
+	return ErrAccountNotFound.withDetail(fmt.Sprintf("account [%s] not found", number))
 }
 
 type ListAccountRequest struct {
@@ -86,22 +233,26 @@ func (server *Server) ListAccounts(ctx *gin.Context) {
 	var req ListAccountRequest
 
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorHandler(err))
+		errorResponse(ctx, err)
 		return
 	}
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	args := db.ListAccountParams{
-		Owner:  authPayload.Username,
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+		Username: authPayload.Username,
+		Limit:    req.PageSize,
+		Offset:   (req.PageID - 1) * req.PageSize,
 	}
 
 	accounts, err := server.store.ListAccount(ctx, args)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorHandler(err))
+		errorResponse(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, accounts)
+	res := make([]accountResponse, len(accounts))
+	for i, account := range accounts {
+		res[i] = getAccountResponse(account)
+	}
 
+	ctx.JSON(http.StatusOK, res)
 }
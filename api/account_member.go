@@ -0,0 +1,287 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	db "simple-bank/db/sqlc"
+	"simple-bank/token"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// accountMemberResponse is a member of an account or an invitation to it, SpendLimit is
+// only set for spenders
+type accountMemberResponse struct {
+	ID         int64      `json:"id"`
+	Account    string     `json:"account"`
+	Username   string     `json:"username"`
+	Role       string     `json:"role"`
+	SpendLimit *int64     `json:"spend_limit,omitempty"`
+	Status     string     `json:"status"`
+	InvitedBy  string     `json:"invited_by"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func getAccountMemberResponse(member db.AccountMember, number string) accountMemberResponse {
+	res := accountMemberResponse{
+		ID:        member.ID,
+		Account:   number,
+		Username:  member.Username,
+		Role:      member.Role,
+		Status:    member.Status,
+		InvitedBy: member.InvitedBy,
+		CreatedAt: member.CreatedAt,
+	}
+	if member.Role == db.MemberSpender {
+		res.SpendLimit = &member.SpendLimit
+	}
+	if member.AcceptedAt.Valid {
+		res.AcceptedAt = &member.AcceptedAt.Time
+	}
+	return res
+}
+
+// inviteAccountMemberRequest invites Username to the account, SpendLimit is required
+// for spenders and not allowed otherwise
+type inviteAccountMemberRequest struct {
+	GetAccountRequest
+	Username   string `json:"username" binding:"required,alphanum"`
+	Role       string `json:"role" binding:"required,oneof=co_owner viewer spender"`
+	SpendLimit int64  `json:"spend_limit" binding:"required_if=Role spender,min=0"`
+}
+
+// InviteAccountMember invites a user to share an account. Only owners and co-owners can
+// invite, and the invitation gives no access until the user accepts it.
+func (server *Server) InviteAccountMember(ctx *gin.Context) {
+	var req inviteAccountMemberRequest
+
+	if err := ctx.ShouldBindUri(&req.GetAccountRequest); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	if req.Role != db.MemberSpender && req.SpendLimit != 0 {
+		errorResponse(ctx, ErrValidationFailed.withDetail("spend_limit is only for spenders"))
+		return
+	}
+
+	account, member, valid := server.getManagedAccount(ctx, req.Number)
+	if !valid {
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, req.Username)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = ErrUserNotFound.withDetail(fmt.Sprintf("user [%s] not found", req.Username))
+		}
+		errorResponse(ctx, err)
+		return
+	}
+
+	invited, err := server.store.CreateAccountMember(ctx, db.CreateAccountMemberParams{
+		AccountID:  account.ID,
+		Username:   user.Username,
+		Role:       req.Role,
+		SpendLimit: req.SpendLimit,
+		InvitedBy:  member.Username,
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, getAccountMemberResponse(invited, account.Number))
+}
+
+// ListAccountMembers lists the members of an account and the pending invitations to it,
+// for any of its members
+func (server *Server) ListAccountMembers(ctx *gin.Context) {
+	var req GetAccountRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	account, valid := server.getUserAccount(ctx, req.Number)
+	if !valid {
+		return
+	}
+
+	members, err := server.store.ListAccountMembers(ctx, account.ID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	res := make([]accountMemberResponse, len(members))
+	for i, member := range members {
+		res[i] = getAccountMemberResponse(member, account.Number)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type accountMemberRequest struct {
+	GetAccountRequest
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+// RemoveAccountMember removes a member or withdraws an invitation. Owners and co-owners
+// remove anyone but the owner, and every other member can leave.
+func (server *Server) RemoveAccountMember(ctx *gin.Context) {
+	var req accountMemberRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	account, member, valid := server.getMemberAccount(ctx, req.Number)
+	if !valid {
+		return
+	}
+	if req.Username != member.Username && !member.CanManage() {
+		errorResponse(ctx, ErrPermissionDenied.withDetail(fmt.Sprintf("a %s can't remove members of account [%s]", member.Role, req.Number)))
+		return
+	}
+
+	removed, err := server.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  req.Username,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = ErrMemberNotFound.withDetail(fmt.Sprintf("[%s] isn't a member of account [%s]", req.Username, req.Number))
+		}
+		errorResponse(ctx, err)
+		return
+	}
+	if removed.Role == db.MemberOwner {
+		errorResponse(ctx, ErrPermissionDenied.withDetail("the owner can't be removed from their account"))
+		return
+	}
+
+	if err := server.store.DeleteAccountMember(ctx, removed.ID); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListAccountInvitations lists the pending invitations of the authenticated user
+func (server *Server) ListAccountInvitations(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	invitations, err := server.store.ListAccountInvitations(ctx, authPayload.Username)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	res := make([]accountMemberResponse, len(invitations))
+	for i, invitation := range invitations {
+		res[i] = getAccountMemberResponse(db.AccountMember{
+			ID:         invitation.ID,
+			AccountID:  invitation.AccountID,
+			Username:   invitation.Username,
+			Role:       invitation.Role,
+			SpendLimit: invitation.SpendLimit,
+			Status:     invitation.Status,
+			InvitedBy:  invitation.InvitedBy,
+			AcceptedAt: invitation.AcceptedAt,
+			CreatedAt:  invitation.CreatedAt,
+		}, invitation.AccountNumber)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type accountInvitationRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// AcceptAccountInvitation makes the authenticated user an active member of the account
+// they were invited to
+func (server *Server) AcceptAccountInvitation(ctx *gin.Context) {
+	var req accountInvitationRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	member, err := server.store.AcceptAccountInvitation(ctx, db.AcceptAccountInvitationParams{
+		ID:       req.ID,
+		Username: authPayload.Username,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = invitationNotFound(req.ID)
+		}
+		errorResponse(ctx, err)
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, member.AccountID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getAccountMemberResponse(member, account.Number))
+}
+
+// DeclineAccountInvitation turns down an invitation of the authenticated user
+func (server *Server) DeclineAccountInvitation(ctx *gin.Context) {
+	var req accountInvitationRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	_, err := server.store.DeclineAccountInvitation(ctx, db.DeclineAccountInvitationParams{
+		ID:       req.ID,
+		Username: authPayload.Username,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = invitationNotFound(req.ID)
+		}
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// getManagedAccount loads an account the authenticated user may invite and remove
+// members of, along with their membership
+func (server *Server) getManagedAccount(ctx *gin.Context, number string) (db.Account, db.AccountMember, bool) {
+	account, member, valid := server.getMemberAccount(ctx, number)
+	if !valid {
+		return account, member, false
+	}
+
+	if !member.CanManage() {
+		errorResponse(ctx, ErrPermissionDenied.withDetail(fmt.Sprintf("a %s can't manage the members of account [%s]", member.Role, number)))
+		return db.Account{}, member, false
+	}
+
+	return account, member, true
+}
+
+func invitationNotFound(id int64) *apiError {
+	return ErrInvitationNotFound.withDetail(fmt.Sprintf("invitation [%d] not found", id))
+}
@@ -0,0 +1,352 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simple-bank/db/mock"
+	db "simple-bank/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInviteAccountMemberAPI(t *testing.T) {
+	owner, _ := randomUser(t)
+	invitee, _ := randomUser(t)
+	viewer, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Spender",
+			username: owner.Username,
+			body:     gin.H{"username": invitee.Username, "role": db.MemberSpender, "spend_limit": 500},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(invitee.Username)).Times(1).Return(invitee, nil)
+				store.EXPECT().
+					CreateAccountMember(gomock.Any(), gomock.Eq(db.CreateAccountMemberParams{
+						AccountID:  account.ID,
+						Username:   invitee.Username,
+						Role:       db.MemberSpender,
+						SpendLimit: 500,
+						InvitedBy:  owner.Username,
+					})).
+					Times(1).
+					Return(db.AccountMember{ID: 7, AccountID: account.ID, Username: invitee.Username, Role: db.MemberSpender, SpendLimit: 500, Status: db.MemberInvited, InvitedBy: owner.Username}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res accountMemberResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, account.Number, res.Account)
+				require.Equal(t, db.MemberInvited, res.Status)
+				require.Equal(t, int64(500), *res.SpendLimit)
+				require.Nil(t, res.AcceptedAt)
+			},
+		},
+		{
+			name:     "SpenderWithoutLimit",
+			username: owner.Username,
+			body:     gin.H{"username": invitee.Username, "role": db.MemberSpender},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				problem := requireProblemCode(t, recorder, ErrValidationFailed.Code)
+				require.Equal(t, "spend_limit", problem.Errors[0].Field)
+			},
+		},
+		{
+			name:     "LimitOfViewer",
+			username: owner.Username,
+			body:     gin.H{"username": invitee.Username, "role": db.MemberViewer, "spend_limit": 500},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "AnotherOwner",
+			username: owner.Username,
+			body:     gin.H{"username": invitee.Username, "role": db.MemberOwner},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "ByViewer",
+			username: viewer.Username,
+			body:     gin.H{"username": invitee.Username, "role": db.MemberViewer},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetActiveAccountMember(gomock.Any(), gomock.Eq(db.GetActiveAccountMemberParams{AccountID: account.ID, Username: viewer.Username})).
+					Times(1).
+					Return(db.AccountMember{AccountID: account.ID, Username: viewer.Username, Role: db.MemberViewer, Status: db.MemberActive}, nil)
+				store.EXPECT().CreateAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, ErrPermissionDenied.Code)
+			},
+		},
+		{
+			name:     "UnknownUser",
+			username: owner.Username,
+			body:     gin.H{"username": "nobody", "role": db.MemberViewer},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq("nobody")).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().CreateAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, ErrUserNotFound.Code)
+			},
+		},
+		{
+			name:     "AlreadyMember",
+			username: owner.Username,
+			body:     gin.H{"username": invitee.Username, "role": db.MemberCoOwner},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(invitee.Username)).Times(1).Return(invitee, nil)
+				store.EXPECT().
+					CreateAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountMember{}, &pq.Error{Code: "23505", Constraint: "account_members_account_username_key"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, ErrMemberExists.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%s/members", account.Number)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestRemoveAccountMemberAPI(t *testing.T) {
+	owner, _ := randomUser(t)
+	spender, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+
+	spenderMember := db.AccountMember{ID: 3, AccountID: account.ID, Username: spender.Username, Role: db.MemberSpender, SpendLimit: 100, Status: db.MemberActive}
+
+	testCases := []struct {
+		name          string
+		username      string
+		removed       string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "ByOwner",
+			username: owner.Username,
+			removed:  spender.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account.ID, Username: spender.Username})).
+					Times(1).
+					Return(spenderMember, nil)
+				store.EXPECT().DeleteAccountMember(gomock.Any(), gomock.Eq(spenderMember.ID)).Times(1).Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:     "Leave",
+			username: spender.Username,
+			removed:  spender.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().GetActiveAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(spenderMember, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(spenderMember, nil)
+				store.EXPECT().DeleteAccountMember(gomock.Any(), gomock.Eq(spenderMember.ID)).Times(1).Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:     "OwnerBySpender",
+			username: spender.Username,
+			removed:  owner.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().GetActiveAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(spenderMember, nil)
+				store.EXPECT().DeleteAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "Owner",
+			username: owner.Username,
+			removed:  owner.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.OwnerMember(account), nil)
+				store.EXPECT().DeleteAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NotAMember",
+			username: owner.Username,
+			removed:  "nobody",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().DeleteAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, ErrMemberNotFound.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%s/members/%s", account.Number, tc.removed)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestAcceptAccountInvitationAPI(t *testing.T) {
+	owner, _ := randomUser(t)
+	invitee, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AcceptAccountInvitation(gomock.Any(), gomock.Eq(db.AcceptAccountInvitationParams{ID: 9, Username: invitee.Username})).
+					Times(1).
+					Return(db.AccountMember{
+						ID:         9,
+						AccountID:  account.ID,
+						Username:   invitee.Username,
+						Role:       db.MemberCoOwner,
+						Status:     db.MemberActive,
+						InvitedBy:  owner.Username,
+						AcceptedAt: sql.NullTime{Time: time.Now(), Valid: true},
+					}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res accountMemberResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, account.Number, res.Account)
+				require.Equal(t, db.MemberActive, res.Status)
+				require.NotNil(t, res.AcceptedAt)
+				require.Nil(t, res.SpendLimit)
+			},
+		},
+		{
+			// someone else's invitation, or one already accepted
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AcceptAccountInvitation(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, ErrInvitationNotFound.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/account-invitations/9/accept", nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, invitee.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
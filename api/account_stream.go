@@ -0,0 +1,98 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	db "simple-bank/db/sqlc"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	eventStreamContentType = "text/event-stream"
+	accountStreamEvent     = "account"
+	streamHeartbeat        = 15 * time.Second
+)
+
+// accountUpdate is the data of an "account" event. The first event carries the
+// current state only, the following ones the entry that changed the balance, or no
+// entry when a hold only changed the available balance.
+type accountUpdate struct {
+	Account accountResponse `json:"account"`
+	Entry   *entryResponse  `json:"entry,omitempty"`
+}
+
+// StreamAccount pushes the balance and new entries of an account as server-sent
+// events. The stream ends when the client goes away or falls too far behind,
+// clients should then reconnect to resynchronize. Members' access is checked again on
+// every change and heartbeat, the stream ends once they were removed.
+func (server *Server) StreamAccount(ctx *gin.Context) {
+	var req GetAccountRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	account, valid := server.getUserAccount(ctx, req.Number)
+	if !valid {
+		return
+	}
+
+	// reload after subscribing so no change falls between the snapshot and the stream
+	changes := server.accounts.SubscribeAccount(ctx.Request.Context(), account.ID)
+	account, err := server.store.GetAccount(ctx, account.ID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.SSEvent(accountStreamEvent, accountUpdate{Account: getAccountResponse(account)})
+	ctx.Writer.Flush()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case change, ok := <-changes:
+			if !ok || !server.stillMember(ctx, account) {
+				return false
+			}
+
+			account.Balance = change.Balance
+			account.HeldBalance = change.HeldBalance
+			update := accountUpdate{Account: getAccountResponse(account)}
+			if change.HasEntry() {
+				entry := getEntryResponse(change.Entry(), account)
+				update.Entry = &entry
+			}
+			ctx.SSEvent(accountStreamEvent, update)
+			return true
+		case <-heartbeat.C:
+			if !server.stillMember(ctx, account) {
+				return false
+			}
+			fmt.Fprint(w, ": heartbeat\n\n")
+			return true
+		case <-ctx.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// stillMember reports whether the authenticated user is still the owner or an active
+// member of a streamed account
+func (server *Server) stillMember(ctx *gin.Context, account db.Account) bool {
+	_, err := server.userMember(ctx, account)
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		ctx.Error(err)
+	}
+	return err == nil
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simple-bank/db/mock"
+	db "simple-bank/db/sqlc"
+	"simple-bank/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// readAccountUpdate reads the next "account" event, skipping heartbeats
+func readAccountUpdate(t *testing.T, reader *bufio.Reader) accountUpdate {
+	var event, data string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimPrefix(line, "event:")
+		case strings.HasPrefix(line, "data:"):
+			data = strings.TrimPrefix(line, "data:")
+		case line == "" && data != "":
+			require.Equal(t, accountStreamEvent, event)
+
+			var update accountUpdate
+			require.NoError(t, json.Unmarshal([]byte(data), &update))
+			return update
+		}
+	}
+}
+
+func TestStreamAccountAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+	hub := db.NewAccountHub()
+	server := NewTestServer(t, store)
+	server.accounts = hub
+	httpServer := httptest.NewServer(server.router)
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/accounts/%s/stream", httpServer.URL, account.Number), nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.True(t, strings.HasPrefix(response.Header.Get("Content-Type"), eventStreamContentType))
+
+	reader := bufio.NewReader(response.Body)
+
+	snapshot := readAccountUpdate(t, reader)
+	require.Equal(t, getAccountResponse(account), snapshot.Account)
+	require.Nil(t, snapshot.Entry)
+
+	// changes of other accounts are not streamed
+	hub.Publish(db.AccountChange{AccountID: account.ID + 1, Balance: 1, EntryID: 1, Amount: 1})
+
+	change := db.AccountChange{
+		AccountID: account.ID,
+		Balance:   account.Balance + 10,
+		EntryID:   utils.RandomInt(1, 1000),
+		Amount:    10,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	hub.Publish(change)
+
+	update := readAccountUpdate(t, reader)
+	require.Equal(t, account.Number, update.Account.Number)
+	require.Equal(t, change.Balance, update.Account.Balance)
+	require.NotNil(t, update.Entry)
+	require.Equal(t, change.EntryID, update.Entry.ID)
+	require.Equal(t, account.Number, update.Entry.Account)
+	require.Equal(t, change.Amount, update.Entry.Amount)
+	require.True(t, change.CreatedAt.Equal(update.Entry.CreatedAt))
+
+	// placing a hold only moves the available balance
+	hold := db.AccountChange{
+		AccountID:   account.ID,
+		Balance:     change.Balance,
+		HeldBalance: 25,
+		CreatedAt:   time.Now().UTC().Truncate(time.Second),
+	}
+	hub.Publish(hold)
+
+	update = readAccountUpdate(t, reader)
+	require.Equal(t, hold.Balance, update.Account.Balance)
+	require.Equal(t, hold.Balance-hold.HeldBalance, update.Account.AvailableBalance)
+	require.Nil(t, update.Entry)
+}
+
+func TestStreamAccountMemberRemoved(t *testing.T) {
+	member, _ := randomUser(t)
+	account := randomAccount(utils.RandomOwner())
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	gomock.InOrder(
+		store.EXPECT().
+			GetActiveAccountMember(gomock.Any(), gomock.Eq(db.GetActiveAccountMemberParams{AccountID: account.ID, Username: member.Username})).
+			Times(1).
+			Return(db.AccountMember{AccountID: account.ID, Username: member.Username, Role: db.MemberViewer, Status: db.MemberActive}, nil),
+		store.EXPECT().
+			GetActiveAccountMember(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(db.AccountMember{}, sql.ErrNoRows),
+	)
+
+	hub := db.NewAccountHub()
+	server := NewTestServer(t, store)
+	server.accounts = hub
+	httpServer := httptest.NewServer(server.router)
+	defer httpServer.Close()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/accounts/%s/stream", httpServer.URL, account.Number), nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, member.Username, time.Minute)
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	reader := bufio.NewReader(response.Body)
+	readAccountUpdate(t, reader)
+
+	// the member was removed before the next change, so the stream ends without it
+	hub.Publish(db.AccountChange{AccountID: account.ID, Balance: account.Balance + 10, EntryID: 1, Amount: 10})
+
+	rest, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NotContains(t, string(rest), "data:")
+}
+
+func TestStreamAccountOfAnotherUser(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(utils.RandomOwner())
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+	store.EXPECT().GetActiveAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%s/stream", account.Number), nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+	requireProblemCode(t, recorder, ErrAccountNotFound.Code)
+}
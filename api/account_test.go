@@ -8,6 +8,8 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	neturl "net/url"
+	"reflect"
 	mockdb "simple-bank/db/mock"
 	db "simple-bank/db/sqlc"
 	"simple-bank/token"
@@ -17,6 +19,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 )
 
@@ -25,21 +28,21 @@ func TestGetAccountApi(t *testing.T) {
 	account := randomAccount(user.Username)
 
 	testCases := []struct {
-		accountId     int64
+		accountNumber string
 		name          string
 		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
-			name:      "OK",
-			accountId: account.ID,
+			name:          "OK",
+			accountNumber: account.Number,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).
 					Times(1).
 					Return(account, nil)
 			},
@@ -49,30 +52,55 @@ func TestGetAccountApi(t *testing.T) {
 			},
 		},
 		{
-			name:      "NotFound",
-			accountId: account.ID,
+			name:          "NotFound",
+			accountNumber: account.Number,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).
 					Times(1).
 					Return(db.Account{}, sql.ErrNoRows)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
+
+				problem := requireProblemCode(t, recorder, ErrAccountNotFound.Code)
+				require.Equal(t, accountNotFound(account.Number).Detail, problem.Detail)
+			},
+		},
+		{
+			name:          "OtherUsersAccount",
+			accountNumber: account.Number,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "someoneelse", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().GetActiveAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+
+				// must be indistinguishable from an account that doesn't exist
+				problem := requireProblemCode(t, recorder, ErrAccountNotFound.Code)
+				require.Equal(t, accountNotFound(account.Number).Detail, problem.Detail)
+				require.NotContains(t, recorder.Body.String(), account.Owner)
 			},
 		},
 		{
-			name:      "InternalError",
-			accountId: account.ID,
+			name:          "InternalError",
+			accountNumber: account.Number,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).
 					Times(1).
 					Return(db.Account{}, sql.ErrConnDone)
 			},
@@ -81,14 +109,30 @@ func TestGetAccountApi(t *testing.T) {
 			},
 		},
 		{
-			name:      "BadRequest",
-			accountId: 0,
+			name:          "InvalidCheckDigits",
+			accountNumber: account.Number[:2] + "00" + account.Number[4:],
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					GetAccountByNumber(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, ErrValidationFailed.Code)
+			},
+		},
+		{
+			name:          "BadRequest",
+			accountNumber: "SB00000000000000",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -114,7 +158,7 @@ func TestGetAccountApi(t *testing.T) {
 			server := NewTestServer(t, store)
 			recorder := httptest.NewRecorder()
 
-			url := fmt.Sprintf("/accounts/%d", tc.accountId)
+			url := fmt.Sprintf("/accounts/%s", tc.accountNumber)
 			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
 
@@ -125,9 +169,147 @@ func TestGetAccountApi(t *testing.T) {
 	}
 }
 
+type eqCreateAccountParamMatcher struct {
+	args db.CreateAccountParams
+}
+
+// Matches ignores the generated account number as long as its check digits are valid
+func (e eqCreateAccountParamMatcher) Matches(x interface{}) bool {
+	args, ok := x.(db.CreateAccountParams)
+	if !ok {
+		return false
+	}
+
+	if !utils.ValidAccountNumber(args.Number) {
+		return false
+	}
+
+	e.args.Number = args.Number
+	return reflect.DeepEqual(e.args, args)
+}
+
+func (e eqCreateAccountParamMatcher) String() string {
+	return fmt.Sprintf("matches args %v with a valid account number", e.args)
+}
+
+func EqCreateAccountParam(args db.CreateAccountParams) gomock.Matcher {
+	return eqCreateAccountParamMatcher{args}
+}
+
+func TestGetAccountBalanceApi(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	at := time.Date(2026, 3, 31, 23, 59, 59, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		number        string
+		at            string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			number: account.Number,
+			at:     at.Format(time.RFC3339),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().GetBalanceAt(gomock.Any(), gomock.Eq(db.GetBalanceAtParams{
+					AccountID: account.ID,
+					At:        at,
+				})).Times(1).Return(int64(42), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res accountBalanceResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, account.Number, res.Number)
+				require.Equal(t, int64(42), res.Balance)
+				require.Equal(t, account.Currency, res.Currency)
+				require.True(t, at.Equal(res.At))
+			},
+		},
+		{
+			name:   "AccountOfAnotherUser",
+			number: account.Number,
+			at:     at.Format(time.RFC3339),
+			buildStubs: func(store *mockdb.MockStore) {
+				other := account
+				other.Owner = utils.RandomOwner()
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(other, nil)
+				store.EXPECT().GetActiveAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().GetBalanceAt(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, ErrAccountNotFound.Code)
+			},
+		},
+		{
+			name:   "FutureInstant",
+			number: account.Number,
+			at:     time.Now().Add(time.Hour).Format(time.RFC3339),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBalanceAt(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, ErrValidationFailed.Code)
+			},
+		},
+		{
+			name:   "MalformedInstant",
+			number: account.Number,
+			at:     "yesterday",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, ErrMalformedRequest.Code)
+			},
+		},
+		{
+			name:   "InternalError",
+			number: account.Number,
+			at:     at.Format(time.RFC3339),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().GetBalanceAt(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%s/balance?at=%s", tc.number, neturl.QueryEscape(tc.at))
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
 func TestCreateAccountApi(t *testing.T) {
 	user, _ := randomUser(t)
 	account := randomAccount(user.Username)
+	checking := db.AccountProduct{Code: db.ProductChecking}
+	savings := db.AccountProduct{Code: db.ProductSavings, Currencies: []string{utils.USD, utils.EUR}}
 
 	testCases := []struct {
 		name         string
@@ -146,12 +328,14 @@ func TestCreateAccountApi(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountProduct(gomock.Any(), gomock.Eq(db.ProductChecking)).Times(1).Return(checking, nil)
 				args := db.CreateAccountParams{
 					Owner:    account.Owner,
 					Currency: account.Currency,
 					Balance:  0,
+					Product:  db.ProductChecking,
 				}
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(args)).Times(1).Return(account, nil)
+				store.EXPECT().CreateAccountTxn(gomock.Any(), EqCreateAccountParam(args)).Times(1).Return(account, nil)
 			},
 			expectStatus: http.StatusCreated,
 		},
@@ -165,15 +349,151 @@ func TestCreateAccountApi(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountProduct(gomock.Any(), gomock.Eq(db.ProductChecking)).Times(1).Return(checking, nil)
 				args := db.CreateAccountParams{
 					Owner:    account.Owner,
 					Currency: account.Currency,
 					Balance:  0,
+					Product:  db.ProductChecking,
 				}
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(args)).Times(1).Return(db.Account{}, sql.ErrConnDone)
+				store.EXPECT().CreateAccountTxn(gomock.Any(), EqCreateAccountParam(args)).Times(1).Return(db.Account{}, sql.ErrConnDone)
 			},
 			expectStatus: http.StatusInternalServerError,
 		},
+		{
+			name: "DuplicateCurrency",
+			body: gin.H{
+				"Owner":    account.Owner,
+				"Currency": account.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountProduct(gomock.Any(), gomock.Eq(db.ProductChecking)).Times(1).Return(checking, nil)
+				store.EXPECT().
+					CreateAccountTxn(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, &pq.Error{Code: "23505", Constraint: "owner_currency_product_key"})
+			},
+			expectStatus: http.StatusConflict,
+		},
+		{
+			name: "AccountNumberTaken",
+			body: gin.H{
+				"Currency": account.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountProduct(gomock.Any(), gomock.Eq(db.ProductChecking)).Times(1).Return(checking, nil)
+				var numbers []string
+				gomock.InOrder(
+					store.EXPECT().
+						CreateAccountTxn(gomock.Any(), gomock.Any()).
+						Times(1).
+						DoAndReturn(func(_ any, args db.CreateAccountParams) (db.Account, error) {
+							numbers = append(numbers, args.Number)
+							return db.Account{}, &pq.Error{Code: "23505", Constraint: db.AccountNumberKey}
+						}),
+					store.EXPECT().
+						CreateAccountTxn(gomock.Any(), gomock.Any()).
+						Times(1).
+						DoAndReturn(func(_ any, args db.CreateAccountParams) (db.Account, error) {
+							require.NotEqual(t, numbers[0], args.Number)
+							return account, nil
+						}),
+				)
+			},
+			expectStatus: http.StatusCreated,
+		},
+		{
+			name: "AccountNumbersExhausted",
+			body: gin.H{
+				"Currency": account.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountProduct(gomock.Any(), gomock.Eq(db.ProductChecking)).Times(1).Return(checking, nil)
+				store.EXPECT().
+					CreateAccountTxn(gomock.Any(), gomock.Any()).
+					Times(utils.AccountNumberAttempts).
+					Return(db.Account{}, &pq.Error{Code: "23505", Constraint: db.AccountNumberKey})
+			},
+			expectStatus: http.StatusInternalServerError,
+		},
+		{
+			name: "UnknownOwner",
+			body: gin.H{
+				"Owner":    account.Owner,
+				"Currency": account.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountProduct(gomock.Any(), gomock.Eq(db.ProductChecking)).Times(1).Return(checking, nil)
+				store.EXPECT().
+					CreateAccountTxn(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, &pq.Error{Code: "23503", Constraint: "accounts_owner_fkey"})
+			},
+			expectStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "Savings",
+			body: gin.H{
+				"Currency": utils.USD,
+				"Product":  db.ProductSavings,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountProduct(gomock.Any(), gomock.Eq(db.ProductSavings)).Times(1).Return(savings, nil)
+				args := db.CreateAccountParams{
+					Owner:    account.Owner,
+					Currency: utils.USD,
+					Balance:  0,
+					Product:  db.ProductSavings,
+				}
+				store.EXPECT().CreateAccountTxn(gomock.Any(), EqCreateAccountParam(args)).Times(1).Return(account, nil)
+			},
+			expectStatus: http.StatusCreated,
+		},
+		{
+			name: "CurrencyNotOffered",
+			body: gin.H{
+				"Currency": utils.INR,
+				"Product":  db.ProductSavings,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountProduct(gomock.Any(), gomock.Eq(db.ProductSavings)).Times(1).Return(savings, nil)
+				store.EXPECT().CreateAccountTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "UnknownProduct",
+			body: gin.H{
+				"Currency": utils.USD,
+				"Product":  "premium",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountProduct(gomock.Any(), gomock.Eq("premium")).Times(1).Return(db.AccountProduct{}, sql.ErrNoRows)
+				store.EXPECT().CreateAccountTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectStatus: http.StatusUnprocessableEntity,
+		},
 		{
 			name: "InvalidCurrency",
 			body: gin.H{
@@ -188,8 +508,9 @@ func TestCreateAccountApi(t *testing.T) {
 					Owner:    account.Owner,
 					Currency: account.Currency,
 					Balance:  0,
+					Product:  db.ProductChecking,
 				}
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(args)).Times(0)
+				store.EXPECT().CreateAccountTxn(gomock.Any(), EqCreateAccountParam(args)).Times(0)
 			},
 			expectStatus: http.StatusBadRequest,
 		},
@@ -256,9 +577,9 @@ func TestListAccountApi(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				args := db.ListAccountParams{
-					Owner:  user.Username,
-					Limit:  int32(n),
-					Offset: 0,
+					Username: user.Username,
+					Limit:    int32(n),
+					Offset:   0,
 				}
 				store.EXPECT().ListAccount(gomock.Any(), gomock.Eq(args)).Times(1).Return(accounts, nil)
 			},
@@ -317,9 +638,9 @@ func TestListAccountApi(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				args := db.ListAccountParams{
-					Owner:  user.Username,
-					Limit:  int32(n),
-					Offset: 0,
+					Username: user.Username,
+					Limit:    int32(n),
+					Offset:   0,
 				}
 				store.EXPECT().ListAccount(gomock.Any(), gomock.Eq(args)).Times(1).Return([]db.Account{}, sql.ErrConnDone)
 			},
@@ -362,9 +683,11 @@ func TestListAccountApi(t *testing.T) {
 func randomAccount(owner string) db.Account {
 	return db.Account{
 		ID:        utils.RandomInt(1, 1000),
+		Number:    utils.RandomAccountNumber(),
 		Owner:     owner,
 		Balance:   utils.RandomMoney(),
 		Currency:  utils.RandomCurrency(),
+		Product:   db.ProductChecking,
 		CreatedAt: time.Now().UTC(),
 	}
 }
@@ -373,10 +696,15 @@ func requireBodyMatchAccount(t *testing.T, body *bytes.Buffer, account db.Accoun
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
 
-	var gotAccount db.Account
+	var gotAccount accountResponse
 
 	err = json.Unmarshal(data, &gotAccount)
 	require.NoError(t, err)
 
-	require.Equal(t, account, gotAccount)
+	require.Equal(t, getAccountResponse(account), gotAccount)
+
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(data, &fields))
+	require.NotContains(t, fields, "id")
+	require.NotContains(t, fields, "ID")
 }
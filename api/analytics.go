@@ -0,0 +1,154 @@
+package api
+
+import (
+	"net/http"
+	db "simple-bank/db/sqlc"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxAnalyticsDays caps the range of an analytics request, about two years
+	maxAnalyticsDays = 731
+	// defaultTopCounterparties is how many counterparties analytics return unless asked
+	defaultTopCounterparties = 5
+)
+
+// analyticsRequest selects the days from From to To, both included. To defaults to
+// today and From to 30 days, 12 weeks or 12 months before it.
+type analyticsRequest struct {
+	GetAccountRequest
+	Granularity string    `form:"granularity" binding:"omitempty,oneof=day week month"`
+	From        time.Time `form:"from" binding:"omitempty" time_format:"2006-01-02" time_utc:"1"`
+	To          time.Time `form:"to" binding:"omitempty" time_format:"2006-01-02" time_utc:"1"`
+	Top         int32     `form:"top" binding:"omitempty,min=1,max=20"`
+}
+
+// analyticsBucketResponse covers the day, ISO week or month starting on Start, in UTC
+type analyticsBucketResponse struct {
+	Start        string `json:"start"`
+	Inflow       int64  `json:"inflow"`
+	Outflow      int64  `json:"outflow"`
+	Net          int64  `json:"net"`
+	Transactions int64  `json:"transactions"`
+}
+
+type counterpartyResponse struct {
+	Account   string `json:"account"`
+	Transfers int64  `json:"transfers"`
+	Sent      int64  `json:"sent"`
+	Received  int64  `json:"received"`
+}
+
+type analyticsResponse struct {
+	Account           string                    `json:"account"`
+	Currency          string                    `json:"currency"`
+	Granularity       string                    `json:"granularity"`
+	From              string                    `json:"from"`
+	To                string                    `json:"to"`
+	Buckets           []analyticsBucketResponse `json:"buckets"`
+	TopCounterparties []counterpartyResponse    `json:"top_counterparties"`
+}
+
+// GetAccountAnalytics returns the inflow, outflow, net and transaction count of an account
+// per day, week or month, every bucket of the range included, along with the accounts it
+// exchanged the most money with. Both are aggregated by the database.
+func (server *Server) GetAccountAnalytics(ctx *gin.Context) {
+	var req analyticsRequest
+
+	if err := ctx.ShouldBindUri(&req.GetAccountRequest); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	if req.Granularity == "" {
+		req.Granularity = "day"
+	}
+	if req.Top == 0 {
+		req.Top = defaultTopCounterparties
+	}
+	if req.To.IsZero() {
+		req.To = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+	if req.From.IsZero() {
+		switch req.Granularity {
+		case "day":
+			req.From = req.To.AddDate(0, 0, -29)
+		case "week":
+			req.From = req.To.AddDate(0, 0, -12*7+1)
+		case "month":
+			req.From = req.To.AddDate(0, -12, 1)
+		}
+	}
+
+	switch {
+	case req.To.Before(req.From):
+		errorResponse(ctx, ErrValidationFailed.withDetail("to must not be before from"))
+		return
+	case req.To.Sub(req.From) >= maxAnalyticsDays*24*time.Hour:
+		errorResponse(ctx, ErrValidationFailed.withDetail("the range can't be longer than 731 days"))
+		return
+	}
+
+	account, valid := server.getUserAccount(ctx, req.Number)
+	if !valid {
+		return
+	}
+
+	to := req.To.AddDate(0, 0, 1)
+	buckets, err := server.store.ListAccountActivity(ctx, db.ListAccountActivityParams{
+		AccountID:   account.ID,
+		FromTime:    req.From,
+		ToTime:      to,
+		Granularity: req.Granularity,
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	counterparties, err := server.store.ListTopCounterparties(ctx, db.ListTopCounterpartiesParams{
+		AccountID: account.ID,
+		FromTime:  req.From,
+		ToTime:    to,
+		Limit:     req.Top,
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	res := analyticsResponse{
+		Account:           account.Number,
+		Currency:          account.Currency,
+		Granularity:       req.Granularity,
+		From:              req.From.Format(dateFormat),
+		To:                req.To.Format(dateFormat),
+		Buckets:           make([]analyticsBucketResponse, len(buckets)),
+		TopCounterparties: make([]counterpartyResponse, len(counterparties)),
+	}
+	for i, bucket := range buckets {
+		res.Buckets[i] = analyticsBucketResponse{
+			Start:        bucket.BucketStart.UTC().Format(dateFormat),
+			Inflow:       bucket.Inflow,
+			Outflow:      bucket.Outflow,
+			Net:          bucket.Net,
+			Transactions: bucket.Transactions,
+		}
+	}
+	for i, counterparty := range counterparties {
+		res.TopCounterparties[i] = counterpartyResponse{
+			Account:   counterparty.Number,
+			Transfers: counterparty.Transfers,
+			Sent:      counterparty.Sent,
+			Received:  counterparty.Received,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simple-bank/db/mock"
+	db "simple-bank/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountAnalyticsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	counterparty := randomAccount(user.Username)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "Defaults",
+			query: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().
+					ListAccountActivity(gomock.Any(), gomock.Eq(db.ListAccountActivityParams{
+						AccountID:   account.ID,
+						FromTime:    today.AddDate(0, 0, -29),
+						ToTime:      today.AddDate(0, 0, 1),
+						Granularity: "day",
+					})).
+					Times(1)
+				store.EXPECT().
+					ListTopCounterparties(gomock.Any(), gomock.Eq(db.ListTopCounterpartiesParams{
+						AccountID: account.ID,
+						FromTime:  today.AddDate(0, 0, -29),
+						ToTime:    today.AddDate(0, 0, 1),
+						Limit:     defaultTopCounterparties,
+					})).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res analyticsResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, "day", res.Granularity)
+				require.Equal(t, today.Format(dateFormat), res.To)
+				require.NotNil(t, res.Buckets)
+				require.NotNil(t, res.TopCounterparties)
+			},
+		},
+		{
+			name:  "Weekly",
+			query: "granularity=week&from=2024-03-04&to=2024-03-17&top=1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().
+					ListAccountActivity(gomock.Any(), gomock.Eq(db.ListAccountActivityParams{
+						AccountID:   account.ID,
+						FromTime:    time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+						ToTime:      time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC),
+						Granularity: "week",
+					})).
+					Times(1).
+					Return([]db.ListAccountActivityRow{
+						{BucketStart: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), Inflow: 100, Outflow: 30, Net: 70, Transactions: 3},
+						{BucketStart: time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)},
+					}, nil)
+				store.EXPECT().
+					ListTopCounterparties(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListTopCounterpartiesRow{
+						{Number: counterparty.Number, Transfers: 2, Sent: 30, Received: 100},
+					}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res analyticsResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res.Buckets, 2)
+				require.Equal(t, analyticsBucketResponse{Start: "2024-03-04", Inflow: 100, Outflow: 30, Net: 70, Transactions: 3}, res.Buckets[0])
+				require.Equal(t, "2024-03-11", res.Buckets[1].Start)
+				require.Zero(t, res.Buckets[1].Transactions)
+				require.Equal(t, []counterpartyResponse{{Account: counterparty.Number, Transfers: 2, Sent: 30, Received: 100}}, res.TopCounterparties)
+			},
+		},
+		{
+			name:  "InvalidGranularity",
+			query: "granularity=hour",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountActivity(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				problem := requireProblemCode(t, recorder, ErrValidationFailed.Code)
+				require.Equal(t, "granularity", problem.Errors[0].Field)
+			},
+		},
+		{
+			name:  "ToBeforeFrom",
+			query: "from=2024-03-31&to=2024-03-01",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountActivity(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, ErrValidationFailed.Code)
+			},
+		},
+		{
+			name:  "RangeTooLong",
+			query: "granularity=month&from=2020-01-01&to=2024-12-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountActivity(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, ErrValidationFailed.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%s/analytics?%s", account.Number, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	db "simple-bank/db/sqlc"
+	"simple-bank/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Batch modes
+const (
+	batchAllOrNothing = "all_or_nothing"
+	batchBestEffort   = "best_effort"
+)
+
+// Batch item statuses. In an all-or-nothing batch that failed, the transfers before the
+// failed one are rolled back and the ones after it are skipped.
+const (
+	batchItemSucceeded  = "succeeded"
+	batchItemFailed     = "failed"
+	batchItemRolledBack = "rolled_back"
+	batchItemSkipped    = "skipped"
+)
+
+type batchTransferItem struct {
+	ToAccountId string `json:"toAccountId" binding:"required,account_number"`
+	Amount      int64  `json:"amount" binding:"required,gt=0"`
+}
+
+type batchTransferRequest struct {
+	FromAccountId string              `json:"fromAccountId" binding:"required,account_number"`
+	Currency      string              `json:"currency" binding:"required,currency"`
+	Mode          string              `json:"mode" binding:"required,oneof=all_or_nothing best_effort"`
+	Transfers     []batchTransferItem `json:"transfers" binding:"required,min=1,max=100,dive"`
+}
+
+// batchItemError is the problem a transfer of a batch ran into, Code is from the error catalogue
+type batchItemError struct {
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+type batchTransferItemResponse struct {
+	Index    int               `json:"index"`
+	Status   string            `json:"status"`
+	Transfer *transferResponse `json:"transfer,omitempty"`
+	Error    *batchItemError   `json:"error,omitempty"`
+}
+
+type batchTransferResponse struct {
+	Mode        string                      `json:"mode"`
+	Succeeded   int                         `json:"succeeded"`
+	Failed      int                         `json:"failed"`
+	FromAccount accountResponse             `json:"from_account"`
+	Transfers   []batchTransferItemResponse `json:"transfers"`
+}
+
+// CreateBatchTransfer sends up to 100 transfers from one account. Every destination is
+// checked before any money moves and all the invalid ones are reported together.
+// The batch is answered with the outcome of each transfer, in request order.
+func (server *Server) CreateBatchTransfer(ctx *gin.Context) {
+	var req batchTransferRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	// a spender's limit applies to each transfer of the batch
+	var largest int64
+	for _, item := range req.Transfers {
+		largest = max(largest, item.Amount)
+	}
+
+	fromAccount, valid := server.getSpendingAccount(ctx, req.FromAccountId, largest)
+	if !valid || !checkCurrency(ctx, fromAccount, req.Currency) {
+		return
+	}
+
+	toAccounts, valid := server.batchToAccounts(ctx, req)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	args := db.BatchTransferTxnParams{
+		FromAccountID: fromAccount.ID,
+		Transfers:     make([]db.BatchTransfer, len(req.Transfers)),
+		AllOrNothing:  req.Mode == batchAllOrNothing,
+		Sender:        authPayload.Username,
+		CoolingOff:    server.payeeCoolingOff(),
+	}
+	for i, item := range req.Transfers {
+		args.Transfers[i] = db.BatchTransfer{
+			ToAccountID: toAccounts[i].ID,
+			Amount:      item.Amount,
+		}
+	}
+
+	res := batchTransferResponse{
+		Mode:        req.Mode,
+		FromAccount: getAccountResponse(fromAccount),
+		Transfers:   make([]batchTransferItemResponse, len(req.Transfers)),
+	}
+
+	result, err := server.store.BatchTransferTxn(ctx, args)
+	var batchErr *db.BatchTransferError
+	if errors.As(err, &batchErr) {
+		for i := range res.Transfers {
+			res.Transfers[i] = batchTransferItemResponse{Index: i, Status: batchItemRolledBack}
+			if i > batchErr.Index {
+				res.Transfers[i].Status = batchItemSkipped
+			}
+		}
+		res.Transfers[batchErr.Index] = getBatchItemFailure(ctx, batchErr.Index, batchErr.Err)
+		res.Failed = 1
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	for i, item := range result.Results {
+		if item.Err != nil {
+			res.Transfers[i] = getBatchItemFailure(ctx, i, item.Err)
+			res.Failed++
+			continue
+		}
+
+		transfer := getTransferTxnResponse(item.Transfer)
+		res.Transfers[i] = batchTransferItemResponse{
+			Index:    i,
+			Status:   batchItemSucceeded,
+			Transfer: &transfer.Transfer,
+		}
+		res.FromAccount = transfer.FromAccount
+		res.Succeeded++
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// batchToAccounts looks up the destinations of a batch in a single query, failing with
+// ErrBatchInvalid listing every transfer whose account is missing, in another currency
+// or a payee still cooling off
+func (server *Server) batchToAccounts(ctx *gin.Context, req batchTransferRequest) ([]db.Account, bool) {
+	numbers := make([]string, len(req.Transfers))
+	for i, item := range req.Transfers {
+		numbers[i] = item.ToAccountId
+	}
+
+	accounts, err := server.store.ListAccountsByNumbers(ctx, numbers)
+	if err != nil {
+		errorResponse(ctx, err)
+		return nil, false
+	}
+	byNumber := make(map[string]db.Account, len(accounts))
+	for _, account := range accounts {
+		byNumber[account.Number] = account
+	}
+
+	payees, err := server.coolingOffPayees(ctx, req)
+	if err != nil {
+		errorResponse(ctx, err)
+		return nil, false
+	}
+
+	toAccounts := make([]db.Account, len(req.Transfers))
+	var violations []FieldViolation
+	for i, item := range req.Transfers {
+		field := fmt.Sprintf("transfers[%d].toAccountId", i)
+
+		account, ok := byNumber[item.ToAccountId]
+		switch {
+		case !ok:
+			violations = append(violations, FieldViolation{
+				Field:   field,
+				Rule:    "exists",
+				Message: fmt.Sprintf("account [%s] not found", item.ToAccountId),
+			})
+		case account.Currency != req.Currency:
+			violations = append(violations, FieldViolation{
+				Field:   field,
+				Rule:    "currency",
+				Message: fmt.Sprintf("account [%s] currency mismatch: %s vs %s", account.Number, account.Currency, req.Currency),
+			})
+		default:
+			payee, ok := payees[account.ID]
+			if !ok {
+				break
+			}
+			if err := server.payeeCoolingOff().Check(payee.ID, payee.CreatedAt, item.Amount); err != nil {
+				violations = append(violations, FieldViolation{
+					Field:   field,
+					Rule:    "cooling_off",
+					Message: err.Error(),
+				})
+			}
+		}
+		toAccounts[i] = account
+	}
+
+	if len(violations) > 0 {
+		apiErr := ErrBatchInvalid.withDetail(fmt.Sprintf("%d of %d transfers are invalid", len(violations), len(req.Transfers)))
+		apiErr.Fields = violations
+		errorResponse(ctx, apiErr)
+		return nil, false
+	}
+
+	return toAccounts, true
+}
+
+// coolingOffPayees returns the authenticated user's payees by account ID, when any
+// transfer of the batch is large enough for their cooling-off period to matter
+func (server *Server) coolingOffPayees(ctx *gin.Context, req batchTransferRequest) (map[int64]db.ListPayeesRow, error) {
+	applies := false
+	for _, item := range req.Transfers {
+		applies = applies || server.payeeCoolingOff().Applies(item.Amount)
+	}
+	if !applies {
+		return nil, nil
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	payees, err := server.store.ListPayees(ctx, authPayload.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	byAccount := make(map[int64]db.ListPayeesRow, len(payees))
+	for _, payee := range payees {
+		byAccount[payee.AccountID] = payee
+	}
+	return byAccount, nil
+}
+
+func getBatchItemFailure(ctx *gin.Context, index int, err error) batchTransferItemResponse {
+	apiErr := toAPIError(err)
+	if apiErr.Status >= http.StatusInternalServerError {
+		ctx.Error(err)
+	}
+
+	return batchTransferItemResponse{
+		Index:  index,
+		Status: batchItemFailed,
+		Error: &batchItemError{
+			Code:   apiErr.Code,
+			Detail: apiErr.Error(),
+		},
+	}
+}
@@ -0,0 +1,255 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simple-bank/db/mock"
+	db "simple-bank/db/sqlc"
+	"simple-bank/utils"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBatchTransferAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	user3, _ := randomUser(t)
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account3 := randomAccount(user3.Username)
+	account1.Currency = utils.USD
+	account2.Currency = utils.USD
+	account3.Currency = utils.USD
+	unknownNumber := utils.RandomAccountNumber()
+
+	body := func(mode string) gin.H {
+		return gin.H{
+			"fromAccountId": account1.Number,
+			"currency":      utils.USD,
+			"mode":          mode,
+			"transfers": []gin.H{
+				{"toAccountId": account2.Number, "amount": 10},
+				{"toAccountId": account3.Number, "amount": 20},
+				{"toAccountId": account2.Number, "amount": 30},
+			},
+		}
+	}
+
+	lookup := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+		store.EXPECT().
+			ListAccountsByNumbers(gomock.Any(), gomock.Eq([]string{account2.Number, account3.Number, account2.Number})).
+			Times(1).
+			Return([]db.Account{account2, account3}, nil)
+	}
+
+	transferResult := func(to db.Account, amount int64) db.TransferTxnResult {
+		from := account1
+		from.Balance -= amount
+		return db.TransferTxnResult{
+			Transfer:    db.Transfer{ID: utils.RandomInt(1, 1000), FromAccountID: account1.ID, ToAccountID: to.ID, Amount: amount},
+			FromAccount: from,
+			ToAccount:   to,
+		}
+	}
+
+	decode := func(t *testing.T, recorder *httptest.ResponseRecorder) batchTransferResponse {
+		var res batchTransferResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+		return res
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "AllOrNothing",
+			body: body(batchAllOrNothing),
+			buildStubs: func(store *mockdb.MockStore) {
+				lookup(store)
+				store.EXPECT().
+					BatchTransferTxn(gomock.Any(), gomock.Eq(db.BatchTransferTxnParams{
+						FromAccountID: account1.ID,
+						Transfers: []db.BatchTransfer{
+							{ToAccountID: account2.ID, Amount: 10},
+							{ToAccountID: account3.ID, Amount: 20},
+							{ToAccountID: account2.ID, Amount: 30},
+						},
+						AllOrNothing: true,
+						Sender:       user1.Username,
+					})).
+					Times(1).
+					Return(db.BatchTransferTxnResult{Results: []db.BatchTransferResult{
+						{Transfer: transferResult(account2, 10)},
+						{Transfer: transferResult(account3, 30)},
+						{Transfer: transferResult(account2, 60)},
+					}}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				res := decode(t, recorder)
+				require.Equal(t, 3, res.Succeeded)
+				require.Zero(t, res.Failed)
+				require.Equal(t, account1.Balance-60, res.FromAccount.Balance)
+				require.Len(t, res.Transfers, 3)
+				for i, item := range res.Transfers {
+					require.Equal(t, i, item.Index)
+					require.Equal(t, batchItemSucceeded, item.Status)
+					require.NotNil(t, item.Transfer)
+					require.Nil(t, item.Error)
+				}
+				require.Equal(t, account3.Number, res.Transfers[1].Transfer.ToAccount)
+			},
+		},
+		{
+			name: "AllOrNothingRolledBack",
+			body: body(batchAllOrNothing),
+			buildStubs: func(store *mockdb.MockStore) {
+				lookup(store)
+				store.EXPECT().
+					BatchTransferTxn(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.BatchTransferTxnResult{}, &db.BatchTransferError{Index: 1, Err: db.ErrInsufficientFunds})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				res := decode(t, recorder)
+				require.Zero(t, res.Succeeded)
+				require.Equal(t, 1, res.Failed)
+				require.Equal(t, account1.Balance, res.FromAccount.Balance)
+				require.Equal(t, batchItemRolledBack, res.Transfers[0].Status)
+				require.Equal(t, batchItemFailed, res.Transfers[1].Status)
+				require.Equal(t, ErrInsufficientFunds.Code, res.Transfers[1].Error.Code)
+				require.Equal(t, batchItemSkipped, res.Transfers[2].Status)
+			},
+		},
+		{
+			name: "BestEffort",
+			body: body(batchBestEffort),
+			buildStubs: func(store *mockdb.MockStore) {
+				lookup(store)
+				store.EXPECT().
+					BatchTransferTxn(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, args db.BatchTransferTxnParams) (db.BatchTransferTxnResult, error) {
+						require.False(t, args.AllOrNothing)
+						return db.BatchTransferTxnResult{Results: []db.BatchTransferResult{
+							{Transfer: transferResult(account2, 10)},
+							{Err: &db.LimitError{Kind: db.LimitDaily, Limit: 25, Used: 10}},
+							{Transfer: transferResult(account2, 40)},
+						}}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				res := decode(t, recorder)
+				require.Equal(t, 2, res.Succeeded)
+				require.Equal(t, 1, res.Failed)
+				require.Equal(t, account1.Balance-40, res.FromAccount.Balance)
+				require.Equal(t, batchItemSucceeded, res.Transfers[0].Status)
+				require.Equal(t, batchItemFailed, res.Transfers[1].Status)
+				require.Nil(t, res.Transfers[1].Transfer)
+				require.Equal(t, ErrDailyLimit.Code, res.Transfers[1].Error.Code)
+				require.Equal(t, batchItemSucceeded, res.Transfers[2].Status)
+			},
+		},
+		{
+			name: "InvalidDestinations",
+			body: gin.H{
+				"fromAccountId": account1.Number,
+				"currency":      utils.USD,
+				"mode":          batchBestEffort,
+				"transfers": []gin.H{
+					{"toAccountId": account2.Number, "amount": 10},
+					{"toAccountId": account3.Number, "amount": 20},
+					{"toAccountId": unknownNumber, "amount": 30},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				eur := account3
+				eur.Currency = utils.EUR
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().ListAccountsByNumbers(gomock.Any(), gomock.Any()).Times(1).Return([]db.Account{account2, eur}, nil)
+				store.EXPECT().BatchTransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				problem := requireProblemCode(t, recorder, ErrBatchInvalid.Code)
+				require.Len(t, problem.Errors, 2)
+				require.Equal(t, "transfers[1].toAccountId", problem.Errors[0].Field)
+				require.Equal(t, "currency", problem.Errors[0].Rule)
+				require.Equal(t, "transfers[2].toAccountId", problem.Errors[1].Field)
+				require.Equal(t, "exists", problem.Errors[1].Rule)
+			},
+		},
+		{
+			name: "InvalidItem",
+			body: gin.H{
+				"fromAccountId": account1.Number,
+				"currency":      utils.USD,
+				"mode":          batchBestEffort,
+				"transfers": []gin.H{
+					{"toAccountId": account2.Number, "amount": 10},
+					{"toAccountId": account3.Number, "amount": 0},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByNumbers(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().BatchTransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				problem := requireProblemCode(t, recorder, ErrValidationFailed.Code)
+				require.Equal(t, "transfers[1].amount", problem.Errors[0].Field)
+			},
+		},
+		{
+			name: "InvalidMode",
+			body: body("some"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BatchTransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				problem := requireProblemCode(t, recorder, ErrValidationFailed.Code)
+				require.Equal(t, "mode", problem.Errors[0].Field)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfer/batch", bytes.NewReader(body))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
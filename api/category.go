@@ -0,0 +1,597 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	db "simple-bank/db/sqlc"
+	"simple-bank/token"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createCategoryRequest struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+type categoryResponse struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func getCategoryResponse(category db.Category) categoryResponse {
+	return categoryResponse{
+		ID:        category.ID,
+		Name:      category.Name,
+		CreatedAt: category.CreatedAt,
+	}
+}
+
+// CreateCategory adds a category for the authenticated user, names are unique per user
+func (server *Server) CreateCategory(ctx *gin.Context) {
+	var req createCategoryRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	category, err := server.store.CreateCategory(ctx, db.CreateCategoryParams{
+		Owner: authPayload.Username,
+		Name:  strings.TrimSpace(req.Name),
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, getCategoryResponse(category))
+}
+
+// ListCategories lists the categories of the authenticated user by name
+func (server *Server) ListCategories(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	categories, err := server.store.ListCategories(ctx, authPayload.Username)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	res := make([]categoryResponse, len(categories))
+	for i, category := range categories {
+		res[i] = getCategoryResponse(category)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type categoryRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// DeleteCategory deletes a category along with its rules, its entries become uncategorized
+func (server *Server) DeleteCategory(ctx *gin.Context) {
+	var req categoryRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	if _, valid := server.getUserCategory(ctx, req.ID); !valid {
+		return
+	}
+
+	if err := server.store.DeleteCategory(ctx, req.ID); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// createCategoryRuleRequest needs at least one condition, all the ones set must match
+type createCategoryRuleRequest struct {
+	CategoryID          int64  `json:"category_id" binding:"required,min=1"`
+	CounterpartyAccount string `json:"counterparty_account" binding:"omitempty,account_number"`
+	MemoKeyword         string `json:"memo_keyword" binding:"omitempty,max=50"`
+	MinAmount           int64  `json:"min_amount" binding:"omitempty,gt=0"`
+	MaxAmount           int64  `json:"max_amount" binding:"omitempty,gtefield=MinAmount"`
+	Priority            int32  `json:"priority" binding:"omitempty,min=0"`
+}
+
+type categoryRuleResponse struct {
+	ID                  int64     `json:"id"`
+	CategoryID          int64     `json:"category_id"`
+	Category            string    `json:"category"`
+	CounterpartyAccount *string   `json:"counterparty_account"`
+	MemoKeyword         *string   `json:"memo_keyword"`
+	MinAmount           *int64    `json:"min_amount"`
+	MaxAmount           *int64    `json:"max_amount"`
+	Priority            int32     `json:"priority"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+func getCategoryRuleResponse(rule db.ListCategoryRulesRow) categoryRuleResponse {
+	return categoryRuleResponse{
+		ID:                  rule.ID,
+		CategoryID:          rule.CategoryID,
+		Category:            rule.CategoryName,
+		CounterpartyAccount: nullString(rule.CounterpartyAccountNumber),
+		MemoKeyword:         nullString(rule.MemoKeyword),
+		MinAmount:           nullInt64(rule.MinAmount),
+		MaxAmount:           nullInt64(rule.MaxAmount),
+		Priority:            rule.Priority,
+		CreatedAt:           rule.CreatedAt,
+	}
+}
+
+// CreateCategoryRule adds a rule categorizing the authenticated user's entries as they
+// are booked. The matching rule with the lowest priority wins.
+func (server *Server) CreateCategoryRule(ctx *gin.Context) {
+	var req createCategoryRuleRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	if req.CounterpartyAccount == "" && req.MemoKeyword == "" && req.MinAmount == 0 && req.MaxAmount == 0 {
+		errorResponse(ctx, ErrValidationFailed.withDetail("a rule needs at least one of counterparty_account, memo_keyword, min_amount or max_amount"))
+		return
+	}
+
+	category, valid := server.getUserCategory(ctx, req.CategoryID)
+	if !valid {
+		return
+	}
+
+	var counterparty db.Account
+	if req.CounterpartyAccount != "" {
+		var err error
+		counterparty, err = server.store.GetAccountByNumber(ctx, req.CounterpartyAccount)
+		if err != nil {
+			if errors.Is(err, db.ErrRecordNotFound) {
+				err = accountNotFound(req.CounterpartyAccount)
+			}
+			errorResponse(ctx, err)
+			return
+		}
+	}
+
+	rule, err := server.store.CreateCategoryRule(ctx, db.CreateCategoryRuleParams{
+		Owner:                 category.Owner,
+		CategoryID:            category.ID,
+		CounterpartyAccountID: sql.NullInt64{Int64: counterparty.ID, Valid: counterparty.ID != 0},
+		MemoKeyword:           sql.NullString{String: req.MemoKeyword, Valid: req.MemoKeyword != ""},
+		MinAmount:             sql.NullInt64{Int64: req.MinAmount, Valid: req.MinAmount != 0},
+		MaxAmount:             sql.NullInt64{Int64: req.MaxAmount, Valid: req.MaxAmount != 0},
+		Priority:              req.Priority,
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, getCategoryRuleResponse(db.ListCategoryRulesRow{
+		ID:                        rule.ID,
+		Owner:                     rule.Owner,
+		CategoryID:                rule.CategoryID,
+		CounterpartyAccountID:     rule.CounterpartyAccountID,
+		MemoKeyword:               rule.MemoKeyword,
+		MinAmount:                 rule.MinAmount,
+		MaxAmount:                 rule.MaxAmount,
+		Priority:                  rule.Priority,
+		CreatedAt:                 rule.CreatedAt,
+		CategoryName:              category.Name,
+		CounterpartyAccountNumber: sql.NullString{String: counterparty.Number, Valid: counterparty.ID != 0},
+	}))
+}
+
+// ListCategoryRules lists the rules of the authenticated user in the order they're tried
+func (server *Server) ListCategoryRules(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	rules, err := server.store.ListCategoryRules(ctx, authPayload.Username)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	res := make([]categoryRuleResponse, len(rules))
+	for i, rule := range rules {
+		res[i] = getCategoryRuleResponse(rule)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type categoryRuleRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// DeleteCategoryRule stops a rule from categorizing new entries, entries it already
+// categorized keep their category
+func (server *Server) DeleteCategoryRule(ctx *gin.Context) {
+	var req categoryRuleRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	rule, err := server.store.GetCategoryRule(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = categoryRuleNotFound(req.ID)
+		}
+		errorResponse(ctx, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if rule.Owner != authPayload.Username {
+		errorResponse(ctx, categoryRuleNotFound(req.ID))
+		return
+	}
+
+	if err := server.store.DeleteCategoryRule(ctx, req.ID); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type categorizedEntryResponse struct {
+	ID         int64     `json:"id"`
+	Amount     int64     `json:"amount"`
+	TransferID *int64    `json:"transfer_id"`
+	CategoryID *int64    `json:"category_id"`
+	Category   string    `json:"category"`
+	Tags       []string  `json:"tags"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func getCategorizedEntryResponse(entry db.Entry, category string) categorizedEntryResponse {
+	return categorizedEntryResponse{
+		ID:         entry.ID,
+		Amount:     entry.Amount,
+		TransferID: nullInt64(entry.TransferID),
+		CategoryID: nullInt64(entry.CategoryID),
+		Category:   category,
+		Tags:       entry.Tags,
+		CreatedAt:  entry.CreatedAt,
+	}
+}
+
+type listAccountEntriesRequest struct {
+	GetAccountRequest
+	CategoryID int64  `form:"category_id" binding:"omitempty,min=1"`
+	Tag        string `form:"tag" binding:"omitempty,max=30"`
+	PageID     int32  `form:"page_id" binding:"required,min=1"`
+	PageSize   int32  `form:"page_size" binding:"required,min=1,max=50"`
+}
+
+// ListAccountEntries lists the entries of an account with their category and tags,
+// newest first, optionally only the ones in a category or with a tag
+func (server *Server) ListAccountEntries(ctx *gin.Context) {
+	var req listAccountEntriesRequest
+
+	if err := ctx.ShouldBindUri(&req.GetAccountRequest); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	account, valid := server.getUserAccount(ctx, req.Number)
+	if !valid {
+		return
+	}
+
+	entries, err := server.store.ListAccountEntries(ctx, db.ListAccountEntriesParams{
+		AccountID:  account.ID,
+		CategoryID: sql.NullInt64{Int64: req.CategoryID, Valid: req.CategoryID != 0},
+		Tag:        sql.NullString{String: normalizeTag(req.Tag), Valid: req.Tag != ""},
+		Limit:      req.PageSize,
+		Offset:     (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	res := make([]categorizedEntryResponse, len(entries))
+	for i, entry := range entries {
+		res[i] = getCategorizedEntryResponse(db.Entry{
+			ID:                   entry.ID,
+			AccountID:            entry.AccountID,
+			Amount:               entry.Amount,
+			CreatedAt:            entry.CreatedAt,
+			TransferID:           entry.TransferID,
+			JournalTransactionID: entry.JournalTransactionID,
+			CategoryID:           entry.CategoryID,
+			Tags:                 entry.Tags,
+		}, entry.CategoryName)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type entryRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// updateEntryCategoryRequest clears the category when CategoryID is null
+type updateEntryCategoryRequest struct {
+	entryRequest
+	CategoryID *int64 `json:"category_id" binding:"omitempty,min=1"`
+}
+
+// UpdateEntryCategory sets the category of an entry by hand, for owners and co-owners of its account
+func (server *Server) UpdateEntryCategory(ctx *gin.Context) {
+	var req updateEntryCategoryRequest
+
+	if err := ctx.ShouldBindUri(&req.entryRequest); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	if _, valid := server.getManagedEntry(ctx, req.ID); !valid {
+		return
+	}
+
+	var category db.Category
+	if req.CategoryID != nil {
+		var valid bool
+		category, valid = server.getUserCategory(ctx, *req.CategoryID)
+		if !valid {
+			return
+		}
+	}
+
+	entry, err := server.store.UpdateEntryCategory(ctx, db.UpdateEntryCategoryParams{
+		ID:         req.ID,
+		CategoryID: sql.NullInt64{Int64: category.ID, Valid: req.CategoryID != nil},
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getCategorizedEntryResponse(entry, category.Name))
+}
+
+// updateEntryTagsRequest replaces all the tags of an entry
+type updateEntryTagsRequest struct {
+	entryRequest
+	Tags []string `json:"tags" binding:"max=10,dive,required,max=30"`
+}
+
+// UpdateEntryTags replaces the tags of an entry, for owners and co-owners of its account.
+// Tags are lowercased and deduplicated.
+func (server *Server) UpdateEntryTags(ctx *gin.Context) {
+	var req updateEntryTagsRequest
+
+	if err := ctx.ShouldBindUri(&req.entryRequest); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	current, valid := server.getManagedEntry(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	entry, err := server.store.UpdateEntryTags(ctx, db.UpdateEntryTagsParams{
+		ID:   req.ID,
+		Tags: normalizeTags(req.Tags),
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	var category string
+	if current.CategoryID.Valid {
+		if c, err := server.store.GetCategory(ctx, current.CategoryID.Int64); err == nil {
+			category = c.Name
+		}
+	}
+
+	ctx.JSON(http.StatusOK, getCategorizedEntryResponse(entry, category))
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// normalizeTags lowercases, deduplicates and sorts tags, never returning nil
+func normalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// spendingRequest selects the days from From to To, both included
+type spendingRequest struct {
+	GetAccountRequest
+	From time.Time `form:"from" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	To   time.Time `form:"to" binding:"required,gtefield=From" time_format:"2006-01-02" time_utc:"1"`
+}
+
+// categorySpendingResponse has a null CategoryID for uncategorized entries
+type categorySpendingResponse struct {
+	CategoryID *int64 `json:"category_id"`
+	Category   string `json:"category"`
+	Spent      int64  `json:"spent"`
+	Entries    int64  `json:"entries"`
+}
+
+type spendingResponse struct {
+	Account    string                     `json:"account"`
+	Currency   string                     `json:"currency"`
+	From       string                     `json:"from"`
+	To         string                     `json:"to"`
+	Total      int64                      `json:"total"`
+	Categories []categorySpendingResponse `json:"categories"`
+}
+
+// GetAccountSpending sums what an account spent per category over a date range, biggest
+// categories first. Fees count as spending.
+func (server *Server) GetAccountSpending(ctx *gin.Context) {
+	var req spendingRequest
+
+	if err := ctx.ShouldBindUri(&req.GetAccountRequest); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	account, valid := server.getUserAccount(ctx, req.Number)
+	if !valid {
+		return
+	}
+
+	rows, err := server.store.ListCategorySpending(ctx, db.ListCategorySpendingParams{
+		AccountID: account.ID,
+		FromTime:  req.From,
+		ToTime:    req.To.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	res := spendingResponse{
+		Account:    account.Number,
+		Currency:   account.Currency,
+		From:       req.From.Format(dateFormat),
+		To:         req.To.Format(dateFormat),
+		Categories: make([]categorySpendingResponse, len(rows)),
+	}
+	for i, row := range rows {
+		res.Total += row.Spent
+		res.Categories[i] = categorySpendingResponse{
+			CategoryID: nullInt64(row.CategoryID),
+			Category:   row.CategoryName,
+			Spent:      row.Spent,
+			Entries:    row.Entries,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// getUserCategory loads a category of the authenticated user, other users' categories are not found
+func (server *Server) getUserCategory(ctx *gin.Context, id int64) (db.Category, bool) {
+	category, err := server.store.GetCategory(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			errorResponse(ctx, categoryNotFound(id))
+			return category, false
+		}
+		errorResponse(ctx, err)
+		return category, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if category.Owner != authPayload.Username {
+		errorResponse(ctx, categoryNotFound(id))
+		return db.Category{}, false
+	}
+
+	return category, true
+}
+
+// getManagedEntry loads an entry of an account the authenticated user manages, for
+// changing its category or tags. Entries of other accounts are reported as not found.
+func (server *Server) getManagedEntry(ctx *gin.Context, id int64) (db.Entry, bool) {
+	entry, err := server.store.GetEntry(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			errorResponse(ctx, entryNotFound(id))
+			return entry, false
+		}
+		errorResponse(ctx, err)
+		return entry, false
+	}
+
+	account, err := server.store.GetAccount(ctx, entry.AccountID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return entry, false
+	}
+
+	member, err := server.userMember(ctx, account)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = entryNotFound(id)
+		}
+		errorResponse(ctx, err)
+		return db.Entry{}, false
+	}
+	if !member.CanManage() {
+		errorResponse(ctx, ErrPermissionDenied.withDetail(fmt.Sprintf("a %s can't change entry [%d]", member.Role, id)))
+		return db.Entry{}, false
+	}
+
+	return entry, true
+}
+
+func categoryNotFound(id int64) *apiError {
+	return ErrCategoryNotFound.withDetail(fmt.Sprintf("category [%d] not found", id))
+}
+
+func categoryRuleNotFound(id int64) *apiError {
+	return ErrCategoryRuleNotFound.withDetail(fmt.Sprintf("category rule [%d] not found", id))
+}
+
+func entryNotFound(id int64) *apiError {
+	return ErrEntryNotFound.withDetail(fmt.Sprintf("entry [%d] not found", id))
+}
+
+// nullInt64 is nil for NULL columns
+func nullInt64(value sql.NullInt64) *int64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Int64
+}
+
+// nullString is nil for NULL columns
+func nullString(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+	return &value.String
+}
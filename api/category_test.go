@@ -0,0 +1,458 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simple-bank/db/mock"
+	db "simple-bank/db/sqlc"
+	"simple-bank/utils"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func randomCategory(owner string) db.Category {
+	return db.Category{
+		ID:        utils.RandomInt(1, 1000),
+		Owner:     owner,
+		Name:      utils.RandomString(8),
+		CreatedAt: time.Now(),
+	}
+}
+
+func TestCreateCategoryAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	category := randomCategory(user.Username)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateCategory(gomock.Any(), gomock.Eq(db.CreateCategoryParams{
+						Owner: user.Username,
+						Name:  category.Name,
+					})).
+					Times(1).
+					Return(category, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res categoryResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, category.ID, res.ID)
+				require.Equal(t, category.Name, res.Name)
+			},
+		},
+		{
+			name: "AlreadyExists",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateCategory(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Category{}, &pq.Error{Code: "23505", Constraint: "categories_owner_name_key"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, ErrCategoryExists.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(gin.H{"name": " " + category.Name + " "})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/categories", bytes.NewReader(body))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestCreateCategoryRuleAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+	category := randomCategory(user.Username)
+	counterparty := randomAccount(other.Username)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"category_id":          category.ID,
+				"counterparty_account": counterparty.Number,
+				"max_amount":           500,
+				"priority":             2,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCategory(gomock.Any(), gomock.Eq(category.ID)).Times(1).Return(category, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(counterparty.Number)).Times(1).Return(counterparty, nil)
+
+				arg := db.CreateCategoryRuleParams{
+					Owner:                 user.Username,
+					CategoryID:            category.ID,
+					CounterpartyAccountID: sql.NullInt64{Int64: counterparty.ID, Valid: true},
+					MaxAmount:             sql.NullInt64{Int64: 500, Valid: true},
+					Priority:              2,
+				}
+				store.EXPECT().
+					CreateCategoryRule(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.CategoryRule{
+						ID:                    utils.RandomInt(1, 1000),
+						Owner:                 arg.Owner,
+						CategoryID:            arg.CategoryID,
+						CounterpartyAccountID: arg.CounterpartyAccountID,
+						MaxAmount:             arg.MaxAmount,
+						Priority:              arg.Priority,
+					}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res categoryRuleResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, category.Name, res.Category)
+				require.Equal(t, counterparty.Number, *res.CounterpartyAccount)
+				require.Nil(t, res.MemoKeyword)
+				require.Nil(t, res.MinAmount)
+				require.Equal(t, int64(500), *res.MaxAmount)
+			},
+		},
+		{
+			name: "NoCondition",
+			body: gin.H{
+				"category_id": category.ID,
+				"priority":    1,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateCategoryRule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, ErrValidationFailed.Code)
+			},
+		},
+		{
+			name: "InvalidAmountRange",
+			body: gin.H{
+				"category_id": category.ID,
+				"min_amount":  500,
+				"max_amount":  100,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateCategoryRule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				problem := requireProblemCode(t, recorder, ErrValidationFailed.Code)
+				require.Equal(t, "max_amount", problem.Errors[0].Field)
+			},
+		},
+		{
+			name: "OtherUsersCategory",
+			body: gin.H{
+				"category_id":  category.ID,
+				"memo_keyword": "coffee",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCategory(gomock.Any(), gomock.Eq(category.ID)).Times(1).Return(randomCategory(other.Username), nil)
+				store.EXPECT().CreateCategoryRule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, ErrCategoryNotFound.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/category-rules", bytes.NewReader(body))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestUpdateEntryCategoryAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	shared := randomAccount(other.Username)
+	shared.ID = account.ID
+	category := randomCategory(user.Username)
+
+	entry := db.Entry{
+		ID:         utils.RandomInt(1, 1000),
+		AccountID:  account.ID,
+		Amount:     -100,
+		CategoryID: sql.NullInt64{Int64: category.ID + 1, Valid: true},
+		Tags:       []string{},
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"category_id": category.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetCategory(gomock.Any(), gomock.Eq(category.ID)).Times(1).Return(category, nil)
+
+				updated := entry
+				updated.CategoryID = sql.NullInt64{Int64: category.ID, Valid: true}
+				store.EXPECT().
+					UpdateEntryCategory(gomock.Any(), gomock.Eq(db.UpdateEntryCategoryParams{
+						ID:         entry.ID,
+						CategoryID: updated.CategoryID,
+					})).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res categorizedEntryResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, category.ID, *res.CategoryID)
+				require.Equal(t, category.Name, res.Category)
+			},
+		},
+		{
+			name: "Clear",
+			body: gin.H{"category_id": nil},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetCategory(gomock.Any(), gomock.Any()).Times(0)
+
+				updated := entry
+				updated.CategoryID = sql.NullInt64{}
+				store.EXPECT().
+					UpdateEntryCategory(gomock.Any(), gomock.Eq(db.UpdateEntryCategoryParams{ID: entry.ID})).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res categorizedEntryResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Nil(t, res.CategoryID)
+				require.Empty(t, res.Category)
+			},
+		},
+		{
+			name: "OtherUsersEntry",
+			body: gin.H{"category_id": category.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(shared, nil)
+				expectNoMember(store, shared, user.Username)
+				store.EXPECT().UpdateEntryCategory(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, ErrEntryNotFound.Code)
+			},
+		},
+		{
+			name: "CoOwner",
+			body: gin.H{"category_id": nil},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(shared, nil)
+				expectMember(store, shared, user.Username, db.MemberCoOwner, 0)
+				store.EXPECT().
+					UpdateEntryCategory(gomock.Any(), gomock.Eq(db.UpdateEntryCategoryParams{ID: entry.ID})).
+					Times(1).
+					Return(entry, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Viewer",
+			body: gin.H{"category_id": nil},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(shared, nil)
+				expectMember(store, shared, user.Username, db.MemberViewer, 0)
+				store.EXPECT().UpdateEntryCategory(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, ErrPermissionDenied.Code)
+			},
+		},
+		{
+			name: "Spender",
+			body: gin.H{"category_id": nil},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(shared, nil)
+				expectMember(store, shared, user.Username, db.MemberSpender, 1000)
+				store.EXPECT().UpdateEntryCategory(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, ErrPermissionDenied.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetEntry(gomock.Any(), gomock.Eq(entry.ID)).Times(1).Return(entry, nil)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/entries/%d/category", entry.ID)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	require.Equal(t, []string{}, normalizeTags(nil))
+	require.Equal(t, []string{"rent", "work"}, normalizeTags([]string{"Work", " rent", "work "}))
+}
+
+func TestGetAccountSpendingAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	category := randomCategory(user.Username)
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "from=2024-03-01&to=2024-03-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().
+					ListCategorySpending(gomock.Any(), gomock.Eq(db.ListCategorySpendingParams{
+						AccountID: account.ID,
+						FromTime:  time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+						ToTime:    time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+					})).
+					Times(1).
+					Return([]db.ListCategorySpendingRow{
+						{CategoryID: sql.NullInt64{Int64: category.ID, Valid: true}, CategoryName: category.Name, Spent: 300, Entries: 2},
+						{Spent: 50, Entries: 1},
+					}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res spendingResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, "2024-03-01", res.From)
+				require.Equal(t, "2024-03-31", res.To)
+				require.Equal(t, int64(350), res.Total)
+				require.Len(t, res.Categories, 2)
+				require.Equal(t, category.ID, *res.Categories[0].CategoryID)
+				require.Nil(t, res.Categories[1].CategoryID)
+			},
+		},
+		{
+			name:  "ToBeforeFrom",
+			query: "from=2024-03-31&to=2024-03-01",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListCategorySpending(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				problem := requireProblemCode(t, recorder, ErrValidationFailed.Code)
+				require.Equal(t, "to", problem.Errors[0].Field)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%s/spending?%s", account.Number, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
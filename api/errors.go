@@ -0,0 +1,311 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	db "simple-bank/db/sqlc"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const problemContentType = "application/problem+json"
+
+// apiError is an entry of the error catalogue. Code is stable and meant for
+// clients to switch on, Detail is a human readable explanation.
+type apiError struct {
+	Status int
+	Code   string
+	Title  string
+	Detail string
+	Fields []FieldViolation
+}
+
+func (e *apiError) Error() string {
+	if e.Detail != "" {
+		return e.Detail
+	}
+	return e.Title
+}
+
+// withDetail returns a copy of the catalogue entry carrying a specific detail
+func (e *apiError) withDetail(detail string) *apiError {
+	err := *e
+	err.Detail = detail
+	return &err
+}
+
+func newAPIError(status int, code string, title string) *apiError {
+	return &apiError{Status: status, Code: code, Title: title}
+}
+
+var (
+	ErrValidationFailed   = newAPIError(http.StatusBadRequest, "VALIDATION_FAILED", "Request validation failed")
+	ErrMalformedRequest   = newAPIError(http.StatusBadRequest, "MALFORMED_REQUEST", "Request could not be parsed")
+	ErrCurrencyMismatch   = newAPIError(http.StatusBadRequest, "CURRENCY_MISMATCH", "Account currency doesn't match the requested currency")
+	ErrUnauthenticated    = newAPIError(http.StatusUnauthorized, "UNAUTHENTICATED", "Authentication is required")
+	ErrInvalidCredentials = newAPIError(http.StatusUnauthorized, "INVALID_CREDENTIALS", "Username or password is incorrect")
+	ErrInvalidSession     = newAPIError(http.StatusUnauthorized, "INVALID_SESSION", "Session is not valid")
+	ErrPermissionDenied   = newAPIError(http.StatusForbidden, "PERMISSION_DENIED", "Not allowed to perform this operation")
+	ErrAlreadyExists      = newAPIError(http.StatusConflict, "ALREADY_EXISTS", "Resource already exists")
+	ErrUsernameTaken      = newAPIError(http.StatusConflict, "USERNAME_TAKEN", "Username is already taken")
+	ErrEmailTaken         = newAPIError(http.StatusConflict, "EMAIL_TAKEN", "Email is already registered")
+	ErrAliasTaken         = newAPIError(http.StatusConflict, "ALIAS_TAKEN", "Alias is already taken")
+	ErrEmailVerified      = newAPIError(http.StatusConflict, "EMAIL_ALREADY_VERIFIED", "Email is already verified")
+	ErrInvalidCode        = newAPIError(http.StatusUnprocessableEntity, "INVALID_VERIFICATION_CODE", "Verification code is invalid or has expired")
+	ErrAccountExists      = newAPIError(http.StatusConflict, "ACCOUNT_CURRENCY_EXISTS", "An account of this product in this currency already exists")
+	ErrPayeeExists        = newAPIError(http.StatusConflict, "PAYEE_EXISTS", "This account is already a payee")
+	ErrReferenceNotFound  = newAPIError(http.StatusUnprocessableEntity, "REFERENCED_RECORD_NOT_FOUND", "A referenced record doesn't exist")
+	ErrNotFound           = newAPIError(http.StatusNotFound, "NOT_FOUND", "Resource not found")
+	ErrAccountNotFound    = newAPIError(http.StatusNotFound, "ACCOUNT_NOT_FOUND", "Account not found")
+	ErrUserNotFound       = newAPIError(http.StatusNotFound, "USER_NOT_FOUND", "User not found")
+	ErrRecipientNotFound  = newAPIError(http.StatusNotFound, "RECIPIENT_NOT_FOUND", "No user matches the recipient")
+	ErrNoRecipientAccount = newAPIError(http.StatusUnprocessableEntity, "RECIPIENT_ACCOUNT_NOT_FOUND", "Recipient has no account in this currency")
+	ErrSessionNotFound    = newAPIError(http.StatusNotFound, "SESSION_NOT_FOUND", "Session not found")
+	ErrWebhookNotFound    = newAPIError(http.StatusNotFound, "WEBHOOK_NOT_FOUND", "Webhook not found")
+	ErrStatementNotFound  = newAPIError(http.StatusNotFound, "STATEMENT_NOT_FOUND", "Statement not found")
+	ErrPayeeNotFound      = newAPIError(http.StatusNotFound, "PAYEE_NOT_FOUND", "Payee not found")
+	ErrHoldNotFound       = newAPIError(http.StatusNotFound, "HOLD_NOT_FOUND", "Hold not found")
+	ErrHoldNotPending     = newAPIError(http.StatusConflict, "HOLD_NOT_PENDING", "Hold has already been captured, voided or expired")
+	ErrHoldExpired        = newAPIError(http.StatusConflict, "HOLD_EXPIRED", "Hold has expired")
+	ErrCaptureExceedsHold = newAPIError(http.StatusUnprocessableEntity, "CAPTURE_EXCEEDS_HOLD", "Capture amount is more than the held amount")
+	ErrInsufficientFunds  = newAPIError(http.StatusUnprocessableEntity, "INSUFFICIENT_FUNDS", "Account balance is too low for this operation")
+	ErrTransferTooLarge   = newAPIError(http.StatusUnprocessableEntity, "TRANSFER_LIMIT_EXCEEDED", "Amount is above the limit for a single transfer")
+	ErrDailyLimit         = newAPIError(http.StatusUnprocessableEntity, "DAILY_LIMIT_EXCEEDED", "Transfer would exceed the daily limit")
+	ErrRollingLimit       = newAPIError(http.StatusUnprocessableEntity, "ROLLING_30_DAY_LIMIT_EXCEEDED", "Transfer would exceed the 30 day limit")
+	ErrTransferRateLimit  = newAPIError(http.StatusUnprocessableEntity, "HOURLY_TRANSFER_LIMIT_EXCEEDED", "Too many transfers in the last hour")
+	ErrMonthlyLimit       = newAPIError(http.StatusUnprocessableEntity, "MONTHLY_TRANSFER_LIMIT_EXCEEDED", "Too many transfers this month for the account's product")
+	ErrOwnAccountsOnly    = newAPIError(http.StatusUnprocessableEntity, "OWN_ACCOUNTS_ONLY", "The account's product only allows transfers to the owner's accounts")
+	ErrSpendLimit         = newAPIError(http.StatusUnprocessableEntity, "SPEND_LIMIT_EXCEEDED", "Amount is above the spender's limit for a single transfer")
+	ErrPayeeCoolingOff    = newAPIError(http.StatusUnprocessableEntity, "PAYEE_COOLING_OFF", "New payees can't receive large transfers yet")
+	ErrInternal           = newAPIError(http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+
+	ErrPaymentRequestNotFound   = newAPIError(http.StatusNotFound, "PAYMENT_REQUEST_NOT_FOUND", "Payment request not found")
+	ErrPaymentRequestNotPending = newAPIError(http.StatusConflict, "PAYMENT_REQUEST_NOT_PENDING", "Payment request has already been paid, declined, cancelled or expired")
+	ErrPaymentRequestExpired    = newAPIError(http.StatusConflict, "PAYMENT_REQUEST_EXPIRED", "Payment request has expired")
+
+	ErrBatchInvalid = newAPIError(http.StatusUnprocessableEntity, "BATCH_INVALID", "One or more transfers of the batch are invalid")
+
+	ErrCategoryExists       = newAPIError(http.StatusConflict, "CATEGORY_EXISTS", "A category with this name already exists")
+	ErrCategoryNotFound     = newAPIError(http.StatusNotFound, "CATEGORY_NOT_FOUND", "Category not found")
+	ErrCategoryRuleNotFound = newAPIError(http.StatusNotFound, "CATEGORY_RULE_NOT_FOUND", "Category rule not found")
+	ErrEntryNotFound        = newAPIError(http.StatusNotFound, "ENTRY_NOT_FOUND", "Entry not found")
+
+	ErrProductNotFound = newAPIError(http.StatusUnprocessableEntity, "PRODUCT_NOT_FOUND", "Account product not found")
+	ErrProductCurrency = newAPIError(http.StatusUnprocessableEntity, "PRODUCT_CURRENCY_NOT_ALLOWED", "The account product isn't offered in this currency")
+
+	ErrMemberExists       = newAPIError(http.StatusConflict, "MEMBER_EXISTS", "The user is already a member of the account or invited to it")
+	ErrMemberNotFound     = newAPIError(http.StatusNotFound, "MEMBER_NOT_FOUND", "Account member not found")
+	ErrInvitationNotFound = newAPIError(http.StatusNotFound, "INVITATION_NOT_FOUND", "Invitation not found")
+)
+
+// limitErrors maps transfer limit kinds to the catalogue entry reported to clients
+var limitErrors = map[string]*apiError{
+	db.LimitPerTransfer:       ErrTransferTooLarge,
+	db.LimitDaily:             ErrDailyLimit,
+	db.LimitRolling30Days:     ErrRollingLimit,
+	db.LimitTransfersPerHour:  ErrTransferRateLimit,
+	db.LimitTransfersPerMonth: ErrMonthlyLimit,
+}
+
+// uniqueViolations maps unique constraints to the catalogue entry reported to clients
+var uniqueViolations = map[string]*apiError{
+	"users_pkey":                           ErrUsernameTaken,
+	"users_email_key":                      ErrEmailTaken,
+	"users_alias_key":                      ErrAliasTaken,
+	"payees_owner_account_key":             ErrPayeeExists,
+	"categories_owner_name_key":            ErrCategoryExists,
+	"account_members_account_username_key": ErrMemberExists,
+	"owner_currency_product_key":           ErrAccountExists,
+}
+
+// ProblemDetails is an RFC 7807 problem document
+type ProblemDetails struct {
+	Type     string           `json:"type"`
+	Title    string           `json:"title"`
+	Status   int              `json:"status"`
+	Detail   string           `json:"detail,omitempty"`
+	Instance string           `json:"instance,omitempty"`
+	Code     string           `json:"code"`
+	Errors   []FieldViolation `json:"errors,omitempty"`
+}
+
+// FieldViolation describes why a single request field was rejected
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// toAPIError maps any error returned by a handler to the error catalogue.
+// Errors that aren't part of the catalogue are reported as INTERNAL_ERROR so
+// database and driver messages never reach the client.
+func toAPIError(err error) *apiError {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return validationError(validationErrs)
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var timeErr *time.ParseError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.As(err, &timeErr) {
+		return ErrMalformedRequest.withDetail(err.Error())
+	}
+
+	var limitErr *db.LimitError
+	if errors.As(err, &limitErr) {
+		if apiErr, ok := limitErrors[limitErr.Kind]; ok {
+			return apiErr.withDetail(limitErr.Error())
+		}
+	}
+
+	var coolingOffErr *db.CoolingOffError
+	if errors.As(err, &coolingOffErr) {
+		return ErrPayeeCoolingOff.withDetail(coolingOffErr.Error())
+	}
+
+	switch {
+	case errors.Is(err, db.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, db.ErrInsufficientFunds):
+		return ErrInsufficientFunds
+	case errors.Is(err, db.ErrOwnAccountsOnly):
+		return ErrOwnAccountsOnly
+	case errors.Is(err, db.ErrHoldNotPending):
+		return ErrHoldNotPending
+	case errors.Is(err, db.ErrHoldExpired):
+		return ErrHoldExpired
+	case errors.Is(err, db.ErrCaptureExceedsHold):
+		return ErrCaptureExceedsHold
+	case errors.Is(err, db.ErrSenderNotAllowed):
+		return ErrPermissionDenied.withDetail("you can no longer send from this account")
+	case errors.Is(err, db.ErrEmailAlreadyVerified):
+		return ErrEmailVerified
+	case errors.Is(err, db.ErrInvalidVerificationCode):
+		return ErrInvalidCode
+	case errors.Is(err, db.ErrPaymentRequestNotPending):
+		return ErrPaymentRequestNotPending
+	case errors.Is(err, db.ErrPaymentRequestExpired):
+		return ErrPaymentRequestExpired
+	}
+
+	switch db.ErrorCode(err) {
+	case db.UniqueViolation:
+		if apiErr, ok := uniqueViolations[db.ConstraintName(err)]; ok {
+			return apiErr
+		}
+		return ErrAlreadyExists
+	case db.ForeignKeyViolation:
+		return ErrReferenceNotFound
+	}
+
+	return ErrInternal
+}
+
+func validationError(errs validator.ValidationErrors) *apiError {
+	apiErr := ErrValidationFailed.withDetail("one or more fields are invalid")
+	for _, fieldErr := range errs {
+		apiErr.Fields = append(apiErr.Fields, FieldViolation{
+			Field:   fieldPath(fieldErr),
+			Rule:    fieldErr.Tag(),
+			Message: validationMessage(fieldErr),
+		})
+	}
+	return apiErr
+}
+
+// fieldPath is the JSON name of the field, along with its path when it's an item of a list
+func fieldPath(fieldErr validator.FieldError) string {
+	_, path, _ := strings.Cut(fieldErr.Namespace(), ".")
+	if strings.Contains(path, "[") {
+		return path
+	}
+	return fieldErr.Field()
+}
+
+func validationMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return "must be at least " + fieldErr.Param()
+	case "max":
+		return "must be at most " + fieldErr.Param()
+	case "gt":
+		return "must be greater than " + fieldErr.Param()
+	case "email":
+		return "must be a valid email address"
+	case "alphanum":
+		return "must contain only letters and digits"
+	case "currency":
+		return "is not a supported currency"
+	case "account_number":
+		return "is not a valid account number"
+	case "url":
+		return "must be a valid URL"
+	case "webhook_event":
+		return "is not a supported event type"
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fieldErr.Param(), " ", ", ")
+	case "datetime":
+		return "must be formatted as " + fieldErr.Param()
+	case "gtefield":
+		return "must not be before " + strings.ToLower(fieldErr.Param())
+	case "required_without", "required_without_all":
+		return "is required when none of " + fieldList(fieldErr.Param()) + " is set"
+	case "excluded_with":
+		return "must not be set along with " + fieldList(fieldErr.Param())
+	case "recipient":
+		return "must be a username, an email or an @alias"
+	case "alias":
+		return "must be 3 to 30 lowercase letters, digits or underscores"
+	case "metadata":
+		return fmt.Sprintf("must be a JSON object of at most %d bytes", maxMetadataSize)
+	}
+	return "failed the " + fieldErr.Tag() + " rule"
+}
+
+// fieldList turns the Go field names of a cross-field rule into the names clients send
+// them with, as far as camel case goes
+func fieldList(param string) string {
+	names := strings.Fields(param)
+	for i, name := range names {
+		names[i] = strings.ToLower(name[:1]) + name[1:]
+	}
+	return strings.Join(names, " or ")
+}
+
+func problemType(code string) string {
+	return "/problems/" + strings.ReplaceAll(strings.ToLower(code), "_", "-")
+}
+
+// errorResponse aborts the request with a problem+json body describing err
+func errorResponse(ctx *gin.Context, err error) {
+	apiErr := toAPIError(err)
+	if apiErr.Status >= http.StatusInternalServerError {
+		ctx.Error(err)
+	}
+
+	problem := ProblemDetails{
+		Type:     problemType(apiErr.Code),
+		Title:    apiErr.Title,
+		Status:   apiErr.Status,
+		Detail:   apiErr.Detail,
+		Instance: ctx.Request.URL.Path,
+		Code:     apiErr.Code,
+		Errors:   apiErr.Fields,
+	}
+
+	ctx.Header("Content-Type", problemContentType)
+	ctx.AbortWithStatusJSON(apiErr.Status, problem)
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	db "simple-bank/db/sqlc"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func requireProblemCode(t *testing.T, recorder *httptest.ResponseRecorder, code string) ProblemDetails {
+	require.Equal(t, problemContentType, recorder.Header().Get("Content-Type"))
+
+	var problem ProblemDetails
+	err := json.Unmarshal(recorder.Body.Bytes(), &problem)
+	require.NoError(t, err)
+	require.Equal(t, code, problem.Code)
+	require.Equal(t, recorder.Code, problem.Status)
+
+	return problem
+}
+
+func TestToAPIError(t *testing.T) {
+	testCases := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{
+			name:   "CatalogueError",
+			err:    ErrAccountNotFound.withDetail("account [1] not found"),
+			status: http.StatusNotFound,
+			code:   "ACCOUNT_NOT_FOUND",
+		},
+		{
+			name:   "RecordNotFound",
+			err:    sql.ErrNoRows,
+			status: http.StatusNotFound,
+			code:   "NOT_FOUND",
+		},
+		{
+			name:   "InsufficientFunds",
+			err:    db.ErrInsufficientFunds,
+			status: http.StatusUnprocessableEntity,
+			code:   "INSUFFICIENT_FUNDS",
+		},
+		{
+			name:   "KnownUniqueConstraint",
+			err:    &pq.Error{Code: "23505", Constraint: "owner_currency_product_key"},
+			status: ErrAccountExists.Status,
+			code:   "ACCOUNT_CURRENCY_EXISTS",
+		},
+		{
+			name:   "UnknownUniqueConstraint",
+			err:    &pq.Error{Code: "23505", Constraint: "something_key"},
+			status: ErrAlreadyExists.Status,
+			code:   "ALREADY_EXISTS",
+		},
+		{
+			name:   "ForeignKeyViolation",
+			err:    &pq.Error{Code: "23503"},
+			status: ErrReferenceNotFound.Status,
+			code:   "REFERENCED_RECORD_NOT_FOUND",
+		},
+		{
+			name:   "UnknownError",
+			err:    errors.New("pq: relation \"accounts\" does not exist"),
+			status: http.StatusInternalServerError,
+			code:   "INTERNAL_ERROR",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			apiErr := toAPIError(tc.err)
+			require.Equal(t, tc.status, apiErr.Status)
+			require.Equal(t, tc.code, apiErr.Code)
+		})
+	}
+}
+
+func TestValidationProblem(t *testing.T) {
+	server := NewTestServer(t, nil)
+	recorder := httptest.NewRecorder()
+
+	server.router.POST("/validate", func(ctx *gin.Context) {
+		var req CreateTransferRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		ctx.Status(http.StatusOK)
+	})
+
+	body, err := json.Marshal(gin.H{
+		"fromAccountId": "SB00000000000000",
+		"currency":      "XYZ",
+		"amount":        -1,
+	})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	problem := requireProblemCode(t, recorder, ErrValidationFailed.Code)
+	require.Equal(t, "/validate", problem.Instance)
+
+	fields := map[string]string{}
+	for _, violation := range problem.Errors {
+		fields[violation.Field] = violation.Rule
+	}
+	require.Equal(t, map[string]string{
+		"fromAccountId": "account_number",
+		"to":            "required_without_all",
+		"currency":      "currency",
+		"amount":        "gt",
+	}, fields)
+}
+
+func TestInternalErrorIsHidden(t *testing.T) {
+	server := NewTestServer(t, nil)
+	recorder := httptest.NewRecorder()
+
+	server.router.GET("/boom", func(ctx *gin.Context) {
+		errorResponse(ctx, errors.New("pq: password authentication failed for user \"root\""))
+	})
+
+	request, err := http.NewRequest(http.MethodGet, "/boom", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	requireProblemCode(t, recorder, ErrInternal.Code)
+	require.NotContains(t, recorder.Body.String(), "pq:")
+}
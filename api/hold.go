@@ -0,0 +1,312 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	db "simple-bank/db/sqlc"
+	"simple-bank/token"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultHoldExpiry = 7 * 24 * time.Hour
+	maxHoldExpiry     = 30 * 24 * time.Hour
+)
+
+// createHoldRequest reserves Amount on the sender's account, ExpiresIn is in seconds
+type createHoldRequest struct {
+	FromAccountId string `json:"fromAccountId" binding:"required,account_number"`
+	ToAccountId   string `json:"toAccountId" binding:"required,account_number"`
+	Currency      string `json:"currency" binding:"required,currency"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	ExpiresIn     int64  `json:"expires_in" binding:"omitempty,min=60,max=2592000"`
+}
+
+type holdResponse struct {
+	ID             int64      `json:"id"`
+	FromAccount    string     `json:"from_account"`
+	ToAccount      string     `json:"to_account"`
+	Amount         int64      `json:"amount"`
+	Fee            int64      `json:"fee"`
+	CapturedAmount int64      `json:"captured_amount"`
+	Status         string     `json:"status"`
+	TransferID     *int64     `json:"transfer_id,omitempty"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	SettledAt      *time.Time `json:"settled_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type placeHoldResponse struct {
+	Hold    holdResponse    `json:"hold"`
+	Account accountResponse `json:"account"`
+}
+
+type captureHoldResponse struct {
+	Hold     holdResponse        `json:"hold"`
+	Transfer transferTxnResponse `json:"transfer"`
+}
+
+func getHoldResponse(hold db.Hold, from db.Account, to db.Account) holdResponse {
+	res := holdResponse{
+		ID:             hold.ID,
+		FromAccount:    from.Number,
+		ToAccount:      to.Number,
+		Amount:         hold.Amount,
+		Fee:            hold.Fee,
+		CapturedAmount: hold.CapturedAmount,
+		Status:         hold.Status,
+		ExpiresAt:      hold.ExpiresAt,
+		CreatedAt:      hold.CreatedAt,
+	}
+	if hold.TransferID.Valid {
+		res.TransferID = &hold.TransferID.Int64
+	}
+	if hold.SettledAt.Valid {
+		res.SettledAt = &hold.SettledAt.Time
+	}
+	return res
+}
+
+// CreateHold authorizes a payment: the amount and its fee stop being available on the
+// sender's account but stay in its balance until the hold is captured, voided or expires.
+// The limits of the sender are checked now rather than at capture.
+func (server *Server) CreateHold(ctx *gin.Context) {
+	var req createHoldRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	fromAccount, valid := server.getSpendingAccount(ctx, req.FromAccountId, req.Amount)
+	if !valid || !checkCurrency(ctx, fromAccount, req.Currency) {
+		return
+	}
+
+	toAccount, valid := server.validAccount(ctx, req.ToAccountId, req.Currency)
+	if !valid {
+		return
+	}
+
+	expiry := defaultHoldExpiry
+	if req.ExpiresIn > 0 {
+		expiry = time.Duration(req.ExpiresIn) * time.Second
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	result, err := server.store.PlaceHoldTxn(ctx, db.PlaceHoldTxnParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        req.Amount,
+		ExpiresAt:     time.Now().Add(expiry),
+		Sender:        authPayload.Username,
+		CoolingOff:    server.payeeCoolingOff(),
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, placeHoldResponse{
+		Hold:    getHoldResponse(result.Hold, result.Account, toAccount),
+		Account: getAccountResponse(result.Account),
+	})
+}
+
+type holdRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) GetHold(ctx *gin.Context) {
+	var req holdRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	held, valid := server.getUserHold(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getHoldResponse(held.hold, held.from, held.to))
+}
+
+// captureHoldRequest captures Amount out of the hold, the whole hold when it's left out
+type captureHoldRequest struct {
+	holdRequest
+	Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+// CaptureHold settles a pending hold with a transfer to the account it was placed for.
+// Capturing less than the held amount releases the rest. The payee captures what it was
+// authorized for, and the payer may pay it out early within what they may send.
+func (server *Server) CaptureHold(ctx *gin.Context) {
+	var req captureHoldRequest
+
+	if err := ctx.ShouldBindUri(&req.holdRequest); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	// the body is optional
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		errorResponse(ctx, err)
+		return
+	}
+
+	held, valid := server.getUserHold(ctx, req.ID)
+	if !valid {
+		return
+	}
+	amount := req.Amount
+	if amount == 0 {
+		amount = held.hold.Amount
+	}
+	if apiErr := held.captureError(amount); apiErr != nil {
+		errorResponse(ctx, apiErr)
+		return
+	}
+
+	args := db.CaptureHoldTxnParams{
+		HoldID: req.ID,
+		Amount: req.Amount,
+	}
+	if !held.byPayee() {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		args.Sender = authPayload.Username
+	}
+
+	result, err := server.store.CaptureHoldTxn(ctx, args)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, captureHoldResponse{
+		Hold:     getHoldResponse(result.Hold, result.Transfer.FromAccount, result.Transfer.ToAccount),
+		Transfer: getTransferTxnResponse(result.Transfer),
+	})
+}
+
+// VoidHold cancels a pending hold, making the whole amount and its fee available again.
+// Only the payee can give up an authorization, the payer waits for it to expire.
+func (server *Server) VoidHold(ctx *gin.Context) {
+	var req holdRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	held, valid := server.getUserHold(ctx, req.ID)
+	if !valid {
+		return
+	}
+	if held.payee == nil {
+		errorResponse(ctx, ErrPermissionDenied.withDetail(fmt.Sprintf("only the payee can void hold [%d], it is released when it expires", req.ID)))
+		return
+	}
+	if !held.payee.CanReceive() {
+		errorResponse(ctx, ErrPermissionDenied.withDetail(fmt.Sprintf("a %s can't void hold [%d]", held.payee.Role, req.ID)))
+		return
+	}
+
+	hold, err := server.store.VoidHoldTxn(ctx, req.ID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getHoldResponse(hold, held.from, held.to))
+}
+
+// userHold is a hold with both its accounts and the authenticated user's active
+// memberships of them, nil for the side they aren't a member of
+type userHold struct {
+	hold  db.Hold
+	from  db.Account
+	to    db.Account
+	payer *db.AccountMember
+	payee *db.AccountMember
+}
+
+// captureError explains why the authenticated user can't capture amount of the hold, nil
+// when they can: as a member of the payee who isn't a viewer, or as a member of the payer
+// who may send amount
+func (held userHold) captureError(amount int64) *apiError {
+	if held.byPayee() {
+		return nil
+	}
+	if held.payer != nil {
+		return spendError(*held.payer, amount, held.from.Number)
+	}
+	return ErrPermissionDenied.withDetail(fmt.Sprintf("a %s can't capture hold [%d]", held.payee.Role, held.hold.ID))
+}
+
+// byPayee reports whether the authenticated user acts on the hold for the payee
+func (held userHold) byPayee() bool {
+	return held.payee != nil && held.payee.CanReceive()
+}
+
+// getUserHold loads a hold placed on or for an account the authenticated user is a
+// member of. Other holds are reported as not found.
+func (server *Server) getUserHold(ctx *gin.Context, id int64) (userHold, bool) {
+	var held userHold
+
+	hold, err := server.store.GetHold(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = holdNotFound(id)
+		}
+		errorResponse(ctx, err)
+		return held, false
+	}
+	held.hold = hold
+
+	held.from, err = server.store.GetAccount(ctx, hold.AccountID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return held, false
+	}
+	held.to, err = server.store.GetAccount(ctx, hold.ToAccountID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return held, false
+	}
+
+	if held.payer, err = server.optionalMember(ctx, held.from); err != nil {
+		errorResponse(ctx, err)
+		return held, false
+	}
+	if held.payee, err = server.optionalMember(ctx, held.to); err != nil {
+		errorResponse(ctx, err)
+		return held, false
+	}
+	if held.payer == nil && held.payee == nil {
+		errorResponse(ctx, holdNotFound(id))
+		return held, false
+	}
+
+	return held, true
+}
+
+// optionalMember is userMember for callers that accept no membership, reported as nil
+func (server *Server) optionalMember(ctx *gin.Context, account db.Account) (*db.AccountMember, error) {
+	member, err := server.userMember(ctx, account)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+func holdNotFound(id int64) *apiError {
+	return ErrHoldNotFound.withDetail(fmt.Sprintf("hold [%d] not found", id))
+}
@@ -0,0 +1,566 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simple-bank/db/mock"
+	db "simple-bank/db/sqlc"
+	"simple-bank/utils"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateHoldAPI(t *testing.T) {
+	amount := int64(100)
+
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account1.Currency = utils.USD
+	account2.Currency = utils.USD
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
+				"currency":      utils.USD,
+				"amount":        amount,
+				"expires_in":    3600,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(account2, nil)
+
+				held := account1
+				held.HeldBalance = amount
+				store.EXPECT().
+					PlaceHoldTxn(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, args db.PlaceHoldTxnParams) (db.PlaceHoldTxnResult, error) {
+						require.Equal(t, account1.ID, args.FromAccountID)
+						require.Equal(t, account2.ID, args.ToAccountID)
+						require.Equal(t, amount, args.Amount)
+						require.WithinDuration(t, time.Now().Add(time.Hour), args.ExpiresAt, time.Minute)
+
+						return db.PlaceHoldTxnResult{
+							Hold: db.Hold{
+								ID:          1,
+								AccountID:   account1.ID,
+								ToAccountID: account2.ID,
+								Amount:      amount,
+								Status:      db.HoldPending,
+								ExpiresAt:   args.ExpiresAt,
+							},
+							Account: held,
+						}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res placeHoldResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.HoldPending, res.Hold.Status)
+				require.Equal(t, account1.Number, res.Hold.FromAccount)
+				require.Equal(t, account2.Number, res.Hold.ToAccount)
+				require.Nil(t, res.Hold.TransferID)
+				require.Equal(t, account1.Balance, res.Account.Balance)
+				require.Equal(t, account1.Balance-amount, res.Account.AvailableBalance)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			body: gin.H{
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
+				"currency":      utils.USD,
+				"amount":        amount,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(account2, nil)
+				store.EXPECT().PlaceHoldTxn(gomock.Any(), gomock.Any()).Times(1).Return(db.PlaceHoldTxnResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, ErrInsufficientFunds.Code)
+			},
+		},
+		{
+			name: "ExpiryTooShort",
+			body: gin.H{
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
+				"currency":      utils.USD,
+				"amount":        amount,
+				"expires_in":    1,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().PlaceHoldTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				problem := requireProblemCode(t, recorder, ErrValidationFailed.Code)
+				require.Equal(t, "expires_in", problem.Errors[0].Field)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/holds", bytes.NewReader(body))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+// expectNoMember stubs the membership lookup of username in account as missing
+func expectNoMember(store *mockdb.MockStore, account db.Account, username string) {
+	store.EXPECT().
+		GetActiveAccountMember(gomock.Any(), gomock.Eq(db.GetActiveAccountMemberParams{AccountID: account.ID, Username: username})).
+		Times(1).
+		Return(db.AccountMember{}, sql.ErrNoRows)
+}
+
+func expectMember(store *mockdb.MockStore, account db.Account, username string, role string, spendLimit int64) {
+	store.EXPECT().
+		GetActiveAccountMember(gomock.Any(), gomock.Eq(db.GetActiveAccountMemberParams{AccountID: account.ID, Username: username})).
+		Times(1).
+		Return(db.AccountMember{
+			AccountID:  account.ID,
+			Username:   username,
+			Role:       role,
+			SpendLimit: spendLimit,
+			Status:     db.MemberActive,
+		}, nil)
+}
+
+func TestCaptureHoldAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	user3, _ := randomUser(t)
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account2.Currency = account1.Currency
+
+	hold := db.Hold{
+		ID:          utils.RandomInt(1, 1000),
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      100,
+		Status:      db.HoldPending,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Partial",
+			username: user1.Username,
+			body:     gin.H{"amount": 60},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				expectNoMember(store, account2, user1.Username)
+
+				captured := hold
+				captured.Status = db.HoldCaptured
+				captured.CapturedAmount = 60
+				captured.TransferID = sql.NullInt64{Int64: 7, Valid: true}
+				store.EXPECT().
+					CaptureHoldTxn(gomock.Any(), gomock.Eq(db.CaptureHoldTxnParams{HoldID: hold.ID, Amount: 60, Sender: user1.Username})).
+					Times(1).
+					Return(db.CaptureHoldTxnResult{
+						Hold: captured,
+						Transfer: db.TransferTxnResult{
+							Transfer:    db.Transfer{ID: 7, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 60},
+							FromAccount: account1,
+							ToAccount:   account2,
+						},
+					}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res captureHoldResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.HoldCaptured, res.Hold.Status)
+				require.Equal(t, int64(60), res.Hold.CapturedAmount)
+				require.Equal(t, int64(7), *res.Hold.TransferID)
+				require.Equal(t, int64(60), res.Transfer.Transfer.Amount)
+				require.Equal(t, account2.Number, res.Transfer.Transfer.ToAccount)
+			},
+		},
+		{
+			name:     "Full",
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(2).Return(account1, nil)
+				store.EXPECT().
+					CaptureHoldTxn(gomock.Any(), gomock.Eq(db.CaptureHoldTxnParams{HoldID: hold.ID})).
+					Times(1).
+					Return(db.CaptureHoldTxnResult{Hold: hold}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Payee",
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				expectNoMember(store, account1, user2.Username)
+				store.EXPECT().
+					CaptureHoldTxn(gomock.Any(), gomock.Eq(db.CaptureHoldTxnParams{HoldID: hold.ID})).
+					Times(1).
+					Return(db.CaptureHoldTxnResult{Hold: hold}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "OtherUsersHold",
+			username: user3.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				expectNoMember(store, account1, user3.Username)
+				expectNoMember(store, account2, user3.Username)
+				store.EXPECT().CaptureHoldTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, ErrHoldNotFound.Code)
+			},
+		},
+		{
+			name:     "PayerViewer",
+			username: user3.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				expectMember(store, account1, user3.Username, db.MemberViewer, 0)
+				expectNoMember(store, account2, user3.Username)
+				store.EXPECT().CaptureHoldTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, ErrPermissionDenied.Code)
+			},
+		},
+		{
+			name:     "PayerSpenderOverLimit",
+			username: user3.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				expectMember(store, account1, user3.Username, db.MemberSpender, 50)
+				expectNoMember(store, account2, user3.Username)
+				store.EXPECT().CaptureHoldTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, ErrSpendLimit.Code)
+			},
+		},
+		{
+			name:     "PayerSpenderWithinLimit",
+			username: user3.Username,
+			body:     gin.H{"amount": 50},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				expectMember(store, account1, user3.Username, db.MemberSpender, 50)
+				expectNoMember(store, account2, user3.Username)
+				store.EXPECT().
+					CaptureHoldTxn(gomock.Any(), gomock.Eq(db.CaptureHoldTxnParams{HoldID: hold.ID, Amount: 50, Sender: user3.Username})).
+					Times(1).
+					Return(db.CaptureHoldTxnResult{Hold: hold}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "PayeeViewer",
+			username: user3.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				expectNoMember(store, account1, user3.Username)
+				expectMember(store, account2, user3.Username, db.MemberViewer, 0)
+				store.EXPECT().CaptureHoldTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, ErrPermissionDenied.Code)
+			},
+		},
+		{
+			name:     "PayeeSpender",
+			username: user3.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				expectNoMember(store, account1, user3.Username)
+				expectMember(store, account2, user3.Username, db.MemberSpender, 0)
+				store.EXPECT().
+					CaptureHoldTxn(gomock.Any(), gomock.Eq(db.CaptureHoldTxnParams{HoldID: hold.ID})).
+					Times(1).
+					Return(db.CaptureHoldTxnResult{Hold: hold}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "NotPending",
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(2).Return(account1, nil)
+				store.EXPECT().CaptureHoldTxn(gomock.Any(), gomock.Any()).Times(1).Return(db.CaptureHoldTxnResult{}, db.ErrHoldNotPending)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, ErrHoldNotPending.Code)
+			},
+		},
+		{
+			name:     "ExceedsHold",
+			username: user1.Username,
+			body:     gin.H{"amount": 101},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(2).Return(account1, nil)
+				store.EXPECT().CaptureHoldTxn(gomock.Any(), gomock.Any()).Times(1).Return(db.CaptureHoldTxnResult{}, db.ErrCaptureExceedsHold)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, ErrCaptureExceedsHold.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body []byte
+			if tc.body != nil {
+				var err error
+				body, err = json.Marshal(tc.body)
+				require.NoError(t, err)
+			}
+
+			url := fmt.Sprintf("/holds/%d/capture", hold.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestVoidHoldAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	user3, _ := randomUser(t)
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account2.Currency = account1.Currency
+
+	hold := db.Hold{
+		ID:          utils.RandomInt(1, 1000),
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      100,
+		Status:      db.HoldPending,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Payee",
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				expectNoMember(store, account1, user2.Username)
+
+				voided := hold
+				voided.Status = db.HoldVoided
+				store.EXPECT().VoidHoldTxn(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(voided, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res holdResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.HoldVoided, res.Status)
+				require.Equal(t, account1.Number, res.FromAccount)
+				require.Equal(t, account2.Number, res.ToAccount)
+			},
+		},
+		{
+			name:     "Payer",
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				expectNoMember(store, account2, user1.Username)
+				store.EXPECT().VoidHoldTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, ErrPermissionDenied.Code)
+			},
+		},
+		{
+			name:     "PayeeViewer",
+			username: user3.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				expectNoMember(store, account1, user3.Username)
+				expectMember(store, account2, user3.Username, db.MemberViewer, 0)
+				store.EXPECT().VoidHoldTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, ErrPermissionDenied.Code)
+			},
+		},
+		{
+			name:     "PayeeSpender",
+			username: user3.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				expectNoMember(store, account1, user3.Username)
+				expectMember(store, account2, user3.Username, db.MemberSpender, 0)
+				store.EXPECT().VoidHoldTxn(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "PayerSpender",
+			username: user3.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				expectMember(store, account1, user3.Username, db.MemberSpender, 1000)
+				expectNoMember(store, account2, user3.Username)
+				store.EXPECT().VoidHoldTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, ErrPermissionDenied.Code)
+			},
+		},
+		{
+			name:     "NotPending",
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(2).Return(account2, nil)
+				store.EXPECT().VoidHoldTxn(gomock.Any(), gomock.Any()).Times(1).Return(db.Hold{}, db.ErrHoldNotPending)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, ErrHoldNotPending.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/holds/%d/void", hold.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
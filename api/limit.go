@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+	db "simple-bank/db/sqlc"
+	"simple-bank/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// limitResponse is the headroom under one limit, Limit and Remaining are null when unlimited
+type limitResponse struct {
+	Limit     *int64 `json:"limit"`
+	Used      int64  `json:"used"`
+	Remaining *int64 `json:"remaining"`
+}
+
+func getLimitResponse(limit int64, used int64) limitResponse {
+	res := limitResponse{Used: used}
+	if limit > 0 {
+		remaining := max(limit-used, 0)
+		res.Limit = &limit
+		res.Remaining = &remaining
+	}
+	return res
+}
+
+// currencyLimitsResponse is the headroom of one account: the tier limits of its
+// currency, used by all of the owner's accounts in it, and the monthly transfer count
+// of its product
+type currencyLimitsResponse struct {
+	Account           string        `json:"account"`
+	Currency          string        `json:"currency"`
+	Product           string        `json:"product"`
+	PerTransfer       limitResponse `json:"per_transfer"`
+	Daily             limitResponse `json:"daily"`
+	Rolling30Days     limitResponse `json:"rolling_30_days"`
+	TransfersPerHour  limitResponse `json:"transfers_per_hour"`
+	TransfersPerMonth limitResponse `json:"transfers_per_month"`
+}
+
+type userLimitsResponse struct {
+	Tier   string                   `json:"tier"`
+	Limits []currencyLimitsResponse `json:"limits"`
+}
+
+// GetUserLimits shows the transfer limits of the authenticated user's tier and the
+// headroom left on each of their accounts
+func (server *Server) GetUserLimits(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	usages, err := server.store.ListLimitUsage(ctx, user.Username)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	res := userLimitsResponse{
+		Tier:   user.Tier,
+		Limits: make([]currencyLimitsResponse, len(usages)),
+	}
+	for i, usage := range usages {
+		res.Limits[i] = getCurrencyLimitsResponse(usage)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+func getCurrencyLimitsResponse(usage db.ListLimitUsageRow) currencyLimitsResponse {
+	return currencyLimitsResponse{
+		Account:           usage.Number,
+		Currency:          usage.Currency,
+		Product:           usage.Product,
+		PerTransfer:       getLimitResponse(usage.MaxPerTransfer, 0),
+		Daily:             getLimitResponse(usage.MaxPerDay, usage.SentToday),
+		Rolling30Days:     getLimitResponse(usage.MaxPer30Days, usage.Sent30Days),
+		TransfersPerHour:  getLimitResponse(usage.MaxTransfersPerHour, usage.TransfersLastHour),
+		TransfersPerMonth: getLimitResponse(usage.MaxTransfersPerMonth, usage.TransfersThisMonth),
+	}
+}
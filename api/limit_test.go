@@ -0,0 +1,106 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simple-bank/db/mock"
+	db "simple-bank/db/sqlc"
+	"simple-bank/utils"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUserLimitsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	user.Tier = db.DefaultTier
+
+	usages := []db.ListLimitUsageRow{
+		{
+			AccountID:            1,
+			Number:               utils.RandomAccountNumber(),
+			Currency:             utils.EUR,
+			Product:              db.ProductSavings,
+			Tier:                 user.Tier,
+			MaxPerTransfer:       1000,
+			MaxPerDay:            2000,
+			MaxTransfersPerHour:  10,
+			MaxTransfersPerMonth: 6,
+			SentToday:            2500,
+			Sent30Days:           4000,
+			TransfersLastHour:    3,
+			TransfersThisMonth:   4,
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().ListLimitUsage(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(usages, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res userLimitsResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.DefaultTier, res.Tier)
+				require.Len(t, res.Limits, 1)
+
+				limits := res.Limits[0]
+				require.Equal(t, utils.EUR, limits.Currency)
+				require.Equal(t, int64(1000), *limits.PerTransfer.Limit)
+				require.Equal(t, int64(1000), *limits.PerTransfer.Remaining)
+				// usage above a lowered limit leaves no headroom rather than a negative one
+				require.Equal(t, int64(2500), limits.Daily.Used)
+				require.Zero(t, *limits.Daily.Remaining)
+				require.Nil(t, limits.Rolling30Days.Limit)
+				require.Nil(t, limits.Rolling30Days.Remaining)
+				require.Equal(t, int64(4000), limits.Rolling30Days.Used)
+				require.Equal(t, int64(7), *limits.TransfersPerHour.Remaining)
+				require.Equal(t, usages[0].Number, limits.Account)
+				require.Equal(t, db.ProductSavings, limits.Product)
+				require.Equal(t, int64(2), *limits.TransfersPerMonth.Remaining)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().ListLimitUsage(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/users/me/limits", nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"os"
 	db "simple-bank/db/sqlc"
+	"simple-bank/storage"
 	"simple-bank/utils"
 	"testing"
 	"time"
@@ -17,7 +18,7 @@ func NewTestServer(t *testing.T, store db.Store) *Server {
 		ExpiryTokenDuration: time.Minute,
 	}
 
-	server, err := NewServer(config, store)
+	server, err := NewServer(config, store, db.NewAccountHub(), storage.NewMemoryStorage())
 	require.NoError(t, err)
 
 	return server
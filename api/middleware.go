@@ -1,9 +1,7 @@
 package api
 
 import (
-	"errors"
 	"fmt"
-	"net/http"
 	"simple-bank/token"
 	"strings"
 
@@ -20,30 +18,28 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorization := ctx.GetHeader(authorizationHeaderKey)
 		if len(authorization) == 0 {
-			err := errors.New("authorization header is not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorHandler(err))
+			errorResponse(ctx, ErrUnauthenticated.withDetail("authorization header is not provided"))
 			return
 		}
 
 		fields := strings.Fields(authorization)
 
 		if len(fields) < 2 {
-			err := errors.New("invalid authorization header format")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorHandler(err))
+			errorResponse(ctx, ErrUnauthenticated.withDetail("invalid authorization header format"))
 			return
 		}
 
 		authorizationType := strings.ToLower(fields[0])
 		if authorizationType != authorizationTypeBearer {
-			err := fmt.Errorf("unsupported authorization format %v", authorizationType)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorHandler(err))
+			detail := fmt.Sprintf("unsupported authorization format %v", authorizationType)
+			errorResponse(ctx, ErrUnauthenticated.withDetail(detail))
 			return
 		}
 
 		accessToken := fields[1]
 		payload, err := tokenMaker.VerifyToken(accessToken)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorHandler(err))
+			errorResponse(ctx, ErrUnauthenticated.withDetail(err.Error()))
 			return
 		}
 
@@ -0,0 +1,1151 @@
+package api
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	db "simple-bank/db/sqlc"
+	"simple-bank/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+//go:embed openapi
+var openAPIFiles embed.FS
+
+const (
+	openAPISpecFile    = "openapi/openapi.json"
+	openAPISwaggerFile = "openapi/swagger.html"
+)
+
+// apiOperation documents a single route registered in setupServerRoutes.
+// Request fields are read from their json, uri and form tags and their
+// binding tags are translated into schema constraints.
+// Responses are application/json unless ContentType says otherwise.
+type apiOperation struct {
+	Method      string
+	Path        string
+	Summary     string
+	Tag         string
+	Auth        bool
+	Request     any
+	Response    any
+	ContentType string
+	Status      int
+}
+
+var apiOperations = []apiOperation{
+	{
+		Method:   http.MethodPost,
+		Path:     "/users",
+		Summary:  "Create a user",
+		Tag:      "users",
+		Request:  createUserRequest{},
+		Response: userResponse{},
+		Status:   http.StatusCreated,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/users/login",
+		Summary:  "Log a user in and start a session",
+		Tag:      "users",
+		Request:  loginUserRequest{},
+		Response: loginUserRes{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/tokens/renew-access",
+		Summary:  "Renew an access token from a refresh token",
+		Tag:      "tokens",
+		Request:  renewTokenRequest{},
+		Response: renewTokenRes{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/users/me/limits",
+		Summary:  "Show the transfer limits of the authenticated user and the headroom left per currency",
+		Tag:      "users",
+		Auth:     true,
+		Response: userLimitsResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodPut,
+		Path:     "/users/me/alias",
+		Summary:  "Set the alias the authenticated user receives transfers at",
+		Tag:      "users",
+		Auth:     true,
+		Request:  updateUserAliasRequest{},
+		Response: userResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/users/me/email/verification",
+		Summary:  "Email a verification code to the authenticated user, valid for 24 hours",
+		Tag:      "users",
+		Auth:     true,
+		Response: emailVerificationResponse{},
+		Status:   http.StatusAccepted,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/users/me/email/verify",
+		Summary:  "Verify the authenticated user's email with the code sent to it, so it can address transfers",
+		Tag:      "users",
+		Auth:     true,
+		Request:  verifyEmailRequest{},
+		Response: userResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/recipients/lookup",
+		Summary:  "Confirm the masked name of a recipient addressed by username, verified email or @alias",
+		Tag:      "transfers",
+		Auth:     true,
+		Request:  lookupRecipientRequest{},
+		Response: recipientResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/account-products",
+		Summary:  "List the products accounts can be opened with",
+		Tag:      "accounts",
+		Auth:     true,
+		Response: []productResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/accounts",
+		Summary:  "Open an account for the authenticated user",
+		Tag:      "accounts",
+		Auth:     true,
+		Request:  CreateAccountRequest{},
+		Response: accountResponse{},
+		Status:   http.StatusCreated,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/accounts/:id",
+		Summary:  "Get an account the authenticated user is a member of",
+		Tag:      "accounts",
+		Auth:     true,
+		Request:  GetAccountRequest{},
+		Response: accountResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/accounts/:id/balance",
+		Summary:  "Get the balance of an account at a past instant",
+		Tag:      "accounts",
+		Auth:     true,
+		Request:  accountBalanceRequest{},
+		Response: accountBalanceResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/accounts/:id/stream",
+		Summary:     "Stream balance changes and new entries of an account as server-sent \"account\" events",
+		Tag:         "accounts",
+		Auth:        true,
+		Request:     GetAccountRequest{},
+		Response:    accountUpdate{},
+		ContentType: eventStreamContentType,
+		Status:      http.StatusOK,
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/accounts/:id/statement",
+		Summary:     "Download the statement of an account for a range of days as CSV, OFX or ISO 20022 camt.053",
+		Tag:         "accounts",
+		Auth:        true,
+		Request:     statementRequest{},
+		Response:    "",
+		ContentType: "application/octet-stream",
+		Status:      http.StatusOK,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/accounts/:id/transfers",
+		Summary:  "List the transfers of an account, newest first, optionally by reference",
+		Tag:      "transfers",
+		Auth:     true,
+		Request:  listAccountTransfersRequest{},
+		Response: []transferResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/accounts/:id/entries",
+		Summary:  "List the entries of an account with their category and tags, newest first",
+		Tag:      "categories",
+		Auth:     true,
+		Request:  listAccountEntriesRequest{},
+		Response: []categorizedEntryResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/accounts/:id/spending",
+		Summary:  "Sum what an account spent per category over a date range",
+		Tag:      "categories",
+		Auth:     true,
+		Request:  spendingRequest{},
+		Response: spendingResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/accounts/:id/analytics",
+		Summary:  "Inflow, outflow, net and transaction count of an account per day, week or month, with its top counterparties",
+		Tag:      "accounts",
+		Auth:     true,
+		Request:  analyticsRequest{},
+		Response: analyticsResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/accounts/:id/statements",
+		Summary:  "List the monthly PDF statements of an account, newest first",
+		Tag:      "accounts",
+		Auth:     true,
+		Request:  listAccountStatementsRequest{},
+		Response: []accountStatementResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/accounts/:id/statements/:period",
+		Summary:     "Download the monthly PDF statement of an account",
+		Tag:         "accounts",
+		Auth:        true,
+		Request:     accountStatementRequest{},
+		Response:    "",
+		ContentType: "application/pdf",
+		Status:      http.StatusOK,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/accounts",
+		Summary:  "List the accounts the authenticated user is a member of",
+		Tag:      "accounts",
+		Auth:     true,
+		Request:  ListAccountRequest{},
+		Response: []accountResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/accounts/:id/members",
+		Summary:  "Invite a user to share an account as co-owner, viewer or spender",
+		Tag:      "accounts",
+		Auth:     true,
+		Request:  inviteAccountMemberRequest{},
+		Response: accountMemberResponse{},
+		Status:   http.StatusCreated,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/accounts/:id/members",
+		Summary:  "List the members of an account and the pending invitations to it",
+		Tag:      "accounts",
+		Auth:     true,
+		Request:  GetAccountRequest{},
+		Response: []accountMemberResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:  http.MethodDelete,
+		Path:    "/accounts/:id/members/:username",
+		Summary: "Remove a member of an account, withdraw an invitation or leave the account",
+		Tag:     "accounts",
+		Auth:    true,
+		Request: accountMemberRequest{},
+		Status:  http.StatusNoContent,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/account-invitations",
+		Summary:  "List the pending invitations of the authenticated user to share accounts",
+		Tag:      "accounts",
+		Auth:     true,
+		Response: []accountMemberResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/account-invitations/:id/accept",
+		Summary:  "Accept an invitation to share an account",
+		Tag:      "accounts",
+		Auth:     true,
+		Request:  accountInvitationRequest{},
+		Response: accountMemberResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:  http.MethodPost,
+		Path:    "/account-invitations/:id/decline",
+		Summary: "Decline an invitation to share an account",
+		Tag:     "accounts",
+		Auth:    true,
+		Request: accountInvitationRequest{},
+		Status:  http.StatusNoContent,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/transfer",
+		Summary:  "Transfer money between two accounts",
+		Tag:      "transfers",
+		Auth:     true,
+		Request:  CreateTransferRequest{},
+		Response: transferTxnResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/transfer/quote",
+		Summary:  "Preview the fee of a transfer without moving money",
+		Tag:      "transfers",
+		Auth:     true,
+		Request:  CreateTransferRequest{},
+		Response: transferQuoteResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/transfer/batch",
+		Summary:  "Send up to 100 transfers from one account, all-or-nothing or best-effort",
+		Tag:      "transfers",
+		Auth:     true,
+		Request:  batchTransferRequest{},
+		Response: batchTransferResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/payees",
+		Summary:  "Save an account as a payee of the authenticated user",
+		Tag:      "payees",
+		Auth:     true,
+		Request:  createPayeeRequest{},
+		Response: payeeResponse{},
+		Status:   http.StatusCreated,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/payees",
+		Summary:  "List the payees of the authenticated user by nickname",
+		Tag:      "payees",
+		Auth:     true,
+		Response: []payeeResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/payees/:id",
+		Summary:  "Get a payee",
+		Tag:      "payees",
+		Auth:     true,
+		Request:  payeeRequest{},
+		Response: payeeResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     "/payees/:id",
+		Summary:  "Rename a payee",
+		Tag:      "payees",
+		Auth:     true,
+		Request:  updatePayeeRequest{},
+		Response: payeeResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:  http.MethodDelete,
+		Path:    "/payees/:id",
+		Summary: "Delete a payee",
+		Tag:     "payees",
+		Auth:    true,
+		Request: payeeRequest{},
+		Status:  http.StatusNoContent,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/payment-requests",
+		Summary:  "Ask another user, by username, verified email or @alias, to pay into an account",
+		Tag:      "payment-requests",
+		Auth:     true,
+		Request:  createPaymentRequestRequest{},
+		Response: paymentRequestResponse{},
+		Status:   http.StatusCreated,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/payment-requests/incoming",
+		Summary:  "List the payment requests the authenticated user is asked to pay, newest first",
+		Tag:      "payment-requests",
+		Auth:     true,
+		Request:  listPaymentRequestsRequest{},
+		Response: []paymentRequestResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/payment-requests/outgoing",
+		Summary:  "List the payment requests the authenticated user sent, newest first",
+		Tag:      "payment-requests",
+		Auth:     true,
+		Request:  listPaymentRequestsRequest{},
+		Response: []paymentRequestResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/payment-requests/:id",
+		Summary:  "Get a payment request sent or received by the authenticated user",
+		Tag:      "payment-requests",
+		Auth:     true,
+		Request:  paymentRequestRequest{},
+		Response: paymentRequestResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/payment-requests/:id/accept",
+		Summary:  "Pay a pending payment request",
+		Tag:      "payment-requests",
+		Auth:     true,
+		Request:  paymentRequestRequest{},
+		Response: acceptPaymentRequestResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/payment-requests/:id/decline",
+		Summary:  "Decline a pending payment request",
+		Tag:      "payment-requests",
+		Auth:     true,
+		Request:  paymentRequestRequest{},
+		Response: paymentRequestResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/payment-requests/:id/cancel",
+		Summary:  "Withdraw a pending payment request",
+		Tag:      "payment-requests",
+		Auth:     true,
+		Request:  paymentRequestRequest{},
+		Response: paymentRequestResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/holds",
+		Summary:  "Reserve funds on an account until the hold is captured, voided or expires",
+		Tag:      "holds",
+		Auth:     true,
+		Request:  createHoldRequest{},
+		Response: placeHoldResponse{},
+		Status:   http.StatusCreated,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/holds/:id",
+		Summary:  "Get a hold placed on or for an account of the authenticated user",
+		Tag:      "holds",
+		Auth:     true,
+		Request:  holdRequest{},
+		Response: holdResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/holds/:id/capture",
+		Summary:  "Capture all or part of a pending hold as a transfer, as its payee or payer",
+		Tag:      "holds",
+		Auth:     true,
+		Request:  captureHoldRequest{},
+		Response: captureHoldResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/holds/:id/void",
+		Summary:  "Cancel a pending hold and release its amount, as its payee",
+		Tag:      "holds",
+		Auth:     true,
+		Request:  holdRequest{},
+		Response: holdResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/categories",
+		Summary:  "Create a spending category",
+		Tag:      "categories",
+		Auth:     true,
+		Request:  createCategoryRequest{},
+		Response: categoryResponse{},
+		Status:   http.StatusCreated,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/categories",
+		Summary:  "List the categories of the authenticated user by name",
+		Tag:      "categories",
+		Auth:     true,
+		Response: []categoryResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:  http.MethodDelete,
+		Path:    "/categories/:id",
+		Summary: "Delete a category and its rules, its entries become uncategorized",
+		Tag:     "categories",
+		Auth:    true,
+		Request: categoryRequest{},
+		Status:  http.StatusNoContent,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/category-rules",
+		Summary:  "Add a rule categorizing entries by counterparty, memo keyword or amount",
+		Tag:      "categories",
+		Auth:     true,
+		Request:  createCategoryRuleRequest{},
+		Response: categoryRuleResponse{},
+		Status:   http.StatusCreated,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/category-rules",
+		Summary:  "List the category rules of the authenticated user in the order they're tried",
+		Tag:      "categories",
+		Auth:     true,
+		Response: []categoryRuleResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:  http.MethodDelete,
+		Path:    "/category-rules/:id",
+		Summary: "Delete a category rule",
+		Tag:     "categories",
+		Auth:    true,
+		Request: categoryRuleRequest{},
+		Status:  http.StatusNoContent,
+	},
+	{
+		Method:   http.MethodPut,
+		Path:     "/entries/:id/category",
+		Summary:  "Set or clear the category of an entry",
+		Tag:      "categories",
+		Auth:     true,
+		Request:  updateEntryCategoryRequest{},
+		Response: categorizedEntryResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodPut,
+		Path:     "/entries/:id/tags",
+		Summary:  "Replace the tags of an entry",
+		Tag:      "categories",
+		Auth:     true,
+		Request:  updateEntryTagsRequest{},
+		Response: categorizedEntryResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/webhooks",
+		Summary:  "Register a webhook endpoint, the response carries the signing secret",
+		Tag:      "webhooks",
+		Auth:     true,
+		Request:  createWebhookRequest{},
+		Response: createWebhookResponse{},
+		Status:   http.StatusCreated,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/webhooks",
+		Summary:  "List the webhooks of the authenticated user",
+		Tag:      "webhooks",
+		Auth:     true,
+		Response: []webhookResponse{},
+		Status:   http.StatusOK,
+	},
+	{
+		Method:  http.MethodDelete,
+		Path:    "/webhooks/:id",
+		Summary: "Delete a webhook",
+		Tag:     "webhooks",
+		Auth:    true,
+		Request: webhookRequest{},
+		Status:  http.StatusNoContent,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/webhooks/:id/deliveries",
+		Summary:  "List the delivery log of a webhook, newest first",
+		Tag:      "webhooks",
+		Auth:     true,
+		Request:  listWebhookDeliveriesRequest{},
+		Response: []webhookDeliveryResponse{},
+		Status:   http.StatusOK,
+	},
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       openAPIInfo                      `json:"info"`
+	Paths      map[string]map[string]*openAPIOp `json:"paths"`
+	Components openAPIComponents                `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*openAPISchema         `json:"schemas"`
+	SecuritySchemes map[string]*openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+type openAPIOp struct {
+	Summary     string                      `json:"summary"`
+	OperationID string                      `json:"operationId"`
+	Tags        []string                    `json:"tags"`
+	Security    []map[string][]string       `json:"security,omitempty"`
+	Parameters  []*openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required"`
+	Schema   *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                         `json:"required"`
+	Content  map[string]*openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref              string                    `json:"$ref,omitempty"`
+	Type             string                    `json:"type,omitempty"`
+	Format           string                    `json:"format,omitempty"`
+	Pattern          string                    `json:"pattern,omitempty"`
+	Enum             []string                  `json:"enum,omitempty"`
+	Minimum          *float64                  `json:"minimum,omitempty"`
+	ExclusiveMinimum bool                      `json:"exclusiveMinimum,omitempty"`
+	Maximum          *float64                  `json:"maximum,omitempty"`
+	MinLength        *int                      `json:"minLength,omitempty"`
+	MaxLength        *int                      `json:"maxLength,omitempty"`
+	MinItems         *int                      `json:"minItems,omitempty"`
+	MaxItems         *int                      `json:"maxItems,omitempty"`
+	Items            *openAPISchema            `json:"items,omitempty"`
+	Properties       map[string]*openAPISchema `json:"properties,omitempty"`
+	Required         []string                  `json:"required,omitempty"`
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	uuidType    = reflect.TypeOf(uuid.UUID{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+// buildOpenAPISpec generates the OpenAPI document from apiOperations
+func buildOpenAPISpec() (*openAPIDocument, error) {
+	doc := &openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title:   "Simple Bank API",
+			Version: "1.0.0",
+		},
+		Paths: map[string]map[string]*openAPIOp{},
+		Components: openAPIComponents{
+			Schemas: map[string]*openAPISchema{},
+			SecuritySchemes: map[string]*openAPISecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer"},
+			},
+		},
+	}
+
+	for _, op := range apiOperations {
+		operation, err := doc.buildOperation(op)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", op.Method, op.Path, err)
+		}
+
+		path := openAPIPath(op.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*openAPIOp{}
+		}
+		doc.Paths[path][strings.ToLower(op.Method)] = operation
+	}
+
+	return doc, nil
+}
+
+func (doc *openAPIDocument) buildOperation(op apiOperation) (*openAPIOp, error) {
+	operation := &openAPIOp{
+		Summary:     op.Summary,
+		OperationID: operationID(op.Method, op.Path),
+		Tags:        []string{op.Tag},
+		Responses:   map[string]*openAPIResponse{},
+	}
+
+	if op.Auth {
+		operation.Security = []map[string][]string{{"bearerAuth": {}}}
+	}
+
+	if op.Request != nil {
+		if err := doc.addRequest(operation, reflect.TypeOf(op.Request)); err != nil {
+			return nil, err
+		}
+	}
+
+	response := &openAPIResponse{Description: http.StatusText(op.Status)}
+	if op.Response != nil {
+		responseSchema, err := doc.schemaFor(reflect.TypeOf(op.Response))
+		if err != nil {
+			return nil, err
+		}
+		response.Content = jsonContent(responseSchema)
+		if op.ContentType != "" {
+			response.Content = map[string]*openAPIMediaType{
+				op.ContentType: {Schema: responseSchema},
+			}
+		}
+	}
+	operation.Responses[strconv.Itoa(op.Status)] = response
+
+	problemSchema, err := doc.schemaFor(reflect.TypeOf(ProblemDetails{}))
+	if err != nil {
+		return nil, err
+	}
+	operation.Responses["default"] = &openAPIResponse{
+		Description: "Error",
+		Content: map[string]*openAPIMediaType{
+			problemContentType: {Schema: problemSchema},
+		},
+	}
+
+	return operation, nil
+}
+
+// addRequest splits the request struct into path, query and body parameters
+// based on the tag each field is bound with
+func (doc *openAPIDocument) addRequest(operation *openAPIOp, t reflect.Type) error {
+	hasBody := false
+	for _, field := range requestFields(t) {
+		var in, name string
+		switch {
+		case field.Tag.Get("uri") != "":
+			in, name = "path", field.Tag.Get("uri")
+		case field.Tag.Get("form") != "":
+			in, name = "query", field.Tag.Get("form")
+		default:
+			hasBody = true
+			continue
+		}
+
+		schema, required, err := fieldSchema(field)
+		if err != nil {
+			return err
+		}
+		operation.Parameters = append(operation.Parameters, &openAPIParameter{
+			Name:     name,
+			In:       in,
+			Required: required || in == "path",
+			Schema:   schema,
+		})
+	}
+
+	if hasBody {
+		schema, err := doc.schemaFor(t)
+		if err != nil {
+			return err
+		}
+		operation.RequestBody = &openAPIRequestBody{
+			Required: true,
+			Content:  jsonContent(schema),
+		}
+	}
+
+	return nil
+}
+
+// requestFields lists the fields of a request, flattening embedded structs
+func requestFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, requestFields(field.Type)...)
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// schemaFor returns the schema of a type, registering named structs as components
+func (doc *openAPIDocument) schemaFor(t reflect.Type) (*openAPISchema, error) {
+	if isScalar(t) {
+		return scalarSchema(t)
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return doc.schemaFor(t.Elem())
+	case reflect.Slice, reflect.Array:
+		items, err := doc.schemaFor(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &openAPISchema{Type: "array", Items: items}, nil
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			// reserve the name first so recursive types terminate
+			doc.Components.Schemas[name] = nil
+			schema, err := doc.structSchema(t)
+			if err != nil {
+				return nil, err
+			}
+			doc.Components.Schemas[name] = schema
+		}
+		return &openAPISchema{Ref: "#/components/schemas/" + name}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+func (doc *openAPIDocument) structSchema(t reflect.Type) (*openAPISchema, error) {
+	schema := &openAPISchema{
+		Type:       "object",
+		Properties: map[string]*openAPISchema{},
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// encoding/json promotes the fields of embedded structs
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			embedded, err := doc.structSchema(field.Type)
+			if err != nil {
+				return nil, err
+			}
+			for name, property := range embedded.Properties {
+				schema.Properties[name] = property
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		name := jsonFieldName(field)
+		if name == "" {
+			continue
+		}
+
+		var property *openAPISchema
+		var required bool
+		var err error
+		switch {
+		case isScalar(field.Type):
+			property, required, err = fieldSchema(field)
+		case field.Type.Kind() == reflect.Slice && field.Tag.Get("binding") != "":
+			property, required, err = doc.sliceFieldSchema(field)
+		default:
+			property, err = doc.schemaFor(field.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("field %s.%s: %w", t.Name(), field.Name, err)
+		}
+
+		schema.Properties[name] = property
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+
+	return schema, nil
+}
+
+// fieldSchema translates the binding tag of a scalar field into schema constraints
+func fieldSchema(field reflect.StructField) (*openAPISchema, bool, error) {
+	schema, err := scalarSchema(field.Type)
+	if err != nil {
+		return nil, false, err
+	}
+	if field.Tag.Get("time_format") == dateFormat {
+		schema.Format = "date"
+	}
+
+	return applyRules(schema, field.Tag.Get("binding"))
+}
+
+// sliceFieldSchema applies the rules before "dive" to the array and the ones
+// after it to the items
+func (doc *openAPIDocument) sliceFieldSchema(field reflect.StructField) (*openAPISchema, bool, error) {
+	arrayRules, itemRules, _ := strings.Cut(field.Tag.Get("binding"), "dive")
+	arrayRules = strings.TrimSuffix(arrayRules, ",")
+	itemRules = strings.TrimPrefix(itemRules, ",")
+
+	var items *openAPISchema
+	var err error
+	if isScalar(field.Type.Elem()) {
+		items, err = scalarSchema(field.Type.Elem())
+		if err == nil {
+			items, _, err = applyRules(items, itemRules)
+		}
+	} else {
+		items, err = doc.schemaFor(field.Type.Elem())
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return applyRules(&openAPISchema{Type: "array", Items: items}, arrayRules)
+}
+
+func applyRules(schema *openAPISchema, binding string) (*openAPISchema, bool, error) {
+	required := false
+	if binding == "" {
+		return schema, false, nil
+	}
+
+	for _, rule := range strings.Split(binding, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "omitempty":
+			// optional fields are only checked when present, the schema already says so
+		case "min", "max", "gt":
+			if err := applyBound(schema, key, value); err != nil {
+				return nil, false, err
+			}
+		case "email":
+			schema.Format = "email"
+		case "alphanum":
+			schema.Pattern = "^[a-zA-Z0-9]+$"
+		case "currency":
+			schema.Enum = utils.SupportedCurrencies
+		case "account_number":
+			schema.Pattern = utils.AccountNumberPattern
+		case "url":
+			schema.Format = "uri"
+		case "webhook_url":
+			schema.Format = "uri"
+			schema.Pattern = "^https://"
+		case "webhook_event":
+			schema.Enum = db.EventTypes
+		case "oneof":
+			schema.Enum = strings.Fields(value)
+		case "gtefield", "required_if", "required_without", "required_without_all", "excluded_with":
+			// cross-field rules can't be expressed in a schema
+		case "recipient":
+			// usernames, emails and @aliases share no single pattern
+		case "alias":
+			schema.Pattern = utils.AliasPattern
+		case "metadata":
+			// the size limit is on the encoded object, which a schema can't express
+		case "datetime":
+			schema.Pattern = datetimePattern(value)
+		default:
+			return nil, false, fmt.Errorf("binding rule %q has no OpenAPI mapping", rule)
+		}
+	}
+
+	return schema, required, nil
+}
+
+// datetimePattern turns a Go time layout made of digits and separators into a regular expression
+func datetimePattern(layout string) string {
+	pattern := regexp.MustCompile("[0-9]").ReplaceAllString(regexp.QuoteMeta(layout), "[0-9]")
+	return "^" + pattern + "$"
+}
+
+func applyBound(schema *openAPISchema, key string, value string) error {
+	bound, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s bound %q", key, value)
+	}
+
+	if schema.Type == "array" {
+		switch key {
+		case "min":
+			schema.MinItems = &bound
+		case "max":
+			schema.MaxItems = &bound
+		default:
+			return fmt.Errorf("binding rule %s is not supported on arrays", key)
+		}
+		return nil
+	}
+
+	if schema.Type == "string" {
+		switch key {
+		case "min":
+			schema.MinLength = &bound
+		case "max":
+			schema.MaxLength = &bound
+		default:
+			return fmt.Errorf("binding rule %s is not supported on strings", key)
+		}
+		return nil
+	}
+
+	number := float64(bound)
+	switch key {
+	case "min":
+		schema.Minimum = &number
+	case "max":
+		schema.Maximum = &number
+	case "gt":
+		schema.Minimum = &number
+		schema.ExclusiveMinimum = true
+	}
+	return nil
+}
+
+func scalarSchema(t reflect.Type) (*openAPISchema, error) {
+	switch {
+	case t == timeType:
+		return &openAPISchema{Type: "string", Format: "date-time"}, nil
+	case t == uuidType:
+		return &openAPISchema{Type: "string", Format: "uuid"}, nil
+	case t == rawJSONType:
+		return &openAPISchema{Type: "object"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &openAPISchema{Type: "string"}, nil
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}, nil
+	case reflect.Int32:
+		return &openAPISchema{Type: "integer", Format: "int32"}, nil
+	case reflect.Int, reflect.Int64:
+		return &openAPISchema{Type: "integer", Format: "int64"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &openAPISchema{Type: "number"}, nil
+	case reflect.Map:
+		return &openAPISchema{Type: "object"}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+func isScalar(t reflect.Type) bool {
+	if t == timeType || t == uuidType || t == rawJSONType {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Ptr:
+		return false
+	}
+	return true
+}
+
+// jsonFieldName mirrors encoding/json naming, returning "" for skipped fields
+func jsonFieldName(field reflect.StructField) string {
+	// path parameters are bound from the uri, not the body
+	if !field.IsExported() || field.Tag.Get("uri") != "" {
+		return ""
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+func jsonContent(schema *openAPISchema) map[string]*openAPIMediaType {
+	return map[string]*openAPIMediaType{
+		"application/json": {Schema: schema},
+	}
+}
+
+// openAPIPath converts gin path parameters (:id) into OpenAPI ones ({id})
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func operationID(method string, path string) string {
+	var id strings.Builder
+	id.WriteString(strings.ToLower(method))
+	for _, segment := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '-' }) {
+		segment = strings.TrimPrefix(segment, ":")
+		id.WriteString(strings.ToUpper(segment[:1]) + segment[1:])
+	}
+	return id.String()
+}
+
+func (server *Server) getOpenAPISpec(ctx *gin.Context) {
+	spec, err := openAPIFiles.ReadFile(openAPISpecFile)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.Data(http.StatusOK, "application/json", spec)
+}
+
+func (server *Server) getSwaggerUI(ctx *gin.Context) {
+	page, err := openAPIFiles.ReadFile(openAPISwaggerFile)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", page)
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var updateOpenAPI = flag.Bool("update-openapi", false, "rewrite api/openapi/openapi.json from the handler structs")
+
+// TestOpenAPISpec fails when the binding tags of a request or the shape of a
+// response no longer match the committed OpenAPI document.
+func TestOpenAPISpec(t *testing.T) {
+	doc, err := buildOpenAPISpec()
+	require.NoError(t, err)
+
+	generated, err := json.MarshalIndent(doc, "", "  ")
+	require.NoError(t, err)
+	generated = append(generated, '\n')
+
+	if *updateOpenAPI {
+		err := os.WriteFile(filepath.FromSlash(openAPISpecFile), generated, 0644)
+		require.NoError(t, err)
+	}
+
+	committed, err := openAPIFiles.ReadFile(openAPISpecFile)
+	require.NoError(t, err)
+	require.JSONEq(t, string(committed), string(generated), "openapi.json is out of date, run make openapi")
+}
+
+func TestOpenAPIRoutes(t *testing.T) {
+	server := NewTestServer(t, nil)
+
+	documented := map[string]bool{}
+	for _, op := range apiOperations {
+		documented[op.Method+" "+op.Path] = true
+	}
+
+	for _, route := range server.router.Routes() {
+		if route.Path == "/openapi.json" || route.Path == "/docs" {
+			continue
+		}
+		key := route.Method + " " + route.Path
+		require.True(t, documented[key], "route %s is missing from apiOperations", key)
+		delete(documented, key)
+	}
+
+	require.Empty(t, documented, "apiOperations documents routes that are not registered")
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	server := NewTestServer(t, nil)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/openapi.json", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var doc openAPIDocument
+	err = json.Unmarshal(recorder.Body.Bytes(), &doc)
+	require.NoError(t, err)
+	require.Equal(t, "3.0.3", doc.OpenAPI)
+	require.NotEmpty(t, doc.Paths)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/docs", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), "swagger-ui")
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	db "simple-bank/db/sqlc"
+	"simple-bank/token"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createPayeeRequest struct {
+	Nickname      string `json:"nickname" binding:"required,max=50"`
+	AccountNumber string `json:"account_number" binding:"required,account_number"`
+	Currency      string `json:"currency" binding:"required,currency"`
+}
+
+// payeeResponse shows CoolingOffUntil while transfers above the cooling-off limit
+// to the payee are still refused
+type payeeResponse struct {
+	ID              int64      `json:"id"`
+	Nickname        string     `json:"nickname"`
+	AccountNumber   string     `json:"account_number"`
+	Currency        string     `json:"currency"`
+	CreatedAt       time.Time  `json:"created_at"`
+	CoolingOffUntil *time.Time `json:"cooling_off_until,omitempty"`
+}
+
+func (server *Server) getPayeeResponse(payee db.GetPayeeRow) payeeResponse {
+	res := payeeResponse{
+		ID:            payee.ID,
+		Nickname:      payee.Nickname,
+		AccountNumber: payee.AccountNumber,
+		Currency:      payee.Currency,
+		CreatedAt:     payee.CreatedAt,
+	}
+	if until := server.payeeCoolingOff().Until(payee.CreatedAt); time.Now().Before(until) {
+		res.CoolingOffUntil = &until
+	}
+	return res
+}
+
+// payeeCoolingOff is the cooling-off policy transfers and holds are placed with
+func (server *Server) payeeCoolingOff() db.PayeeCoolingOff {
+	return db.PayeeCoolingOff{
+		Period: server.config.PayeeCoolingOffPeriod,
+		Limit:  server.config.PayeeCoolingOffLimit,
+	}
+}
+
+func (server *Server) CreatePayee(ctx *gin.Context) {
+	var req createPayeeRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	account, valid := server.validAccount(ctx, req.AccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	payee, err := server.store.CreatePayee(ctx, db.CreatePayeeParams{
+		Owner:     authPayload.Username,
+		Nickname:  req.Nickname,
+		AccountID: account.ID,
+		Currency:  account.Currency,
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, server.getPayeeResponse(db.GetPayeeRow{
+		ID:            payee.ID,
+		Owner:         payee.Owner,
+		Nickname:      payee.Nickname,
+		AccountID:     payee.AccountID,
+		Currency:      payee.Currency,
+		CreatedAt:     payee.CreatedAt,
+		AccountNumber: account.Number,
+	}))
+}
+
+func (server *Server) ListPayees(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	payees, err := server.store.ListPayees(ctx, authPayload.Username)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	res := make([]payeeResponse, len(payees))
+	for i, payee := range payees {
+		res[i] = server.getPayeeResponse(db.GetPayeeRow(payee))
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type payeeRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) GetPayee(ctx *gin.Context) {
+	var req payeeRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	payee, valid := server.getUserPayee(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, server.getPayeeResponse(payee))
+}
+
+type updatePayeeRequest struct {
+	payeeRequest
+	Nickname string `json:"nickname" binding:"required,max=50"`
+}
+
+// UpdatePayee renames a payee, its account can't change so the cooling-off period isn't reset
+func (server *Server) UpdatePayee(ctx *gin.Context) {
+	var req updatePayeeRequest
+
+	if err := ctx.ShouldBindUri(&req.payeeRequest); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	payee, valid := server.getUserPayee(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	updated, err := server.store.UpdatePayeeNickname(ctx, db.UpdatePayeeNicknameParams{
+		ID:       payee.ID,
+		Nickname: req.Nickname,
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	payee.Nickname = updated.Nickname
+	ctx.JSON(http.StatusOK, server.getPayeeResponse(payee))
+}
+
+func (server *Server) DeletePayee(ctx *gin.Context) {
+	var req payeeRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	if _, valid := server.getUserPayee(ctx, req.ID); !valid {
+		return
+	}
+
+	if err := server.store.DeletePayee(ctx, req.ID); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// payeeAccount resolves a transfer to a payee, the transfer applies its cooling-off
+func (server *Server) payeeAccount(ctx *gin.Context, id int64, currency string) (db.Account, bool) {
+	payee, valid := server.getUserPayee(ctx, id)
+	if !valid {
+		return db.Account{}, false
+	}
+
+	account, err := server.store.GetAccount(ctx, payee.AccountID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return account, false
+	}
+
+	return account, checkCurrency(ctx, account, currency)
+}
+
+// getUserPayee loads a payee of the authenticated user, other users' payees are reported as not found
+func (server *Server) getUserPayee(ctx *gin.Context, id int64) (db.GetPayeeRow, bool) {
+	payee, err := server.store.GetPayee(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			errorResponse(ctx, payeeNotFound(id))
+			return payee, false
+		}
+		errorResponse(ctx, err)
+		return payee, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if payee.Owner != authPayload.Username {
+		errorResponse(ctx, payeeNotFound(id))
+		return db.GetPayeeRow{}, false
+	}
+
+	return payee, true
+}
+
+func payeeNotFound(id int64) *apiError {
+	return ErrPayeeNotFound.withDetail(fmt.Sprintf("payee [%d] not found", id))
+}
@@ -0,0 +1,335 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simple-bank/db/mock"
+	db "simple-bank/db/sqlc"
+	"simple-bank/utils"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatePayeeAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	recipient, _ := randomUser(t)
+	account := randomAccount(recipient.Username)
+
+	payee := db.Payee{
+		ID:        utils.RandomInt(1, 1000),
+		Owner:     user.Username,
+		Nickname:  "rent",
+		AccountID: account.ID,
+		Currency:  account.Currency,
+		CreatedAt: time.Now(),
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().
+					CreatePayee(gomock.Any(), gomock.Eq(db.CreatePayeeParams{
+						Owner:     user.Username,
+						Nickname:  payee.Nickname,
+						AccountID: account.ID,
+						Currency:  account.Currency,
+					})).
+					Times(1).
+					Return(payee, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res payeeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, payee.ID, res.ID)
+				require.Equal(t, account.Number, res.AccountNumber)
+				require.NotNil(t, res.CoolingOffUntil)
+				require.WithinDuration(t, payee.CreatedAt.Add(time.Hour), *res.CoolingOffUntil, time.Second)
+			},
+		},
+		{
+			name: "AlreadyAPayee",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().
+					CreatePayee(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Payee{}, &pq.Error{Code: "23505", Constraint: "payees_owner_account_key"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, ErrPayeeExists.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			server.config.PayeeCoolingOffPeriod = time.Hour
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(gin.H{
+				"nickname":       payee.Nickname,
+				"account_number": account.Number,
+				"currency":       account.Currency,
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/payees", bytes.NewReader(body))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestTransferToPayeeAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account2.Currency = account1.Currency
+
+	payee := db.GetPayeeRow{
+		ID:            utils.RandomInt(1, 1000),
+		Owner:         user1.Username,
+		Nickname:      "rent",
+		AccountID:     account2.ID,
+		Currency:      account2.Currency,
+		AccountNumber: account2.Number,
+		CreatedAt:     time.Now(),
+	}
+	coolingOff := db.PayeeCoolingOff{Period: time.Hour, Limit: 100}
+
+	testCases := []struct {
+		name          string
+		amount        int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			amount: 100,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TransferTxn(gomock.Any(), gomock.Eq(db.TransferTxnParam{
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        100,
+						Sender:        user1.Username,
+						CoolingOff:    coolingOff,
+					})).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "CoolingOff",
+			amount: 101,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TransferTxn(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxnResult{}, coolingOff.Check(payee.ID, payee.CreatedAt, 101))
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, ErrPayeeCoolingOff.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+
+			store.EXPECT().GetPayee(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			server.config.PayeeCoolingOffPeriod = coolingOff.Period
+			server.config.PayeeCoolingOffLimit = coolingOff.Limit
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(gin.H{
+				"fromAccountId": account1.Number,
+				"payee_id":      payee.ID,
+				"currency":      account1.Currency,
+				"amount":        tc.amount,
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfer", bytes.NewReader(body))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestPayeeCoolingOffByAccountAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account2.Currency = account1.Currency
+
+	payee := db.Payee{
+		ID:        utils.RandomInt(1, 1000),
+		Owner:     user1.Username,
+		Nickname:  "rent",
+		AccountID: account2.ID,
+		Currency:  account2.Currency,
+		CreatedAt: time.Now(),
+	}
+	coolingOff := db.PayeeCoolingOff{Period: time.Hour, Limit: 100}
+	coolingOffErr := coolingOff.Check(payee.ID, payee.CreatedAt, 101)
+
+	testCases := []struct {
+		name          string
+		path          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "AccountNumber",
+			path: "/transfer",
+			body: gin.H{"toAccountId": account2.Number, "amount": 101},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					TransferTxn(gomock.Any(), gomock.Eq(db.TransferTxnParam{
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        101,
+						Sender:        user1.Username,
+						CoolingOff:    coolingOff,
+					})).
+					Times(1).
+					Return(db.TransferTxnResult{}, coolingOffErr)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblemCode(t, recorder, ErrPayeeCoolingOff.Code)
+			},
+		},
+		{
+			name: "Recipient",
+			path: "/transfer",
+			body: gin.H{"to": user2.Username, "amount": 101},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetRecipient(gomock.Any(), gomock.Any()).Times(1).Return(user2, nil)
+				store.EXPECT().GetAccountByOwnerCurrency(gomock.Any(), gomock.Any()).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxnResult{}, coolingOffErr)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblemCode(t, recorder, ErrPayeeCoolingOff.Code)
+			},
+		},
+		{
+			name: "Hold",
+			path: "/holds",
+			body: gin.H{"toAccountId": account2.Number, "amount": 101},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					PlaceHoldTxn(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, args db.PlaceHoldTxnParams) (db.PlaceHoldTxnResult, error) {
+						require.Equal(t, coolingOff, args.CoolingOff)
+						return db.PlaceHoldTxnResult{}, coolingOffErr
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblemCode(t, recorder, ErrPayeeCoolingOff.Code)
+			},
+		},
+		{
+			name: "Batch",
+			path: "/transfer/batch",
+			body: gin.H{
+				"mode": batchBestEffort,
+				"transfers": []gin.H{
+					{"toAccountId": account2.Number, "amount": 100},
+					{"toAccountId": account2.Number, "amount": 101},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByNumbers(gomock.Any(), gomock.Any()).Times(1).Return([]db.Account{account2}, nil)
+				store.EXPECT().ListPayees(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return([]db.ListPayeesRow{{
+					ID:        payee.ID,
+					Owner:     payee.Owner,
+					AccountID: payee.AccountID,
+					CreatedAt: payee.CreatedAt,
+				}}, nil)
+				store.EXPECT().BatchTransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				problem := requireProblemCode(t, recorder, ErrBatchInvalid.Code)
+				require.Len(t, problem.Errors, 1)
+				require.Equal(t, "transfers[1].toAccountId", problem.Errors[0].Field)
+				require.Equal(t, "cooling_off", problem.Errors[0].Rule)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			server.config.PayeeCoolingOffPeriod = coolingOff.Period
+			server.config.PayeeCoolingOffLimit = coolingOff.Limit
+			recorder := httptest.NewRecorder()
+
+			tc.body["fromAccountId"] = account1.Number
+			tc.body["currency"] = account1.Currency
+			body, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, tc.path, bytes.NewReader(body))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
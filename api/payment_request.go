@@ -0,0 +1,329 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	db "simple-bank/db/sqlc"
+	"simple-bank/token"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultPaymentRequestExpiry = 7 * 24 * time.Hour
+
+// createPaymentRequestRequest asks Payer, a username, verified email or @alias, to pay
+// Amount into ToAccountId, an account of the requester. ExpiresIn is in seconds.
+type createPaymentRequestRequest struct {
+	ToAccountId string `json:"toAccountId" binding:"required,account_number"`
+	Payer       string `json:"payer" binding:"required,recipient"`
+	Currency    string `json:"currency" binding:"required,currency"`
+	Amount      int64  `json:"amount" binding:"required,gt=0"`
+	Note        string `json:"note" binding:"max=140"`
+	ExpiresIn   int64  `json:"expires_in" binding:"omitempty,min=60,max=2592000"`
+}
+
+type paymentRequestResponse struct {
+	ID          int64      `json:"id"`
+	Requester   string     `json:"requester"`
+	Payer       string     `json:"payer"`
+	ToAccount   string     `json:"to_account"`
+	Amount      int64      `json:"amount"`
+	Currency    string     `json:"currency"`
+	Note        string     `json:"note"`
+	Status      string     `json:"status"`
+	TransferID  *int64     `json:"transfer_id,omitempty"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func getPaymentRequestResponse(request db.PaymentRequest, toAccountNumber string) paymentRequestResponse {
+	res := paymentRequestResponse{
+		ID:        request.ID,
+		Requester: request.Requester,
+		Payer:     request.Payer,
+		ToAccount: toAccountNumber,
+		Amount:    request.Amount,
+		Currency:  request.Currency,
+		Note:      request.Note,
+		Status:    request.Status,
+		ExpiresAt: request.ExpiresAt,
+		CreatedAt: request.CreatedAt,
+	}
+	if request.TransferID.Valid {
+		res.TransferID = &request.TransferID.Int64
+	}
+	if request.RespondedAt.Valid {
+		res.RespondedAt = &request.RespondedAt.Time
+	}
+	return res
+}
+
+// getPaymentRequestRowResponse exposes a request read with its account number joined in
+func getPaymentRequestRowResponse(row db.GetPaymentRequestRow) paymentRequestResponse {
+	return getPaymentRequestResponse(db.PaymentRequest{
+		ID:          row.ID,
+		Requester:   row.Requester,
+		ToAccountID: row.ToAccountID,
+		Payer:       row.Payer,
+		Amount:      row.Amount,
+		Currency:    row.Currency,
+		Note:        row.Note,
+		Status:      row.Status,
+		TransferID:  row.TransferID,
+		ExpiresAt:   row.ExpiresAt,
+		RespondedAt: row.RespondedAt,
+		CreatedAt:   row.CreatedAt,
+	}, row.ToAccountNumber)
+}
+
+// CreatePaymentRequest asks another user for money. The payer must hold an account in
+// the requested currency, the same way a transfer to them would be resolved.
+func (server *Server) CreatePaymentRequest(ctx *gin.Context) {
+	var req createPaymentRequestRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	toAccount, valid := server.getUserAccount(ctx, req.ToAccountId)
+	if !valid || !checkCurrency(ctx, toAccount, req.Currency) {
+		return
+	}
+
+	payer, valid := server.getRecipient(ctx, req.Payer)
+	if !valid {
+		return
+	}
+	// any member of the account can request money into it, the request is theirs
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if payer.Username == authPayload.Username {
+		errorResponse(ctx, ErrValidationFailed.withDetail("can't request money from yourself"))
+		return
+	}
+	if _, valid := server.getRecipientAccount(ctx, payer, req.Payer, req.Currency); !valid {
+		return
+	}
+
+	expiry := defaultPaymentRequestExpiry
+	if req.ExpiresIn > 0 {
+		expiry = time.Duration(req.ExpiresIn) * time.Second
+	}
+
+	request, err := server.store.CreatePaymentRequest(ctx, db.CreatePaymentRequestParams{
+		Requester:   authPayload.Username,
+		ToAccountID: toAccount.ID,
+		Payer:       payer.Username,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Note:        req.Note,
+		ExpiresAt:   time.Now().Add(expiry),
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, getPaymentRequestResponse(request, toAccount.Number))
+}
+
+type listPaymentRequestsRequest struct {
+	Status   string `form:"status" binding:"omitempty,oneof=pending paid declined cancelled expired"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=1,max=50"`
+}
+
+// ListIncomingPaymentRequests lists the requests the authenticated user is asked to pay, newest first
+func (server *Server) ListIncomingPaymentRequests(ctx *gin.Context) {
+	var req listPaymentRequestsRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	requests, err := server.store.ListIncomingPaymentRequests(ctx, db.ListIncomingPaymentRequestsParams{
+		Payer:  authPayload.Username,
+		Status: sql.NullString{String: req.Status, Valid: req.Status != ""},
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	res := make([]paymentRequestResponse, len(requests))
+	for i, request := range requests {
+		res[i] = getPaymentRequestRowResponse(db.GetPaymentRequestRow(request))
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// ListOutgoingPaymentRequests lists the requests the authenticated user sent, newest first
+func (server *Server) ListOutgoingPaymentRequests(ctx *gin.Context) {
+	var req listPaymentRequestsRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	requests, err := server.store.ListOutgoingPaymentRequests(ctx, db.ListOutgoingPaymentRequestsParams{
+		Requester: authPayload.Username,
+		Status:    sql.NullString{String: req.Status, Valid: req.Status != ""},
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	res := make([]paymentRequestResponse, len(requests))
+	for i, request := range requests {
+		res[i] = getPaymentRequestRowResponse(db.GetPaymentRequestRow(request))
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type paymentRequestRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// GetPaymentRequest shows a request to its requester or its payer
+func (server *Server) GetPaymentRequest(ctx *gin.Context) {
+	var req paymentRequestRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	request, valid := server.getUserPaymentRequest(ctx, req.ID, func(request db.GetPaymentRequestRow, username string) bool {
+		return request.Requester == username || request.Payer == username
+	})
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getPaymentRequestRowResponse(request))
+}
+
+type acceptPaymentRequestResponse struct {
+	Request  paymentRequestResponse `json:"request"`
+	Transfer transferTxnResponse    `json:"transfer"`
+}
+
+// AcceptPaymentRequest pays a pending request from the payer's account in its currency
+func (server *Server) AcceptPaymentRequest(ctx *gin.Context) {
+	var req paymentRequestRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	request, valid := server.getUserPaymentRequest(ctx, req.ID, isPayer)
+	if !valid {
+		return
+	}
+
+	result, err := server.store.AcceptPaymentRequestTxn(ctx, db.AcceptPaymentRequestTxnParams{
+		RequestID:  request.ID,
+		CoolingOff: server.payeeCoolingOff(),
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, acceptPaymentRequestResponse{
+		Request:  getPaymentRequestResponse(result.Request, request.ToAccountNumber),
+		Transfer: getTransferTxnResponse(result.Transfer),
+	})
+}
+
+// DeclinePaymentRequest lets the payer turn a pending request down
+func (server *Server) DeclinePaymentRequest(ctx *gin.Context) {
+	server.respondPaymentRequest(ctx, db.PaymentRequestDeclined, isPayer)
+}
+
+// CancelPaymentRequest lets the requester withdraw a pending request
+func (server *Server) CancelPaymentRequest(ctx *gin.Context) {
+	server.respondPaymentRequest(ctx, db.PaymentRequestCancelled, func(request db.GetPaymentRequestRow, username string) bool {
+		return request.Requester == username
+	})
+}
+
+func isPayer(request db.GetPaymentRequestRow, username string) bool {
+	return request.Payer == username
+}
+
+// respondPaymentRequest closes a pending request without paying it
+func (server *Server) respondPaymentRequest(ctx *gin.Context, status string, allowed func(db.GetPaymentRequestRow, string) bool) {
+	var req paymentRequestRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	request, valid := server.getUserPaymentRequest(ctx, req.ID, allowed)
+	if !valid {
+		return
+	}
+	if !request.ExpiresAt.After(time.Now()) {
+		errorResponse(ctx, ErrPaymentRequestExpired)
+		return
+	}
+
+	updated, err := server.store.RespondPaymentRequest(ctx, db.RespondPaymentRequestParams{
+		ID:     request.ID,
+		Status: status,
+	})
+	if err != nil {
+		// the request was no longer pending
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = ErrPaymentRequestNotPending
+		}
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getPaymentRequestResponse(updated, request.ToAccountNumber))
+}
+
+// getUserPaymentRequest loads a request the authenticated user is allowed to act on,
+// any other request is reported as not found
+func (server *Server) getUserPaymentRequest(ctx *gin.Context, id int64, allowed func(db.GetPaymentRequestRow, string) bool) (db.GetPaymentRequestRow, bool) {
+	request, err := server.store.GetPaymentRequest(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			errorResponse(ctx, paymentRequestNotFound(id))
+			return request, false
+		}
+		errorResponse(ctx, err)
+		return request, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !allowed(request, authPayload.Username) {
+		errorResponse(ctx, paymentRequestNotFound(id))
+		return db.GetPaymentRequestRow{}, false
+	}
+
+	return request, true
+}
+
+func paymentRequestNotFound(id int64) *apiError {
+	return ErrPaymentRequestNotFound.withDetail(fmt.Sprintf("payment request [%d] not found", id))
+}
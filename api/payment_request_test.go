@@ -0,0 +1,271 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simple-bank/db/mock"
+	db "simple-bank/db/sqlc"
+	"simple-bank/utils"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatePaymentRequestAPI(t *testing.T) {
+	requester, _ := randomUser(t)
+	payer, _ := randomUser(t)
+	account := randomAccount(requester.Username)
+	account.Currency = utils.EUR
+	payerAccount := randomAccount(payer.Username)
+	payerAccount.Currency = utils.EUR
+
+	payerParams := db.GetRecipientParams{Username: sql.NullString{String: payer.Username, Valid: true}}
+
+	testCases := []struct {
+		name          string
+		payer         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			payer: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().GetRecipient(gomock.Any(), gomock.Eq(payerParams)).Times(1).Return(payer, nil)
+				store.EXPECT().GetAccountByOwnerCurrency(gomock.Any(), gomock.Any()).Times(1).Return(payerAccount, nil)
+				store.EXPECT().
+					CreatePaymentRequest(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, args db.CreatePaymentRequestParams) (db.PaymentRequest, error) {
+						require.Equal(t, requester.Username, args.Requester)
+						require.Equal(t, account.ID, args.ToAccountID)
+						require.Equal(t, payer.Username, args.Payer)
+						require.Equal(t, "dinner", args.Note)
+						require.WithinDuration(t, time.Now().Add(defaultPaymentRequestExpiry), args.ExpiresAt, time.Minute)
+
+						return db.PaymentRequest{
+							ID:          1,
+							Requester:   args.Requester,
+							ToAccountID: args.ToAccountID,
+							Payer:       args.Payer,
+							Amount:      args.Amount,
+							Currency:    args.Currency,
+							Note:        args.Note,
+							Status:      db.PaymentRequestPending,
+							ExpiresAt:   args.ExpiresAt,
+						}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res paymentRequestResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.PaymentRequestPending, res.Status)
+				require.Equal(t, account.Number, res.ToAccount)
+				require.Equal(t, payer.Username, res.Payer)
+			},
+		},
+		{
+			name:  "PayerWithoutAccountInCurrency",
+			payer: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().GetRecipient(gomock.Any(), gomock.Eq(payerParams)).Times(1).Return(payer, nil)
+				store.EXPECT().GetAccountByOwnerCurrency(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, ErrNoRecipientAccount.Code)
+			},
+		},
+		{
+			name:  "FromYourself",
+			payer: requester.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().GetRecipient(gomock.Any(), gomock.Any()).Times(1).Return(requester, nil)
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, ErrValidationFailed.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(gin.H{
+				"toAccountId": account.Number,
+				"payer":       tc.payer,
+				"currency":    utils.EUR,
+				"amount":      250,
+				"note":        "dinner",
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/payment-requests", bytes.NewReader(body))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, requester.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestRespondPaymentRequestAPI(t *testing.T) {
+	requester, _ := randomUser(t)
+	payer, _ := randomUser(t)
+	account := randomAccount(requester.Username)
+
+	pending := db.GetPaymentRequestRow{
+		ID:              utils.RandomInt(1, 1000),
+		Requester:       requester.Username,
+		ToAccountID:     account.ID,
+		Payer:           payer.Username,
+		Amount:          250,
+		Currency:        account.Currency,
+		Status:          db.PaymentRequestPending,
+		ExpiresAt:       time.Now().Add(time.Hour),
+		ToAccountNumber: account.Number,
+	}
+	expired := pending
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+
+	testCases := []struct {
+		name          string
+		action        string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Accept",
+			action:   "accept",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().
+					AcceptPaymentRequestTxn(gomock.Any(), gomock.Eq(db.AcceptPaymentRequestTxnParams{RequestID: pending.ID})).
+					Times(1).
+					Return(db.AcceptPaymentRequestTxnResult{
+						Request: db.PaymentRequest{
+							ID:         pending.ID,
+							Status:     db.PaymentRequestPaid,
+							TransferID: sql.NullInt64{Int64: 9, Valid: true},
+						},
+						Transfer: db.TransferTxnResult{Transfer: db.Transfer{ID: 9, Amount: pending.Amount}},
+					}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res acceptPaymentRequestResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.PaymentRequestPaid, res.Request.Status)
+				require.Equal(t, int64(9), *res.Request.TransferID)
+				require.Equal(t, pending.Amount, res.Transfer.Transfer.Amount)
+			},
+		},
+		{
+			name:     "AcceptByRequester",
+			action:   "accept",
+			username: requester.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().AcceptPaymentRequestTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, ErrPaymentRequestNotFound.Code)
+			},
+		},
+		{
+			name:     "DeclineAlreadyAnswered",
+			action:   "decline",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().RespondPaymentRequest(gomock.Any(), gomock.Any()).Times(1).Return(db.PaymentRequest{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, ErrPaymentRequestNotPending.Code)
+			},
+		},
+		{
+			name:     "DeclineExpired",
+			action:   "decline",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(expired, nil)
+				store.EXPECT().RespondPaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, ErrPaymentRequestExpired.Code)
+			},
+		},
+		{
+			name:     "Cancel",
+			action:   "cancel",
+			username: requester.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().
+					RespondPaymentRequest(gomock.Any(), gomock.Eq(db.RespondPaymentRequestParams{ID: pending.ID, Status: db.PaymentRequestCancelled})).
+					Times(1).
+					Return(db.PaymentRequest{ID: pending.ID, Status: db.PaymentRequestCancelled}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res paymentRequestResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.PaymentRequestCancelled, res.Status)
+				require.Equal(t, account.Number, res.ToAccount)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/payment-requests/%d/%s", pending.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
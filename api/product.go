@@ -0,0 +1,74 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	db "simple-bank/db/sqlc"
+
+	"github.com/gin-gonic/gin"
+)
+
+// productResponse describes an account product, Currencies is empty when every
+// supported currency is offered and MaxTransfersPerMonth is 0 when unlimited
+type productResponse struct {
+	Code                 string   `json:"code"`
+	Name                 string   `json:"name"`
+	Currencies           []string `json:"currencies"`
+	InterestRateBps      int32    `json:"interest_rate_bps"`
+	OverdraftLimit       int64    `json:"overdraft_limit"`
+	MaxTransfersPerMonth int32    `json:"max_transfers_per_month"`
+	OwnAccountsOnly      bool     `json:"own_accounts_only"`
+}
+
+func getProductResponse(product db.AccountProduct) productResponse {
+	currencies := product.Currencies
+	if currencies == nil {
+		currencies = []string{}
+	}
+	return productResponse{
+		Code:                 product.Code,
+		Name:                 product.Name,
+		Currencies:           currencies,
+		InterestRateBps:      product.InterestRateBps,
+		OverdraftLimit:       product.OverdraftLimit,
+		MaxTransfersPerMonth: product.MaxTransfersPerMonth,
+		OwnAccountsOnly:      product.OwnAccountsOnly,
+	}
+}
+
+// ListAccountProducts lists the products accounts can be opened with
+func (server *Server) ListAccountProducts(ctx *gin.Context) {
+	products, err := server.store.ListAccountProducts(ctx)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	res := make([]productResponse, len(products))
+	for i, product := range products {
+		res[i] = getProductResponse(product)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// getAccountProduct loads the product an account is opened with and checks it's
+// offered in currency
+func (server *Server) getAccountProduct(ctx *gin.Context, code string, currency string) (db.AccountProduct, bool) {
+	product, err := server.store.GetAccountProduct(ctx, code)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = ErrProductNotFound.withDetail(fmt.Sprintf("product [%s] not found", code))
+		}
+		errorResponse(ctx, err)
+		return product, false
+	}
+
+	if !product.AllowsCurrency(currency) {
+		errorResponse(ctx, ErrProductCurrency.withDetail(fmt.Sprintf("product [%s] isn't offered in %s", code, currency)))
+		return product, false
+	}
+
+	return product, true
+}
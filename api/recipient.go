@@ -0,0 +1,107 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	db "simple-bank/db/sqlc"
+	"simple-bank/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type lookupRecipientRequest struct {
+	To       string `form:"to" binding:"required,recipient"`
+	Currency string `form:"currency" binding:"required,currency"`
+}
+
+// recipientResponse confirms who a transfer would reach without disclosing their details
+type recipientResponse struct {
+	To       string `json:"to"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+}
+
+// LookupRecipient resolves a username, verified email or @alias to the recipient's
+// masked full name, so the sender can check it before transferring
+func (server *Server) LookupRecipient(ctx *gin.Context) {
+	var req lookupRecipientRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	user, valid := server.getRecipient(ctx, req.To)
+	if !valid {
+		return
+	}
+	if _, valid := server.getRecipientAccount(ctx, user, req.To, req.Currency); !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recipientResponse{
+		To:       req.To,
+		Name:     utils.MaskName(user.FullName),
+		Currency: req.Currency,
+	})
+}
+
+// resolveToAccount finds the account a transfer goes to: by its number, a saved payee
+// or the recipient's account in the transfer currency
+func (server *Server) resolveToAccount(ctx *gin.Context, req CreateTransferRequest) (db.Account, bool) {
+	if req.PayeeId != 0 {
+		return server.payeeAccount(ctx, req.PayeeId, req.Currency)
+	}
+
+	if req.ToAccountId != "" {
+		return server.validAccount(ctx, req.ToAccountId, req.Currency)
+	}
+
+	user, valid := server.getRecipient(ctx, req.To)
+	if !valid {
+		return db.Account{}, false
+	}
+	return server.getRecipientAccount(ctx, user, req.To, req.Currency)
+}
+
+func (server *Server) getRecipient(ctx *gin.Context, to string) (db.User, bool) {
+	var args db.GetRecipientParams
+	kind, value, _ := utils.ParseRecipient(to)
+	switch kind {
+	case utils.RecipientAlias:
+		args.Alias = sql.NullString{String: value, Valid: true}
+	case utils.RecipientEmail:
+		args.Email = sql.NullString{String: value, Valid: true}
+	default:
+		args.Username = sql.NullString{String: value, Valid: true}
+	}
+
+	user, err := server.store.GetRecipient(ctx, args)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = ErrRecipientNotFound.withDetail(fmt.Sprintf("recipient [%s] not found", to))
+		}
+		errorResponse(ctx, err)
+		return user, false
+	}
+	return user, true
+}
+
+// getRecipientAccount picks the recipient's account in currency, their checking account
+// when they hold several products in it
+func (server *Server) getRecipientAccount(ctx *gin.Context, user db.User, to string, currency string) (db.Account, bool) {
+	account, err := server.store.GetAccountByOwnerCurrency(ctx, db.GetAccountByOwnerCurrencyParams{
+		Owner:    user.Username,
+		Currency: currency,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = ErrNoRecipientAccount.withDetail(fmt.Sprintf("recipient [%s] has no %s account", to, currency))
+		}
+		errorResponse(ctx, err)
+		return account, false
+	}
+	return account, true
+}
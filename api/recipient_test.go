@@ -0,0 +1,104 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	mockdb "simple-bank/db/mock"
+	db "simple-bank/db/sqlc"
+	"simple-bank/utils"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupRecipientAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	recipient, _ := randomUser(t)
+	recipient.FullName = "Sam Taylor"
+	account := randomAccount(recipient.Username)
+	account.Currency = utils.USD
+
+	testCases := []struct {
+		name          string
+		to            string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Email",
+			to:   recipient.Email,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetRecipient(gomock.Any(), gomock.Eq(db.GetRecipientParams{Email: sql.NullString{String: recipient.Email, Valid: true}})).
+					Times(1).
+					Return(recipient, nil)
+				store.EXPECT().
+					GetAccountByOwnerCurrency(gomock.Any(), gomock.Eq(db.GetAccountByOwnerCurrencyParams{Owner: recipient.Username, Currency: utils.USD})).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res recipientResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, recipientResponse{
+					To:       recipient.Email,
+					Name:     "S** T*****",
+					Currency: utils.USD,
+				}, res)
+				require.NotContains(t, recorder.Body.String(), account.Number)
+			},
+		},
+		{
+			name: "NotFound",
+			to:   "@nobody",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetRecipient(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, db.ErrRecordNotFound)
+				store.EXPECT().GetAccountByOwnerCurrency(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, ErrRecipientNotFound.Code)
+			},
+		},
+		{
+			name: "MalformedAlias",
+			to:   "@No",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetRecipient(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				problem := requireProblemCode(t, recorder, ErrValidationFailed.Code)
+				require.Equal(t, "to", problem.Errors[0].Field)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			query := neturl.Values{"to": {tc.to}, "currency": {utils.USD}}
+			request, err := http.NewRequest(http.MethodGet, "/recipients/lookup?"+query.Encode(), nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
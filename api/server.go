@@ -2,6 +2,7 @@ package api
 
 import (
 	db "simple-bank/db/sqlc"
+	"simple-bank/storage"
 	"simple-bank/token"
 	"simple-bank/utils"
 
@@ -14,10 +15,12 @@ type Server struct {
 	config     utils.Config
 	tokenMaker token.Maker
 	store      db.Store
+	accounts   db.AccountSubscriber
+	blobs      storage.Storage
 	router     *gin.Engine
 }
 
-func NewServer(config utils.Config, store db.Store) (*Server, error) {
+func NewServer(config utils.Config, store db.Store, accounts db.AccountSubscriber, blobs storage.Storage) (*Server, error) {
 	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, err
@@ -25,11 +28,20 @@ func NewServer(config utils.Config, store db.Store) (*Server, error) {
 	server := &Server{
 		config:     config,
 		store:      store,
+		accounts:   accounts,
+		blobs:      blobs,
 		tokenMaker: tokenMaker,
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("account_number", validAccountNumber)
+		v.RegisterValidation("webhook_url", validWebhookURL)
+		v.RegisterValidation("webhook_event", validWebhookEvent)
+		v.RegisterValidation("recipient", validRecipient)
+		v.RegisterValidation("alias", validAlias)
+		v.RegisterValidation("metadata", validMetadata)
+		v.RegisterTagNameFunc(requestFieldName)
 	}
 
 	server.setupServerRoutes()
@@ -40,22 +52,80 @@ func (Server *Server) Start(address string) error {
 	return Server.router.Run(address)
 }
 
-func errorHandler(err error) gin.H {
-	return gin.H{"error": err.Error()}
-}
-
 func (Server *Server) setupServerRoutes() {
 	router := gin.Default()
+	router.GET("/openapi.json", Server.getOpenAPISpec)
+	router.GET("/docs", Server.getSwaggerUI)
+
 	router.POST("/users", Server.CreateUser)
 	router.POST("/users/login", Server.LoginUser)
 	router.POST("/tokens/renew-access", Server.renewToken)
 
 	routerGroup := router.Group("/", authMiddleware(Server.tokenMaker))
 
+	routerGroup.GET("/users/me/limits", Server.GetUserLimits)
+	routerGroup.PUT("/users/me/alias", Server.UpdateUserAlias)
+	routerGroup.POST("/users/me/email/verification", Server.RequestEmailVerification)
+	routerGroup.POST("/users/me/email/verify", Server.VerifyEmail)
+	routerGroup.GET("/recipients/lookup", Server.LookupRecipient)
+
+	routerGroup.GET("/account-products", Server.ListAccountProducts)
 	routerGroup.POST("/accounts", Server.CreateAccount)
 	routerGroup.GET("/accounts/:id", Server.GetAccount)
+	routerGroup.GET("/accounts/:id/balance", Server.GetAccountBalance)
+	routerGroup.GET("/accounts/:id/stream", Server.StreamAccount)
+	routerGroup.GET("/accounts/:id/transfers", Server.ListAccountTransfers)
+	routerGroup.GET("/accounts/:id/entries", Server.ListAccountEntries)
+	routerGroup.GET("/accounts/:id/spending", Server.GetAccountSpending)
+	routerGroup.GET("/accounts/:id/analytics", Server.GetAccountAnalytics)
+	routerGroup.GET("/accounts/:id/statement", Server.GetStatement)
+	routerGroup.GET("/accounts/:id/statements", Server.ListAccountStatements)
+	routerGroup.GET("/accounts/:id/statements/:period", Server.GetAccountStatement)
 	routerGroup.GET("/accounts", Server.ListAccounts)
+	routerGroup.POST("/accounts/:id/members", Server.InviteAccountMember)
+	routerGroup.GET("/accounts/:id/members", Server.ListAccountMembers)
+	routerGroup.DELETE("/accounts/:id/members/:username", Server.RemoveAccountMember)
+
+	routerGroup.GET("/account-invitations", Server.ListAccountInvitations)
+	routerGroup.POST("/account-invitations/:id/accept", Server.AcceptAccountInvitation)
+	routerGroup.POST("/account-invitations/:id/decline", Server.DeclineAccountInvitation)
 
 	routerGroup.POST("/transfer", Server.CreateTransfer)
+	routerGroup.POST("/transfer/quote", Server.QuoteTransfer)
+	routerGroup.POST("/transfer/batch", Server.CreateBatchTransfer)
+
+	routerGroup.PUT("/entries/:id/category", Server.UpdateEntryCategory)
+	routerGroup.PUT("/entries/:id/tags", Server.UpdateEntryTags)
+
+	routerGroup.POST("/categories", Server.CreateCategory)
+	routerGroup.GET("/categories", Server.ListCategories)
+	routerGroup.DELETE("/categories/:id", Server.DeleteCategory)
+	routerGroup.POST("/category-rules", Server.CreateCategoryRule)
+	routerGroup.GET("/category-rules", Server.ListCategoryRules)
+	routerGroup.DELETE("/category-rules/:id", Server.DeleteCategoryRule)
+
+	routerGroup.POST("/payees", Server.CreatePayee)
+	routerGroup.GET("/payees", Server.ListPayees)
+	routerGroup.GET("/payees/:id", Server.GetPayee)
+	routerGroup.PATCH("/payees/:id", Server.UpdatePayee)
+	routerGroup.DELETE("/payees/:id", Server.DeletePayee)
+
+	routerGroup.POST("/payment-requests", Server.CreatePaymentRequest)
+	routerGroup.GET("/payment-requests/incoming", Server.ListIncomingPaymentRequests)
+	routerGroup.GET("/payment-requests/outgoing", Server.ListOutgoingPaymentRequests)
+	routerGroup.GET("/payment-requests/:id", Server.GetPaymentRequest)
+	routerGroup.POST("/payment-requests/:id/accept", Server.AcceptPaymentRequest)
+	routerGroup.POST("/payment-requests/:id/decline", Server.DeclinePaymentRequest)
+	routerGroup.POST("/payment-requests/:id/cancel", Server.CancelPaymentRequest)
+
+	routerGroup.POST("/holds", Server.CreateHold)
+	routerGroup.GET("/holds/:id", Server.GetHold)
+	routerGroup.POST("/holds/:id/capture", Server.CaptureHold)
+	routerGroup.POST("/holds/:id/void", Server.VoidHold)
+
+	routerGroup.POST("/webhooks", Server.CreateWebhook)
+	routerGroup.GET("/webhooks", Server.ListWebhooks)
+	routerGroup.DELETE("/webhooks/:id", Server.DeleteWebhook)
+	routerGroup.GET("/webhooks/:id/deliveries", Server.ListWebhookDeliveries)
 	Server.router = router
 }
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	db "simple-bank/db/sqlc"
+	"simple-bank/statement"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	dateFormat   = "2006-01-02"
+	periodFormat = "2006-01"
+)
+
+// statementRequest selects the days from From to To, both included
+type statementRequest struct {
+	GetAccountRequest
+	From   time.Time `form:"from" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	To     time.Time `form:"to" binding:"required,gtefield=From" time_format:"2006-01-02" time_utc:"1"`
+	Format string    `form:"format" binding:"required,oneof=csv ofx camt053"`
+}
+
+// GetStatement streams the statement of an account as CSV, OFX or camt.053.
+// Lines are written as they are read, so once the first byte is sent errors
+// can only end the download early.
+func (server *Server) GetStatement(ctx *gin.Context) {
+	var req statementRequest
+
+	if err := ctx.ShouldBindUri(&req.GetAccountRequest); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	account, valid := server.getUserAccount(ctx, req.Number)
+	if !valid {
+		return
+	}
+
+	writer, err := statement.NewWriter(req.Format, ctx.Writer)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	to := req.To.AddDate(0, 0, 1)
+	filename := fmt.Sprintf("statement-%s-%s-%s.%s", account.Number, req.From.Format(dateFormat), req.To.Format(dateFormat), statement.FileExtension(req.Format))
+
+	var balance int64
+	started := false
+	err = server.store.StatementTxn(ctx, db.StatementTxnParams{
+		AccountID: account.ID,
+		From:      req.From,
+		To:        to,
+	}, func(balances db.GetStatementBalancesRow) error {
+		started = true
+		balance = balances.OpeningBalance
+
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		ctx.Header("Content-Type", statement.ContentType(req.Format))
+		ctx.Status(http.StatusOK)
+
+		return writer.WriteHeader(statement.Header{
+			AccountNumber:  account.Number,
+			Owner:          account.Owner,
+			Currency:       account.Currency,
+			From:           req.From,
+			To:             to,
+			OpeningBalance: balances.OpeningBalance,
+			ClosingBalance: balances.ClosingBalance,
+			CreatedAt:      time.Now(),
+		})
+	}, func(line db.ListStatementLinesRow) error {
+		balance += line.Amount
+
+		return writer.WriteLine(statement.Line{
+			EntryID:             line.ID,
+			TransferID:          line.TransferID.Int64,
+			BookedAt:            line.CreatedAt,
+			Amount:              line.Amount,
+			Balance:             balance,
+			CounterpartyAccount: line.CounterpartyNumber.String,
+			CounterpartyOwner:   line.CounterpartyOwner.String,
+			Memo:                line.Description,
+			Reference:           line.Reference,
+		})
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+
+	if err != nil {
+		if !started {
+			errorResponse(ctx, err)
+			return
+		}
+		log.Printf("statement of account %s ended early: %s", account.Number, err)
+		ctx.Error(err)
+	}
+}
+
+type accountStatementResponse struct {
+	Period         string    `json:"period"`
+	Currency       string    `json:"currency"`
+	OpeningBalance int64     `json:"opening_balance"`
+	ClosingBalance int64     `json:"closing_balance"`
+	Size           int64     `json:"size"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func getAccountStatementResponse(accountStatement db.AccountStatement, account db.Account) accountStatementResponse {
+	return accountStatementResponse{
+		Period:         accountStatement.Period.Format(periodFormat),
+		Currency:       account.Currency,
+		OpeningBalance: accountStatement.OpeningBalance,
+		ClosingBalance: accountStatement.ClosingBalance,
+		Size:           accountStatement.Size,
+		CreatedAt:      accountStatement.CreatedAt,
+	}
+}
+
+type listAccountStatementsRequest struct {
+	GetAccountRequest
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=1,max=24"`
+}
+
+// ListAccountStatements lists the monthly PDF statements of an account, newest first
+func (server *Server) ListAccountStatements(ctx *gin.Context) {
+	var req listAccountStatementsRequest
+
+	if err := ctx.ShouldBindUri(&req.GetAccountRequest); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	account, valid := server.getUserAccount(ctx, req.Number)
+	if !valid {
+		return
+	}
+
+	statements, err := server.store.ListAccountStatements(ctx, db.ListAccountStatementsParams{
+		AccountID: account.ID,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	res := make([]accountStatementResponse, len(statements))
+	for i, accountStatement := range statements {
+		res[i] = getAccountStatementResponse(accountStatement, account)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type accountStatementRequest struct {
+	GetAccountRequest
+	Period string `uri:"period" binding:"required,datetime=2006-01"`
+}
+
+// GetAccountStatement downloads a monthly PDF statement
+func (server *Server) GetAccountStatement(ctx *gin.Context) {
+	var req accountStatementRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	account, valid := server.getUserAccount(ctx, req.Number)
+	if !valid {
+		return
+	}
+
+	period, err := time.Parse(periodFormat, req.Period)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	accountStatement, err := server.store.GetAccountStatement(ctx, db.GetAccountStatementParams{
+		AccountID: account.ID,
+		Period:    period,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = ErrStatementNotFound.withDetail(fmt.Sprintf("no statement for %s on account [%s]", req.Period, account.Number))
+		}
+		errorResponse(ctx, err)
+		return
+	}
+
+	blob, err := server.blobs.Get(ctx, accountStatement.StorageKey)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	defer blob.Close()
+
+	filename := fmt.Sprintf("statement-%s-%s.pdf", account.Number, req.Period)
+	ctx.DataFromReader(http.StatusOK, accountStatement.Size, statement.ContentType(statement.FormatPDF), blob, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", filename),
+	})
+}
@@ -0,0 +1,306 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simple-bank/db/mock"
+	db "simple-bank/db/sqlc"
+	"simple-bank/statement"
+	"simple-bank/utils"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStatementAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	counterparty := randomAccount(utils.RandomOwner())
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	balances := db.GetStatementBalancesRow{OpeningBalance: 100, ClosingBalance: 80}
+	lines := []db.ListStatementLinesRow{
+		{
+			ID:                 1,
+			Amount:             -30,
+			CreatedAt:          from.Add(time.Hour),
+			TransferID:         sql.NullInt64{Int64: 9, Valid: true},
+			CounterpartyNumber: sql.NullString{String: counterparty.Number, Valid: true},
+			CounterpartyOwner:  sql.NullString{String: counterparty.Owner, Valid: true},
+		},
+		{
+			ID:        2,
+			Amount:    10,
+			CreatedAt: from.Add(2 * time.Hour),
+		},
+	}
+
+	streamStatement := func(_ context.Context, args db.StatementTxnParams, header func(db.GetStatementBalancesRow) error, line func(db.ListStatementLinesRow) error) error {
+		require.Equal(t, account.ID, args.AccountID)
+		require.Equal(t, from, args.From)
+		require.Equal(t, to.AddDate(0, 0, 1), args.To)
+
+		if err := header(balances); err != nil {
+			return err
+		}
+		for _, l := range lines {
+			if err := line(l); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "CSV",
+			query:    "from=2026-01-01&to=2026-01-31&format=csv",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().StatementTxn(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(streamStatement)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, statement.ContentType(statement.FormatCSV), recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Header().Get("Content-Disposition"), fmt.Sprintf("statement-%s-2026-01-01-2026-01-31.csv", account.Number))
+
+				records, err := csv.NewReader(recorder.Body).ReadAll()
+				require.NoError(t, err)
+				require.Len(t, records, 5)
+				require.Equal(t, "100", records[1][6])
+				require.Equal(t, "-30", records[2][4])
+				require.Equal(t, "70", records[2][6])
+				require.Equal(t, counterparty.Number, records[2][7])
+				require.Equal(t, counterparty.Owner, records[2][8])
+				require.Equal(t, "80", records[3][6])
+				require.Equal(t, "80", records[4][6])
+			},
+		},
+		{
+			name:     "Camt053",
+			query:    "from=2026-01-01&to=2026-01-31&format=camt053",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().StatementTxn(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(streamStatement)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/xml", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Header().Get("Content-Disposition"), ".xml")
+				require.Contains(t, recorder.Body.String(), "<Cd>OPBD</Cd>")
+				require.Contains(t, recorder.Body.String(), counterparty.Number)
+			},
+		},
+		{
+			name:     "AccountOfAnotherUser",
+			query:    "from=2026-01-01&to=2026-01-31&format=csv",
+			username: counterparty.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().GetActiveAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().StatementTxn(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, ErrAccountNotFound.Code)
+			},
+		},
+		{
+			name:     "UnsupportedFormat",
+			query:    "from=2026-01-01&to=2026-01-31&format=pdf",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				problem := requireProblemCode(t, recorder, ErrValidationFailed.Code)
+				require.Equal(t, "format", problem.Errors[0].Field)
+				require.Equal(t, "oneof", problem.Errors[0].Rule)
+			},
+		},
+		{
+			name:     "ToBeforeFrom",
+			query:    "from=2026-01-31&to=2026-01-01&format=csv",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				problem := requireProblemCode(t, recorder, ErrValidationFailed.Code)
+				require.Equal(t, "to", problem.Errors[0].Field)
+			},
+		},
+		{
+			name:     "MalformedDate",
+			query:    "from=01/01/2026&to=2026-01-31&format=csv",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, ErrMalformedRequest.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			query:    "from=2026-01-01&to=2026-01-31&format=ofx",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().StatementTxn(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireProblemCode(t, recorder, ErrInternal.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%s/statement?%s", account.Number, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAccountStatementsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	period := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	pdf := []byte("%PDF-1.3 statement")
+	accountStatement := db.AccountStatement{
+		ID:             1,
+		AccountID:      account.ID,
+		Period:         period,
+		StorageKey:     statement.StorageKey(account.Number, period),
+		Size:           int64(len(pdf)),
+		OpeningBalance: 100,
+		ClosingBalance: 70,
+		CreatedAt:      period.AddDate(0, 1, 0),
+	}
+
+	testCases := []struct {
+		name          string
+		url           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "List",
+			url:  fmt.Sprintf("/accounts/%s/statements?page_id=1&page_size=12", account.Number),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountStatements(gomock.Any(), gomock.Eq(db.ListAccountStatementsParams{
+					AccountID: account.ID,
+					Limit:     12,
+					Offset:    0,
+				})).Times(1).Return([]db.AccountStatement{accountStatement}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res []accountStatementResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, []accountStatementResponse{getAccountStatementResponse(accountStatement, account)}, res)
+				require.Equal(t, "2026-01", res[0].Period)
+			},
+		},
+		{
+			name: "Download",
+			url:  fmt.Sprintf("/accounts/%s/statements/2026-01", account.Number),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountStatement(gomock.Any(), gomock.Eq(db.GetAccountStatementParams{
+					AccountID: account.ID,
+					Period:    period,
+				})).Times(1).Return(accountStatement, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/pdf", recorder.Header().Get("Content-Type"))
+				require.Equal(t, pdf, recorder.Body.Bytes())
+			},
+		},
+		{
+			name: "NoStatement",
+			url:  fmt.Sprintf("/accounts/%s/statements/2025-12", account.Number),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.Number)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountStatement(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountStatement{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, ErrStatementNotFound.Code)
+			},
+		},
+		{
+			name: "InvalidPeriod",
+			url:  fmt.Sprintf("/accounts/%s/statements/2026-13", account.Number),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				problem := requireProblemCode(t, recorder, ErrValidationFailed.Code)
+				require.Equal(t, "period", problem.Errors[0].Field)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			err := server.blobs.Put(context.Background(), accountStatement.StorageKey, bytes.NewReader(pdf))
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
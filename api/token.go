@@ -1,9 +1,9 @@
 package api
 
 import (
-	"database/sql"
 	"errors"
 	"net/http"
+	db "simple-bank/db/sqlc"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -21,54 +21,50 @@ type renewTokenRes struct {
 func (server *Server) renewToken(ctx *gin.Context) {
 	var req renewTokenRequest
 
-	if err := ctx.BindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorHandler(err))
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorResponse(ctx, err)
 		return
 	}
 
 	refreshPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorHandler(err))
+		errorResponse(ctx, ErrUnauthenticated.withDetail(err.Error()))
 		return
 	}
 
 	session, err := server.store.GetSession(ctx, refreshPayload.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorHandler(err))
+		if errors.Is(err, db.ErrRecordNotFound) {
+			errorResponse(ctx, ErrSessionNotFound)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorHandler(err))
+		errorResponse(ctx, err)
 		return
 	}
 
 	if session.IsBlocked {
-		err := errors.New("blocked session")
-		ctx.JSON(http.StatusUnauthorized, errorHandler(err))
+		errorResponse(ctx, ErrInvalidSession.withDetail("blocked session"))
 		return
 	}
 
 	if session.Username != refreshPayload.Username {
-		err := errors.New("incorrect session user")
-		ctx.JSON(http.StatusUnauthorized, errorHandler(err))
+		errorResponse(ctx, ErrInvalidSession.withDetail("incorrect session user"))
 		return
 	}
 
 	if session.RefreshToken != req.RefreshToken {
-		err := errors.New("invalid token")
-		ctx.JSON(http.StatusUnauthorized, errorHandler(err))
+		errorResponse(ctx, ErrInvalidSession.withDetail("invalid token"))
 		return
 	}
 
 	if time.Now().After(session.ExpiresAt) {
-		err := errors.New("token expired")
-		ctx.JSON(http.StatusUnauthorized, errorHandler(err))
+		errorResponse(ctx, ErrInvalidSession.withDetail("token expired"))
 		return
 	}
 
 	token, accessTokenPayload, err := server.tokenMaker.CreateToken(refreshPayload.Username, server.config.ExpiryTokenDuration)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorHandler(err))
+		errorResponse(ctx, err)
 		return
 	}
 
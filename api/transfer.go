@@ -2,78 +2,254 @@ package api
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	db "simple-bank/db/sqlc"
 	"simple-bank/token"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// CreateTransferRequest addresses the recipient with exactly one of ToAccountId, an
+// account number, To, a username, verified email or @alias, or PayeeId, a saved payee.
+// Description and Reference are shown to both sides, Metadata only to the sender.
 type CreateTransferRequest struct {
-	FromAccountId int64  `json:"fromAccountId" binding:"required,min=1"`
-	ToAccountId   int64  `json:"toAccountId" binding:"required,min=1"`
+	FromAccountId string `json:"fromAccountId" binding:"required,account_number"`
+	ToAccountId   string `json:"toAccountId" binding:"omitempty,account_number"`
+	To            string `json:"to" binding:"required_without_all=ToAccountId PayeeId,excluded_with=ToAccountId PayeeId,omitempty,recipient"`
+	PayeeId       int64  `json:"payee_id" binding:"excluded_with=ToAccountId,omitempty,min=1"`
 	Currency      string `json:"currency" binding:"required,currency"`
 	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Description   string `json:"description" binding:"omitempty,max=140"`
+	Reference     string `json:"reference" binding:"omitempty,max=64"`
+	// Metadata is a JSON object of up to maxMetadataSize bytes
+	Metadata json.RawMessage `json:"metadata" binding:"omitempty,metadata"`
+}
+
+type transferResponse struct {
+	ID          int64           `json:"id"`
+	FromAccount string          `json:"from_account"`
+	ToAccount   string          `json:"to_account"`
+	Amount      int64           `json:"amount"`
+	Fee         int64           `json:"fee"`
+	Description string          `json:"description"`
+	Reference   string          `json:"reference"`
+	Metadata    json.RawMessage `json:"metadata"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// getTransferResponse refers to the accounts of a transfer by their numbers
+func getTransferResponse(transfer db.Transfer, fromAccount string, toAccount string) transferResponse {
+	return transferResponse{
+		ID:          transfer.ID,
+		FromAccount: fromAccount,
+		ToAccount:   toAccount,
+		Amount:      transfer.Amount,
+		Fee:         transfer.Fee,
+		Description: transfer.Description,
+		Reference:   transfer.Reference,
+		Metadata:    transfer.Metadata,
+		CreatedAt:   transfer.CreatedAt,
+	}
+}
+
+type entryResponse struct {
+	ID        int64     `json:"id"`
+	Account   string    `json:"account"`
+	Amount    int64     `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type transferTxnResponse struct {
+	Transfer    transferResponse `json:"transfer"`
+	FromAccount accountResponse  `json:"from_account"`
+	ToAccount   accountResponse  `json:"to_account"`
+	FromEntry   entryResponse    `json:"from_entry"`
+	ToEntry     entryResponse    `json:"to_entry"`
+}
+
+// getTransferTxnResponse replaces the internal account IDs of a transfer with account numbers
+func getTransferTxnResponse(result db.TransferTxnResult) transferTxnResponse {
+	return transferTxnResponse{
+		Transfer:    getTransferResponse(result.Transfer, result.FromAccount.Number, result.ToAccount.Number),
+		FromAccount: getAccountResponse(result.FromAccount),
+		ToAccount:   getAccountResponse(result.ToAccount),
+		FromEntry:   getEntryResponse(result.FromEntry, result.FromAccount),
+		ToEntry:     getEntryResponse(result.ToEntry, result.ToAccount),
+	}
+}
+
+func getEntryResponse(entry db.Entry, account db.Account) entryResponse {
+	return entryResponse{
+		ID:        entry.ID,
+		Account:   account.Number,
+		Amount:    entry.Amount,
+		CreatedAt: entry.CreatedAt,
+	}
 }
 
 func (server *Server) CreateTransfer(ctx *gin.Context) {
 	var req CreateTransferRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorHandler(err))
+		errorResponse(ctx, err)
 		return
 	}
 
-	fromAccount, valid := server.validAccount(ctx, req.FromAccountId, req.Currency)
+	fromAccount, valid := server.getSpendingAccount(ctx, req.FromAccountId, req.Amount)
+	if !valid || !checkCurrency(ctx, fromAccount, req.Currency) {
+		return
+	}
+
+	toAccount, valid := server.resolveToAccount(ctx, req)
 	if !valid {
 		return
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if authPayload.Username != fromAccount.Owner {
-		err := errors.New("from account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorHandler(err))
+	args := db.TransferTxnParam{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        req.Amount,
+		Description:   req.Description,
+		Reference:     req.Reference,
+		Metadata:      req.Metadata,
+		Sender:        authPayload.Username,
+		CoolingOff:    server.payeeCoolingOff(),
+	}
+
+	result, err := server.store.TransferTxn(ctx, args)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getTransferTxnResponse(result))
+}
+
+type listAccountTransfersRequest struct {
+	GetAccountRequest
+	Reference string `form:"reference" binding:"omitempty,max=64"`
+	PageID    int32  `form:"page_id" binding:"required,min=1"`
+	PageSize  int32  `form:"page_size" binding:"required,min=1,max=50"`
+}
+
+// ListAccountTransfers lists the transfers sent or received by an account, newest first,
+// optionally only the ones with a given reference. Received transfers come without the
+// sender's metadata.
+func (server *Server) ListAccountTransfers(ctx *gin.Context) {
+	var req listAccountTransfersRequest
+
+	if err := ctx.ShouldBindUri(&req.GetAccountRequest); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errorResponse(ctx, err)
 		return
 	}
 
-	_, valid = server.validAccount(ctx, req.ToAccountId, req.Currency)
+	account, valid := server.getUserAccount(ctx, req.Number)
 	if !valid {
 		return
 	}
 
-	args := db.TransferTxnParam{
-		FromAccountID: req.FromAccountId,
-		ToAccountID:   req.ToAccountId,
-		Amount:        req.Amount,
+	transfers, err := server.store.ListAccountTransfers(ctx, db.ListAccountTransfersParams{
+		AccountID: account.ID,
+		Reference: sql.NullString{String: req.Reference, Valid: req.Reference != ""},
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
 	}
 
-	result, err := server.store.TransferTxn(ctx, args)
+	res := make([]transferResponse, len(transfers))
+	for i, transfer := range transfers {
+		if transfer.FromAccountID != account.ID {
+			transfer.Metadata = nil
+		}
+		res[i] = getTransferResponse(db.Transfer{
+			ID:            transfer.ID,
+			FromAccountID: transfer.FromAccountID,
+			ToAccountID:   transfer.ToAccountID,
+			Amount:        transfer.Amount,
+			CreatedAt:     transfer.CreatedAt,
+			Fee:           transfer.Fee,
+			Description:   transfer.Description,
+			Reference:     transfer.Reference,
+			Metadata:      transfer.Metadata,
+		}, transfer.FromAccountNumber, transfer.ToAccountNumber)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type transferQuoteResponse struct {
+	Amount   int64  `json:"amount"`
+	Fee      int64  `json:"fee"`
+	Total    int64  `json:"total"`
+	Currency string `json:"currency"`
+}
+
+// QuoteTransfer previews the fee of a transfer without moving any money.
+// The sender is debited Total, the amount plus the fee.
+func (server *Server) QuoteTransfer(ctx *gin.Context) {
+	var req CreateTransferRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	fromAccount, valid := server.getUserAccount(ctx, req.FromAccountId)
+	if !valid || !checkCurrency(ctx, fromAccount, req.Currency) {
+		return
+	}
+
+	toAccount, valid := server.resolveToAccount(ctx, req)
+	if !valid {
+		return
+	}
+
+	quote, err := db.QuoteTransferFee(ctx, server.store, fromAccount, toAccount, req.Amount)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorHandler(err))
+		errorResponse(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, result)
+	ctx.JSON(http.StatusOK, transferQuoteResponse{
+		Amount:   req.Amount,
+		Fee:      quote.Amount,
+		Total:    req.Amount + quote.Amount,
+		Currency: quote.Currency,
+	})
 }
 
-func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
-	account, err := server.store.GetAccount(ctx, accountID)
+func (server *Server) validAccount(ctx *gin.Context, number string, currency string) (db.Account, bool) {
+	account, err := server.store.GetAccountByNumber(ctx, number)
 
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorHandler(err))
+		if errors.Is(err, db.ErrRecordNotFound) {
+			errorResponse(ctx, accountNotFound(number))
 			return account, false
 		}
-		ctx.JSON(http.StatusInternalServerError, errorHandler(err))
+		errorResponse(ctx, err)
 		return account, false
 	}
 
+	return account, checkCurrency(ctx, account, currency)
+}
+
+func checkCurrency(ctx *gin.Context, account db.Account, currency string) bool {
 	if account.Currency != currency {
-		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
-		ctx.JSON(http.StatusBadRequest, errorHandler(err))
-		return account, false
+		detail := fmt.Sprintf("account [%s] currency mismatch: %s vs %s", account.Number, account.Currency, currency)
+		errorResponse(ctx, ErrCurrencyMismatch.withDetail(detail))
+		return false
 	}
-	return account, true
+	return true
 }
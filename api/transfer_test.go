@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	mockdb "simple-bank/db/mock"
@@ -43,8 +44,8 @@ func TestCreateTransfer(t *testing.T) {
 		{
 			name: "Success",
 			body: gin.H{
-				"fromAccountId": account1.ID,
-				"toAccountId":   account2.ID,
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
 				"currency":      utils.INR,
 				"amount":        amount,
 			},
@@ -52,13 +53,14 @@ func TestCreateTransfer(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(account2, nil)
 
 				arg := db.TransferTxnParam{
 					FromAccountID: account1.ID,
 					ToAccountID:   account2.ID,
 					Amount:        amount,
+					Sender:        user1.Username,
 				}
 				store.EXPECT().TransferTxn(gomock.Any(), gomock.Eq(arg)).Times(1)
 			},
@@ -67,10 +69,186 @@ func TestCreateTransfer(t *testing.T) {
 			},
 		},
 		{
-			name: "Unauthorized",
+			name: "ToAlias",
 			body: gin.H{
-				"fromAccountId": account1.ID,
-				"toAccountId":   account2.ID,
+				"fromAccountId": account1.Number,
+				"to":            "@" + user2.Username,
+				"currency":      utils.INR,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					GetRecipient(gomock.Any(), gomock.Eq(db.GetRecipientParams{Alias: sql.NullString{String: user2.Username, Valid: true}})).
+					Times(1).
+					Return(user2, nil)
+				store.EXPECT().
+					GetAccountByOwnerCurrency(gomock.Any(), gomock.Eq(db.GetAccountByOwnerCurrencyParams{Owner: user2.Username, Currency: utils.INR})).
+					Times(1).
+					Return(account2, nil)
+
+				arg := db.TransferTxnParam{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        amount,
+					Sender:        user1.Username,
+				}
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "WithDetails",
+			body: gin.H{
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
+				"currency":      utils.INR,
+				"amount":        amount,
+				"description":   "March rent",
+				"reference":     "INV-2026-03",
+				"metadata":      gin.H{"order_id": 42},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(account2, nil)
+
+				arg := db.TransferTxnParam{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        amount,
+					Description:   "March rent",
+					Reference:     "INV-2026-03",
+					Metadata:      json.RawMessage(`{"order_id":42}`),
+					Sender:        user1.Username,
+				}
+				store.EXPECT().
+					TransferTxn(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferTxnResult{
+						Transfer: db.Transfer{
+							ID:          1,
+							Amount:      amount,
+							Description: arg.Description,
+							Reference:   arg.Reference,
+							Metadata:    arg.Metadata,
+						},
+						FromAccount: account1,
+						ToAccount:   account2,
+					}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res transferTxnResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, "March rent", res.Transfer.Description)
+				require.Equal(t, "INV-2026-03", res.Transfer.Reference)
+				require.JSONEq(t, `{"order_id":42}`, string(res.Transfer.Metadata))
+			},
+		},
+		{
+			name: "MetadataNotAnObject",
+			body: gin.H{
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
+				"currency":      utils.INR,
+				"amount":        amount,
+				"metadata":      []int{1, 2},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				problem := requireProblemCode(t, recorder, ErrValidationFailed.Code)
+				require.Equal(t, "metadata", problem.Errors[0].Field)
+			},
+		},
+		{
+			name: "MetadataTooLarge",
+			body: gin.H{
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
+				"currency":      utils.INR,
+				"amount":        amount,
+				"metadata":      gin.H{"note": utils.RandomString(maxMetadataSize)},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				problem := requireProblemCode(t, recorder, ErrValidationFailed.Code)
+				require.Equal(t, "metadata", problem.Errors[0].Field)
+				require.Equal(t, "metadata", problem.Errors[0].Rule)
+			},
+		},
+		{
+			name: "RecipientWithoutAccountInCurrency",
+			body: gin.H{
+				"fromAccountId": account1.Number,
+				"to":            user3.Username,
+				"currency":      utils.INR,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					GetRecipient(gomock.Any(), gomock.Eq(db.GetRecipientParams{Username: sql.NullString{String: user3.Username, Valid: true}})).
+					Times(1).
+					Return(user3, nil)
+				store.EXPECT().GetAccountByOwnerCurrency(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, ErrNoRecipientAccount.Code)
+			},
+		},
+		{
+			name: "AccountNumberAndRecipient",
+			body: gin.H{
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
+				"to":            user2.Username,
+				"currency":      utils.INR,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				problem := requireProblemCode(t, recorder, ErrValidationFailed.Code)
+				require.Equal(t, "to", problem.Errors[0].Field)
+				require.Equal(t, "excluded_with", problem.Errors[0].Rule)
+			},
+		},
+		{
+			name: "FromAccountOfAnotherUser",
+			body: gin.H{
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
 				"currency":      utils.INR,
 				"amount":        amount,
 			},
@@ -78,28 +256,174 @@ func TestCreateTransfer(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().GetActiveAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(0)
 
 				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, ErrAccountNotFound.Code)
+			},
+		},
+		{
+			name: "SpenderWithinLimit",
+			body: gin.H{
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
+				"currency":      utils.INR,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user3.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					GetActiveAccountMember(gomock.Any(), gomock.Eq(db.GetActiveAccountMemberParams{AccountID: account1.ID, Username: user3.Username})).
+					Times(1).
+					Return(db.AccountMember{AccountID: account1.ID, Username: user3.Username, Role: db.MemberSpender, SpendLimit: amount, Status: db.MemberActive}, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "SpenderAboveLimit",
+			body: gin.H{
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
+				"currency":      utils.INR,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user3.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					GetActiveAccountMember(gomock.Any(), gomock.Eq(db.GetActiveAccountMemberParams{AccountID: account1.ID, Username: user3.Username})).
+					Times(1).
+					Return(db.AccountMember{AccountID: account1.ID, Username: user3.Username, Role: db.MemberSpender, SpendLimit: amount - 1, Status: db.MemberActive}, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(0)
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, ErrSpendLimit.Code)
+			},
+		},
+		{
+			name: "SpenderRemovedMidRequest",
+			body: gin.H{
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
+				"currency":      utils.INR,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user3.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					GetActiveAccountMember(gomock.Any(), gomock.Eq(db.GetActiveAccountMemberParams{AccountID: account1.ID, Username: user3.Username})).
+					Times(1).
+					Return(db.AccountMember{AccountID: account1.ID, Username: user3.Username, Role: db.MemberSpender, SpendLimit: amount, Status: db.MemberActive}, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxnResult{}, db.ErrSenderNotAllowed)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, ErrPermissionDenied.Code)
+			},
+		},
+		{
+			name: "Viewer",
+			body: gin.H{
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
+				"currency":      utils.INR,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user3.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					GetActiveAccountMember(gomock.Any(), gomock.Eq(db.GetActiveAccountMemberParams{AccountID: account1.ID, Username: user3.Username})).
+					Times(1).
+					Return(db.AccountMember{AccountID: account1.ID, Username: user3.Username, Role: db.MemberViewer, SpendLimit: 0, Status: db.MemberActive}, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(0)
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, ErrPermissionDenied.Code)
+			},
+		},
+		{
+			name: "FromAccountOfAnotherUserCurrencyMismatch",
+			body: gin.H{
+				"fromAccountId": account3.Number,
+				"toAccountId":   account2.Number,
+				"currency":      utils.INR,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account3.Number)).Times(1).Return(account3, nil)
+				store.EXPECT().GetActiveAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(0)
+
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				// the currency of a foreign account must not leak through a mismatch error
+				problem := requireProblemCode(t, recorder, ErrAccountNotFound.Code)
+				require.Equal(t, fmt.Sprintf("account [%s] not found", account3.Number), problem.Detail)
+			},
+		},
+		{
+			name: "InvalidToAccountNumber",
+			body: gin.H{
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number[:2] + "00" + account2.Number[4:],
+				"currency":      utils.INR,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, ErrValidationFailed.Code)
 			},
 		},
 		{
 			name: "NoAuthorization",
 			body: gin.H{
-				"fromAccountId": account1.ID,
-				"toAccountId":   account2.ID,
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
 				"currency":      utils.INR,
 				"amount":        amount,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(0)
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(0)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -108,8 +432,8 @@ func TestCreateTransfer(t *testing.T) {
 		{
 			name: "FromAccountNotFound",
 			body: gin.H{
-				"fromAccountId": account1.ID,
-				"toAccountId":   account2.ID,
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
 				"currency":      utils.INR,
 				"amount":        amount,
 			},
@@ -117,18 +441,19 @@ func TestCreateTransfer(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, ErrAccountNotFound.Code)
 			},
 		},
 		{
 			name: "ToAccountNotFound",
 			body: gin.H{
-				"fromAccountId": account1.ID,
-				"toAccountId":   account2.ID,
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
 				"currency":      utils.INR,
 				"amount":        amount,
 			},
@@ -136,8 +461,8 @@ func TestCreateTransfer(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(db.Account{}, sql.ErrNoRows)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
@@ -146,8 +471,8 @@ func TestCreateTransfer(t *testing.T) {
 		{
 			name: "CurrencyMismatch",
 			body: gin.H{
-				"fromAccountId": account1.ID,
-				"toAccountId":   account3.ID,
+				"fromAccountId": account1.Number,
+				"toAccountId":   account3.Number,
 				"currency":      utils.INR,
 				"amount":        amount,
 			},
@@ -155,20 +480,21 @@ func TestCreateTransfer(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account3.Number)).Times(1).Return(account3, nil)
 
 				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, ErrCurrencyMismatch.Code)
 			},
 		},
 		{
 			name: "InvalidCurrency",
 			body: gin.H{
-				"fromAccountId": account1.ID,
-				"toAccountId":   account3.ID,
+				"fromAccountId": account1.Number,
+				"toAccountId":   account3.Number,
 				"currency":      "XYZ",
 				"amount":        amount,
 			},
@@ -176,8 +502,8 @@ func TestCreateTransfer(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(0)
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(0)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(0)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account3.Number)).Times(0)
 
 				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
 			},
@@ -188,8 +514,8 @@ func TestCreateTransfer(t *testing.T) {
 		{
 			name: "NegativeAmount",
 			body: gin.H{
-				"fromAccountId": account1.ID,
-				"toAccountId":   account2.ID,
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
 				"currency":      utils.INR,
 				"amount":        -amount,
 			},
@@ -197,8 +523,8 @@ func TestCreateTransfer(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(0)
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(0)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(0)
 
 				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
 			},
@@ -209,8 +535,8 @@ func TestCreateTransfer(t *testing.T) {
 		{
 			name: "ErrGetAccount",
 			body: gin.H{
-				"fromAccountId": account1.ID,
-				"toAccountId":   account2.ID,
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
 				"currency":      utils.INR,
 				"amount":        amount,
 			},
@@ -218,8 +544,8 @@ func TestCreateTransfer(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, sql.ErrConnDone)
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(db.Account{}, sql.ErrConnDone)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(0)
 
 				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
 			},
@@ -227,11 +553,57 @@ func TestCreateTransfer(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "InsufficientFunds",
+			body: gin.H{
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
+				"currency":      utils.INR,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(account2, nil)
+
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxnResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, ErrInsufficientFunds.Code)
+			},
+		},
+		{
+			name: "DailyLimitExceeded",
+			body: gin.H{
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
+				"currency":      utils.INR,
+				"amount":        amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(account2, nil)
+
+				limitErr := &db.LimitError{Kind: db.LimitDaily, Limit: 100, Used: 95}
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxnResult{}, limitErr)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				problem := requireProblemCode(t, recorder, ErrDailyLimit.Code)
+				require.Contains(t, problem.Detail, "daily limit of 100")
+			},
+		},
 		{
 			name: "ErrTransaction",
 			body: gin.H{
-				"fromAccountId": account1.ID,
-				"toAccountId":   account2.ID,
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
 				"currency":      utils.INR,
 				"amount":        amount,
 			},
@@ -239,8 +611,8 @@ func TestCreateTransfer(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(account2, nil)
 
 				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxnResult{}, sql.ErrTxDone)
 			},
@@ -274,3 +646,229 @@ func TestCreateTransfer(t *testing.T) {
 		})
 	}
 }
+
+func TestQuoteTransfer(t *testing.T) {
+	amount := int64(1000)
+
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account1.Currency = utils.USD
+	account2.Currency = utils.USD
+
+	rules := []db.FeeRule{
+		{ID: 1, Currency: utils.USD, Kind: db.FeeFlat, MinAmount: 5000, FlatFee: 100},
+		{ID: 2, Currency: utils.USD, Kind: db.FeePercentage, CrossUserOnly: true, BasisPoints: 150, MinFee: 5, MaxFee: 500},
+	}
+	feeRulesParams := db.ListActiveFeeRulesParams{
+		Currency: utils.USD,
+		Product:  sql.NullString{String: db.ProductChecking, Valid: true},
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(account2, nil)
+				store.EXPECT().ListActiveFeeRules(gomock.Any(), gomock.Eq(feeRulesParams)).Times(1).Return(rules, nil)
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res transferQuoteResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, transferQuoteResponse{
+					Amount:   amount,
+					Fee:      15,
+					Total:    amount + 15,
+					Currency: utils.USD,
+				}, res)
+			},
+		},
+		{
+			name: "NoMatchingRule",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(account2, nil)
+				store.EXPECT().ListActiveFeeRules(gomock.Any(), gomock.Eq(feeRulesParams)).Times(1).Return(rules[:1], nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res transferQuoteResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Zero(t, res.Fee)
+				require.Equal(t, amount, res.Total)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(account2, nil)
+				store.EXPECT().ListActiveFeeRules(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(gin.H{
+				"fromAccountId": account1.Number,
+				"toAccountId":   account2.Number,
+				"currency":      utils.USD,
+				"amount":        amount,
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfer/quote", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestListAccountTransfersAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+
+	sent := db.ListAccountTransfersRow{
+		ID:                2,
+		FromAccountID:     account1.ID,
+		ToAccountID:       account2.ID,
+		Amount:            10,
+		Reference:         "INV-7",
+		Metadata:          json.RawMessage(`{"order_id":7}`),
+		FromAccountNumber: account1.Number,
+		ToAccountNumber:   account2.Number,
+	}
+	received := db.ListAccountTransfersRow{
+		ID:                1,
+		FromAccountID:     account2.ID,
+		ToAccountID:       account1.ID,
+		Amount:            20,
+		Reference:         "INV-7",
+		Metadata:          json.RawMessage(`{"secret":true}`),
+		FromAccountNumber: account2.Number,
+		ToAccountNumber:   account1.Number,
+	}
+
+	testCases := []struct {
+		name          string
+		url           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "ByReference",
+			url:  fmt.Sprintf("/accounts/%s/transfers?reference=INV-7&page_id=2&page_size=10", account1.Number),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Eq(db.ListAccountTransfersParams{
+					AccountID: account1.ID,
+					Reference: sql.NullString{String: "INV-7", Valid: true},
+					Limit:     10,
+					Offset:    10,
+				})).Times(1).Return([]db.ListAccountTransfersRow{sent, received}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res []transferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res, 2)
+				require.Equal(t, account2.Number, res[0].ToAccount)
+				require.Equal(t, "INV-7", res[0].Reference)
+				require.JSONEq(t, `{"order_id":7}`, string(res[0].Metadata))
+				// the sender's metadata isn't shown to the recipient
+				require.Equal(t, account2.Number, res[1].FromAccount)
+				require.Equal(t, "INV-7", res[1].Reference)
+				require.Equal(t, "null", string(res[1].Metadata))
+			},
+		},
+		{
+			name: "All",
+			url:  fmt.Sprintf("/accounts/%s/transfers?page_id=1&page_size=5", account1.Number),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Eq(db.ListAccountTransfersParams{
+					AccountID: account1.ID,
+					Limit:     5,
+				})).Times(1).Return([]db.ListAccountTransfersRow{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, `[]`, recorder.Body.String())
+			},
+		},
+		{
+			name: "AccountOfAnotherUser",
+			url:  fmt.Sprintf("/accounts/%s/transfers?page_id=1&page_size=5", account2.Number),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(account2, nil)
+				store.EXPECT().GetActiveAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, ErrAccountNotFound.Code)
+			},
+		},
+		{
+			name: "PageTooLarge",
+			url:  fmt.Sprintf("/accounts/%s/transfers?page_id=1&page_size=51", account1.Number),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				problem := requireProblemCode(t, recorder, ErrValidationFailed.Code)
+				require.Equal(t, "page_size", problem.Errors[0].Field)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -2,14 +2,15 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
 	db "simple-bank/db/sqlc"
+	"simple-bank/token"
 	"simple-bank/utils"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/lib/pq"
 )
 
 type createUserRequest struct {
@@ -23,6 +24,8 @@ type userResponse struct {
 	Username          string    `json:"username"`
 	FullName          string    `json:"full_name"`
 	Email             string    `json:"email"`
+	EmailVerified     bool      `json:"email_verified"`
+	Alias             string    `json:"alias,omitempty"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 }
@@ -32,6 +35,8 @@ func getUserResponse(user *db.User) userResponse {
 		Username:          user.Username,
 		FullName:          user.FullName,
 		Email:             user.Email,
+		EmailVerified:     user.EmailVerified,
+		Alias:             user.Alias.String,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
 	}
@@ -41,13 +46,13 @@ func (server *Server) CreateUser(ctx *gin.Context) {
 	var req createUserRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorHandler(err))
+		errorResponse(ctx, err)
 		return
 	}
 
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorHandler(err))
+		errorResponse(ctx, err)
 		return
 	}
 	args := db.CreateUserParams{
@@ -59,14 +64,7 @@ func (server *Server) CreateUser(ctx *gin.Context) {
 
 	user, err := server.store.CreateUser(ctx, args)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorHandler(err))
-				return
-			}
-		}
-		ctx.JSON(http.StatusInternalServerError, errorHandler(err))
+		errorResponse(ctx, err)
 		return
 	}
 
@@ -91,35 +89,35 @@ type loginUserRes struct {
 func (server *Server) LoginUser(ctx *gin.Context) {
 	var req loginUserRequest
 
-	if err := ctx.BindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorHandler(err))
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorResponse(ctx, err)
 		return
 	}
 
 	user, err := server.store.GetUser(ctx, req.Username)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorHandler(err))
+		if errors.Is(err, db.ErrRecordNotFound) {
+			errorResponse(ctx, ErrUserNotFound)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorHandler(err))
+		errorResponse(ctx, err)
 		return
 	}
 
 	if err := utils.CheckPassword(req.Password, user.HashedPassword); err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorHandler(err))
+		errorResponse(ctx, ErrInvalidCredentials)
 		return
 	}
 
 	token, accessTokenPayload, err := server.tokenMaker.CreateToken(req.Username, server.config.ExpiryTokenDuration)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorHandler(err))
+		errorResponse(ctx, err)
 		return
 	}
 
 	refreshToken, refreshTokenPayload, err := server.tokenMaker.CreateToken(req.Username, server.config.RefreshTokenDuration)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorHandler(err))
+		errorResponse(ctx, err)
 		return
 	}
 
@@ -144,3 +142,76 @@ func (server *Server) LoginUser(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, res)
 }
+
+type updateUserAliasRequest struct {
+	Alias string `json:"alias" binding:"required,alias"`
+}
+
+// UpdateUserAlias sets the alias other users can send money to as @alias
+func (server *Server) UpdateUserAlias(ctx *gin.Context) {
+	var req updateUserAliasRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, err := server.store.UpdateUserAlias(ctx, db.UpdateUserAliasParams{
+		Username: authPayload.Username,
+		Alias:    sql.NullString{String: req.Alias, Valid: true},
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getUserResponse(&user))
+}
+
+type emailVerificationResponse struct {
+	Email     string    `json:"email"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RequestEmailVerification emails a verification code to the authenticated user's
+// address, verified emails can be used to address transfers and payment requests
+func (server *Server) RequestEmailVerification(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	verification, err := server.store.RequestEmailVerificationTxn(ctx, authPayload.Username)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, emailVerificationResponse{
+		Email:     verification.Email,
+		ExpiresAt: verification.ExpiresAt,
+	})
+}
+
+type verifyEmailRequest struct {
+	Code string `json:"code" binding:"required,max=64"`
+}
+
+// VerifyEmail verifies the authenticated user's email with a code sent to it
+func (server *Server) VerifyEmail(ctx *gin.Context) {
+	var req verifyEmailRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, err := server.store.VerifyEmailTxn(ctx, db.VerifyEmailTxnParams{
+		Username: authPayload.Username,
+		Code:     req.Code,
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getUserResponse(&user))
+}
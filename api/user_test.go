@@ -12,6 +12,7 @@ import (
 	db "simple-bank/db/sqlc"
 	"simple-bank/utils"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
@@ -136,10 +137,11 @@ func TestCreateUserApi(t *testing.T) {
 				"email":     user.Email,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, &pq.Error{Code: "23505"})
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, &pq.Error{Code: "23505", Constraint: "users_pkey"})
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, ErrUsernameTaken.Code)
 			},
 		},
 	}
@@ -290,3 +292,137 @@ func randomUser(t *testing.T) (user db.User, password string) {
 	}
 	return
 }
+
+func TestRequestEmailVerificationAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	verification := db.EmailVerification{
+		Username:  user.Username,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(db.EmailVerificationTTL),
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RequestEmailVerificationTxn(gomock.Any(), user.Username).Times(1).Return(verification, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var res emailVerificationResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, user.Email, res.Email)
+				require.NotContains(t, recorder.Body.String(), "code")
+			},
+		},
+		{
+			name: "AlreadyVerified",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RequestEmailVerificationTxn(gomock.Any(), user.Username).Times(1).Return(db.EmailVerification{}, db.ErrEmailAlreadyVerified)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblemCode(t, recorder, "EMAIL_ALREADY_VERIFIED")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/email/verification", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestVerifyEmailAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	code := utils.RandomString(32)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"code": code},
+			buildStubs: func(store *mockdb.MockStore) {
+				verified := user
+				verified.EmailVerified = true
+				store.EXPECT().
+					VerifyEmailTxn(gomock.Any(), db.VerifyEmailTxnParams{Username: user.Username, Code: code}).
+					Times(1).
+					Return(verified, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res userResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.True(t, res.EmailVerified)
+			},
+		},
+		{
+			name: "InvalidCode",
+			body: gin.H{"code": code},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().VerifyEmailTxn(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, db.ErrInvalidVerificationCode)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblemCode(t, recorder, "INVALID_VERIFICATION_CODE")
+			},
+		},
+		{
+			name: "MissingCode",
+			body: gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().VerifyEmailTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireProblemCode(t, recorder, "VALIDATION_FAILED")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/email/verify", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
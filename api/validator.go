@@ -1,7 +1,12 @@
 package api
 
 import (
+	"encoding/json"
+	"reflect"
+	db "simple-bank/db/sqlc"
 	"simple-bank/utils"
+	"simple-bank/webhook"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -13,3 +18,75 @@ var validCurrency validator.Func = func(FieldLevel validator.FieldLevel) bool {
 
 	return false
 }
+
+// validAccountNumber rejects mistyped account numbers before they reach the database
+var validAccountNumber validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if number, ok := fieldLevel.Field().Interface().(string); ok {
+		return utils.ValidAccountNumber(number)
+	}
+
+	return false
+}
+
+// validWebhookURL accepts https URLs that don't point at the bank's own network
+var validWebhookURL validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if url, ok := fieldLevel.Field().Interface().(string); ok {
+		return webhook.ValidURL(url)
+	}
+
+	return false
+}
+
+var validWebhookEvent validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if eventType, ok := fieldLevel.Field().Interface().(string); ok {
+		return db.IsEventType(eventType)
+	}
+
+	return false
+}
+
+// validRecipient accepts a username, an email or a well formed @alias
+var validRecipient validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if to, ok := fieldLevel.Field().Interface().(string); ok {
+		_, _, valid := utils.ParseRecipient(to)
+		return valid
+	}
+
+	return false
+}
+
+var validAlias validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if alias, ok := fieldLevel.Field().Interface().(string); ok {
+		return utils.ValidAlias(alias)
+	}
+
+	return false
+}
+
+// maxMetadataSize caps the JSON metadata clients attach to a transfer, in bytes
+const maxMetadataSize = 4096
+
+// validMetadata accepts a JSON object of up to maxMetadataSize bytes
+var validMetadata validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	metadata, ok := fieldLevel.Field().Interface().(json.RawMessage)
+	if !ok || len(metadata) > maxMetadataSize {
+		return false
+	}
+
+	var object map[string]any
+	return json.Unmarshal(metadata, &object) == nil && object != nil
+}
+
+// requestFieldName reports validation errors under the name clients send the field with
+func requestFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
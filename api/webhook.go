@@ -0,0 +1,215 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	db "simple-bank/db/sqlc"
+	"simple-bank/token"
+	"simple-bank/webhook"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createWebhookRequest struct {
+	Url    string   `json:"url" binding:"required,webhook_url"`
+	Events []string `json:"events" binding:"required,min=1,dive,webhook_event"`
+}
+
+type webhookResponse struct {
+	ID        int64     `json:"id"`
+	Url       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// createWebhookResponse is the only response carrying the signing secret
+type createWebhookResponse struct {
+	webhookResponse
+	Secret string `json:"secret"`
+}
+
+func getWebhookResponse(hook db.Webhook) webhookResponse {
+	return webhookResponse{
+		ID:        hook.ID,
+		Url:       hook.Url,
+		Events:    hook.Events,
+		Active:    hook.Active,
+		CreatedAt: hook.CreatedAt,
+	}
+}
+
+func (server *Server) CreateWebhook(ctx *gin.Context) {
+	var req createWebhookRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	hook, err := server.store.CreateWebhook(ctx, db.CreateWebhookParams{
+		Owner:  authPayload.Username,
+		Url:    req.Url,
+		Secret: secret,
+		Events: req.Events,
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createWebhookResponse{
+		webhookResponse: getWebhookResponse(hook),
+		Secret:          hook.Secret,
+	})
+}
+
+func (server *Server) ListWebhooks(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	hooks, err := server.store.ListWebhooks(ctx, authPayload.Username)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	res := make([]webhookResponse, len(hooks))
+	for i, hook := range hooks {
+		res[i] = getWebhookResponse(hook)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type webhookRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) DeleteWebhook(ctx *gin.Context) {
+	var req webhookRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	if _, valid := server.getUserWebhook(ctx, req.ID); !valid {
+		return
+	}
+
+	if err := server.store.DeleteWebhook(ctx, req.ID); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type listWebhookDeliveriesRequest struct {
+	webhookRequest
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=1,max=50"`
+}
+
+type webhookDeliveryResponse struct {
+	ID             int64      `json:"id"`
+	EventID        int64      `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int32      `json:"attempts"`
+	ResponseStatus int32      `json:"response_status,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func getWebhookDeliveryResponse(delivery db.WebhookDelivery) webhookDeliveryResponse {
+	res := webhookDeliveryResponse{
+		ID:             delivery.ID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus.Int32,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+	}
+	if delivery.Status == webhook.StatusPending {
+		res.NextAttemptAt = &delivery.NextAttemptAt
+	}
+	if delivery.DeliveredAt.Valid {
+		res.DeliveredAt = &delivery.DeliveredAt.Time
+	}
+	return res
+}
+
+// ListWebhookDeliveries returns the delivery log of a webhook, newest first
+func (server *Server) ListWebhookDeliveries(ctx *gin.Context) {
+	var req listWebhookDeliveriesRequest
+
+	if err := ctx.ShouldBindUri(&req.webhookRequest); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	if _, valid := server.getUserWebhook(ctx, req.ID); !valid {
+		return
+	}
+
+	deliveries, err := server.store.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{
+		WebhookID: req.ID,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	res := make([]webhookDeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		res[i] = getWebhookDeliveryResponse(delivery)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// getUserWebhook loads a webhook of the authenticated user, other users'
+// webhooks are reported as not found
+func (server *Server) getUserWebhook(ctx *gin.Context, id int64) (db.Webhook, bool) {
+	hook, err := server.store.GetWebhook(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			errorResponse(ctx, webhookNotFound(id))
+			return hook, false
+		}
+		errorResponse(ctx, err)
+		return hook, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if hook.Owner != authPayload.Username {
+		errorResponse(ctx, webhookNotFound(id))
+		return db.Webhook{}, false
+	}
+
+	return hook, true
+}
+
+func webhookNotFound(id int64) *apiError {
+	return ErrWebhookNotFound.withDetail(fmt.Sprintf("webhook [%d] not found", id))
+}
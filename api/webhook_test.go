@@ -0,0 +1,335 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simple-bank/db/mock"
+	db "simple-bank/db/sqlc"
+	"simple-bank/utils"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWebhookApi(t *testing.T) {
+	user, _ := randomUser(t)
+	hook := randomWebhook(user.Username)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"url":    hook.Url,
+				"events": hook.Events,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhook(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, args db.CreateWebhookParams) (db.Webhook, error) {
+						require.Equal(t, user.Username, args.Owner)
+						require.Equal(t, hook.Url, args.Url)
+						require.Equal(t, hook.Events, args.Events)
+						require.NotEmpty(t, args.Secret)
+
+						created := hook
+						created.Secret = args.Secret
+						return created, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res createWebhookResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, hook.ID, res.ID)
+				require.NotEmpty(t, res.Secret)
+			},
+		},
+		{
+			name: "UnknownEvent",
+			body: gin.H{
+				"url":    hook.Url,
+				"events": []string{"account.deleted"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				problem := requireProblemCode(t, recorder, ErrValidationFailed.Code)
+				require.Equal(t, "webhook_event", problem.Errors[0].Rule)
+			},
+		},
+		{
+			name: "NoEvents",
+			body: gin.H{
+				"url":    hook.Url,
+				"events": []string{},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "PlainHTTPURL",
+			body: gin.H{
+				"url":    "http://example.com/hooks",
+				"events": hook.Events,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalURL",
+			body: gin.H{
+				"url":    "https://169.254.169.254/latest/meta-data",
+				"events": hook.Events,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidURL",
+			body: gin.H{
+				"url":    "not a url",
+				"events": hook.Events,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestDeleteWebhookApi(t *testing.T) {
+	user, _ := randomUser(t)
+	hook := randomWebhook(user.Username)
+
+	testCases := []struct {
+		name       string
+		username   string
+		buildStubs func(store *mockdb.MockStore)
+		status     int
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhook(gomock.Any(), gomock.Eq(hook.ID)).Times(1).Return(hook, nil)
+				store.EXPECT().DeleteWebhook(gomock.Any(), gomock.Eq(hook.ID)).Times(1).Return(nil)
+			},
+			status: http.StatusNoContent,
+		},
+		{
+			name:     "OtherUsersWebhook",
+			username: "someoneelse",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhook(gomock.Any(), gomock.Eq(hook.ID)).Times(1).Return(hook, nil)
+				store.EXPECT().DeleteWebhook(gomock.Any(), gomock.Any()).Times(0)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name:     "NotFound",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhook(gomock.Any(), gomock.Eq(hook.ID)).Times(1).Return(db.Webhook{}, sql.ErrNoRows)
+				store.EXPECT().DeleteWebhook(gomock.Any(), gomock.Any()).Times(0)
+			},
+			status: http.StatusNotFound,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/webhooks/%d", hook.ID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+		})
+	}
+}
+
+func TestListWebhookDeliveriesApi(t *testing.T) {
+	user, _ := randomUser(t)
+	hook := randomWebhook(user.Username)
+	deliveries := []db.WebhookDelivery{
+		{
+			ID:             2,
+			WebhookID:      hook.ID,
+			EventID:        11,
+			EventType:      db.EventTransferCreated,
+			Status:         "pending",
+			Attempts:       1,
+			ResponseStatus: sql.NullInt32{Int32: http.StatusServiceUnavailable, Valid: true},
+			LastError:      "endpoint responded with 503 Service Unavailable",
+			NextAttemptAt:  time.Now().Add(time.Minute),
+			CreatedAt:      time.Now(),
+		},
+		{
+			ID:          1,
+			WebhookID:   hook.ID,
+			EventID:     10,
+			EventType:   db.EventTransferCreated,
+			Status:      "succeeded",
+			Attempts:    1,
+			DeliveredAt: sql.NullTime{Time: time.Now(), Valid: true},
+			CreatedAt:   time.Now(),
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			query:    "page_id=1&page_size=10",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhook(gomock.Any(), gomock.Eq(hook.ID)).Times(1).Return(hook, nil)
+				args := db.ListWebhookDeliveriesParams{WebhookID: hook.ID, Limit: 10, Offset: 0}
+				store.EXPECT().ListWebhookDeliveries(gomock.Any(), gomock.Eq(args)).Times(1).Return(deliveries, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res []webhookDeliveryResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res, 2)
+				require.Equal(t, int32(http.StatusServiceUnavailable), res[0].ResponseStatus)
+				require.NotNil(t, res[0].NextAttemptAt)
+				require.NotNil(t, res[1].DeliveredAt)
+				require.Nil(t, res[1].NextAttemptAt)
+			},
+		},
+		{
+			name:     "OtherUsersWebhook",
+			username: "someoneelse",
+			query:    "page_id=1&page_size=10",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhook(gomock.Any(), gomock.Eq(hook.ID)).Times(1).Return(hook, nil)
+				store.EXPECT().ListWebhookDeliveries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, ErrWebhookNotFound.Code)
+			},
+		},
+		{
+			name:     "InvalidPageSize",
+			username: user.Username,
+			query:    "page_id=1&page_size=500",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhook(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/webhooks/%d/deliveries?%s", hook.ID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func randomWebhook(owner string) db.Webhook {
+	return db.Webhook{
+		ID:        utils.RandomInt(1, 1000),
+		Owner:     owner,
+		Url:       "https://example.com/hooks/" + utils.RandomString(6),
+		Secret:    "whsec_" + utils.RandomString(32),
+		Events:    []string{db.EventTransferCreated, db.EventTransferReceived},
+		Active:    true,
+		CreatedAt: time.Now().UTC(),
+	}
+}
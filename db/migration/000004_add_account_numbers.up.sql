@@ -0,0 +1,15 @@
+ALTER TABLE "accounts" ADD COLUMN "number" varchar;
+
+-- backfill existing accounts using the same check digit scheme as
+-- utils.NewAccountNumber: 'SB' || check digits || 12 random digits
+UPDATE "accounts" AS a
+SET "number" = 'SB' || lpad((98 - (n.body || '281100')::numeric % 97)::text, 2, '0') || n.body
+FROM (
+  SELECT "id", lpad(floor(random() * 1e12)::bigint::text, 12, '0') AS body
+  FROM "accounts"
+) AS n
+WHERE a."id" = n."id";
+
+ALTER TABLE "accounts" ALTER COLUMN "number" SET NOT NULL;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_number_key" UNIQUE ("number");
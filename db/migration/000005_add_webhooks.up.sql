@@ -0,0 +1,49 @@
+CREATE TABLE "outbox" (
+  "id" bigserial PRIMARY KEY,
+  "topic" varchar NOT NULL,
+  "key" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "processed_at" timestamptz
+);
+
+CREATE INDEX ON "outbox" ("id") WHERE "processed_at" IS NULL;
+
+CREATE TABLE "webhooks" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "url" varchar NOT NULL,
+  "secret" varchar NOT NULL,
+  "events" varchar[] NOT NULL,
+  "active" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_deliveries" (
+  "id" bigserial PRIMARY KEY,
+  "webhook_id" bigint NOT NULL,
+  "event_id" bigint NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "attempts" integer NOT NULL DEFAULT 0,
+  "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+  "response_status" integer,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "delivered_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "webhooks" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("webhook_id") REFERENCES "webhooks" ("id") ON DELETE CASCADE;
+
+CREATE INDEX ON "webhooks" ("owner");
+
+CREATE UNIQUE INDEX ON "webhook_deliveries" ("webhook_id", "event_id");
+
+CREATE INDEX ON "webhook_deliveries" ("status", "next_attempt_at");
+
+COMMENT ON COLUMN "outbox"."key" IS 'routing key, the username for account and transfer events';
+
+COMMENT ON COLUMN "webhook_deliveries"."status" IS 'pending, succeeded or failed';
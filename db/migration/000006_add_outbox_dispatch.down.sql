@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "outbox_dead_letters";
+
+ALTER TABLE IF EXISTS "outbox" DROP COLUMN IF EXISTS "last_error";
+
+ALTER TABLE IF EXISTS "outbox" DROP COLUMN IF EXISTS "next_attempt_at";
+
+ALTER TABLE IF EXISTS "outbox" DROP COLUMN IF EXISTS "attempts";
@@ -0,0 +1,17 @@
+ALTER TABLE "outbox" ADD COLUMN "attempts" integer NOT NULL DEFAULT 0;
+
+ALTER TABLE "outbox" ADD COLUMN "next_attempt_at" timestamptz NOT NULL DEFAULT (now());
+
+ALTER TABLE "outbox" ADD COLUMN "last_error" varchar NOT NULL DEFAULT '';
+
+CREATE TABLE "outbox_dead_letters" (
+  "id" bigserial PRIMARY KEY,
+  "event_id" bigint UNIQUE NOT NULL,
+  "topic" varchar NOT NULL,
+  "key" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "attempts" integer NOT NULL,
+  "last_error" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL,
+  "dead_lettered_at" timestamptz NOT NULL DEFAULT (now())
+);
@@ -0,0 +1,17 @@
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+-- entries written by TransferTxn share the transaction's timestamp with their transfer
+UPDATE "entries" e
+SET "transfer_id" = t."id"
+FROM "transfers" t
+WHERE e."created_at" = t."created_at"
+  AND (
+    (e."account_id" = t."from_account_id" AND e."amount" = -t."amount") OR
+    (e."account_id" = t."to_account_id" AND e."amount" = t."amount")
+  );
+
+CREATE INDEX ON "entries" ("transfer_id");
+
+CREATE INDEX ON "entries" ("account_id", "created_at");
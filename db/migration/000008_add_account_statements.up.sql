@@ -0,0 +1,16 @@
+CREATE TABLE "account_statements" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "period" date NOT NULL,
+  "storage_key" varchar NOT NULL,
+  "size" bigint NOT NULL,
+  "opening_balance" bigint NOT NULL,
+  "closing_balance" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "account_statements" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE UNIQUE INDEX ON "account_statements" ("account_id", "period");
+
+COMMENT ON COLUMN "account_statements"."period" IS 'first day of the month the statement covers';
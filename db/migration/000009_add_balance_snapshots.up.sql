@@ -0,0 +1,13 @@
+CREATE TABLE "balance_snapshots" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "balance" bigint NOT NULL,
+  "taken_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "balance_snapshots" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE UNIQUE INDEX ON "balance_snapshots" ("account_id", "taken_at");
+
+COMMENT ON COLUMN "balance_snapshots"."balance" IS 'balance including every entry created up to taken_at';
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS "entries_journal_balanced" ON "entries";
+
+DROP FUNCTION IF EXISTS "check_journal_balanced";
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "journal_transaction_id";
+
+DROP TABLE IF EXISTS "journal_transactions";
@@ -0,0 +1,57 @@
+CREATE TABLE "journal_transactions" (
+  "id" bigserial PRIMARY KEY,
+  "type" varchar NOT NULL,
+  "reference" varchar NOT NULL,
+  "metadata" jsonb NOT NULL DEFAULT '{}',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "journal_transactions" ("reference");
+
+COMMENT ON COLUMN "journal_transactions"."type" IS 'transfer, deposit, fee, fx or reversal';
+
+ALTER TABLE "entries" ADD COLUMN "journal_transaction_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("journal_transaction_id") REFERENCES "journal_transactions" ("id");
+
+CREATE INDEX ON "entries" ("journal_transaction_id");
+
+-- every existing transfer becomes a journal transaction holding its two entries
+INSERT INTO "journal_transactions" ("type", "reference", "created_at")
+SELECT 'transfer', 'transfer:' || t."id", t."created_at"
+FROM "transfers" t;
+
+UPDATE "entries" e
+SET "journal_transaction_id" = j."id"
+FROM "journal_transactions" j
+WHERE j."type" = 'transfer' AND j."reference" = 'transfer:' || e."transfer_id";
+
+-- postings of a journal transaction must sum to zero in every currency. The check
+-- is deferred to commit so the entries can be written one by one.
+CREATE FUNCTION "check_journal_balanced"() RETURNS trigger AS $$
+DECLARE
+  unbalanced record;
+BEGIN
+  SELECT a."currency", sum(e."amount") AS "total" INTO unbalanced
+  FROM "entries" e
+  JOIN "accounts" a ON a."id" = e."account_id"
+  WHERE e."journal_transaction_id" = NEW."journal_transaction_id"
+  GROUP BY a."currency"
+  HAVING sum(e."amount") <> 0
+  LIMIT 1;
+
+  IF FOUND THEN
+    RAISE EXCEPTION 'journal transaction % is unbalanced by % %', NEW."journal_transaction_id", unbalanced."total", unbalanced."currency"
+      USING ERRCODE = 'check_violation', CONSTRAINT = 'journal_transactions_balanced';
+  END IF;
+
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER "entries_journal_balanced"
+AFTER INSERT OR UPDATE ON "entries"
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW
+WHEN (NEW."journal_transaction_id" IS NOT NULL)
+EXECUTE FUNCTION "check_journal_balanced"();
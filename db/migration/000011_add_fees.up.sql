@@ -0,0 +1,37 @@
+CREATE TABLE "fee_rules" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "kind" varchar NOT NULL,
+  "priority" integer NOT NULL DEFAULT 0,
+  "cross_user_only" boolean NOT NULL DEFAULT false,
+  "min_amount" bigint NOT NULL DEFAULT 0,
+  "flat_fee" bigint NOT NULL DEFAULT 0,
+  "basis_points" integer NOT NULL DEFAULT 0,
+  "tiers" jsonb NOT NULL DEFAULT '[]',
+  "min_fee" bigint NOT NULL DEFAULT 0,
+  "max_fee" bigint NOT NULL DEFAULT 0,
+  "active" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "fee_rules_kind_check" CHECK ("kind" IN ('flat', 'percentage', 'tiered'))
+);
+
+CREATE INDEX ON "fee_rules" ("currency", "priority");
+
+COMMENT ON COLUMN "fee_rules"."kind" IS 'flat, percentage or tiered';
+
+COMMENT ON COLUMN "fee_rules"."priority" IS 'the first matching rule, lowest priority first, sets the fee';
+
+COMMENT ON COLUMN "fee_rules"."min_amount" IS 'the rule applies to transfers of at least this amount';
+
+COMMENT ON COLUMN "fee_rules"."tiers" IS 'tiered rules: [{"up_to", "flat_fee", "basis_points"}], up_to 0 is unbounded';
+
+COMMENT ON COLUMN "fee_rules"."max_fee" IS '0 means uncapped';
+
+ALTER TABLE "transfers" ADD COLUMN "fee" bigint NOT NULL DEFAULT 0;
+
+-- owns the bank's own accounts, such as fee revenue. The name can't be registered
+-- through the API and the password hash never matches.
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('simple-bank', '!', 'Simple Bank', 'system@simple-bank.invalid')
+ON CONFLICT DO NOTHING;
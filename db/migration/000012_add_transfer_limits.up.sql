@@ -0,0 +1,24 @@
+ALTER TABLE "users" ADD COLUMN "tier" varchar NOT NULL DEFAULT 'standard';
+
+CREATE TABLE "transfer_limits" (
+  "id" bigserial PRIMARY KEY,
+  "tier" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "max_per_transfer" bigint NOT NULL DEFAULT 0,
+  "max_per_day" bigint NOT NULL DEFAULT 0,
+  "max_per_30_days" bigint NOT NULL DEFAULT 0,
+  "max_transfers_per_hour" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "transfer_limits" ("tier", "currency");
+
+COMMENT ON TABLE "transfer_limits" IS 'limits are checked per sending account, 0 means unlimited';
+
+COMMENT ON COLUMN "transfer_limits"."max_per_day" IS 'total amount sent since midnight UTC';
+
+COMMENT ON COLUMN "transfer_limits"."max_per_30_days" IS 'total amount sent over the last 30 days';
+
+COMMENT ON COLUMN "transfer_limits"."max_transfers_per_hour" IS 'number of transfers sent over the last hour';
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
@@ -0,0 +1,34 @@
+ALTER TABLE "accounts" ADD COLUMN "held_balance" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_held_balance_check" CHECK ("held_balance" >= 0);
+
+COMMENT ON COLUMN "accounts"."held_balance" IS 'sum of pending holds, the available balance is balance - held_balance';
+
+CREATE TABLE "holds" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "captured_amount" bigint NOT NULL DEFAULT 0,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "transfer_id" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "settled_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "holds_amount_check" CHECK ("amount" > 0),
+  CONSTRAINT "holds_status_check" CHECK ("status" IN ('pending', 'captured', 'voided', 'expired'))
+);
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "holds" ("account_id");
+
+CREATE INDEX ON "holds" ("status", "expires_at");
+
+COMMENT ON COLUMN "holds"."status" IS 'pending, captured, voided or expired';
+
+COMMENT ON COLUMN "holds"."transfer_id" IS 'the transfer a captured hold turned into';
@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "email_verified";
+
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "alias";
@@ -0,0 +1,7 @@
+ALTER TABLE "users" ADD COLUMN "alias" varchar UNIQUE;
+
+ALTER TABLE "users" ADD COLUMN "email_verified" boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN "users"."alias" IS 'user-chosen handle to receive transfers, sent to as @alias';
+
+COMMENT ON COLUMN "users"."email_verified" IS 'only verified emails resolve transfer recipients';
@@ -0,0 +1,36 @@
+CREATE TABLE "payment_requests" (
+  "id" bigserial PRIMARY KEY,
+  "requester" varchar NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "payer" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "note" varchar NOT NULL DEFAULT '',
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "transfer_id" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "responded_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "payment_requests_amount_check" CHECK ("amount" > 0),
+  CONSTRAINT "payment_requests_status_check" CHECK ("status" IN ('pending', 'paid', 'declined', 'cancelled', 'expired'))
+);
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("requester") REFERENCES "users" ("username");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("payer") REFERENCES "users" ("username");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "payment_requests" ("requester", "created_at");
+
+CREATE INDEX ON "payment_requests" ("payer", "created_at");
+
+CREATE INDEX ON "payment_requests" ("status", "expires_at");
+
+COMMENT ON COLUMN "payment_requests"."to_account_id" IS 'the requester account the payment goes to';
+
+COMMENT ON COLUMN "payment_requests"."status" IS 'pending, then paid, declined, cancelled or expired';
+
+COMMENT ON COLUMN "payment_requests"."transfer_id" IS 'the transfer paying an accepted request';
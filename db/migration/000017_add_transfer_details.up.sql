@@ -0,0 +1,13 @@
+ALTER TABLE "transfers" ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers" ADD COLUMN "reference" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers" ADD COLUMN "metadata" jsonb NOT NULL DEFAULT '{}';
+
+CREATE INDEX ON "transfers" ("reference");
+
+COMMENT ON COLUMN "transfers"."description" IS 'memo shown to both sides of the transfer';
+
+COMMENT ON COLUMN "transfers"."reference" IS 'reference set by the sending client, searchable';
+
+COMMENT ON COLUMN "transfers"."metadata" IS 'free-form JSON object set by the sending client';
@@ -0,0 +1,7 @@
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "tags";
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "category_id";
+
+DROP TABLE IF EXISTS "category_rules";
+
+DROP TABLE IF EXISTS "categories";
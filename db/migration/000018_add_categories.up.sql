@@ -0,0 +1,46 @@
+CREATE TABLE "categories" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "name" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "categories_owner_name_key" UNIQUE ("owner", "name")
+);
+
+CREATE TABLE "category_rules" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "category_id" bigint NOT NULL,
+  "counterparty_account_id" bigint,
+  "memo_keyword" varchar,
+  "min_amount" bigint,
+  "max_amount" bigint,
+  "priority" int NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "category_rules_condition_check" CHECK (
+    "counterparty_account_id" IS NOT NULL OR "memo_keyword" IS NOT NULL OR "min_amount" IS NOT NULL OR "max_amount" IS NOT NULL
+  )
+);
+
+ALTER TABLE "entries" ADD COLUMN "category_id" bigint;
+
+ALTER TABLE "entries" ADD COLUMN "tags" varchar[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX ON "category_rules" ("owner", "priority");
+
+CREATE INDEX ON "entries" ("category_id");
+
+COMMENT ON COLUMN "category_rules"."memo_keyword" IS 'matches transfers whose description contains it, case insensitive';
+
+COMMENT ON COLUMN "category_rules"."priority" IS 'the matching rule with the lowest priority sets the category';
+
+COMMENT ON COLUMN "entries"."category_id" IS 'set by the owner or by their category rules when booked';
+
+ALTER TABLE "categories" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "category_rules" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "category_rules" ADD FOREIGN KEY ("category_id") REFERENCES "categories" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "category_rules" ADD FOREIGN KEY ("counterparty_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("category_id") REFERENCES "categories" ("id") ON DELETE SET NULL;
@@ -0,0 +1 @@
+CREATE INDEX ON "transfers" ("to_account_id", "created_at");
@@ -0,0 +1,7 @@
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "product";
+
+DROP TABLE IF EXISTS "interest_accruals";
+
+DROP TABLE IF EXISTS "account_products";
+
+COMMENT ON COLUMN "journal_transactions"."type" IS 'transfer, deposit, fee, fx or reversal';
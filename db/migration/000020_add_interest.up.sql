@@ -0,0 +1,48 @@
+CREATE TABLE "account_products" (
+  "code" varchar PRIMARY KEY,
+  "name" varchar NOT NULL,
+  "interest_rate_bps" integer NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "account_products_interest_rate_check" CHECK ("interest_rate_bps" >= 0)
+);
+
+CREATE TABLE "interest_accruals" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "interest_rate_bps" integer NOT NULL,
+  "amount_micros" bigint NOT NULL,
+  "journal_transaction_id" bigint,
+  "posted_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "interest_accruals_account_date_key" UNIQUE ("account_id", "accrual_date")
+);
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("journal_transaction_id") REFERENCES "journal_transactions" ("id");
+
+CREATE INDEX ON "interest_accruals" ("posted_at", "accrual_date");
+
+COMMENT ON COLUMN "account_products"."interest_rate_bps" IS 'annual interest rate in basis points, accrued daily on actual/365';
+
+COMMENT ON COLUMN "interest_accruals"."balance" IS 'end-of-day balance the interest was computed on';
+
+COMMENT ON COLUMN "interest_accruals"."amount_micros" IS 'interest in millionths of the minor unit, rounded half to even';
+
+COMMENT ON COLUMN "interest_accruals"."posted_at" IS 'set once the month is paid out, journal_transaction_id is null when it rounded to 0';
+
+INSERT INTO "account_products" ("code", "name", "interest_rate_bps")
+VALUES ('checking', 'Checking', 0), ('savings', 'Savings', 200);
+
+ALTER TABLE "accounts" ADD COLUMN "product" varchar NOT NULL DEFAULT 'checking';
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("product") REFERENCES "account_products" ("code");
+
+COMMENT ON COLUMN "journal_transactions"."type" IS 'transfer, deposit, fee, fx, reversal or interest';
+
+-- owns the accounts interest is paid from, one per currency
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('simple-bank-interest', '!', 'Simple Bank Interest', 'interest@simple-bank.invalid')
+ON CONFLICT DO NOTHING;
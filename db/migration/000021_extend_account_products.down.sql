@@ -0,0 +1,17 @@
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "owner_currency_product_key";
+
+ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "owner_currency_key" UNIQUE ("owner", "currency");
+
+ALTER TABLE IF EXISTS "fee_rules" DROP COLUMN IF EXISTS "product";
+
+DELETE FROM "account_products" WHERE "code" = 'business';
+
+ALTER TABLE IF EXISTS "account_products" DROP CONSTRAINT IF EXISTS "account_products_overdraft_limit_check";
+
+ALTER TABLE IF EXISTS "account_products" DROP COLUMN IF EXISTS "own_accounts_only";
+
+ALTER TABLE IF EXISTS "account_products" DROP COLUMN IF EXISTS "max_transfers_per_month";
+
+ALTER TABLE IF EXISTS "account_products" DROP COLUMN IF EXISTS "overdraft_limit";
+
+ALTER TABLE IF EXISTS "account_products" DROP COLUMN IF EXISTS "currencies";
@@ -0,0 +1,34 @@
+ALTER TABLE "account_products" ADD COLUMN "currencies" varchar[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE "account_products" ADD COLUMN "overdraft_limit" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "account_products" ADD COLUMN "max_transfers_per_month" integer NOT NULL DEFAULT 0;
+
+ALTER TABLE "account_products" ADD COLUMN "own_accounts_only" boolean NOT NULL DEFAULT false;
+
+ALTER TABLE "account_products" ADD CONSTRAINT "account_products_overdraft_limit_check" CHECK ("overdraft_limit" >= 0);
+
+COMMENT ON COLUMN "account_products"."currencies" IS 'currencies accounts can be opened in, any supported one when empty';
+
+COMMENT ON COLUMN "account_products"."overdraft_limit" IS 'how far below 0 debits may take the available balance';
+
+COMMENT ON COLUMN "account_products"."max_transfers_per_month" IS 'outgoing transfers per UTC calendar month, 0 is unlimited';
+
+COMMENT ON COLUMN "account_products"."own_accounts_only" IS 'outgoing transfers may only go to accounts of the same owner';
+
+UPDATE "account_products"
+SET "max_transfers_per_month" = 6, "own_accounts_only" = true
+WHERE "code" = 'savings';
+
+INSERT INTO "account_products" ("code", "name")
+VALUES ('business', 'Business');
+
+ALTER TABLE "fee_rules" ADD COLUMN "product" varchar;
+
+ALTER TABLE "fee_rules" ADD FOREIGN KEY ("product") REFERENCES "account_products" ("code");
+
+COMMENT ON COLUMN "fee_rules"."product" IS 'the rule only applies to senders of this product, to all when null';
+
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "owner_currency_key";
+
+ALTER TABLE "accounts" ADD CONSTRAINT "owner_currency_product_key" UNIQUE ("owner", "currency", "product");
@@ -0,0 +1,34 @@
+CREATE TABLE "account_members" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "username" varchar NOT NULL,
+  "role" varchar NOT NULL,
+  "spend_limit" bigint NOT NULL DEFAULT 0,
+  "status" varchar NOT NULL DEFAULT 'invited',
+  "invited_by" varchar NOT NULL,
+  "accepted_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "account_members_account_username_key" UNIQUE ("account_id", "username"),
+  CONSTRAINT "account_members_role_check" CHECK ("role" IN ('owner', 'co_owner', 'viewer', 'spender')),
+  CONSTRAINT "account_members_status_check" CHECK ("status" IN ('invited', 'active')),
+  CONSTRAINT "account_members_spend_limit_check" CHECK ("spend_limit" >= 0)
+);
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("username");
+
+CREATE INDEX ON "account_members" ("username", "status");
+
+COMMENT ON COLUMN "account_members"."role" IS 'owner, co_owner, viewer or spender';
+
+COMMENT ON COLUMN "account_members"."spend_limit" IS 'most a spender can send in one transfer, unused for other roles';
+
+COMMENT ON COLUMN "account_members"."status" IS 'invited until the user accepts, then active';
+
+-- every existing account is shared with its owner only
+INSERT INTO "account_members" ("account_id", "username", "role", "status", "invited_by", "accepted_at")
+SELECT "id", "owner", 'owner', 'active', "owner", "created_at"
+FROM "accounts";
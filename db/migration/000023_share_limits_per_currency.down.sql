@@ -0,0 +1 @@
+COMMENT ON TABLE "transfer_limits" IS 'limits are checked per sending account, 0 means unlimited';
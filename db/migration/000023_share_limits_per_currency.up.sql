@@ -0,0 +1 @@
+COMMENT ON TABLE "transfer_limits" IS 'limits are shared by the accounts of an owner in the currency, 0 means unlimited';
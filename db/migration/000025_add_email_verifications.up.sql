@@ -0,0 +1,17 @@
+CREATE TABLE "email_verifications" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "email" varchar NOT NULL,
+  "code_hash" varchar NOT NULL,
+  "used_at" timestamptz,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "email_verifications" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+CREATE INDEX ON "email_verifications" ("username", "code_hash");
+
+COMMENT ON COLUMN "email_verifications"."email" IS 'the address the code was sent to, verifying it fails once the user changes email';
+
+COMMENT ON COLUMN "email_verifications"."code_hash" IS 'sha256 of the code, the code itself is only sent by email';
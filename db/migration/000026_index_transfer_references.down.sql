@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "transfers_to_account_id_reference_idx";
+
+DROP INDEX IF EXISTS "transfers_from_account_id_reference_idx";
+
+CREATE INDEX ON "transfers" ("reference");
@@ -0,0 +1,7 @@
+-- ListAccountTransfers filters by reference within an account, so the reference is
+-- indexed behind each side of the transfer instead of on its own
+DROP INDEX IF EXISTS "transfers_reference_idx";
+
+CREATE INDEX ON "transfers" ("from_account_id", "reference");
+
+CREATE INDEX ON "transfers" ("to_account_id", "reference");
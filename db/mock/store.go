@@ -6,6 +6,7 @@ package mockdb
 
 import (
 	context "context"
+	sql "database/sql"
 	reflect "reflect"
 	db "simple-bank/db/sqlc"
 
@@ -36,6 +37,36 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AcceptAccountInvitation mocks base method.
+func (m *MockStore) AcceptAccountInvitation(arg0 context.Context, arg1 db.AcceptAccountInvitationParams) (db.AccountMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptAccountInvitation", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptAccountInvitation indicates an expected call of AcceptAccountInvitation.
+func (mr *MockStoreMockRecorder) AcceptAccountInvitation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptAccountInvitation", reflect.TypeOf((*MockStore)(nil).AcceptAccountInvitation), arg0, arg1)
+}
+
+// AcceptPaymentRequestTxn mocks base method.
+func (m *MockStore) AcceptPaymentRequestTxn(arg0 context.Context, arg1 db.AcceptPaymentRequestTxnParams) (db.AcceptPaymentRequestTxnResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptPaymentRequestTxn", arg0, arg1)
+	ret0, _ := ret[0].(db.AcceptPaymentRequestTxnResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptPaymentRequestTxn indicates an expected call of AcceptPaymentRequestTxn.
+func (mr *MockStoreMockRecorder) AcceptPaymentRequestTxn(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptPaymentRequestTxn", reflect.TypeOf((*MockStore)(nil).AcceptPaymentRequestTxn), arg0, arg1)
+}
+
 // AddAccountBalance mocks base method.
 func (m *MockStore) AddAccountBalance(arg0 context.Context, arg1 db.AddAccountBalanceParams) (db.Account, error) {
 	m.ctrl.T.Helper()
@@ -51,6 +82,95 @@ func (mr *MockStoreMockRecorder) AddAccountBalance(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountBalance", reflect.TypeOf((*MockStore)(nil).AddAccountBalance), arg0, arg1)
 }
 
+// AddAccountHeldBalance mocks base method.
+func (m *MockStore) AddAccountHeldBalance(arg0 context.Context, arg1 db.AddAccountHeldBalanceParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAccountHeldBalance", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAccountHeldBalance indicates an expected call of AddAccountHeldBalance.
+func (mr *MockStoreMockRecorder) AddAccountHeldBalance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountHeldBalance", reflect.TypeOf((*MockStore)(nil).AddAccountHeldBalance), arg0, arg1)
+}
+
+// BatchTransferTxn mocks base method.
+func (m *MockStore) BatchTransferTxn(arg0 context.Context, arg1 db.BatchTransferTxnParams) (db.BatchTransferTxnResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchTransferTxn", arg0, arg1)
+	ret0, _ := ret[0].(db.BatchTransferTxnResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchTransferTxn indicates an expected call of BatchTransferTxn.
+func (mr *MockStoreMockRecorder) BatchTransferTxn(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchTransferTxn", reflect.TypeOf((*MockStore)(nil).BatchTransferTxn), arg0, arg1)
+}
+
+// CaptureHoldTxn mocks base method.
+func (m *MockStore) CaptureHoldTxn(arg0 context.Context, arg1 db.CaptureHoldTxnParams) (db.CaptureHoldTxnResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureHoldTxn", arg0, arg1)
+	ret0, _ := ret[0].(db.CaptureHoldTxnResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureHoldTxn indicates an expected call of CaptureHoldTxn.
+func (mr *MockStoreMockRecorder) CaptureHoldTxn(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHoldTxn", reflect.TypeOf((*MockStore)(nil).CaptureHoldTxn), arg0, arg1)
+}
+
+// CategorizeTransferEntries mocks base method.
+func (m *MockStore) CategorizeTransferEntries(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CategorizeTransferEntries", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CategorizeTransferEntries indicates an expected call of CategorizeTransferEntries.
+func (mr *MockStoreMockRecorder) CategorizeTransferEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CategorizeTransferEntries", reflect.TypeOf((*MockStore)(nil).CategorizeTransferEntries), arg0, arg1)
+}
+
+// ClaimOutboxEvents mocks base method.
+func (m *MockStore) ClaimOutboxEvents(arg0 context.Context, arg1 int32) ([]db.Outbox, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimOutboxEvents", arg0, arg1)
+	ret0, _ := ret[0].([]db.Outbox)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimOutboxEvents indicates an expected call of ClaimOutboxEvents.
+func (mr *MockStoreMockRecorder) ClaimOutboxEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOutboxEvents", reflect.TypeOf((*MockStore)(nil).ClaimOutboxEvents), arg0, arg1)
+}
+
+// ClaimWebhookDeliveries mocks base method.
+func (m *MockStore) ClaimWebhookDeliveries(arg0 context.Context, arg1 db.ClaimWebhookDeliveriesParams) ([]db.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]db.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookDeliveries indicates an expected call of ClaimWebhookDeliveries.
+func (mr *MockStoreMockRecorder) ClaimWebhookDeliveries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).ClaimWebhookDeliveries), arg0, arg1)
+}
+
 // CreateAccount mocks base method.
 func (m *MockStore) CreateAccount(arg0 context.Context, arg1 db.CreateAccountParams) (db.Account, error) {
 	m.ctrl.T.Helper()
@@ -66,241 +186,1798 @@ func (mr *MockStoreMockRecorder) CreateAccount(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStore)(nil).CreateAccount), arg0, arg1)
 }
 
-// CreateEntry mocks base method.
-func (m *MockStore) CreateEntry(arg0 context.Context, arg1 db.CreateEntryParams) (db.Entry, error) {
+// CreateAccountMember mocks base method.
+func (m *MockStore) CreateAccountMember(arg0 context.Context, arg1 db.CreateAccountMemberParams) (db.AccountMember, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateEntry", arg0, arg1)
-	ret0, _ := ret[0].(db.Entry)
+	ret := m.ctrl.Call(m, "CreateAccountMember", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountMember)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateEntry indicates an expected call of CreateEntry.
-func (mr *MockStoreMockRecorder) CreateEntry(arg0, arg1 interface{}) *gomock.Call {
+// CreateAccountMember indicates an expected call of CreateAccountMember.
+func (mr *MockStoreMockRecorder) CreateAccountMember(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockStore)(nil).CreateEntry), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountMember", reflect.TypeOf((*MockStore)(nil).CreateAccountMember), arg0, arg1)
 }
 
-// CreateSession mocks base method.
-func (m *MockStore) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (db.Session, error) {
+// CreateAccountOwner mocks base method.
+func (m *MockStore) CreateAccountOwner(arg0 context.Context, arg1 db.CreateAccountOwnerParams) (db.AccountMember, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSession", arg0, arg1)
-	ret0, _ := ret[0].(db.Session)
+	ret := m.ctrl.Call(m, "CreateAccountOwner", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountMember)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateSession indicates an expected call of CreateSession.
-func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock.Call {
+// CreateAccountOwner indicates an expected call of CreateAccountOwner.
+func (mr *MockStoreMockRecorder) CreateAccountOwner(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountOwner", reflect.TypeOf((*MockStore)(nil).CreateAccountOwner), arg0, arg1)
 }
 
-// CreateTransfer mocks base method.
-func (m *MockStore) CreateTransfer(arg0 context.Context, arg1 db.CreateTransferParams) (db.Transfer, error) {
+// CreateAccountProduct mocks base method.
+func (m *MockStore) CreateAccountProduct(arg0 context.Context, arg1 db.CreateAccountProductParams) (db.AccountProduct, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateTransfer", arg0, arg1)
-	ret0, _ := ret[0].(db.Transfer)
+	ret := m.ctrl.Call(m, "CreateAccountProduct", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountProduct)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateTransfer indicates an expected call of CreateTransfer.
-func (mr *MockStoreMockRecorder) CreateTransfer(arg0, arg1 interface{}) *gomock.Call {
+// CreateAccountProduct indicates an expected call of CreateAccountProduct.
+func (mr *MockStoreMockRecorder) CreateAccountProduct(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockStore)(nil).CreateTransfer), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountProduct", reflect.TypeOf((*MockStore)(nil).CreateAccountProduct), arg0, arg1)
 }
 
-// CreateUser mocks base method.
-func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+// CreateAccountStatement mocks base method.
+func (m *MockStore) CreateAccountStatement(arg0 context.Context, arg1 db.CreateAccountStatementParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "CreateAccountStatement", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAccountStatement indicates an expected call of CreateAccountStatement.
+func (mr *MockStoreMockRecorder) CreateAccountStatement(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountStatement", reflect.TypeOf((*MockStore)(nil).CreateAccountStatement), arg0, arg1)
+}
+
+// CreateAccountTxn mocks base method.
+func (m *MockStore) CreateAccountTxn(arg0 context.Context, arg1 db.CreateAccountParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountTxn", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateUser indicates an expected call of CreateUser.
-func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+// CreateAccountTxn indicates an expected call of CreateAccountTxn.
+func (mr *MockStoreMockRecorder) CreateAccountTxn(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountTxn", reflect.TypeOf((*MockStore)(nil).CreateAccountTxn), arg0, arg1)
 }
 
-// DeleteAccount mocks base method.
-func (m *MockStore) DeleteAccount(arg0 context.Context, arg1 int64) error {
+// CreateBalanceSnapshots mocks base method.
+func (m *MockStore) CreateBalanceSnapshots(arg0 context.Context, arg1 db.CreateBalanceSnapshotsParams) ([]db.BalanceSnapshot, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "CreateBalanceSnapshots", arg0, arg1)
+	ret0, _ := ret[0].([]db.BalanceSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteAccount indicates an expected call of DeleteAccount.
-func (mr *MockStoreMockRecorder) DeleteAccount(arg0, arg1 interface{}) *gomock.Call {
+// CreateBalanceSnapshots indicates an expected call of CreateBalanceSnapshots.
+func (mr *MockStoreMockRecorder) CreateBalanceSnapshots(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockStore)(nil).DeleteAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBalanceSnapshots", reflect.TypeOf((*MockStore)(nil).CreateBalanceSnapshots), arg0, arg1)
 }
 
-// GetAccount mocks base method.
-func (m *MockStore) GetAccount(arg0 context.Context, arg1 int64) (db.Account, error) {
+// CreateCategory mocks base method.
+func (m *MockStore) CreateCategory(arg0 context.Context, arg1 db.CreateCategoryParams) (db.Category, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
-	ret0, _ := ret[0].(db.Account)
+	ret := m.ctrl.Call(m, "CreateCategory", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccount indicates an expected call of GetAccount.
-func (mr *MockStoreMockRecorder) GetAccount(arg0, arg1 interface{}) *gomock.Call {
+// CreateCategory indicates an expected call of CreateCategory.
+func (mr *MockStoreMockRecorder) CreateCategory(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockStore)(nil).GetAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategory", reflect.TypeOf((*MockStore)(nil).CreateCategory), arg0, arg1)
 }
 
-// GetAccountForUpdate mocks base method.
-func (m *MockStore) GetAccountForUpdate(arg0 context.Context, arg1 int64) (db.Account, error) {
+// CreateCategoryRule mocks base method.
+func (m *MockStore) CreateCategoryRule(arg0 context.Context, arg1 db.CreateCategoryRuleParams) (db.CategoryRule, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccountForUpdate", arg0, arg1)
-	ret0, _ := ret[0].(db.Account)
+	ret := m.ctrl.Call(m, "CreateCategoryRule", arg0, arg1)
+	ret0, _ := ret[0].(db.CategoryRule)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
-func (mr *MockStoreMockRecorder) GetAccountForUpdate(arg0, arg1 interface{}) *gomock.Call {
+// CreateCategoryRule indicates an expected call of CreateCategoryRule.
+func (mr *MockStoreMockRecorder) CreateCategoryRule(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountForUpdate), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategoryRule", reflect.TypeOf((*MockStore)(nil).CreateCategoryRule), arg0, arg1)
 }
 
-// GetEntries mocks base method.
-func (m *MockStore) GetEntries(arg0 context.Context, arg1 db.GetEntriesParams) ([]db.Entry, error) {
+// CreateEmailVerification mocks base method.
+func (m *MockStore) CreateEmailVerification(arg0 context.Context, arg1 db.CreateEmailVerificationParams) (db.EmailVerification, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetEntries", arg0, arg1)
-	ret0, _ := ret[0].([]db.Entry)
+	ret := m.ctrl.Call(m, "CreateEmailVerification", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailVerification)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetEntries indicates an expected call of GetEntries.
-func (mr *MockStoreMockRecorder) GetEntries(arg0, arg1 interface{}) *gomock.Call {
+// CreateEmailVerification indicates an expected call of CreateEmailVerification.
+func (mr *MockStoreMockRecorder) CreateEmailVerification(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntries", reflect.TypeOf((*MockStore)(nil).GetEntries), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailVerification", reflect.TypeOf((*MockStore)(nil).CreateEmailVerification), arg0, arg1)
 }
 
-// GetEntry mocks base method.
-func (m *MockStore) GetEntry(arg0 context.Context, arg1 int64) (db.Entry, error) {
+// CreateEntry mocks base method.
+func (m *MockStore) CreateEntry(arg0 context.Context, arg1 db.CreateEntryParams) (db.Entry, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetEntry", arg0, arg1)
+	ret := m.ctrl.Call(m, "CreateEntry", arg0, arg1)
 	ret0, _ := ret[0].(db.Entry)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetEntry indicates an expected call of GetEntry.
-func (mr *MockStoreMockRecorder) GetEntry(arg0, arg1 interface{}) *gomock.Call {
+// CreateEntry indicates an expected call of CreateEntry.
+func (mr *MockStoreMockRecorder) CreateEntry(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockStore)(nil).GetEntry), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockStore)(nil).CreateEntry), arg0, arg1)
 }
 
-// GetSession mocks base method.
-func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+// CreateFeeRule mocks base method.
+func (m *MockStore) CreateFeeRule(arg0 context.Context, arg1 db.CreateFeeRuleParams) (db.FeeRule, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
-	ret0, _ := ret[0].(db.Session)
+	ret := m.ctrl.Call(m, "CreateFeeRule", arg0, arg1)
+	ret0, _ := ret[0].(db.FeeRule)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetSession indicates an expected call of GetSession.
-func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+// CreateFeeRule indicates an expected call of CreateFeeRule.
+func (mr *MockStoreMockRecorder) CreateFeeRule(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFeeRule", reflect.TypeOf((*MockStore)(nil).CreateFeeRule), arg0, arg1)
 }
 
-// GetTransfer mocks base method.
-func (m *MockStore) GetTransfer(arg0 context.Context, arg1 int64) (db.Transfer, error) {
+// CreateHold mocks base method.
+func (m *MockStore) CreateHold(arg0 context.Context, arg1 db.CreateHoldParams) (db.Hold, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTransfer", arg0, arg1)
-	ret0, _ := ret[0].(db.Transfer)
+	ret := m.ctrl.Call(m, "CreateHold", arg0, arg1)
+	ret0, _ := ret[0].(db.Hold)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTransfer indicates an expected call of GetTransfer.
-func (mr *MockStoreMockRecorder) GetTransfer(arg0, arg1 interface{}) *gomock.Call {
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockStoreMockRecorder) CreateHold(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfer", reflect.TypeOf((*MockStore)(nil).GetTransfer), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockStore)(nil).CreateHold), arg0, arg1)
 }
 
-// GetTransfers mocks base method.
-func (m *MockStore) GetTransfers(arg0 context.Context, arg1 db.GetTransfersParams) ([]db.Transfer, error) {
+// CreateInterestAccrual mocks base method.
+func (m *MockStore) CreateInterestAccrual(arg0 context.Context, arg1 db.CreateInterestAccrualParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTransfers", arg0, arg1)
-	ret0, _ := ret[0].([]db.Transfer)
+	ret := m.ctrl.Call(m, "CreateInterestAccrual", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateInterestAccrual indicates an expected call of CreateInterestAccrual.
+func (mr *MockStoreMockRecorder) CreateInterestAccrual(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInterestAccrual", reflect.TypeOf((*MockStore)(nil).CreateInterestAccrual), arg0, arg1)
+}
+
+// CreateJournalTransaction mocks base method.
+func (m *MockStore) CreateJournalTransaction(arg0 context.Context, arg1 db.CreateJournalTransactionParams) (db.JournalTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJournalTransaction", arg0, arg1)
+	ret0, _ := ret[0].(db.JournalTransaction)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTransfers indicates an expected call of GetTransfers.
-func (mr *MockStoreMockRecorder) GetTransfers(arg0, arg1 interface{}) *gomock.Call {
+// CreateJournalTransaction indicates an expected call of CreateJournalTransaction.
+func (mr *MockStoreMockRecorder) CreateJournalTransaction(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfers", reflect.TypeOf((*MockStore)(nil).GetTransfers), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJournalTransaction", reflect.TypeOf((*MockStore)(nil).CreateJournalTransaction), arg0, arg1)
 }
 
-// GetUser mocks base method.
-func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+// CreateOutboxEvent mocks base method.
+func (m *MockStore) CreateOutboxEvent(arg0 context.Context, arg1 db.CreateOutboxEventParams) (db.Outbox, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "CreateOutboxEvent", arg0, arg1)
+	ret0, _ := ret[0].(db.Outbox)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUser indicates an expected call of GetUser.
-func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+// CreateOutboxEvent indicates an expected call of CreateOutboxEvent.
+func (mr *MockStoreMockRecorder) CreateOutboxEvent(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOutboxEvent", reflect.TypeOf((*MockStore)(nil).CreateOutboxEvent), arg0, arg1)
 }
 
-// ListAccount mocks base method.
-func (m *MockStore) ListAccount(arg0 context.Context, arg1 db.ListAccountParams) ([]db.Account, error) {
+// CreatePayee mocks base method.
+func (m *MockStore) CreatePayee(arg0 context.Context, arg1 db.CreatePayeeParams) (db.Payee, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListAccount", arg0, arg1)
-	ret0, _ := ret[0].([]db.Account)
+	ret := m.ctrl.Call(m, "CreatePayee", arg0, arg1)
+	ret0, _ := ret[0].(db.Payee)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListAccount indicates an expected call of ListAccount.
-func (mr *MockStoreMockRecorder) ListAccount(arg0, arg1 interface{}) *gomock.Call {
+// CreatePayee indicates an expected call of CreatePayee.
+func (mr *MockStoreMockRecorder) CreatePayee(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccount", reflect.TypeOf((*MockStore)(nil).ListAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePayee", reflect.TypeOf((*MockStore)(nil).CreatePayee), arg0, arg1)
 }
 
-// TransferTxn mocks base method.
-func (m *MockStore) TransferTxn(arg0 context.Context, arg1 db.TransferTxnParam) (db.TransferTxnResult, error) {
+// CreatePaymentRequest mocks base method.
+func (m *MockStore) CreatePaymentRequest(arg0 context.Context, arg1 db.CreatePaymentRequestParams) (db.PaymentRequest, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "TransferTxn", arg0, arg1)
-	ret0, _ := ret[0].(db.TransferTxnResult)
+	ret := m.ctrl.Call(m, "CreatePaymentRequest", arg0, arg1)
+	ret0, _ := ret[0].(db.PaymentRequest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// TransferTxn indicates an expected call of TransferTxn.
-func (mr *MockStoreMockRecorder) TransferTxn(arg0, arg1 interface{}) *gomock.Call {
+// CreatePaymentRequest indicates an expected call of CreatePaymentRequest.
+func (mr *MockStoreMockRecorder) CreatePaymentRequest(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferTxn", reflect.TypeOf((*MockStore)(nil).TransferTxn), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentRequest", reflect.TypeOf((*MockStore)(nil).CreatePaymentRequest), arg0, arg1)
 }
 
-// UpdateAccount mocks base method.
-func (m *MockStore) UpdateAccount(arg0 context.Context, arg1 db.UpdateAccountParams) (db.Account, error) {
+// CreateSession mocks base method.
+func (m *MockStore) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (db.Session, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateAccount", arg0, arg1)
-	ret0, _ := ret[0].(db.Account)
+	ret := m.ctrl.Call(m, "CreateSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateAccount indicates an expected call of UpdateAccount.
-func (mr *MockStoreMockRecorder) UpdateAccount(arg0, arg1 interface{}) *gomock.Call {
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccount", reflect.TypeOf((*MockStore)(nil).UpdateAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
+}
+
+// CreateSystemAccount mocks base method.
+func (m *MockStore) CreateSystemAccount(arg0 context.Context, arg1 db.CreateSystemAccountParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSystemAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSystemAccount indicates an expected call of CreateSystemAccount.
+func (mr *MockStoreMockRecorder) CreateSystemAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSystemAccount", reflect.TypeOf((*MockStore)(nil).CreateSystemAccount), arg0, arg1)
+}
+
+// CreateTransfer mocks base method.
+func (m *MockStore) CreateTransfer(arg0 context.Context, arg1 db.CreateTransferParams) (db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockStoreMockRecorder) CreateTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockStore)(nil).CreateTransfer), arg0, arg1)
+}
+
+// CreateUser mocks base method.
+func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockStore) CreateWebhook(arg0 context.Context, arg1 db.CreateWebhookParams) (db.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", arg0, arg1)
+	ret0, _ := ret[0].(db.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockStoreMockRecorder) CreateWebhook(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockStore)(nil).CreateWebhook), arg0, arg1)
+}
+
+// CreateWebhookDelivery mocks base method.
+func (m *MockStore) CreateWebhookDelivery(arg0 context.Context, arg1 db.CreateWebhookDeliveryParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookDelivery", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhookDelivery indicates an expected call of CreateWebhookDelivery.
+func (mr *MockStoreMockRecorder) CreateWebhookDelivery(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookDelivery", reflect.TypeOf((*MockStore)(nil).CreateWebhookDelivery), arg0, arg1)
+}
+
+// DeactivateFeeRule mocks base method.
+func (m *MockStore) DeactivateFeeRule(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateFeeRule", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeactivateFeeRule indicates an expected call of DeactivateFeeRule.
+func (mr *MockStoreMockRecorder) DeactivateFeeRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateFeeRule", reflect.TypeOf((*MockStore)(nil).DeactivateFeeRule), arg0, arg1)
+}
+
+// DeadLetterOutboxEvent mocks base method.
+func (m *MockStore) DeadLetterOutboxEvent(arg0 context.Context, arg1 db.DeadLetterOutboxEventParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeadLetterOutboxEvent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeadLetterOutboxEvent indicates an expected call of DeadLetterOutboxEvent.
+func (mr *MockStoreMockRecorder) DeadLetterOutboxEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeadLetterOutboxEvent", reflect.TypeOf((*MockStore)(nil).DeadLetterOutboxEvent), arg0, arg1)
+}
+
+// DeclineAccountInvitation mocks base method.
+func (m *MockStore) DeclineAccountInvitation(arg0 context.Context, arg1 db.DeclineAccountInvitationParams) (db.AccountMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeclineAccountInvitation", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeclineAccountInvitation indicates an expected call of DeclineAccountInvitation.
+func (mr *MockStoreMockRecorder) DeclineAccountInvitation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeclineAccountInvitation", reflect.TypeOf((*MockStore)(nil).DeclineAccountInvitation), arg0, arg1)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockStore) DeleteAccount(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockStoreMockRecorder) DeleteAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockStore)(nil).DeleteAccount), arg0, arg1)
+}
+
+// DeleteAccountMember mocks base method.
+func (m *MockStore) DeleteAccountMember(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccountMember", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccountMember indicates an expected call of DeleteAccountMember.
+func (mr *MockStoreMockRecorder) DeleteAccountMember(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccountMember", reflect.TypeOf((*MockStore)(nil).DeleteAccountMember), arg0, arg1)
+}
+
+// DeleteCategory mocks base method.
+func (m *MockStore) DeleteCategory(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategory", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCategory indicates an expected call of DeleteCategory.
+func (mr *MockStoreMockRecorder) DeleteCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockStore)(nil).DeleteCategory), arg0, arg1)
+}
+
+// DeleteCategoryRule mocks base method.
+func (m *MockStore) DeleteCategoryRule(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategoryRule", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCategoryRule indicates an expected call of DeleteCategoryRule.
+func (mr *MockStoreMockRecorder) DeleteCategoryRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategoryRule", reflect.TypeOf((*MockStore)(nil).DeleteCategoryRule), arg0, arg1)
+}
+
+// DeletePayee mocks base method.
+func (m *MockStore) DeletePayee(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePayee", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePayee indicates an expected call of DeletePayee.
+func (mr *MockStoreMockRecorder) DeletePayee(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePayee", reflect.TypeOf((*MockStore)(nil).DeletePayee), arg0, arg1)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockStore) DeleteWebhook(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockStoreMockRecorder) DeleteWebhook(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockStore)(nil).DeleteWebhook), arg0, arg1)
+}
+
+// ExpireHolds mocks base method.
+func (m *MockStore) ExpireHolds(arg0 context.Context, arg1 int32) ([]db.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireHolds", arg0, arg1)
+	ret0, _ := ret[0].([]db.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireHolds indicates an expected call of ExpireHolds.
+func (mr *MockStoreMockRecorder) ExpireHolds(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireHolds", reflect.TypeOf((*MockStore)(nil).ExpireHolds), arg0, arg1)
+}
+
+// ExpireHoldsTxn mocks base method.
+func (m *MockStore) ExpireHoldsTxn(arg0 context.Context, arg1 int32) ([]db.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireHoldsTxn", arg0, arg1)
+	ret0, _ := ret[0].([]db.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireHoldsTxn indicates an expected call of ExpireHoldsTxn.
+func (mr *MockStoreMockRecorder) ExpireHoldsTxn(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireHoldsTxn", reflect.TypeOf((*MockStore)(nil).ExpireHoldsTxn), arg0, arg1)
+}
+
+// ExpirePaymentRequests mocks base method.
+func (m *MockStore) ExpirePaymentRequests(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpirePaymentRequests", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpirePaymentRequests indicates an expected call of ExpirePaymentRequests.
+func (mr *MockStoreMockRecorder) ExpirePaymentRequests(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpirePaymentRequests", reflect.TypeOf((*MockStore)(nil).ExpirePaymentRequests), arg0)
+}
+
+// GetAccount mocks base method.
+func (m *MockStore) GetAccount(arg0 context.Context, arg1 int64) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccount indicates an expected call of GetAccount.
+func (mr *MockStoreMockRecorder) GetAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockStore)(nil).GetAccount), arg0, arg1)
+}
+
+// GetAccountByNumber mocks base method.
+func (m *MockStore) GetAccountByNumber(arg0 context.Context, arg1 string) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountByNumber", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountByNumber indicates an expected call of GetAccountByNumber.
+func (mr *MockStoreMockRecorder) GetAccountByNumber(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByNumber", reflect.TypeOf((*MockStore)(nil).GetAccountByNumber), arg0, arg1)
+}
+
+// GetAccountByOwnerCurrency mocks base method.
+func (m *MockStore) GetAccountByOwnerCurrency(arg0 context.Context, arg1 db.GetAccountByOwnerCurrencyParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountByOwnerCurrency", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountByOwnerCurrency indicates an expected call of GetAccountByOwnerCurrency.
+func (mr *MockStoreMockRecorder) GetAccountByOwnerCurrency(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByOwnerCurrency", reflect.TypeOf((*MockStore)(nil).GetAccountByOwnerCurrency), arg0, arg1)
+}
+
+// GetAccountForUpdate mocks base method.
+func (m *MockStore) GetAccountForUpdate(arg0 context.Context, arg1 int64) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
+func (mr *MockStoreMockRecorder) GetAccountForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountForUpdate), arg0, arg1)
+}
+
+// GetAccountMember mocks base method.
+func (m *MockStore) GetAccountMember(arg0 context.Context, arg1 db.GetAccountMemberParams) (db.AccountMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountMember", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountMember indicates an expected call of GetAccountMember.
+func (mr *MockStoreMockRecorder) GetAccountMember(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountMember", reflect.TypeOf((*MockStore)(nil).GetAccountMember), arg0, arg1)
+}
+
+// GetAccountProduct mocks base method.
+func (m *MockStore) GetAccountProduct(arg0 context.Context, arg1 string) (db.AccountProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountProduct", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountProduct indicates an expected call of GetAccountProduct.
+func (mr *MockStoreMockRecorder) GetAccountProduct(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountProduct", reflect.TypeOf((*MockStore)(nil).GetAccountProduct), arg0, arg1)
+}
+
+// GetAccountStatement mocks base method.
+func (m *MockStore) GetAccountStatement(arg0 context.Context, arg1 db.GetAccountStatementParams) (db.AccountStatement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountStatement", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountStatement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountStatement indicates an expected call of GetAccountStatement.
+func (mr *MockStoreMockRecorder) GetAccountStatement(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountStatement", reflect.TypeOf((*MockStore)(nil).GetAccountStatement), arg0, arg1)
+}
+
+// GetActiveAccountMember mocks base method.
+func (m *MockStore) GetActiveAccountMember(arg0 context.Context, arg1 db.GetActiveAccountMemberParams) (db.AccountMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveAccountMember", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveAccountMember indicates an expected call of GetActiveAccountMember.
+func (mr *MockStoreMockRecorder) GetActiveAccountMember(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveAccountMember", reflect.TypeOf((*MockStore)(nil).GetActiveAccountMember), arg0, arg1)
+}
+
+// GetBalanceAt mocks base method.
+func (m *MockStore) GetBalanceAt(arg0 context.Context, arg1 db.GetBalanceAtParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceAt", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceAt indicates an expected call of GetBalanceAt.
+func (mr *MockStoreMockRecorder) GetBalanceAt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceAt", reflect.TypeOf((*MockStore)(nil).GetBalanceAt), arg0, arg1)
+}
+
+// GetCategory mocks base method.
+func (m *MockStore) GetCategory(arg0 context.Context, arg1 int64) (db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategory", arg0, arg1)
+	ret0, _ := ret[0].(db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategory indicates an expected call of GetCategory.
+func (mr *MockStoreMockRecorder) GetCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategory", reflect.TypeOf((*MockStore)(nil).GetCategory), arg0, arg1)
+}
+
+// GetCategoryRule mocks base method.
+func (m *MockStore) GetCategoryRule(arg0 context.Context, arg1 int64) (db.CategoryRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryRule", arg0, arg1)
+	ret0, _ := ret[0].(db.CategoryRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryRule indicates an expected call of GetCategoryRule.
+func (mr *MockStoreMockRecorder) GetCategoryRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryRule", reflect.TypeOf((*MockStore)(nil).GetCategoryRule), arg0, arg1)
+}
+
+// GetEntries mocks base method.
+func (m *MockStore) GetEntries(arg0 context.Context, arg1 db.GetEntriesParams) ([]db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntries", arg0, arg1)
+	ret0, _ := ret[0].([]db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntries indicates an expected call of GetEntries.
+func (mr *MockStoreMockRecorder) GetEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntries", reflect.TypeOf((*MockStore)(nil).GetEntries), arg0, arg1)
+}
+
+// GetEntry mocks base method.
+func (m *MockStore) GetEntry(arg0 context.Context, arg1 int64) (db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntry", arg0, arg1)
+	ret0, _ := ret[0].(db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntry indicates an expected call of GetEntry.
+func (mr *MockStoreMockRecorder) GetEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockStore)(nil).GetEntry), arg0, arg1)
+}
+
+// GetHold mocks base method.
+func (m *MockStore) GetHold(arg0 context.Context, arg1 int64) (db.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHold", arg0, arg1)
+	ret0, _ := ret[0].(db.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHold indicates an expected call of GetHold.
+func (mr *MockStoreMockRecorder) GetHold(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHold", reflect.TypeOf((*MockStore)(nil).GetHold), arg0, arg1)
+}
+
+// GetHoldForUpdate mocks base method.
+func (m *MockStore) GetHoldForUpdate(arg0 context.Context, arg1 int64) (db.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHoldForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHoldForUpdate indicates an expected call of GetHoldForUpdate.
+func (mr *MockStoreMockRecorder) GetHoldForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHoldForUpdate", reflect.TypeOf((*MockStore)(nil).GetHoldForUpdate), arg0, arg1)
+}
+
+// GetJournalTransaction mocks base method.
+func (m *MockStore) GetJournalTransaction(arg0 context.Context, arg1 int64) (db.JournalTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJournalTransaction", arg0, arg1)
+	ret0, _ := ret[0].(db.JournalTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJournalTransaction indicates an expected call of GetJournalTransaction.
+func (mr *MockStoreMockRecorder) GetJournalTransaction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJournalTransaction", reflect.TypeOf((*MockStore)(nil).GetJournalTransaction), arg0, arg1)
+}
+
+// GetOutboxEvent mocks base method.
+func (m *MockStore) GetOutboxEvent(arg0 context.Context, arg1 int64) (db.Outbox, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutboxEvent", arg0, arg1)
+	ret0, _ := ret[0].(db.Outbox)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutboxEvent indicates an expected call of GetOutboxEvent.
+func (mr *MockStoreMockRecorder) GetOutboxEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboxEvent", reflect.TypeOf((*MockStore)(nil).GetOutboxEvent), arg0, arg1)
+}
+
+// GetPayee mocks base method.
+func (m *MockStore) GetPayee(arg0 context.Context, arg1 int64) (db.GetPayeeRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPayee", arg0, arg1)
+	ret0, _ := ret[0].(db.GetPayeeRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPayee indicates an expected call of GetPayee.
+func (mr *MockStoreMockRecorder) GetPayee(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPayee", reflect.TypeOf((*MockStore)(nil).GetPayee), arg0, arg1)
+}
+
+// GetPayeeByAccount mocks base method.
+func (m *MockStore) GetPayeeByAccount(arg0 context.Context, arg1 db.GetPayeeByAccountParams) (db.Payee, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPayeeByAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Payee)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPayeeByAccount indicates an expected call of GetPayeeByAccount.
+func (mr *MockStoreMockRecorder) GetPayeeByAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPayeeByAccount", reflect.TypeOf((*MockStore)(nil).GetPayeeByAccount), arg0, arg1)
+}
+
+// GetPaymentRequest mocks base method.
+func (m *MockStore) GetPaymentRequest(arg0 context.Context, arg1 int64) (db.GetPaymentRequestRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentRequest", arg0, arg1)
+	ret0, _ := ret[0].(db.GetPaymentRequestRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentRequest indicates an expected call of GetPaymentRequest.
+func (mr *MockStoreMockRecorder) GetPaymentRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentRequest", reflect.TypeOf((*MockStore)(nil).GetPaymentRequest), arg0, arg1)
+}
+
+// GetPaymentRequestForUpdate mocks base method.
+func (m *MockStore) GetPaymentRequestForUpdate(arg0 context.Context, arg1 int64) (db.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentRequestForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentRequestForUpdate indicates an expected call of GetPaymentRequestForUpdate.
+func (mr *MockStoreMockRecorder) GetPaymentRequestForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentRequestForUpdate", reflect.TypeOf((*MockStore)(nil).GetPaymentRequestForUpdate), arg0, arg1)
+}
+
+// GetRecipient mocks base method.
+func (m *MockStore) GetRecipient(arg0 context.Context, arg1 db.GetRecipientParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecipient", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecipient indicates an expected call of GetRecipient.
+func (mr *MockStoreMockRecorder) GetRecipient(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecipient", reflect.TypeOf((*MockStore)(nil).GetRecipient), arg0, arg1)
+}
+
+// GetSession mocks base method.
+func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+}
+
+// GetStatementBalances mocks base method.
+func (m *MockStore) GetStatementBalances(arg0 context.Context, arg1 db.GetStatementBalancesParams) (db.GetStatementBalancesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatementBalances", arg0, arg1)
+	ret0, _ := ret[0].(db.GetStatementBalancesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatementBalances indicates an expected call of GetStatementBalances.
+func (mr *MockStoreMockRecorder) GetStatementBalances(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatementBalances", reflect.TypeOf((*MockStore)(nil).GetStatementBalances), arg0, arg1)
+}
+
+// GetTransfer mocks base method.
+func (m *MockStore) GetTransfer(arg0 context.Context, arg1 int64) (db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransfer indicates an expected call of GetTransfer.
+func (mr *MockStoreMockRecorder) GetTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfer", reflect.TypeOf((*MockStore)(nil).GetTransfer), arg0, arg1)
+}
+
+// GetTransfers mocks base method.
+func (m *MockStore) GetTransfers(arg0 context.Context, arg1 db.GetTransfersParams) ([]db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransfers indicates an expected call of GetTransfers.
+func (mr *MockStoreMockRecorder) GetTransfers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfers", reflect.TypeOf((*MockStore)(nil).GetTransfers), arg0, arg1)
+}
+
+// GetUser mocks base method.
+func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+}
+
+// GetWebhook mocks base method.
+func (m *MockStore) GetWebhook(arg0 context.Context, arg1 int64) (db.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", arg0, arg1)
+	ret0, _ := ret[0].(db.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockStoreMockRecorder) GetWebhook(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockStore)(nil).GetWebhook), arg0, arg1)
+}
+
+// ListAccount mocks base method.
+func (m *MockStore) ListAccount(arg0 context.Context, arg1 db.ListAccountParams) ([]db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccount", arg0, arg1)
+	ret0, _ := ret[0].([]db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccount indicates an expected call of ListAccount.
+func (mr *MockStoreMockRecorder) ListAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccount", reflect.TypeOf((*MockStore)(nil).ListAccount), arg0, arg1)
+}
+
+// ListAccountActivity mocks base method.
+func (m *MockStore) ListAccountActivity(arg0 context.Context, arg1 db.ListAccountActivityParams) ([]db.ListAccountActivityRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountActivity", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListAccountActivityRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountActivity indicates an expected call of ListAccountActivity.
+func (mr *MockStoreMockRecorder) ListAccountActivity(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountActivity", reflect.TypeOf((*MockStore)(nil).ListAccountActivity), arg0, arg1)
+}
+
+// ListAccountEntries mocks base method.
+func (m *MockStore) ListAccountEntries(arg0 context.Context, arg1 db.ListAccountEntriesParams) ([]db.ListAccountEntriesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountEntries", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListAccountEntriesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountEntries indicates an expected call of ListAccountEntries.
+func (mr *MockStoreMockRecorder) ListAccountEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountEntries", reflect.TypeOf((*MockStore)(nil).ListAccountEntries), arg0, arg1)
+}
+
+// ListAccountInvitations mocks base method.
+func (m *MockStore) ListAccountInvitations(arg0 context.Context, arg1 string) ([]db.ListAccountInvitationsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountInvitations", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListAccountInvitationsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountInvitations indicates an expected call of ListAccountInvitations.
+func (mr *MockStoreMockRecorder) ListAccountInvitations(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountInvitations", reflect.TypeOf((*MockStore)(nil).ListAccountInvitations), arg0, arg1)
+}
+
+// ListAccountMembers mocks base method.
+func (m *MockStore) ListAccountMembers(arg0 context.Context, arg1 int64) ([]db.AccountMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountMembers", arg0, arg1)
+	ret0, _ := ret[0].([]db.AccountMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountMembers indicates an expected call of ListAccountMembers.
+func (mr *MockStoreMockRecorder) ListAccountMembers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountMembers", reflect.TypeOf((*MockStore)(nil).ListAccountMembers), arg0, arg1)
+}
+
+// ListAccountProducts mocks base method.
+func (m *MockStore) ListAccountProducts(arg0 context.Context) ([]db.AccountProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountProducts", arg0)
+	ret0, _ := ret[0].([]db.AccountProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountProducts indicates an expected call of ListAccountProducts.
+func (mr *MockStoreMockRecorder) ListAccountProducts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountProducts", reflect.TypeOf((*MockStore)(nil).ListAccountProducts), arg0)
+}
+
+// ListAccountStatements mocks base method.
+func (m *MockStore) ListAccountStatements(arg0 context.Context, arg1 db.ListAccountStatementsParams) ([]db.AccountStatement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountStatements", arg0, arg1)
+	ret0, _ := ret[0].([]db.AccountStatement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountStatements indicates an expected call of ListAccountStatements.
+func (mr *MockStoreMockRecorder) ListAccountStatements(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountStatements", reflect.TypeOf((*MockStore)(nil).ListAccountStatements), arg0, arg1)
+}
+
+// ListAccountTransfers mocks base method.
+func (m *MockStore) ListAccountTransfers(arg0 context.Context, arg1 db.ListAccountTransfersParams) ([]db.ListAccountTransfersRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListAccountTransfersRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountTransfers indicates an expected call of ListAccountTransfers.
+func (mr *MockStoreMockRecorder) ListAccountTransfers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountTransfers", reflect.TypeOf((*MockStore)(nil).ListAccountTransfers), arg0, arg1)
+}
+
+// ListAccountsByNumbers mocks base method.
+func (m *MockStore) ListAccountsByNumbers(arg0 context.Context, arg1 []string) ([]db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountsByNumbers", arg0, arg1)
+	ret0, _ := ret[0].([]db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountsByNumbers indicates an expected call of ListAccountsByNumbers.
+func (mr *MockStoreMockRecorder) ListAccountsByNumbers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountsByNumbers", reflect.TypeOf((*MockStore)(nil).ListAccountsByNumbers), arg0, arg1)
+}
+
+// ListAccountsMissingStatement mocks base method.
+func (m *MockStore) ListAccountsMissingStatement(arg0 context.Context, arg1 db.ListAccountsMissingStatementParams) ([]db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountsMissingStatement", arg0, arg1)
+	ret0, _ := ret[0].([]db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountsMissingStatement indicates an expected call of ListAccountsMissingStatement.
+func (mr *MockStoreMockRecorder) ListAccountsMissingStatement(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountsMissingStatement", reflect.TypeOf((*MockStore)(nil).ListAccountsMissingStatement), arg0, arg1)
+}
+
+// ListActiveCoOwners mocks base method.
+func (m *MockStore) ListActiveCoOwners(arg0 context.Context, arg1 int64) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveCoOwners", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActiveCoOwners indicates an expected call of ListActiveCoOwners.
+func (mr *MockStoreMockRecorder) ListActiveCoOwners(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveCoOwners", reflect.TypeOf((*MockStore)(nil).ListActiveCoOwners), arg0, arg1)
+}
+
+// ListActiveFeeRules mocks base method.
+func (m *MockStore) ListActiveFeeRules(arg0 context.Context, arg1 db.ListActiveFeeRulesParams) ([]db.FeeRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveFeeRules", arg0, arg1)
+	ret0, _ := ret[0].([]db.FeeRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActiveFeeRules indicates an expected call of ListActiveFeeRules.
+func (mr *MockStoreMockRecorder) ListActiveFeeRules(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveFeeRules", reflect.TypeOf((*MockStore)(nil).ListActiveFeeRules), arg0, arg1)
+}
+
+// ListCategories mocks base method.
+func (m *MockStore) ListCategories(arg0 context.Context, arg1 string) ([]db.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCategories", arg0, arg1)
+	ret0, _ := ret[0].([]db.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCategories indicates an expected call of ListCategories.
+func (mr *MockStoreMockRecorder) ListCategories(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCategories", reflect.TypeOf((*MockStore)(nil).ListCategories), arg0, arg1)
+}
+
+// ListCategoryRules mocks base method.
+func (m *MockStore) ListCategoryRules(arg0 context.Context, arg1 string) ([]db.ListCategoryRulesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCategoryRules", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListCategoryRulesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCategoryRules indicates an expected call of ListCategoryRules.
+func (mr *MockStoreMockRecorder) ListCategoryRules(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCategoryRules", reflect.TypeOf((*MockStore)(nil).ListCategoryRules), arg0, arg1)
+}
+
+// ListCategorySpending mocks base method.
+func (m *MockStore) ListCategorySpending(arg0 context.Context, arg1 db.ListCategorySpendingParams) ([]db.ListCategorySpendingRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCategorySpending", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListCategorySpendingRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCategorySpending indicates an expected call of ListCategorySpending.
+func (mr *MockStoreMockRecorder) ListCategorySpending(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCategorySpending", reflect.TypeOf((*MockStore)(nil).ListCategorySpending), arg0, arg1)
+}
+
+// ListIncomingPaymentRequests mocks base method.
+func (m *MockStore) ListIncomingPaymentRequests(arg0 context.Context, arg1 db.ListIncomingPaymentRequestsParams) ([]db.ListIncomingPaymentRequestsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListIncomingPaymentRequests", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListIncomingPaymentRequestsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListIncomingPaymentRequests indicates an expected call of ListIncomingPaymentRequests.
+func (mr *MockStoreMockRecorder) ListIncomingPaymentRequests(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIncomingPaymentRequests", reflect.TypeOf((*MockStore)(nil).ListIncomingPaymentRequests), arg0, arg1)
+}
+
+// ListInterestAccrualCandidates mocks base method.
+func (m *MockStore) ListInterestAccrualCandidates(arg0 context.Context, arg1 db.ListInterestAccrualCandidatesParams) ([]db.ListInterestAccrualCandidatesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInterestAccrualCandidates", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListInterestAccrualCandidatesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInterestAccrualCandidates indicates an expected call of ListInterestAccrualCandidates.
+func (mr *MockStoreMockRecorder) ListInterestAccrualCandidates(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInterestAccrualCandidates", reflect.TypeOf((*MockStore)(nil).ListInterestAccrualCandidates), arg0, arg1)
+}
+
+// ListInterestAccruals mocks base method.
+func (m *MockStore) ListInterestAccruals(arg0 context.Context, arg1 int64) ([]db.InterestAccrual, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInterestAccruals", arg0, arg1)
+	ret0, _ := ret[0].([]db.InterestAccrual)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInterestAccruals indicates an expected call of ListInterestAccruals.
+func (mr *MockStoreMockRecorder) ListInterestAccruals(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInterestAccruals", reflect.TypeOf((*MockStore)(nil).ListInterestAccruals), arg0, arg1)
+}
+
+// ListJournalEntries mocks base method.
+func (m *MockStore) ListJournalEntries(arg0 context.Context, arg1 sql.NullInt64) ([]db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJournalEntries", arg0, arg1)
+	ret0, _ := ret[0].([]db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJournalEntries indicates an expected call of ListJournalEntries.
+func (mr *MockStoreMockRecorder) ListJournalEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJournalEntries", reflect.TypeOf((*MockStore)(nil).ListJournalEntries), arg0, arg1)
+}
+
+// ListLimitUsage mocks base method.
+func (m *MockStore) ListLimitUsage(arg0 context.Context, arg1 string) ([]db.ListLimitUsageRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLimitUsage", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListLimitUsageRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLimitUsage indicates an expected call of ListLimitUsage.
+func (mr *MockStoreMockRecorder) ListLimitUsage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLimitUsage", reflect.TypeOf((*MockStore)(nil).ListLimitUsage), arg0, arg1)
+}
+
+// ListOutboxDeadLetters mocks base method.
+func (m *MockStore) ListOutboxDeadLetters(arg0 context.Context, arg1 db.ListOutboxDeadLettersParams) ([]db.OutboxDeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOutboxDeadLetters", arg0, arg1)
+	ret0, _ := ret[0].([]db.OutboxDeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOutboxDeadLetters indicates an expected call of ListOutboxDeadLetters.
+func (mr *MockStoreMockRecorder) ListOutboxDeadLetters(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOutboxDeadLetters", reflect.TypeOf((*MockStore)(nil).ListOutboxDeadLetters), arg0, arg1)
+}
+
+// ListOutgoingPaymentRequests mocks base method.
+func (m *MockStore) ListOutgoingPaymentRequests(arg0 context.Context, arg1 db.ListOutgoingPaymentRequestsParams) ([]db.ListOutgoingPaymentRequestsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOutgoingPaymentRequests", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListOutgoingPaymentRequestsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOutgoingPaymentRequests indicates an expected call of ListOutgoingPaymentRequests.
+func (mr *MockStoreMockRecorder) ListOutgoingPaymentRequests(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOutgoingPaymentRequests", reflect.TypeOf((*MockStore)(nil).ListOutgoingPaymentRequests), arg0, arg1)
+}
+
+// ListPayees mocks base method.
+func (m *MockStore) ListPayees(arg0 context.Context, arg1 string) ([]db.ListPayeesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPayees", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListPayeesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPayees indicates an expected call of ListPayees.
+func (mr *MockStoreMockRecorder) ListPayees(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPayees", reflect.TypeOf((*MockStore)(nil).ListPayees), arg0, arg1)
+}
+
+// ListStatementLines mocks base method.
+func (m *MockStore) ListStatementLines(arg0 context.Context, arg1 db.ListStatementLinesParams) ([]db.ListStatementLinesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStatementLines", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListStatementLinesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStatementLines indicates an expected call of ListStatementLines.
+func (mr *MockStoreMockRecorder) ListStatementLines(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStatementLines", reflect.TypeOf((*MockStore)(nil).ListStatementLines), arg0, arg1)
+}
+
+// ListSubscribedWebhooks mocks base method.
+func (m *MockStore) ListSubscribedWebhooks(arg0 context.Context, arg1 db.ListSubscribedWebhooksParams) ([]db.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscribedWebhooks", arg0, arg1)
+	ret0, _ := ret[0].([]db.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubscribedWebhooks indicates an expected call of ListSubscribedWebhooks.
+func (mr *MockStoreMockRecorder) ListSubscribedWebhooks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscribedWebhooks", reflect.TypeOf((*MockStore)(nil).ListSubscribedWebhooks), arg0, arg1)
+}
+
+// ListTopCounterparties mocks base method.
+func (m *MockStore) ListTopCounterparties(arg0 context.Context, arg1 db.ListTopCounterpartiesParams) ([]db.ListTopCounterpartiesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTopCounterparties", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListTopCounterpartiesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTopCounterparties indicates an expected call of ListTopCounterparties.
+func (mr *MockStoreMockRecorder) ListTopCounterparties(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTopCounterparties", reflect.TypeOf((*MockStore)(nil).ListTopCounterparties), arg0, arg1)
+}
+
+// ListUnpostedInterestMonths mocks base method.
+func (m *MockStore) ListUnpostedInterestMonths(arg0 context.Context, arg1 db.ListUnpostedInterestMonthsParams) ([]db.ListUnpostedInterestMonthsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUnpostedInterestMonths", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListUnpostedInterestMonthsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUnpostedInterestMonths indicates an expected call of ListUnpostedInterestMonths.
+func (mr *MockStoreMockRecorder) ListUnpostedInterestMonths(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUnpostedInterestMonths", reflect.TypeOf((*MockStore)(nil).ListUnpostedInterestMonths), arg0, arg1)
+}
+
+// ListWebhookDeliveries mocks base method.
+func (m *MockStore) ListWebhookDeliveries(arg0 context.Context, arg1 db.ListWebhookDeliveriesParams) ([]db.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]db.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockStoreMockRecorder) ListWebhookDeliveries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).ListWebhookDeliveries), arg0, arg1)
+}
+
+// ListWebhooks mocks base method.
+func (m *MockStore) ListWebhooks(arg0 context.Context, arg1 string) ([]db.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", arg0, arg1)
+	ret0, _ := ret[0].([]db.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockStoreMockRecorder) ListWebhooks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockStore)(nil).ListWebhooks), arg0, arg1)
+}
+
+// LockAccounts mocks base method.
+func (m *MockStore) LockAccounts(arg0 context.Context, arg1 []int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockAccounts indicates an expected call of LockAccounts.
+func (mr *MockStoreMockRecorder) LockAccounts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockAccounts", reflect.TypeOf((*MockStore)(nil).LockAccounts), arg0, arg1)
+}
+
+// LockActiveAccountMember mocks base method.
+func (m *MockStore) LockActiveAccountMember(arg0 context.Context, arg1 db.LockActiveAccountMemberParams) (db.AccountMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockActiveAccountMember", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockActiveAccountMember indicates an expected call of LockActiveAccountMember.
+func (mr *MockStoreMockRecorder) LockActiveAccountMember(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockActiveAccountMember", reflect.TypeOf((*MockStore)(nil).LockActiveAccountMember), arg0, arg1)
+}
+
+// LockTransferLimits mocks base method.
+func (m *MockStore) LockTransferLimits(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockTransferLimits", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockTransferLimits indicates an expected call of LockTransferLimits.
+func (mr *MockStoreMockRecorder) LockTransferLimits(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockTransferLimits", reflect.TypeOf((*MockStore)(nil).LockTransferLimits), arg0, arg1)
+}
+
+// LockUnpostedInterest mocks base method.
+func (m *MockStore) LockUnpostedInterest(arg0 context.Context, arg1 db.LockUnpostedInterestParams) ([]db.InterestAccrual, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockUnpostedInterest", arg0, arg1)
+	ret0, _ := ret[0].([]db.InterestAccrual)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockUnpostedInterest indicates an expected call of LockUnpostedInterest.
+func (mr *MockStoreMockRecorder) LockUnpostedInterest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockUnpostedInterest", reflect.TypeOf((*MockStore)(nil).LockUnpostedInterest), arg0, arg1)
+}
+
+// MarkInterestPosted mocks base method.
+func (m *MockStore) MarkInterestPosted(arg0 context.Context, arg1 db.MarkInterestPostedParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkInterestPosted", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkInterestPosted indicates an expected call of MarkInterestPosted.
+func (mr *MockStoreMockRecorder) MarkInterestPosted(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkInterestPosted", reflect.TypeOf((*MockStore)(nil).MarkInterestPosted), arg0, arg1)
+}
+
+// MarkOutboxEventProcessed mocks base method.
+func (m *MockStore) MarkOutboxEventProcessed(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventProcessed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventProcessed indicates an expected call of MarkOutboxEventProcessed.
+func (mr *MockStoreMockRecorder) MarkOutboxEventProcessed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventProcessed", reflect.TypeOf((*MockStore)(nil).MarkOutboxEventProcessed), arg0, arg1)
+}
+
+// NotifyAccountChange mocks base method.
+func (m *MockStore) NotifyAccountChange(arg0 context.Context, arg1 db.NotifyAccountChangeParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyAccountChange", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyAccountChange indicates an expected call of NotifyAccountChange.
+func (mr *MockStoreMockRecorder) NotifyAccountChange(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyAccountChange", reflect.TypeOf((*MockStore)(nil).NotifyAccountChange), arg0, arg1)
+}
+
+// PlaceHoldTxn mocks base method.
+func (m *MockStore) PlaceHoldTxn(arg0 context.Context, arg1 db.PlaceHoldTxnParams) (db.PlaceHoldTxnResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlaceHoldTxn", arg0, arg1)
+	ret0, _ := ret[0].(db.PlaceHoldTxnResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PlaceHoldTxn indicates an expected call of PlaceHoldTxn.
+func (mr *MockStoreMockRecorder) PlaceHoldTxn(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlaceHoldTxn", reflect.TypeOf((*MockStore)(nil).PlaceHoldTxn), arg0, arg1)
+}
+
+// PostInterestTxn mocks base method.
+func (m *MockStore) PostInterestTxn(arg0 context.Context, arg1 db.PostInterestTxnParams) (db.PostInterestTxnResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostInterestTxn", arg0, arg1)
+	ret0, _ := ret[0].(db.PostInterestTxnResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostInterestTxn indicates an expected call of PostInterestTxn.
+func (mr *MockStoreMockRecorder) PostInterestTxn(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostInterestTxn", reflect.TypeOf((*MockStore)(nil).PostInterestTxn), arg0, arg1)
+}
+
+// PostJournalTxn mocks base method.
+func (m *MockStore) PostJournalTxn(arg0 context.Context, arg1 db.PostJournalTxnParams) (db.PostJournalTxnResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostJournalTxn", arg0, arg1)
+	ret0, _ := ret[0].(db.PostJournalTxnResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostJournalTxn indicates an expected call of PostJournalTxn.
+func (mr *MockStoreMockRecorder) PostJournalTxn(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostJournalTxn", reflect.TypeOf((*MockStore)(nil).PostJournalTxn), arg0, arg1)
+}
+
+// RedactOutboxEvent mocks base method.
+func (m *MockStore) RedactOutboxEvent(arg0 context.Context, arg1 db.RedactOutboxEventParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedactOutboxEvent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RedactOutboxEvent indicates an expected call of RedactOutboxEvent.
+func (mr *MockStoreMockRecorder) RedactOutboxEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedactOutboxEvent", reflect.TypeOf((*MockStore)(nil).RedactOutboxEvent), arg0, arg1)
+}
+
+// RequestEmailVerificationTxn mocks base method.
+func (m *MockStore) RequestEmailVerificationTxn(arg0 context.Context, arg1 string) (db.EmailVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestEmailVerificationTxn", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestEmailVerificationTxn indicates an expected call of RequestEmailVerificationTxn.
+func (mr *MockStoreMockRecorder) RequestEmailVerificationTxn(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestEmailVerificationTxn", reflect.TypeOf((*MockStore)(nil).RequestEmailVerificationTxn), arg0, arg1)
+}
+
+// RescheduleOutboxEvent mocks base method.
+func (m *MockStore) RescheduleOutboxEvent(arg0 context.Context, arg1 db.RescheduleOutboxEventParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RescheduleOutboxEvent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RescheduleOutboxEvent indicates an expected call of RescheduleOutboxEvent.
+func (mr *MockStoreMockRecorder) RescheduleOutboxEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RescheduleOutboxEvent", reflect.TypeOf((*MockStore)(nil).RescheduleOutboxEvent), arg0, arg1)
+}
+
+// RespondPaymentRequest mocks base method.
+func (m *MockStore) RespondPaymentRequest(arg0 context.Context, arg1 db.RespondPaymentRequestParams) (db.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RespondPaymentRequest", arg0, arg1)
+	ret0, _ := ret[0].(db.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RespondPaymentRequest indicates an expected call of RespondPaymentRequest.
+func (mr *MockStoreMockRecorder) RespondPaymentRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RespondPaymentRequest", reflect.TypeOf((*MockStore)(nil).RespondPaymentRequest), arg0, arg1)
+}
+
+// SettleHold mocks base method.
+func (m *MockStore) SettleHold(arg0 context.Context, arg1 db.SettleHoldParams) (db.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SettleHold", arg0, arg1)
+	ret0, _ := ret[0].(db.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SettleHold indicates an expected call of SettleHold.
+func (mr *MockStoreMockRecorder) SettleHold(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SettleHold", reflect.TypeOf((*MockStore)(nil).SettleHold), arg0, arg1)
+}
+
+// StatementTxn mocks base method.
+func (m *MockStore) StatementTxn(arg0 context.Context, arg1 db.StatementTxnParams, arg2 func(db.GetStatementBalancesRow) error, arg3 func(db.ListStatementLinesRow) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatementTxn", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StatementTxn indicates an expected call of StatementTxn.
+func (mr *MockStoreMockRecorder) StatementTxn(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatementTxn", reflect.TypeOf((*MockStore)(nil).StatementTxn), arg0, arg1, arg2, arg3)
+}
+
+// TransferTxn mocks base method.
+func (m *MockStore) TransferTxn(arg0 context.Context, arg1 db.TransferTxnParam) (db.TransferTxnResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferTxn", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferTxnResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransferTxn indicates an expected call of TransferTxn.
+func (mr *MockStoreMockRecorder) TransferTxn(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferTxn", reflect.TypeOf((*MockStore)(nil).TransferTxn), arg0, arg1)
+}
+
+// UpdateAccount mocks base method.
+func (m *MockStore) UpdateAccount(arg0 context.Context, arg1 db.UpdateAccountParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAccount indicates an expected call of UpdateAccount.
+func (mr *MockStoreMockRecorder) UpdateAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccount", reflect.TypeOf((*MockStore)(nil).UpdateAccount), arg0, arg1)
+}
+
+// UpdateEntryCategory mocks base method.
+func (m *MockStore) UpdateEntryCategory(arg0 context.Context, arg1 db.UpdateEntryCategoryParams) (db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEntryCategory", arg0, arg1)
+	ret0, _ := ret[0].(db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateEntryCategory indicates an expected call of UpdateEntryCategory.
+func (mr *MockStoreMockRecorder) UpdateEntryCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEntryCategory", reflect.TypeOf((*MockStore)(nil).UpdateEntryCategory), arg0, arg1)
+}
+
+// UpdateEntryTags mocks base method.
+func (m *MockStore) UpdateEntryTags(arg0 context.Context, arg1 db.UpdateEntryTagsParams) (db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEntryTags", arg0, arg1)
+	ret0, _ := ret[0].(db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateEntryTags indicates an expected call of UpdateEntryTags.
+func (mr *MockStoreMockRecorder) UpdateEntryTags(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEntryTags", reflect.TypeOf((*MockStore)(nil).UpdateEntryTags), arg0, arg1)
+}
+
+// UpdatePayeeNickname mocks base method.
+func (m *MockStore) UpdatePayeeNickname(arg0 context.Context, arg1 db.UpdatePayeeNicknameParams) (db.Payee, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePayeeNickname", arg0, arg1)
+	ret0, _ := ret[0].(db.Payee)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePayeeNickname indicates an expected call of UpdatePayeeNickname.
+func (mr *MockStoreMockRecorder) UpdatePayeeNickname(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePayeeNickname", reflect.TypeOf((*MockStore)(nil).UpdatePayeeNickname), arg0, arg1)
+}
+
+// UpdateUserAlias mocks base method.
+func (m *MockStore) UpdateUserAlias(arg0 context.Context, arg1 db.UpdateUserAliasParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserAlias", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserAlias indicates an expected call of UpdateUserAlias.
+func (mr *MockStoreMockRecorder) UpdateUserAlias(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserAlias", reflect.TypeOf((*MockStore)(nil).UpdateUserAlias), arg0, arg1)
+}
+
+// UpdateUserTier mocks base method.
+func (m *MockStore) UpdateUserTier(arg0 context.Context, arg1 db.UpdateUserTierParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTier", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserTier indicates an expected call of UpdateUserTier.
+func (mr *MockStoreMockRecorder) UpdateUserTier(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTier", reflect.TypeOf((*MockStore)(nil).UpdateUserTier), arg0, arg1)
+}
+
+// UpdateWebhookDelivery mocks base method.
+func (m *MockStore) UpdateWebhookDelivery(arg0 context.Context, arg1 db.UpdateWebhookDeliveryParams) (db.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookDelivery", arg0, arg1)
+	ret0, _ := ret[0].(db.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWebhookDelivery indicates an expected call of UpdateWebhookDelivery.
+func (mr *MockStoreMockRecorder) UpdateWebhookDelivery(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookDelivery", reflect.TypeOf((*MockStore)(nil).UpdateWebhookDelivery), arg0, arg1)
+}
+
+// UpsertTransferLimit mocks base method.
+func (m *MockStore) UpsertTransferLimit(arg0 context.Context, arg1 db.UpsertTransferLimitParams) (db.TransferLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertTransferLimit", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertTransferLimit indicates an expected call of UpsertTransferLimit.
+func (mr *MockStoreMockRecorder) UpsertTransferLimit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTransferLimit", reflect.TypeOf((*MockStore)(nil).UpsertTransferLimit), arg0, arg1)
+}
+
+// UseEmailVerification mocks base method.
+func (m *MockStore) UseEmailVerification(arg0 context.Context, arg1 db.UseEmailVerificationParams) (db.EmailVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseEmailVerification", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseEmailVerification indicates an expected call of UseEmailVerification.
+func (mr *MockStoreMockRecorder) UseEmailVerification(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseEmailVerification", reflect.TypeOf((*MockStore)(nil).UseEmailVerification), arg0, arg1)
+}
+
+// VerifyEmailTxn mocks base method.
+func (m *MockStore) VerifyEmailTxn(arg0 context.Context, arg1 db.VerifyEmailTxnParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmailTxn", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmailTxn indicates an expected call of VerifyEmailTxn.
+func (mr *MockStoreMockRecorder) VerifyEmailTxn(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmailTxn", reflect.TypeOf((*MockStore)(nil).VerifyEmailTxn), arg0, arg1)
+}
+
+// VerifyUserEmail mocks base method.
+func (m *MockStore) VerifyUserEmail(arg0 context.Context, arg1 db.VerifyUserEmailParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyUserEmail", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyUserEmail indicates an expected call of VerifyUserEmail.
+func (mr *MockStoreMockRecorder) VerifyUserEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyUserEmail", reflect.TypeOf((*MockStore)(nil).VerifyUserEmail), arg0, arg1)
+}
+
+// VoidHoldTxn mocks base method.
+func (m *MockStore) VoidHoldTxn(arg0 context.Context, arg1 int64) (db.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidHoldTxn", arg0, arg1)
+	ret0, _ := ret[0].(db.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VoidHoldTxn indicates an expected call of VoidHoldTxn.
+func (mr *MockStoreMockRecorder) VoidHoldTxn(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidHoldTxn", reflect.TypeOf((*MockStore)(nil).VoidHoldTxn), arg0, arg1)
 }
@@ -2,9 +2,11 @@
 INSERT INTO accounts (
     owner,
     balance,
-    currency
+    currency,
+    number,
+    product
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5
 ) 
 RETURNING *;
 
@@ -12,17 +14,23 @@ RETURNING *;
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1;
 
+-- name: GetAccountByNumber :one
+SELECT * FROM accounts
+WHERE number = $1 LIMIT 1;
+
 -- name: GetAccountForUpdate :one
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE;
 
 -- name: ListAccount :many
-SELECT * FROM accounts
-WHERE owner = $1
-ORDER BY id 
-LIMIT $2
-OFFSET $3;
+-- The accounts the user is an active member of, in any role.
+SELECT a.* FROM accounts a
+JOIN account_members m ON m.account_id = a.id
+WHERE m.username = sqlc.arg(username) AND m.status = 'active'
+ORDER BY a.id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
 
 -- name: UpdateAccount :one
 UPDATE accounts
@@ -38,4 +46,40 @@ RETURNING *;
 
 -- name: DeleteAccount :exec
 DELETE FROM accounts
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: NotifyAccountChange :exec
+SELECT pg_notify(sqlc.arg(channel)::text, sqlc.arg(payload)::text);
+-- name: CreateSystemAccount :exec
+INSERT INTO accounts (
+    owner,
+    balance,
+    currency,
+    number
+) VALUES (
+    $1, 0, $2, $3
+)
+ON CONFLICT ON CONSTRAINT owner_currency_product_key DO NOTHING;
+
+-- name: GetAccountByOwnerCurrency :one
+-- The owner's checking account in the currency, or their oldest other one.
+SELECT * FROM accounts
+WHERE owner = $1 AND currency = $2
+ORDER BY product = 'checking' DESC, id
+LIMIT 1;
+
+-- name: AddAccountHeldBalance :one
+UPDATE accounts
+SET held_balance = held_balance + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListAccountsByNumbers :many
+SELECT * FROM accounts
+WHERE number = ANY(sqlc.arg(numbers)::varchar[]);
+
+-- name: LockAccounts :many
+SELECT id FROM accounts
+WHERE id = ANY(sqlc.arg(ids)::bigint[])
+ORDER BY id
+FOR NO KEY UPDATE;
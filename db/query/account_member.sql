@@ -0,0 +1,70 @@
+-- name: CreateAccountOwner :one
+-- The owner is active from the start, as the account was opened by them.
+INSERT INTO account_members (
+    account_id,
+    username,
+    role,
+    status,
+    invited_by,
+    accepted_at
+) VALUES (
+    sqlc.arg(account_id), sqlc.arg(username), 'owner', 'active', sqlc.arg(username), now()
+) RETURNING *;
+
+-- name: CreateAccountMember :one
+INSERT INTO account_members (
+    account_id,
+    username,
+    role,
+    spend_limit,
+    invited_by
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetAccountMember :one
+SELECT * FROM account_members
+WHERE account_id = $1 AND username = $2 LIMIT 1;
+
+-- name: GetActiveAccountMember :one
+SELECT * FROM account_members
+WHERE account_id = $1 AND username = $2 AND status = 'active' LIMIT 1;
+
+-- name: ListAccountMembers :many
+SELECT * FROM account_members
+WHERE account_id = $1
+ORDER BY id;
+
+-- name: ListAccountInvitations :many
+-- The pending invitations of the user, with the account they were invited to.
+SELECT m.*, a.number AS account_number, a.currency
+FROM account_members m
+JOIN accounts a ON a.id = m.account_id
+WHERE m.username = $1 AND m.status = 'invited'
+ORDER BY m.id;
+
+-- name: AcceptAccountInvitation :one
+UPDATE account_members
+SET status = 'active', accepted_at = now()
+WHERE id = $1 AND username = $2 AND status = 'invited'
+RETURNING *;
+
+-- name: DeclineAccountInvitation :one
+DELETE FROM account_members
+WHERE id = $1 AND username = $2 AND status = 'invited'
+RETURNING *;
+
+-- name: DeleteAccountMember :exec
+DELETE FROM account_members
+WHERE id = $1;
+
+-- name: LockActiveAccountMember :one
+-- Held until the sending transaction commits, so the member can't be removed meanwhile.
+SELECT * FROM account_members
+WHERE account_id = $1 AND username = $2 AND status = 'active' LIMIT 1
+FOR SHARE;
+
+-- name: ListActiveCoOwners :many
+SELECT username FROM account_members
+WHERE account_id = $1 AND status = 'active' AND role = 'co_owner'
+ORDER BY id;
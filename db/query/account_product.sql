@@ -0,0 +1,21 @@
+-- name: CreateAccountProduct :one
+INSERT INTO account_products (
+    code,
+    name,
+    interest_rate_bps,
+    currencies,
+    overdraft_limit,
+    max_transfers_per_month,
+    own_accounts_only
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: GetAccountProduct :one
+SELECT * FROM account_products
+WHERE code = $1 LIMIT 1;
+
+-- name: ListAccountProducts :many
+SELECT * FROM account_products
+ORDER BY code;
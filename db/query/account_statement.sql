@@ -0,0 +1,34 @@
+-- name: CreateAccountStatement :exec
+INSERT INTO account_statements (
+    account_id,
+    period,
+    storage_key,
+    size,
+    opening_balance,
+    closing_balance
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) ON CONFLICT (account_id, period) DO NOTHING;
+
+-- name: GetAccountStatement :one
+SELECT * FROM account_statements
+WHERE account_id = $1 AND period = $2
+LIMIT 1;
+
+-- name: ListAccountStatements :many
+SELECT * FROM account_statements
+WHERE account_id = $1
+ORDER BY period DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ListAccountsMissingStatement :many
+SELECT a.* FROM accounts a
+WHERE a.created_at < sqlc.arg(period_end)
+    AND a.id > sqlc.arg(after_id)
+    AND NOT EXISTS (
+        SELECT 1 FROM account_statements s
+        WHERE s.account_id = a.id AND s.period = sqlc.arg(period)
+    )
+ORDER BY a.id
+LIMIT sqlc.arg(max_accounts);
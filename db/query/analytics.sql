@@ -0,0 +1,45 @@
+-- name: ListAccountActivity :many
+-- Inflow, outflow, net and entry count of the account per UTC day, week or month over
+-- [from_time, to_time). Buckets without entries are zero.
+WITH buckets AS (
+    SELECT
+        (b AT TIME ZONE 'UTC')::timestamptz AS bucket_start,
+        ((b + ('1 ' || sqlc.arg(granularity)::text)::interval) AT TIME ZONE 'UTC')::timestamptz AS bucket_end
+    FROM generate_series(
+        date_trunc(sqlc.arg(granularity)::text, sqlc.arg(from_time)::timestamptz AT TIME ZONE 'UTC'),
+        sqlc.arg(to_time)::timestamptz AT TIME ZONE 'UTC' - interval '1 microsecond',
+        ('1 ' || sqlc.arg(granularity)::text)::interval
+    ) AS b
+)
+SELECT
+    bu.bucket_start,
+    COALESCE(SUM(e.amount) FILTER (WHERE e.amount > 0), 0)::bigint AS inflow,
+    COALESCE(-SUM(e.amount) FILTER (WHERE e.amount < 0), 0)::bigint AS outflow,
+    COALESCE(SUM(e.amount), 0)::bigint AS net,
+    COUNT(e.id) AS transactions
+FROM buckets bu
+LEFT JOIN entries e
+    ON e.account_id = sqlc.arg(account_id)
+   AND e.created_at >= GREATEST(bu.bucket_start, sqlc.arg(from_time)::timestamptz)
+   AND e.created_at < LEAST(bu.bucket_end, sqlc.arg(to_time)::timestamptz)
+GROUP BY bu.bucket_start
+ORDER BY bu.bucket_start;
+
+-- name: ListTopCounterparties :many
+-- The accounts the account exchanged the most money with over [from_time, to_time).
+SELECT
+    a.number,
+    COUNT(*) AS transfers,
+    COALESCE(SUM(t.amount) FILTER (WHERE t.from_account_id = sqlc.arg(account_id)), 0)::bigint AS sent,
+    COALESCE(SUM(t.amount) FILTER (WHERE t.to_account_id = sqlc.arg(account_id)), 0)::bigint AS received
+FROM transfers t
+JOIN accounts a ON a.id = CASE
+    WHEN t.from_account_id = sqlc.arg(account_id) THEN t.to_account_id
+    ELSE t.from_account_id
+END
+WHERE (t.from_account_id = sqlc.arg(account_id) OR t.to_account_id = sqlc.arg(account_id))
+  AND t.created_at >= sqlc.arg(from_time)
+  AND t.created_at < sqlc.arg(to_time)
+GROUP BY a.id, a.number
+ORDER BY SUM(t.amount) DESC, transfers DESC, a.number
+LIMIT sqlc.arg('limit');
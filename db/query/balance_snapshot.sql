@@ -0,0 +1,44 @@
+-- name: CreateBalanceSnapshots :many
+-- Snapshots the balance at taken_at of up to max_accounts accounts that don't
+-- have one yet, walking entries back from the current balance.
+INSERT INTO balance_snapshots (account_id, balance, taken_at)
+SELECT
+    a.id,
+    a.balance - COALESCE((
+        SELECT sum(e.amount) FROM entries e
+        WHERE e.account_id = a.id AND e.created_at > sqlc.arg(taken_at)::timestamptz
+    ), 0),
+    sqlc.arg(taken_at)::timestamptz
+FROM accounts a
+WHERE a.created_at <= sqlc.arg(taken_at)::timestamptz
+    AND NOT EXISTS (
+        SELECT 1 FROM balance_snapshots s
+        WHERE s.account_id = a.id AND s.taken_at = sqlc.arg(taken_at)::timestamptz
+    )
+ORDER BY a.id
+LIMIT sqlc.arg(max_accounts)
+ON CONFLICT (account_id, taken_at) DO NOTHING
+RETURNING *;
+
+-- name: GetBalanceAt :one
+-- Starts from the snapshot closest to at, or from the current balance, and
+-- applies the entries between that point and at.
+WITH anchor AS (
+    SELECT anchors.balance, anchors.taken_at FROM (
+        SELECT s.balance, s.taken_at FROM balance_snapshots s
+        WHERE s.account_id = sqlc.arg(account_id)
+        UNION ALL
+        SELECT a.balance, 'infinity'::timestamptz FROM accounts a
+        WHERE a.id = sqlc.arg(account_id)
+    ) anchors
+    ORDER BY abs(extract(epoch FROM least(anchors.taken_at, now()) - sqlc.arg(at)::timestamptz))
+    LIMIT 1
+)
+SELECT (anchor.balance + COALESCE((
+    SELECT sum(CASE WHEN e.created_at > sqlc.arg(at)::timestamptz THEN -e.amount ELSE e.amount END)
+    FROM entries e
+    WHERE e.account_id = sqlc.arg(account_id)
+        AND e.created_at > least(anchor.taken_at, sqlc.arg(at)::timestamptz)
+        AND e.created_at <= greatest(anchor.taken_at, sqlc.arg(at)::timestamptz)
+), 0))::bigint AS balance
+FROM anchor;
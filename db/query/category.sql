@@ -0,0 +1,111 @@
+-- name: CreateCategory :one
+INSERT INTO categories (
+    owner,
+    name
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: GetCategory :one
+SELECT * FROM categories
+WHERE id = $1 LIMIT 1;
+
+-- name: ListCategories :many
+SELECT * FROM categories
+WHERE owner = $1
+ORDER BY name;
+
+-- name: DeleteCategory :exec
+DELETE FROM categories
+WHERE id = $1;
+
+-- name: CreateCategoryRule :one
+INSERT INTO category_rules (
+    owner,
+    category_id,
+    counterparty_account_id,
+    memo_keyword,
+    min_amount,
+    max_amount,
+    priority
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetCategoryRule :one
+SELECT * FROM category_rules
+WHERE id = $1 LIMIT 1;
+
+-- name: ListCategoryRules :many
+SELECT r.*, c.name AS category_name, a.number AS counterparty_account_number
+FROM category_rules r
+JOIN categories c ON c.id = r.category_id
+LEFT JOIN accounts a ON a.id = r.counterparty_account_id
+WHERE r.owner = $1
+ORDER BY r.priority, r.id;
+
+-- name: DeleteCategoryRule :exec
+DELETE FROM category_rules
+WHERE id = $1;
+
+-- name: CategorizeTransferEntries :exec
+-- Sets the category of both sides of a transfer from the first matching rule of each
+-- account's owner. Every condition a rule sets must match.
+UPDATE entries e
+SET category_id = (
+    SELECT r.category_id
+    FROM category_rules r
+    JOIN accounts a ON a.owner = r.owner
+    WHERE a.id = e.account_id
+      AND (r.counterparty_account_id IS NULL OR r.counterparty_account_id =
+          CASE WHEN e.account_id = t.from_account_id THEN t.to_account_id ELSE t.from_account_id END)
+      AND (r.memo_keyword IS NULL OR strpos(lower(t.description), lower(r.memo_keyword)) > 0)
+      AND (r.min_amount IS NULL OR t.amount >= r.min_amount)
+      AND (r.max_amount IS NULL OR t.amount <= r.max_amount)
+    ORDER BY r.priority, r.id
+    LIMIT 1
+)
+FROM transfers t
+WHERE t.id = sqlc.arg(transfer_id)
+  AND e.transfer_id = t.id
+  AND e.account_id IN (t.from_account_id, t.to_account_id);
+
+-- name: UpdateEntryCategory :one
+UPDATE entries
+SET category_id = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateEntryTags :one
+UPDATE entries
+SET tags = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: ListAccountEntries :many
+SELECT e.*, COALESCE(c.name, '')::varchar AS category_name
+FROM entries e
+LEFT JOIN categories c ON c.id = e.category_id
+WHERE e.account_id = sqlc.arg(account_id)
+  AND (sqlc.narg(category_id)::bigint IS NULL OR e.category_id = sqlc.narg(category_id))
+  AND (sqlc.narg(tag)::varchar IS NULL OR sqlc.narg(tag) = ANY(e.tags))
+ORDER BY e.id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListCategorySpending :many
+-- Debits of the account over [from_time, to_time) by category, fees included.
+-- Uncategorized entries are grouped under a null category_id.
+SELECT
+    e.category_id,
+    COALESCE(c.name, '')::varchar AS category_name,
+    (-SUM(e.amount))::bigint AS spent,
+    COUNT(*) AS entries
+FROM entries e
+LEFT JOIN categories c ON c.id = e.category_id
+WHERE e.account_id = sqlc.arg(account_id)
+  AND e.amount < 0
+  AND e.created_at >= sqlc.arg(from_time)
+  AND e.created_at < sqlc.arg(to_time)
+GROUP BY e.category_id, c.name
+ORDER BY spent DESC, category_name;
@@ -0,0 +1,20 @@
+-- name: CreateEmailVerification :one
+INSERT INTO email_verifications (
+    username,
+    email,
+    code_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: UseEmailVerification :one
+-- Marks an unused, unexpired code as used, so each code verifies at most once.
+UPDATE email_verifications
+SET used_at = now()
+WHERE username = sqlc.arg(username)
+  AND code_hash = sqlc.arg(code_hash)
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING *;
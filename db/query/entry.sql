@@ -1,10 +1,14 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
     account_id,
-    amount
+    amount,
+    transfer_id,
+    journal_transaction_id
 ) VALUES (
     $1,
-    $2
+    $2,
+    $3,
+    $4
 )
 RETURNING *;
 
@@ -18,3 +22,32 @@ WHERE account_id = $1
 ORDER BY id
 LIMIT $2
 OFFSET $3;
+
+-- name: GetStatementBalances :one
+SELECT
+    (a.balance - COALESCE(SUM(e.amount) FILTER (WHERE e.created_at >= sqlc.arg(from_time)), 0))::bigint AS opening_balance,
+    (a.balance - COALESCE(SUM(e.amount) FILTER (WHERE e.created_at >= sqlc.arg(to_time)), 0))::bigint AS closing_balance
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+WHERE a.id = sqlc.arg(account_id)
+GROUP BY a.id;
+
+-- name: ListStatementLines :many
+SELECT
+    e.id,
+    e.amount,
+    e.created_at,
+    e.transfer_id,
+    COALESCE(t.description, '')::varchar AS description,
+    COALESCE(t.reference, '')::varchar AS reference,
+    c.number AS counterparty_number,
+    c.owner AS counterparty_owner
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN accounts c ON c.id = CASE WHEN t.from_account_id = e.account_id THEN t.to_account_id ELSE t.from_account_id END
+WHERE e.account_id = sqlc.arg(account_id)
+    AND e.created_at >= sqlc.arg(from_time)
+    AND e.created_at < sqlc.arg(to_time)
+    AND e.id > sqlc.arg(after_id)
+ORDER BY e.id
+LIMIT sqlc.arg(max_lines);
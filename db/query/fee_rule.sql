@@ -0,0 +1,29 @@
+-- name: CreateFeeRule :one
+INSERT INTO fee_rules (
+    name,
+    currency,
+    kind,
+    priority,
+    cross_user_only,
+    min_amount,
+    flat_fee,
+    basis_points,
+    tiers,
+    min_fee,
+    max_fee,
+    product
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, sqlc.narg(product)
+)
+RETURNING *;
+
+-- name: ListActiveFeeRules :many
+-- The active rules of the currency that apply to senders of the product.
+SELECT * FROM fee_rules
+WHERE currency = $1 AND (product IS NULL OR product = $2) AND active
+ORDER BY priority, id;
+
+-- name: DeactivateFeeRule :exec
+UPDATE fee_rules
+SET active = false
+WHERE id = $1;
@@ -0,0 +1,44 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+    account_id,
+    to_account_id,
+    amount,
+    fee,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetHold :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: SettleHold :one
+UPDATE holds
+SET
+    status = sqlc.arg(status),
+    captured_amount = sqlc.arg(captured_amount),
+    transfer_id = sqlc.narg(transfer_id),
+    settled_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ExpireHolds :many
+-- Expires up to max_holds pending holds past their expiry. Holds locked by a
+-- capture or a void in progress are skipped.
+UPDATE holds
+SET status = 'expired', settled_at = now()
+WHERE id IN (
+    SELECT h.id FROM holds h
+    WHERE h.status = 'pending' AND h.expires_at <= now()
+    ORDER BY h.id
+    LIMIT sqlc.arg(max_holds)
+    FOR NO KEY UPDATE SKIP LOCKED
+)
+RETURNING *;
@@ -0,0 +1,61 @@
+-- name: ListInterestAccrualCandidates :many
+-- Accounts earning interest that were open at day_end and have no accrual for
+-- accrual_date yet, with their balance at day_end walked back from the current one.
+SELECT
+    a.id,
+    p.interest_rate_bps,
+    (a.balance - COALESCE((
+        SELECT sum(e.amount) FROM entries e
+        WHERE e.account_id = a.id AND e.created_at >= sqlc.arg(day_end)::timestamptz
+    ), 0))::bigint AS balance
+FROM accounts a
+JOIN account_products p ON p.code = a.product
+WHERE p.interest_rate_bps > 0
+    AND a.created_at < sqlc.arg(day_end)::timestamptz
+    AND NOT EXISTS (
+        SELECT 1 FROM interest_accruals i
+        WHERE i.account_id = a.id AND i.accrual_date = sqlc.arg(accrual_date)
+    )
+ORDER BY a.id
+LIMIT sqlc.arg(max_accounts);
+
+-- name: CreateInterestAccrual :exec
+INSERT INTO interest_accruals (
+    account_id,
+    accrual_date,
+    balance,
+    interest_rate_bps,
+    amount_micros
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT ON CONSTRAINT interest_accruals_account_date_key DO NOTHING;
+
+-- name: ListUnpostedInterestMonths :many
+-- The months, per account, with accruals dated before the given day left to pay.
+SELECT DISTINCT account_id, date_trunc('month', accrual_date)::date AS month
+FROM interest_accruals
+WHERE posted_at IS NULL
+    AND accrual_date < sqlc.arg(before)
+ORDER BY account_id, month
+LIMIT sqlc.arg(max_months);
+
+-- name: LockUnpostedInterest :many
+SELECT * FROM interest_accruals
+WHERE account_id = sqlc.arg(account_id)
+    AND accrual_date >= sqlc.arg(month)::date
+    AND accrual_date < (sqlc.arg(month)::date + interval '1 month')
+    AND posted_at IS NULL
+ORDER BY accrual_date
+FOR UPDATE;
+
+-- name: MarkInterestPosted :exec
+UPDATE interest_accruals
+SET posted_at = now(),
+    journal_transaction_id = sqlc.narg(journal_transaction_id)
+WHERE id = ANY(sqlc.arg(ids)::bigint[]);
+
+-- name: ListInterestAccruals :many
+SELECT * FROM interest_accruals
+WHERE account_id = $1
+ORDER BY accrual_date;
@@ -0,0 +1,18 @@
+-- name: CreateJournalTransaction :one
+INSERT INTO journal_transactions (
+    type,
+    reference,
+    metadata
+) VALUES (
+    $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetJournalTransaction :one
+SELECT * FROM journal_transactions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListJournalEntries :many
+SELECT * FROM entries
+WHERE journal_transaction_id = $1
+ORDER BY id;
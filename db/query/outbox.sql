@@ -0,0 +1,57 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox (
+    topic,
+    key,
+    payload
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetOutboxEvent :one
+SELECT * FROM outbox
+WHERE id = $1 LIMIT 1;
+
+-- name: ClaimOutboxEvents :many
+SELECT * FROM outbox
+WHERE processed_at IS NULL AND next_attempt_at <= now()
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxEventProcessed :exec
+UPDATE outbox
+SET processed_at = now()
+WHERE id = $1;
+
+-- name: RedactOutboxEvent :exec
+UPDATE outbox
+SET payload = payload - sqlc.arg(fields)::text[]
+WHERE id = sqlc.arg(id);
+
+-- name: RescheduleOutboxEvent :exec
+UPDATE outbox
+SET
+    attempts = $2,
+    next_attempt_at = $3,
+    last_error = $4
+WHERE id = $1;
+
+-- name: DeadLetterOutboxEvent :exec
+INSERT INTO outbox_dead_letters (
+    event_id,
+    topic,
+    key,
+    payload,
+    attempts,
+    last_error,
+    created_at
+)
+SELECT o.id, o.topic, o.key, o.payload, sqlc.arg(attempts)::integer, sqlc.arg(last_error)::varchar, o.created_at
+FROM outbox o
+WHERE o.id = sqlc.arg(id);
+
+-- name: ListOutboxDeadLetters :many
+SELECT * FROM outbox_dead_letters
+ORDER BY id DESC
+LIMIT $1
+OFFSET $2;
@@ -0,0 +1,36 @@
+-- name: CreatePayee :one
+INSERT INTO payees (
+    owner,
+    nickname,
+    account_id,
+    currency
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetPayee :one
+SELECT p.*, a.number AS account_number
+FROM payees p
+JOIN accounts a ON a.id = p.account_id
+WHERE p.id = $1 LIMIT 1;
+
+-- name: GetPayeeByAccount :one
+SELECT * FROM payees
+WHERE owner = $1 AND account_id = $2 LIMIT 1;
+
+-- name: ListPayees :many
+SELECT p.*, a.number AS account_number
+FROM payees p
+JOIN accounts a ON a.id = p.account_id
+WHERE p.owner = $1
+ORDER BY p.nickname, p.id;
+
+-- name: UpdatePayeeNickname :one
+UPDATE payees
+SET nickname = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: DeletePayee :exec
+DELETE FROM payees
+WHERE id = $1;
@@ -0,0 +1,58 @@
+-- name: CreatePaymentRequest :one
+INSERT INTO payment_requests (
+    requester,
+    to_account_id,
+    payer,
+    amount,
+    currency,
+    note,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetPaymentRequest :one
+SELECT r.*, a.number AS to_account_number
+FROM payment_requests r
+JOIN accounts a ON a.id = r.to_account_id
+WHERE r.id = $1 LIMIT 1;
+
+-- name: GetPaymentRequestForUpdate :one
+SELECT * FROM payment_requests
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListIncomingPaymentRequests :many
+SELECT r.*, a.number AS to_account_number
+FROM payment_requests r
+JOIN accounts a ON a.id = r.to_account_id
+WHERE r.payer = sqlc.arg(payer)
+  AND (sqlc.narg(status)::varchar IS NULL OR r.status = sqlc.narg(status))
+ORDER BY r.created_at DESC, r.id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListOutgoingPaymentRequests :many
+SELECT r.*, a.number AS to_account_number
+FROM payment_requests r
+JOIN accounts a ON a.id = r.to_account_id
+WHERE r.requester = sqlc.arg(requester)
+  AND (sqlc.narg(status)::varchar IS NULL OR r.status = sqlc.narg(status))
+ORDER BY r.created_at DESC, r.id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: RespondPaymentRequest :one
+-- Moves a pending request to its final status, no rows when it isn't pending anymore.
+UPDATE payment_requests
+SET
+    status = sqlc.arg(status),
+    transfer_id = sqlc.narg(transfer_id),
+    responded_at = now()
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
+
+-- name: ExpirePaymentRequests :execrows
+UPDATE payment_requests
+SET status = 'expired', responded_at = now()
+WHERE status = 'pending' AND expires_at <= now();
@@ -2,9 +2,13 @@
 INSERT INTO transfers (
     from_account_id,
     to_account_id,
-    amount
+    amount,
+    fee,
+    description,
+    reference,
+    metadata
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5, $6, $7
 ) RETURNING *;
 
 -- name: GetTransfer :one
@@ -19,3 +23,14 @@ WHERE
 ORDER BY id
 LIMIT $3
 OFFSET $4;
+
+-- name: ListAccountTransfers :many
+SELECT t.*, f.number AS from_account_number, r.number AS to_account_number
+FROM transfers t
+JOIN accounts f ON f.id = t.from_account_id
+JOIN accounts r ON r.id = t.to_account_id
+WHERE (t.from_account_id = sqlc.arg(account_id) OR t.to_account_id = sqlc.arg(account_id))
+  AND (sqlc.narg(reference)::varchar IS NULL OR t.reference = sqlc.narg(reference))
+ORDER BY t.id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
@@ -0,0 +1,82 @@
+-- name: UpsertTransferLimit :one
+INSERT INTO transfer_limits (
+    tier,
+    currency,
+    max_per_transfer,
+    max_per_day,
+    max_per_30_days,
+    max_transfers_per_hour
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (tier, currency) DO UPDATE SET
+    max_per_transfer = EXCLUDED.max_per_transfer,
+    max_per_day = EXCLUDED.max_per_day,
+    max_per_30_days = EXCLUDED.max_per_30_days,
+    max_transfers_per_hour = EXCLUDED.max_transfers_per_hour
+RETURNING *;
+
+-- name: LockTransferLimits :exec
+-- Serializes the limit checks of transfers sent by an owner, from any of their
+-- accounts, until the transaction ends. Taken before any account row lock; accepting
+-- a payment request locks the request first, but nothing waits for that row while
+-- holding this lock, so it can't cause deadlocks.
+SELECT pg_advisory_xact_lock(hashtext(sqlc.arg(owner)::text));
+
+-- name: ListLimitUsage :many
+-- Limits of the owner's tier and of each account's product. Tier limits are shared by
+-- the owner's accounts in a currency, so what was sent since midnight UTC, over the
+-- last 30 days and over the last hour is summed over all of them; the transfers
+-- since the start of the UTC month are counted per account, like its product limit.
+-- Pending holds count as sent when they were placed, until they are captured as a
+-- transfer or released.
+SELECT
+    a.id AS account_id,
+    a.number,
+    a.currency,
+    a.product,
+    u.tier,
+    COALESCE(l.max_per_transfer, 0)::bigint AS max_per_transfer,
+    COALESCE(l.max_per_day, 0)::bigint AS max_per_day,
+    COALESCE(l.max_per_30_days, 0)::bigint AS max_per_30_days,
+    COALESCE(l.max_transfers_per_hour, 0)::bigint AS max_transfers_per_hour,
+    p.max_transfers_per_month::bigint AS max_transfers_per_month,
+    COALESCE(s.sent_today, 0)::bigint AS sent_today,
+    COALESCE(s.sent_30_days, 0)::bigint AS sent_30_days,
+    COALESCE(s.transfers_last_hour, 0)::bigint AS transfers_last_hour,
+    COALESCE(m.transfers_this_month, 0)::bigint AS transfers_this_month
+FROM accounts a
+JOIN users u ON u.username = a.owner
+JOIN account_products p ON p.code = a.product
+LEFT JOIN transfer_limits l ON l.tier = u.tier AND l.currency = a.currency
+LEFT JOIN LATERAL (
+    SELECT
+        sum(u.amount) FILTER (WHERE u.created_at >= date_trunc('day', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC') AS sent_today,
+        sum(u.amount) AS sent_30_days,
+        count(*) FILTER (WHERE u.created_at >= now() - interval '1 hour') AS transfers_last_hour
+    FROM (
+        SELECT t.amount, t.created_at
+        FROM transfers t
+        JOIN accounts o ON o.id = t.from_account_id
+        WHERE o.owner = a.owner AND o.currency = a.currency
+            AND t.created_at >= now() - interval '30 days'
+        UNION ALL
+        SELECT h.amount, h.created_at
+        FROM holds h
+        JOIN accounts o ON o.id = h.account_id
+        WHERE o.owner = a.owner AND o.currency = a.currency
+            AND h.status = 'pending' AND h.created_at >= now() - interval '30 days'
+    ) u
+) s ON true
+LEFT JOIN LATERAL (
+    SELECT
+        (SELECT count(*) FROM transfers t
+         WHERE t.from_account_id = a.id
+            AND t.created_at >= date_trunc('month', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC')
+        + (SELECT count(*) FROM holds h
+           WHERE h.account_id = a.id AND h.status = 'pending'
+            AND h.created_at >= date_trunc('month', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC')
+        AS transfers_this_month
+) m ON true
+WHERE a.owner = $1
+ORDER BY a.currency, a.id;
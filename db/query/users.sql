@@ -11,4 +11,30 @@ RETURNING *;
 
 -- name: GetUser :one
 SELECT * FROM users
-WHERE username = $1 LIMIT 1;
\ No newline at end of file
+WHERE username = $1 LIMIT 1;
+-- name: UpdateUserTier :one
+UPDATE users
+SET tier = $2
+WHERE username = $1
+RETURNING *;
+
+-- name: UpdateUserAlias :one
+UPDATE users
+SET alias = sqlc.narg(alias)
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: VerifyUserEmail :one
+-- Only verifies the address the code was sent to, in case the user changed it since.
+UPDATE users
+SET email_verified = true
+WHERE username = sqlc.arg(username) AND email = sqlc.arg(email)
+RETURNING *;
+
+-- name: GetRecipient :one
+-- Resolves a transfer recipient by exactly one of username, verified email or alias.
+SELECT * FROM users
+WHERE username = sqlc.narg(username)
+   OR (email = sqlc.narg(email) AND email_verified)
+   OR alias = sqlc.narg(alias)
+LIMIT 1;
@@ -0,0 +1,70 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (
+    owner,
+    url,
+    secret,
+    events
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetWebhook :one
+SELECT * FROM webhooks
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhooks :many
+SELECT * FROM webhooks
+WHERE owner = $1
+ORDER BY id;
+
+-- name: ListSubscribedWebhooks :many
+SELECT * FROM webhooks
+WHERE owner = $1 AND active AND sqlc.arg(event_type)::varchar = ANY(events)
+ORDER BY id;
+
+-- name: DeleteWebhook :exec
+DELETE FROM webhooks
+WHERE id = $1;
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (
+    webhook_id,
+    event_id,
+    event_type,
+    payload
+) VALUES (
+    $1, $2, $3, $4
+) ON CONFLICT (webhook_id, event_id) DO NOTHING;
+
+-- name: ClaimWebhookDeliveries :many
+-- pushes next_attempt_at past the lease so concurrent workers skip the
+-- claimed deliveries until this one has reported the outcome
+UPDATE webhook_deliveries
+SET next_attempt_at = sqlc.arg(lease_until)
+WHERE id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= now()
+    ORDER BY next_attempt_at
+    LIMIT sqlc.arg(max_deliveries)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: UpdateWebhookDelivery :one
+UPDATE webhook_deliveries
+SET
+    status = $2,
+    attempts = $3,
+    next_attempt_at = $4,
+    response_status = $5,
+    last_error = $6,
+    delivered_at = $7
+WHERE id = $1
+RETURNING *;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE webhook_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
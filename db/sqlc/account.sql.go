@@ -7,13 +7,15 @@ package db
 
 import (
 	"context"
+
+	"github.com/lib/pq"
 )
 
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, number, held_balance, product
 `
 
 type AddAccountBalanceParams struct {
@@ -30,6 +32,37 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Number,
+		&i.HeldBalance,
+		&i.Product,
+	)
+	return i, err
+}
+
+const addAccountHeldBalance = `-- name: AddAccountHeldBalance :one
+UPDATE accounts
+SET held_balance = held_balance + $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, number, held_balance, product
+`
+
+type AddAccountHeldBalanceParams struct {
+	Amount int64
+	ID     int64
+}
+
+func (q *Queries) AddAccountHeldBalance(ctx context.Context, arg AddAccountHeldBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountHeldBalance, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Number,
+		&i.HeldBalance,
+		&i.Product,
 	)
 	return i, err
 }
@@ -38,21 +71,31 @@ const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
     owner,
     balance,
-    currency
+    currency,
+    number,
+    product
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5
 ) 
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, number, held_balance, product
 `
 
 type CreateAccountParams struct {
 	Owner    string
 	Balance  int64
 	Currency string
+	Number   string
+	Product  string
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRowContext(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.Number,
+		arg.Product,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -60,10 +103,36 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Number,
+		&i.HeldBalance,
+		&i.Product,
 	)
 	return i, err
 }
 
+const createSystemAccount = `-- name: CreateSystemAccount :exec
+INSERT INTO accounts (
+    owner,
+    balance,
+    currency,
+    number
+) VALUES (
+    $1, 0, $2, $3
+)
+ON CONFLICT ON CONSTRAINT owner_currency_product_key DO NOTHING
+`
+
+type CreateSystemAccountParams struct {
+	Owner    string
+	Currency string
+	Number   string
+}
+
+func (q *Queries) CreateSystemAccount(ctx context.Context, arg CreateSystemAccountParams) error {
+	_, err := q.db.ExecContext(ctx, createSystemAccount, arg.Owner, arg.Currency, arg.Number)
+	return err
+}
+
 const deleteAccount = `-- name: DeleteAccount :exec
 DELETE FROM accounts
 WHERE id = $1
@@ -75,7 +144,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, number, held_balance, product FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -88,12 +157,65 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Number,
+		&i.HeldBalance,
+		&i.Product,
+	)
+	return i, err
+}
+
+const getAccountByNumber = `-- name: GetAccountByNumber :one
+SELECT id, owner, balance, currency, created_at, number, held_balance, product FROM accounts
+WHERE number = $1 LIMIT 1
+`
+
+func (q *Queries) GetAccountByNumber(ctx context.Context, number string) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountByNumber, number)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Number,
+		&i.HeldBalance,
+		&i.Product,
+	)
+	return i, err
+}
+
+const getAccountByOwnerCurrency = `-- name: GetAccountByOwnerCurrency :one
+SELECT id, owner, balance, currency, created_at, number, held_balance, product FROM accounts
+WHERE owner = $1 AND currency = $2
+ORDER BY product = 'checking' DESC, id
+LIMIT 1
+`
+
+type GetAccountByOwnerCurrencyParams struct {
+	Owner    string
+	Currency string
+}
+
+// The owner's checking account in the currency, or their oldest other one.
+func (q *Queries) GetAccountByOwnerCurrency(ctx context.Context, arg GetAccountByOwnerCurrencyParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountByOwnerCurrency, arg.Owner, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Number,
+		&i.HeldBalance,
+		&i.Product,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, number, held_balance, product FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -107,26 +229,68 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Number,
+		&i.HeldBalance,
+		&i.Product,
 	)
 	return i, err
 }
 
 const listAccount = `-- name: ListAccount :many
-SELECT id, owner, balance, currency, created_at FROM accounts
-WHERE owner = $1
-ORDER BY id 
-LIMIT $2
-OFFSET $3
+SELECT a.id, a.owner, a.balance, a.currency, a.created_at, a.number, a.held_balance, a.product FROM accounts a
+JOIN account_members m ON m.account_id = a.id
+WHERE m.username = $1 AND m.status = 'active'
+ORDER BY a.id
+LIMIT $3
+OFFSET $2
 `
 
 type ListAccountParams struct {
-	Owner  string
-	Limit  int32
-	Offset int32
+	Username string
+	Offset   int32
+	Limit    int32
 }
 
+// The accounts the user is an active member of, in any role.
 func (q *Queries) ListAccount(ctx context.Context, arg ListAccountParams) ([]Account, error) {
-	rows, err := q.db.QueryContext(ctx, listAccount, arg.Owner, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listAccount, arg.Username, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Account
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.Number,
+			&i.HeldBalance,
+			&i.Product,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsByNumbers = `-- name: ListAccountsByNumbers :many
+SELECT id, owner, balance, currency, created_at, number, held_balance, product FROM accounts
+WHERE number = ANY($1::varchar[])
+`
+
+func (q *Queries) ListAccountsByNumbers(ctx context.Context, numbers []string) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsByNumbers, pq.Array(numbers))
 	if err != nil {
 		return nil, err
 	}
@@ -140,6 +304,9 @@ func (q *Queries) ListAccount(ctx context.Context, arg ListAccountParams) ([]Acc
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.Number,
+			&i.HeldBalance,
+			&i.Product,
 		); err != nil {
 			return nil, err
 		}
@@ -154,11 +321,55 @@ func (q *Queries) ListAccount(ctx context.Context, arg ListAccountParams) ([]Acc
 	return items, nil
 }
 
+const lockAccounts = `-- name: LockAccounts :many
+SELECT id FROM accounts
+WHERE id = ANY($1::bigint[])
+ORDER BY id
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) LockAccounts(ctx context.Context, ids []int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, lockAccounts, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const notifyAccountChange = `-- name: NotifyAccountChange :exec
+SELECT pg_notify($1::text, $2::text)
+`
+
+type NotifyAccountChangeParams struct {
+	Channel string
+	Payload string
+}
+
+func (q *Queries) NotifyAccountChange(ctx context.Context, arg NotifyAccountChangeParams) error {
+	_, err := q.db.ExecContext(ctx, notifyAccountChange, arg.Channel, arg.Payload)
+	return err
+}
+
 const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
 SET balance = $2
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, number, held_balance, product
 `
 
 type UpdateAccountParams struct {
@@ -175,6 +386,9 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Number,
+		&i.HeldBalance,
+		&i.Product,
 	)
 	return i, err
 }
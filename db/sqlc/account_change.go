@@ -0,0 +1,204 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// AccountChangesChannel is the Postgres NOTIFY channel account changes are sent on
+const AccountChangesChannel = "account_changes"
+
+const (
+	accountChangeBuffer  = 16
+	listenerPingInterval = 90 * time.Second
+)
+
+// AccountChange describes a new entry on an account and the balances it left behind.
+// Placing, voiding and expiring holds only change HeldBalance and carry no entry.
+type AccountChange struct {
+	AccountID   int64     `json:"account_id"`
+	Balance     int64     `json:"balance"`
+	HeldBalance int64     `json:"held_balance"`
+	EntryID     int64     `json:"entry_id,omitempty"`
+	Amount      int64     `json:"amount,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// HasEntry reports whether the change was made by a new entry
+func (change AccountChange) HasEntry() bool {
+	return change.EntryID != 0
+}
+
+func (change AccountChange) Entry() Entry {
+	return Entry{
+		ID:        change.EntryID,
+		AccountID: change.AccountID,
+		Amount:    change.Amount,
+		CreatedAt: change.CreatedAt,
+	}
+}
+
+// publishAccountChange notifies listeners of the change, Postgres only delivers
+// the notification once the surrounding transaction commits
+func publishAccountChange(ctx context.Context, q *Queries, account Account, entry Entry) error {
+	change := AccountChange{
+		AccountID:   account.ID,
+		Balance:     account.Balance,
+		HeldBalance: account.HeldBalance,
+		EntryID:     entry.ID,
+		Amount:      entry.Amount,
+		CreatedAt:   entry.CreatedAt,
+	}
+	if !change.HasEntry() {
+		change.CreatedAt = time.Now()
+	}
+
+	payload, err := json.Marshal(change)
+	if err != nil {
+		return fmt.Errorf("cannot encode account change: %w", err)
+	}
+
+	return q.NotifyAccountChange(ctx, NotifyAccountChangeParams{
+		Channel: AccountChangesChannel,
+		Payload: string(payload),
+	})
+}
+
+// AccountSubscriber streams the committed changes of an account. The channel is
+// closed when ctx is done, or early when the subscriber can no longer be kept in
+// sync, in which case it should reload the account and subscribe again.
+type AccountSubscriber interface {
+	SubscribeAccount(ctx context.Context, accountID int64) <-chan AccountChange
+}
+
+// AccountHub fans account changes out to in-process subscribers
+type AccountHub struct {
+	mu          sync.Mutex
+	subscribers map[int64]map[chan AccountChange]struct{}
+}
+
+func NewAccountHub() *AccountHub {
+	return &AccountHub{subscribers: map[int64]map[chan AccountChange]struct{}{}}
+}
+
+func (hub *AccountHub) SubscribeAccount(ctx context.Context, accountID int64) <-chan AccountChange {
+	changes := make(chan AccountChange, accountChangeBuffer)
+
+	hub.mu.Lock()
+	if hub.subscribers[accountID] == nil {
+		hub.subscribers[accountID] = map[chan AccountChange]struct{}{}
+	}
+	hub.subscribers[accountID][changes] = struct{}{}
+	hub.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		hub.mu.Lock()
+		defer hub.mu.Unlock()
+		hub.unsubscribe(accountID, changes)
+	}()
+
+	return changes
+}
+
+// Publish hands the change to the account's subscribers. Subscribers too slow
+// to keep up are dropped rather than blocking everyone else.
+func (hub *AccountHub) Publish(change AccountChange) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	for changes := range hub.subscribers[change.AccountID] {
+		select {
+		case changes <- change:
+		default:
+			hub.unsubscribe(change.AccountID, changes)
+		}
+	}
+}
+
+// Reset drops every subscriber, used when changes may have been missed
+func (hub *AccountHub) Reset() {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	for accountID, subscribers := range hub.subscribers {
+		for changes := range subscribers {
+			hub.unsubscribe(accountID, changes)
+		}
+	}
+}
+
+// unsubscribe closes the channel once, callers must hold mu
+func (hub *AccountHub) unsubscribe(accountID int64, changes chan AccountChange) {
+	if _, ok := hub.subscribers[accountID][changes]; !ok {
+		return
+	}
+
+	delete(hub.subscribers[accountID], changes)
+	if len(hub.subscribers[accountID]) == 0 {
+		delete(hub.subscribers, accountID)
+	}
+	close(changes)
+}
+
+// AccountListener LISTENs on AccountChangesChannel and feeds its hub. Every
+// replica runs one, so a change committed through any replica reaches all streams.
+type AccountListener struct {
+	*AccountHub
+	listener *pq.Listener
+}
+
+func NewAccountListener(dataSource string) (*AccountListener, error) {
+	listener := pq.NewListener(dataSource, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("account listener: %s", err)
+		}
+	})
+
+	if err := listener.Listen(AccountChangesChannel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	return &AccountListener{
+		AccountHub: NewAccountHub(),
+		listener:   listener,
+	}, nil
+}
+
+// Start dispatches notifications until ctx is cancelled
+func (listener *AccountListener) Start(ctx context.Context) {
+	defer listener.listener.Close()
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			listener.Reset()
+			return
+		case notification := <-listener.listener.Notify:
+			// a nil notification follows a reconnect, changes may have been lost meanwhile
+			if notification == nil {
+				listener.Reset()
+				continue
+			}
+
+			var change AccountChange
+			if err := json.Unmarshal([]byte(notification.Extra), &change); err != nil {
+				log.Printf("account listener: cannot decode notification: %s", err)
+				continue
+			}
+			listener.Publish(change)
+		case <-ticker.C:
+			go listener.listener.Ping()
+		}
+	}
+}
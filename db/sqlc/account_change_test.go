@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountHub(t *testing.T) {
+	hub := NewAccountHub()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := hub.SubscribeAccount(ctx, 1)
+	other := hub.SubscribeAccount(context.Background(), 2)
+
+	hub.Publish(AccountChange{AccountID: 1, Balance: 10})
+	require.Equal(t, int64(10), (<-changes).Balance)
+	require.Empty(t, other)
+
+	cancel()
+	_, ok := <-changes
+	require.False(t, ok)
+
+	// subscribers that fall behind are dropped
+	for i := 0; i <= accountChangeBuffer; i++ {
+		hub.Publish(AccountChange{AccountID: 2, Balance: int64(i)})
+	}
+	for i := 0; i < accountChangeBuffer; i++ {
+		<-other
+	}
+	_, ok = <-other
+	require.False(t, ok)
+}
+
+func TestAccountListener(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomTestAccount(t)
+	account2 := createRandomTestAccountIn(t, account1.Currency)
+
+	listener, err := NewAccountListener(testDBSource)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go listener.Start(ctx)
+
+	changes := listener.SubscribeAccount(ctx, account2.ID)
+
+	result, err := store.TransferTxn(context.Background(), TransferTxnParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	select {
+	case change := <-changes:
+		require.Equal(t, account2.ID, change.AccountID)
+		require.Equal(t, result.ToAccount.Balance, change.Balance)
+		require.Equal(t, result.ToEntry.ID, change.EntryID)
+		require.Equal(t, int64(10), change.Amount)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no account change received")
+	}
+
+	held := listener.SubscribeAccount(ctx, account1.ID)
+
+	placed, err := store.PlaceHoldTxn(context.Background(), PlaceHoldTxnParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        5,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	select {
+	case change := <-held:
+		require.Equal(t, account1.ID, change.AccountID)
+		require.Equal(t, placed.Account.Balance, change.Balance)
+		require.Equal(t, placed.Account.HeldBalance, change.HeldBalance)
+		require.False(t, change.HasEntry())
+	case <-time.After(5 * time.Second):
+		t.Fatal("no hold change received")
+	}
+}
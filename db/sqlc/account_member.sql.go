@@ -0,0 +1,367 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: account_member.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const acceptAccountInvitation = `-- name: AcceptAccountInvitation :one
+UPDATE account_members
+SET status = 'active', accepted_at = now()
+WHERE id = $1 AND username = $2 AND status = 'invited'
+RETURNING id, account_id, username, role, spend_limit, status, invited_by, accepted_at, created_at
+`
+
+type AcceptAccountInvitationParams struct {
+	ID       int64
+	Username string
+}
+
+func (q *Queries) AcceptAccountInvitation(ctx context.Context, arg AcceptAccountInvitationParams) (AccountMember, error) {
+	row := q.db.QueryRowContext(ctx, acceptAccountInvitation, arg.ID, arg.Username)
+	var i AccountMember
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Username,
+		&i.Role,
+		&i.SpendLimit,
+		&i.Status,
+		&i.InvitedBy,
+		&i.AcceptedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createAccountMember = `-- name: CreateAccountMember :one
+INSERT INTO account_members (
+    account_id,
+    username,
+    role,
+    spend_limit,
+    invited_by
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING id, account_id, username, role, spend_limit, status, invited_by, accepted_at, created_at
+`
+
+type CreateAccountMemberParams struct {
+	AccountID  int64
+	Username   string
+	Role       string
+	SpendLimit int64
+	InvitedBy  string
+}
+
+func (q *Queries) CreateAccountMember(ctx context.Context, arg CreateAccountMemberParams) (AccountMember, error) {
+	row := q.db.QueryRowContext(ctx, createAccountMember,
+		arg.AccountID,
+		arg.Username,
+		arg.Role,
+		arg.SpendLimit,
+		arg.InvitedBy,
+	)
+	var i AccountMember
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Username,
+		&i.Role,
+		&i.SpendLimit,
+		&i.Status,
+		&i.InvitedBy,
+		&i.AcceptedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createAccountOwner = `-- name: CreateAccountOwner :one
+INSERT INTO account_members (
+    account_id,
+    username,
+    role,
+    status,
+    invited_by,
+    accepted_at
+) VALUES (
+    $1, $2, 'owner', 'active', $2, now()
+) RETURNING id, account_id, username, role, spend_limit, status, invited_by, accepted_at, created_at
+`
+
+type CreateAccountOwnerParams struct {
+	AccountID int64
+	Username  string
+}
+
+// The owner is active from the start, as the account was opened by them.
+func (q *Queries) CreateAccountOwner(ctx context.Context, arg CreateAccountOwnerParams) (AccountMember, error) {
+	row := q.db.QueryRowContext(ctx, createAccountOwner, arg.AccountID, arg.Username)
+	var i AccountMember
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Username,
+		&i.Role,
+		&i.SpendLimit,
+		&i.Status,
+		&i.InvitedBy,
+		&i.AcceptedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const declineAccountInvitation = `-- name: DeclineAccountInvitation :one
+DELETE FROM account_members
+WHERE id = $1 AND username = $2 AND status = 'invited'
+RETURNING id, account_id, username, role, spend_limit, status, invited_by, accepted_at, created_at
+`
+
+type DeclineAccountInvitationParams struct {
+	ID       int64
+	Username string
+}
+
+func (q *Queries) DeclineAccountInvitation(ctx context.Context, arg DeclineAccountInvitationParams) (AccountMember, error) {
+	row := q.db.QueryRowContext(ctx, declineAccountInvitation, arg.ID, arg.Username)
+	var i AccountMember
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Username,
+		&i.Role,
+		&i.SpendLimit,
+		&i.Status,
+		&i.InvitedBy,
+		&i.AcceptedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteAccountMember = `-- name: DeleteAccountMember :exec
+DELETE FROM account_members
+WHERE id = $1
+`
+
+func (q *Queries) DeleteAccountMember(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteAccountMember, id)
+	return err
+}
+
+const getAccountMember = `-- name: GetAccountMember :one
+SELECT id, account_id, username, role, spend_limit, status, invited_by, accepted_at, created_at FROM account_members
+WHERE account_id = $1 AND username = $2 LIMIT 1
+`
+
+type GetAccountMemberParams struct {
+	AccountID int64
+	Username  string
+}
+
+func (q *Queries) GetAccountMember(ctx context.Context, arg GetAccountMemberParams) (AccountMember, error) {
+	row := q.db.QueryRowContext(ctx, getAccountMember, arg.AccountID, arg.Username)
+	var i AccountMember
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Username,
+		&i.Role,
+		&i.SpendLimit,
+		&i.Status,
+		&i.InvitedBy,
+		&i.AcceptedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getActiveAccountMember = `-- name: GetActiveAccountMember :one
+SELECT id, account_id, username, role, spend_limit, status, invited_by, accepted_at, created_at FROM account_members
+WHERE account_id = $1 AND username = $2 AND status = 'active' LIMIT 1
+`
+
+type GetActiveAccountMemberParams struct {
+	AccountID int64
+	Username  string
+}
+
+func (q *Queries) GetActiveAccountMember(ctx context.Context, arg GetActiveAccountMemberParams) (AccountMember, error) {
+	row := q.db.QueryRowContext(ctx, getActiveAccountMember, arg.AccountID, arg.Username)
+	var i AccountMember
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Username,
+		&i.Role,
+		&i.SpendLimit,
+		&i.Status,
+		&i.InvitedBy,
+		&i.AcceptedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAccountInvitations = `-- name: ListAccountInvitations :many
+SELECT m.id, m.account_id, m.username, m.role, m.spend_limit, m.status, m.invited_by, m.accepted_at, m.created_at, a.number AS account_number, a.currency
+FROM account_members m
+JOIN accounts a ON a.id = m.account_id
+WHERE m.username = $1 AND m.status = 'invited'
+ORDER BY m.id
+`
+
+type ListAccountInvitationsRow struct {
+	ID            int64
+	AccountID     int64
+	Username      string
+	Role          string
+	SpendLimit    int64
+	Status        string
+	InvitedBy     string
+	AcceptedAt    sql.NullTime
+	CreatedAt     time.Time
+	AccountNumber string
+	Currency      string
+}
+
+// The pending invitations of the user, with the account they were invited to.
+func (q *Queries) ListAccountInvitations(ctx context.Context, username string) ([]ListAccountInvitationsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountInvitations, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAccountInvitationsRow
+	for rows.Next() {
+		var i ListAccountInvitationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Username,
+			&i.Role,
+			&i.SpendLimit,
+			&i.Status,
+			&i.InvitedBy,
+			&i.AcceptedAt,
+			&i.CreatedAt,
+			&i.AccountNumber,
+			&i.Currency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountMembers = `-- name: ListAccountMembers :many
+SELECT id, account_id, username, role, spend_limit, status, invited_by, accepted_at, created_at FROM account_members
+WHERE account_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListAccountMembers(ctx context.Context, accountID int64) ([]AccountMember, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountMembers, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AccountMember
+	for rows.Next() {
+		var i AccountMember
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Username,
+			&i.Role,
+			&i.SpendLimit,
+			&i.Status,
+			&i.InvitedBy,
+			&i.AcceptedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listActiveCoOwners = `-- name: ListActiveCoOwners :many
+SELECT username FROM account_members
+WHERE account_id = $1 AND status = 'active' AND role = 'co_owner'
+ORDER BY id
+`
+
+func (q *Queries) ListActiveCoOwners(ctx context.Context, accountID int64) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveCoOwners, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, err
+		}
+		items = append(items, username)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockActiveAccountMember = `-- name: LockActiveAccountMember :one
+SELECT id, account_id, username, role, spend_limit, status, invited_by, accepted_at, created_at FROM account_members
+WHERE account_id = $1 AND username = $2 AND status = 'active' LIMIT 1
+FOR SHARE
+`
+
+type LockActiveAccountMemberParams struct {
+	AccountID int64
+	Username  string
+}
+
+// Held until the sending transaction commits, so the member can't be removed meanwhile.
+func (q *Queries) LockActiveAccountMember(ctx context.Context, arg LockActiveAccountMemberParams) (AccountMember, error) {
+	row := q.db.QueryRowContext(ctx, lockActiveAccountMember, arg.AccountID, arg.Username)
+	var i AccountMember
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Username,
+		&i.Role,
+		&i.SpendLimit,
+		&i.Status,
+		&i.InvitedBy,
+		&i.AcceptedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
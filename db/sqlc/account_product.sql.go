@@ -0,0 +1,119 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: account_product.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+)
+
+const createAccountProduct = `-- name: CreateAccountProduct :one
+INSERT INTO account_products (
+    code,
+    name,
+    interest_rate_bps,
+    currencies,
+    overdraft_limit,
+    max_transfers_per_month,
+    own_accounts_only
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING code, name, interest_rate_bps, created_at, currencies, overdraft_limit, max_transfers_per_month, own_accounts_only
+`
+
+type CreateAccountProductParams struct {
+	Code                 string
+	Name                 string
+	InterestRateBps      int32
+	Currencies           []string
+	OverdraftLimit       int64
+	MaxTransfersPerMonth int32
+	OwnAccountsOnly      bool
+}
+
+func (q *Queries) CreateAccountProduct(ctx context.Context, arg CreateAccountProductParams) (AccountProduct, error) {
+	row := q.db.QueryRowContext(ctx, createAccountProduct,
+		arg.Code,
+		arg.Name,
+		arg.InterestRateBps,
+		pq.Array(arg.Currencies),
+		arg.OverdraftLimit,
+		arg.MaxTransfersPerMonth,
+		arg.OwnAccountsOnly,
+	)
+	var i AccountProduct
+	err := row.Scan(
+		&i.Code,
+		&i.Name,
+		&i.InterestRateBps,
+		&i.CreatedAt,
+		pq.Array(&i.Currencies),
+		&i.OverdraftLimit,
+		&i.MaxTransfersPerMonth,
+		&i.OwnAccountsOnly,
+	)
+	return i, err
+}
+
+const getAccountProduct = `-- name: GetAccountProduct :one
+SELECT code, name, interest_rate_bps, created_at, currencies, overdraft_limit, max_transfers_per_month, own_accounts_only FROM account_products
+WHERE code = $1 LIMIT 1
+`
+
+func (q *Queries) GetAccountProduct(ctx context.Context, code string) (AccountProduct, error) {
+	row := q.db.QueryRowContext(ctx, getAccountProduct, code)
+	var i AccountProduct
+	err := row.Scan(
+		&i.Code,
+		&i.Name,
+		&i.InterestRateBps,
+		&i.CreatedAt,
+		pq.Array(&i.Currencies),
+		&i.OverdraftLimit,
+		&i.MaxTransfersPerMonth,
+		&i.OwnAccountsOnly,
+	)
+	return i, err
+}
+
+const listAccountProducts = `-- name: ListAccountProducts :many
+SELECT code, name, interest_rate_bps, created_at, currencies, overdraft_limit, max_transfers_per_month, own_accounts_only FROM account_products
+ORDER BY code
+`
+
+func (q *Queries) ListAccountProducts(ctx context.Context) ([]AccountProduct, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountProducts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AccountProduct
+	for rows.Next() {
+		var i AccountProduct
+		if err := rows.Scan(
+			&i.Code,
+			&i.Name,
+			&i.InterestRateBps,
+			&i.CreatedAt,
+			pq.Array(&i.Currencies),
+			&i.OverdraftLimit,
+			&i.MaxTransfersPerMonth,
+			&i.OwnAccountsOnly,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
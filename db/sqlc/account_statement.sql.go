@@ -0,0 +1,174 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: account_statement.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createAccountStatement = `-- name: CreateAccountStatement :exec
+INSERT INTO account_statements (
+    account_id,
+    period,
+    storage_key,
+    size,
+    opening_balance,
+    closing_balance
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) ON CONFLICT (account_id, period) DO NOTHING
+`
+
+type CreateAccountStatementParams struct {
+	AccountID      int64
+	Period         time.Time
+	StorageKey     string
+	Size           int64
+	OpeningBalance int64
+	ClosingBalance int64
+}
+
+func (q *Queries) CreateAccountStatement(ctx context.Context, arg CreateAccountStatementParams) error {
+	_, err := q.db.ExecContext(ctx, createAccountStatement,
+		arg.AccountID,
+		arg.Period,
+		arg.StorageKey,
+		arg.Size,
+		arg.OpeningBalance,
+		arg.ClosingBalance,
+	)
+	return err
+}
+
+const getAccountStatement = `-- name: GetAccountStatement :one
+SELECT id, account_id, period, storage_key, size, opening_balance, closing_balance, created_at FROM account_statements
+WHERE account_id = $1 AND period = $2
+LIMIT 1
+`
+
+type GetAccountStatementParams struct {
+	AccountID int64
+	Period    time.Time
+}
+
+func (q *Queries) GetAccountStatement(ctx context.Context, arg GetAccountStatementParams) (AccountStatement, error) {
+	row := q.db.QueryRowContext(ctx, getAccountStatement, arg.AccountID, arg.Period)
+	var i AccountStatement
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Period,
+		&i.StorageKey,
+		&i.Size,
+		&i.OpeningBalance,
+		&i.ClosingBalance,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAccountStatements = `-- name: ListAccountStatements :many
+SELECT id, account_id, period, storage_key, size, opening_balance, closing_balance, created_at FROM account_statements
+WHERE account_id = $1
+ORDER BY period DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListAccountStatementsParams struct {
+	AccountID int64
+	Limit     int32
+	Offset    int32
+}
+
+func (q *Queries) ListAccountStatements(ctx context.Context, arg ListAccountStatementsParams) ([]AccountStatement, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountStatements, arg.AccountID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AccountStatement
+	for rows.Next() {
+		var i AccountStatement
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Period,
+			&i.StorageKey,
+			&i.Size,
+			&i.OpeningBalance,
+			&i.ClosingBalance,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsMissingStatement = `-- name: ListAccountsMissingStatement :many
+SELECT a.id, a.owner, a.balance, a.currency, a.created_at, a.number, a.held_balance, a.product FROM accounts a
+WHERE a.created_at < $1
+    AND a.id > $2
+    AND NOT EXISTS (
+        SELECT 1 FROM account_statements s
+        WHERE s.account_id = a.id AND s.period = $3
+    )
+ORDER BY a.id
+LIMIT $4
+`
+
+type ListAccountsMissingStatementParams struct {
+	PeriodEnd   time.Time
+	AfterID     int64
+	Period      time.Time
+	MaxAccounts int32
+}
+
+func (q *Queries) ListAccountsMissingStatement(ctx context.Context, arg ListAccountsMissingStatementParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsMissingStatement,
+		arg.PeriodEnd,
+		arg.AfterID,
+		arg.Period,
+		arg.MaxAccounts,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Account
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.Number,
+			&i.HeldBalance,
+			&i.Product,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
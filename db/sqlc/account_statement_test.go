@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountStatements(t *testing.T) {
+	account := createRandomTestAccount(t)
+	period := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	missing, err := testQueries.ListAccountsMissingStatement(context.Background(), ListAccountsMissingStatementParams{
+		Period:      period,
+		PeriodEnd:   time.Now().Add(time.Minute),
+		AfterID:     account.ID - 1,
+		MaxAccounts: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, missing, 1)
+	require.Equal(t, account.ID, missing[0].ID)
+
+	args := CreateAccountStatementParams{
+		AccountID:      account.ID,
+		Period:         period,
+		StorageKey:     "statements/" + account.Number + "/2026-01.pdf",
+		Size:           1024,
+		OpeningBalance: 100,
+		ClosingBalance: 70,
+	}
+	require.NoError(t, testQueries.CreateAccountStatement(context.Background(), args))
+
+	// a second run keeps the first statement
+	duplicate := args
+	duplicate.Size = 2048
+	require.NoError(t, testQueries.CreateAccountStatement(context.Background(), duplicate))
+
+	accountStatement, err := testQueries.GetAccountStatement(context.Background(), GetAccountStatementParams{
+		AccountID: account.ID,
+		Period:    period,
+	})
+	require.NoError(t, err)
+	require.Equal(t, args.StorageKey, accountStatement.StorageKey)
+	require.Equal(t, args.Size, accountStatement.Size)
+	require.Equal(t, args.OpeningBalance, accountStatement.OpeningBalance)
+	require.Equal(t, args.ClosingBalance, accountStatement.ClosingBalance)
+	require.True(t, period.Equal(accountStatement.Period))
+
+	statements, err := testQueries.ListAccountStatements(context.Background(), ListAccountStatementsParams{
+		AccountID: account.ID,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+	require.Equal(t, accountStatement.ID, statements[0].ID)
+
+	missing, err = testQueries.ListAccountsMissingStatement(context.Background(), ListAccountsMissingStatementParams{
+		Period:      period,
+		PeriodEnd:   time.Now().Add(time.Minute),
+		AfterID:     account.ID - 1,
+		MaxAccounts: 1,
+	})
+	require.NoError(t, err)
+	for _, other := range missing {
+		require.NotEqual(t, account.ID, other.ID)
+	}
+}
@@ -11,20 +11,28 @@ import (
 )
 
 func createRandomTestAccount(t *testing.T) Account {
+	return createRandomTestAccountIn(t, utils.RandomCurrency())
+}
+
+// createRandomTestAccountIn creates an account in currency, so transfers can be booked against it
+func createRandomTestAccountIn(t *testing.T, currency string) Account {
 	user := createRandomTestUser(t)
 	args := CreateAccountParams{
 		Owner:    user.Username,
-		Balance:  utils.RandomMoney(),
-		Currency: utils.RandomCurrency(),
+		Balance:  utils.RandomInt(100, 1000),
+		Currency: currency,
+		Number:   utils.RandomAccountNumber(),
+		Product:  ProductChecking,
 	}
 
-	account, err := testQueries.CreateAccount(context.Background(), args)
+	account, err := NewStore(testDB).CreateAccountTxn(context.Background(), args)
 	require.NoError(t, err)
 	require.NotEmpty(t, account)
 
 	require.Equal(t, args.Owner, account.Owner)
 	require.Equal(t, args.Balance, account.Balance)
 	require.Equal(t, args.Currency, account.Currency)
+	require.Equal(t, args.Number, account.Number)
 
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
@@ -50,6 +58,21 @@ func TestGetAccount(t *testing.T) {
 	require.WithinDuration(t, account1.CreatedAt, account2.CreatedAt, time.Second)
 }
 
+func TestGetAccountByNumber(t *testing.T) {
+	account1 := createRandomTestAccount(t)
+	account2, err := testQueries.GetAccountByNumber(context.Background(), account1.Number)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, account2)
+
+	require.Equal(t, account1.ID, account2.ID)
+	require.Equal(t, account1.Number, account2.Number)
+	require.Equal(t, account1.Owner, account2.Owner)
+
+	_, err = testQueries.GetAccountByNumber(context.Background(), utils.RandomAccountNumber())
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
 func TestUpdateAccount(t *testing.T) {
 	account1 := createRandomTestAccount(t)
 
@@ -87,9 +110,9 @@ func TestListAccount(t *testing.T) {
 	}
 
 	args := ListAccountParams{
-		Owner:  lastAccount.Owner,
-		Limit:  5,
-		Offset: 0,
+		Username: lastAccount.Owner,
+		Limit:    5,
+		Offset:   0,
 	}
 
 	accounts, err := testQueries.ListAccount(context.Background(), args)
@@ -0,0 +1,155 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: analytics.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const listAccountActivity = `-- name: ListAccountActivity :many
+WITH buckets AS (
+    SELECT
+        (b AT TIME ZONE 'UTC')::timestamptz AS bucket_start,
+        ((b + ('1 ' || $4::text)::interval) AT TIME ZONE 'UTC')::timestamptz AS bucket_end
+    FROM generate_series(
+        date_trunc($4::text, $2::timestamptz AT TIME ZONE 'UTC'),
+        $3::timestamptz AT TIME ZONE 'UTC' - interval '1 microsecond',
+        ('1 ' || $4::text)::interval
+    ) AS b
+)
+SELECT
+    bu.bucket_start,
+    COALESCE(SUM(e.amount) FILTER (WHERE e.amount > 0), 0)::bigint AS inflow,
+    COALESCE(-SUM(e.amount) FILTER (WHERE e.amount < 0), 0)::bigint AS outflow,
+    COALESCE(SUM(e.amount), 0)::bigint AS net,
+    COUNT(e.id) AS transactions
+FROM buckets bu
+LEFT JOIN entries e
+    ON e.account_id = $1
+   AND e.created_at >= GREATEST(bu.bucket_start, $2::timestamptz)
+   AND e.created_at < LEAST(bu.bucket_end, $3::timestamptz)
+GROUP BY bu.bucket_start
+ORDER BY bu.bucket_start
+`
+
+type ListAccountActivityParams struct {
+	AccountID   int64
+	FromTime    time.Time
+	ToTime      time.Time
+	Granularity string
+}
+
+type ListAccountActivityRow struct {
+	BucketStart  time.Time
+	Inflow       int64
+	Outflow      int64
+	Net          int64
+	Transactions int64
+}
+
+// Inflow, outflow, net and entry count of the account per UTC day, week or month over
+// [from_time, to_time). Buckets without entries are zero.
+func (q *Queries) ListAccountActivity(ctx context.Context, arg ListAccountActivityParams) ([]ListAccountActivityRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountActivity,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Granularity,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAccountActivityRow
+	for rows.Next() {
+		var i ListAccountActivityRow
+		if err := rows.Scan(
+			&i.BucketStart,
+			&i.Inflow,
+			&i.Outflow,
+			&i.Net,
+			&i.Transactions,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTopCounterparties = `-- name: ListTopCounterparties :many
+SELECT
+    a.number,
+    COUNT(*) AS transfers,
+    COALESCE(SUM(t.amount) FILTER (WHERE t.from_account_id = $1), 0)::bigint AS sent,
+    COALESCE(SUM(t.amount) FILTER (WHERE t.to_account_id = $1), 0)::bigint AS received
+FROM transfers t
+JOIN accounts a ON a.id = CASE
+    WHEN t.from_account_id = $1 THEN t.to_account_id
+    ELSE t.from_account_id
+END
+WHERE (t.from_account_id = $1 OR t.to_account_id = $1)
+  AND t.created_at >= $2
+  AND t.created_at < $3
+GROUP BY a.id, a.number
+ORDER BY SUM(t.amount) DESC, transfers DESC, a.number
+LIMIT $4
+`
+
+type ListTopCounterpartiesParams struct {
+	AccountID int64
+	FromTime  time.Time
+	ToTime    time.Time
+	Limit     int32
+}
+
+type ListTopCounterpartiesRow struct {
+	Number    string
+	Transfers int64
+	Sent      int64
+	Received  int64
+}
+
+// The accounts the account exchanged the most money with over [from_time, to_time).
+func (q *Queries) ListTopCounterparties(ctx context.Context, arg ListTopCounterpartiesParams) ([]ListTopCounterpartiesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTopCounterparties,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTopCounterpartiesRow
+	for rows.Next() {
+		var i ListTopCounterpartiesRow
+		if err := rows.Scan(
+			&i.Number,
+			&i.Transfers,
+			&i.Sent,
+			&i.Received,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAccountActivity(t *testing.T) {
+	account := createRandomTestAccount(t)
+	addTestEntry(t, account, 100)
+	addTestEntry(t, account, -30)
+	addTestEntry(t, account, -20)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	buckets, err := testQueries.ListAccountActivity(context.Background(), ListAccountActivityParams{
+		AccountID:   account.ID,
+		FromTime:    today.AddDate(0, 0, -2),
+		ToTime:      today.AddDate(0, 0, 1),
+		Granularity: "day",
+	})
+	require.NoError(t, err)
+	require.Len(t, buckets, 3)
+
+	// days without entries are zero
+	for _, bucket := range buckets[:2] {
+		require.Zero(t, bucket.Transactions)
+		require.Zero(t, bucket.Net)
+	}
+
+	require.True(t, today.Equal(buckets[2].BucketStart))
+	require.Equal(t, int64(100), buckets[2].Inflow)
+	require.Equal(t, int64(50), buckets[2].Outflow)
+	require.Equal(t, int64(50), buckets[2].Net)
+	require.Equal(t, int64(3), buckets[2].Transactions)
+
+	months, err := testQueries.ListAccountActivity(context.Background(), ListAccountActivityParams{
+		AccountID:   account.ID,
+		FromTime:    today,
+		ToTime:      today.AddDate(0, 0, 1),
+		Granularity: "month",
+	})
+	require.NoError(t, err)
+	require.Len(t, months, 1)
+	require.Equal(t, 1, months[0].BucketStart.UTC().Day())
+	require.Equal(t, int64(3), months[0].Transactions)
+}
+
+func TestListTopCounterparties(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomTestAccount(t)
+	account2 := createRandomTestAccountIn(t, account1.Currency)
+	account3 := createRandomTestAccountIn(t, account1.Currency)
+
+	for _, arg := range []TransferTxnParam{
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10},
+		{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 5},
+		{FromAccountID: account1.ID, ToAccountID: account3.ID, Amount: 40},
+	} {
+		_, err := store.TransferTxn(context.Background(), arg)
+		require.NoError(t, err)
+	}
+
+	counterparties, err := testQueries.ListTopCounterparties(context.Background(), ListTopCounterpartiesParams{
+		AccountID: account1.ID,
+		FromTime:  time.Now().Add(-time.Hour),
+		ToTime:    time.Now().Add(time.Hour),
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []ListTopCounterpartiesRow{
+		{Number: account3.Number, Transfers: 1, Sent: 40},
+		{Number: account2.Number, Transfers: 2, Sent: 10, Received: 5},
+	}, counterparties)
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	defaultSnapshotBatchSize    = 500
+	defaultSnapshotPollInterval = time.Hour
+	defaultSnapshotDelay        = 10 * time.Minute
+)
+
+// SnapshotWriter records the balance of every account at the start of each UTC day,
+// so GetBalanceAt only replays the entries between the closest snapshot and the
+// requested time. A day is snapshotted Delay after it started, once transfers begun
+// the day before have committed.
+type SnapshotWriter struct {
+	querier      Querier
+	BatchSize    int32
+	PollInterval time.Duration
+	Delay        time.Duration
+	now          func() time.Time
+}
+
+func NewSnapshotWriter(querier Querier) *SnapshotWriter {
+	return &SnapshotWriter{
+		querier:      querier,
+		BatchSize:    defaultSnapshotBatchSize,
+		PollInterval: defaultSnapshotPollInterval,
+		Delay:        defaultSnapshotDelay,
+		now:          time.Now,
+	}
+}
+
+// Start writes snapshots until ctx is cancelled
+func (writer *SnapshotWriter) Start(ctx context.Context) {
+	ticker := time.NewTicker(writer.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := writer.RunOnce(ctx); err != nil {
+			log.Printf("balance snapshot writer: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce snapshots the accounts missing a snapshot for the latest settled day
+// and reports how many were written
+func (writer *SnapshotWriter) RunOnce(ctx context.Context) (int, error) {
+	now := writer.now().Add(-writer.Delay).UTC()
+	takenAt := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	written := 0
+	for {
+		snapshots, err := writer.querier.CreateBalanceSnapshots(ctx, CreateBalanceSnapshotsParams{
+			TakenAt:     takenAt,
+			MaxAccounts: writer.BatchSize,
+		})
+		if err != nil {
+			return written, fmt.Errorf("cannot snapshot balances at %s: %w", takenAt.Format(time.RFC3339), err)
+		}
+
+		written += len(snapshots)
+		if len(snapshots) < int(writer.BatchSize) {
+			return written, nil
+		}
+	}
+}
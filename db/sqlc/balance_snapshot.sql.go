@@ -0,0 +1,104 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: balance_snapshot.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createBalanceSnapshots = `-- name: CreateBalanceSnapshots :many
+INSERT INTO balance_snapshots (account_id, balance, taken_at)
+SELECT
+    a.id,
+    a.balance - COALESCE((
+        SELECT sum(e.amount) FROM entries e
+        WHERE e.account_id = a.id AND e.created_at > $1::timestamptz
+    ), 0),
+    $1::timestamptz
+FROM accounts a
+WHERE a.created_at <= $1::timestamptz
+    AND NOT EXISTS (
+        SELECT 1 FROM balance_snapshots s
+        WHERE s.account_id = a.id AND s.taken_at = $1::timestamptz
+    )
+ORDER BY a.id
+LIMIT $2
+ON CONFLICT (account_id, taken_at) DO NOTHING
+RETURNING id, account_id, balance, taken_at, created_at
+`
+
+type CreateBalanceSnapshotsParams struct {
+	TakenAt     time.Time
+	MaxAccounts int32
+}
+
+// Snapshots the balance at taken_at of up to max_accounts accounts that don't
+// have one yet, walking entries back from the current balance.
+func (q *Queries) CreateBalanceSnapshots(ctx context.Context, arg CreateBalanceSnapshotsParams) ([]BalanceSnapshot, error) {
+	rows, err := q.db.QueryContext(ctx, createBalanceSnapshots, arg.TakenAt, arg.MaxAccounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BalanceSnapshot
+	for rows.Next() {
+		var i BalanceSnapshot
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Balance,
+			&i.TakenAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBalanceAt = `-- name: GetBalanceAt :one
+WITH anchor AS (
+    SELECT anchors.balance, anchors.taken_at FROM (
+        SELECT s.balance, s.taken_at FROM balance_snapshots s
+        WHERE s.account_id = $2
+        UNION ALL
+        SELECT a.balance, 'infinity'::timestamptz FROM accounts a
+        WHERE a.id = $2
+    ) anchors
+    ORDER BY abs(extract(epoch FROM least(anchors.taken_at, now()) - $1::timestamptz))
+    LIMIT 1
+)
+SELECT (anchor.balance + COALESCE((
+    SELECT sum(CASE WHEN e.created_at > $1::timestamptz THEN -e.amount ELSE e.amount END)
+    FROM entries e
+    WHERE e.account_id = $2
+        AND e.created_at > least(anchor.taken_at, $1::timestamptz)
+        AND e.created_at <= greatest(anchor.taken_at, $1::timestamptz)
+), 0))::bigint AS balance
+FROM anchor
+`
+
+type GetBalanceAtParams struct {
+	At        time.Time
+	AccountID int64
+}
+
+// Starts from the snapshot closest to at, or from the current balance, and
+// applies the entries between that point and at.
+func (q *Queries) GetBalanceAt(ctx context.Context, arg GetBalanceAtParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getBalanceAt, arg.At, arg.AccountID)
+	var balance int64
+	err := row.Scan(&balance)
+	return balance, err
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func addTestEntry(t *testing.T, account Account, amount int64) Entry {
+	entry, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID: account.ID,
+		Amount:    amount,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account.ID,
+		Amount: amount,
+	})
+	require.NoError(t, err)
+	return entry
+}
+
+func TestGetBalanceAt(t *testing.T) {
+	account := createRandomTestAccount(t)
+	entry1 := addTestEntry(t, account, 10)
+	entry2 := addTestEntry(t, account, -3)
+
+	testCases := []struct {
+		name    string
+		at      time.Time
+		balance int64
+	}{
+		{name: "BeforeEntries", at: account.CreatedAt, balance: account.Balance},
+		{name: "AfterFirstEntry", at: entry1.CreatedAt, balance: account.Balance + 10},
+		{name: "AfterSecondEntry", at: entry2.CreatedAt, balance: account.Balance + 7},
+		{name: "Now", at: time.Now().Add(time.Minute), balance: account.Balance + 7},
+	}
+
+	check := func(t *testing.T) {
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				balance, err := testQueries.GetBalanceAt(context.Background(), GetBalanceAtParams{
+					AccountID: account.ID,
+					At:        tc.at,
+				})
+				require.NoError(t, err)
+				require.Equal(t, tc.balance, balance)
+			})
+		}
+	}
+
+	t.Run("FromCurrentBalance", check)
+
+	snapshots, err := testQueries.CreateBalanceSnapshots(context.Background(), CreateBalanceSnapshotsParams{
+		TakenAt:     entry1.CreatedAt,
+		MaxAccounts: 1 << 20,
+	})
+	require.NoError(t, err)
+
+	var found bool
+	for _, snapshot := range snapshots {
+		if snapshot.AccountID == account.ID {
+			found = true
+			require.Equal(t, account.Balance+10, snapshot.Balance)
+		}
+	}
+	require.True(t, found)
+
+	t.Run("FromSnapshot", check)
+}
+
+func TestSnapshotWriter(t *testing.T) {
+	writer := NewSnapshotWriter(testQueries)
+	writer.BatchSize = 2
+
+	_, err := writer.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	// the day is already snapshotted
+	written, err := writer.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, written)
+}
@@ -0,0 +1,167 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// batchSavepoint isolates a single transfer of a best-effort batch
+const batchSavepoint = "batch_transfer"
+
+type BatchTransfer struct {
+	ToAccountID int64 `json:"to_account_id"`
+	Amount      int64 `json:"amount"`
+}
+
+type BatchTransferTxnParams struct {
+	FromAccountID int64           `json:"from_account_id"`
+	Transfers     []BatchTransfer `json:"transfers"`
+	// AllOrNothing rolls the whole batch back when a transfer fails,
+	// otherwise the failed transfers are skipped and the rest go through
+	AllOrNothing bool `json:"all_or_nothing"`
+	// Sender is the user sending the batch, when set their membership is checked again
+	Sender string `json:"sender"`
+	// CoolingOff is applied to every transfer as for TransferTxn
+	CoolingOff PayeeCoolingOff `json:"cooling_off"`
+}
+
+// BatchTransferResult is the outcome of one transfer of a batch, Err is set when it failed
+type BatchTransferResult struct {
+	Transfer TransferTxnResult `json:"transfer"`
+	Err      error             `json:"-"`
+}
+
+type BatchTransferTxnResult struct {
+	// Results are in the order of the transfers
+	Results []BatchTransferResult `json:"results"`
+}
+
+// BatchTransferError reports the transfer that rolled back an all-or-nothing batch
+type BatchTransferError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchTransferError) Error() string {
+	return fmt.Sprintf("transfer %d: %s", e.Index, e.Err)
+}
+
+func (e *BatchTransferError) Unwrap() error {
+	return e.Err
+}
+
+// BatchTransferTxn sends several transfers from one account in a single transaction.
+// Each transfer goes through the same checks, fees and events as TransferTxn.
+// All the accounts of the batch are locked up front in account ID order, so batches
+// can't deadlock with each other or with single transfers.
+// An all-or-nothing batch fails with a *BatchTransferError for the first transfer that
+// fails; a best-effort batch runs each transfer in a savepoint and reports the ones that
+// failed in their result.
+func (store *SQLStore) BatchTransferTxn(ctx context.Context, args BatchTransferTxnParams) (BatchTransferTxnResult, error) {
+	var result BatchTransferTxnResult
+
+	err := store.execTxn(ctx, func(q *Queries) error {
+		result.Results = make([]BatchTransferResult, len(args.Transfers))
+
+		if err := lockBatchAccounts(ctx, q, args); err != nil {
+			return err
+		}
+		if err := checkBatchSender(ctx, q, args); err != nil {
+			return err
+		}
+
+		for i, item := range args.Transfers {
+			params := TransferTxnParam{
+				FromAccountID: args.FromAccountID,
+				ToAccountID:   item.ToAccountID,
+				Amount:        item.Amount,
+				Sender:        args.Sender,
+				CoolingOff:    args.CoolingOff,
+			}
+
+			if args.AllOrNothing {
+				var err error
+				result.Results[i].Transfer, err = transfer(ctx, q, params, nil)
+				if err != nil {
+					return &BatchTransferError{Index: i, Err: err}
+				}
+				continue
+			}
+
+			itemErr, err := inSavepoint(ctx, q, batchSavepoint, func() error {
+				var err error
+				result.Results[i].Transfer, err = transfer(ctx, q, params, nil)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			if itemErr != nil {
+				result.Results[i] = BatchTransferResult{Err: itemErr}
+			}
+		}
+		return nil
+	})
+
+	return result, journalError(err)
+}
+
+// checkBatchSender checks the sender may still send the largest transfer of the batch
+func checkBatchSender(ctx context.Context, q *Queries, args BatchTransferTxnParams) error {
+	if args.Sender == "" {
+		return nil
+	}
+
+	from, err := q.GetAccount(ctx, args.FromAccountID)
+	if err != nil {
+		return err
+	}
+
+	var largest int64
+	for _, item := range args.Transfers {
+		largest = max(largest, item.Amount)
+	}
+	return checkSender(ctx, q, from, args.Sender, largest)
+}
+
+// lockBatchAccounts takes the limit lock of the sending owner, then locks every
+// account the batch posts to, revenue account included, in account ID order
+func lockBatchAccounts(ctx context.Context, q *Queries, args BatchTransferTxnParams) error {
+	from, err := q.GetAccount(ctx, args.FromAccountID)
+	if err != nil {
+		return err
+	}
+	if err := q.LockTransferLimits(ctx, from.Owner); err != nil {
+		return err
+	}
+
+	revenue, err := revenueAccount(ctx, q, from.Currency)
+	if err != nil {
+		return err
+	}
+
+	ids := []int64{from.ID, revenue.ID}
+	for _, item := range args.Transfers {
+		ids = append(ids, item.ToAccountID)
+	}
+	_, err = q.LockAccounts(ctx, ids)
+	return err
+}
+
+// inSavepoint runs fn in a savepoint of the transaction q runs in. When fn fails only
+// its work is rolled back and its error is returned as fnErr; err is set when the
+// savepoint itself fails and the transaction can't go on.
+func inSavepoint(ctx context.Context, q *Queries, name string, fn func() error) (fnErr error, err error) {
+	if _, err := q.db.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+
+	if fnErr = fn(); fnErr != nil {
+		if _, err := q.db.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); err != nil {
+			return fnErr, fmt.Errorf("%v, rollback to savepoint: %w", fnErr, err)
+		}
+	}
+
+	_, err = q.db.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return fnErr, err
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchTransferTxn(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomTestAccount(t)
+	account2 := createRandomTestAccountIn(t, account1.Currency)
+	account3 := createRandomTestAccountIn(t, account1.Currency)
+
+	getBalance := func(id int64) int64 {
+		account, err := store.GetAccount(context.Background(), id)
+		require.NoError(t, err)
+		return account.Balance
+	}
+
+	// the second transfer is more than the account holds
+	transfers := []BatchTransfer{
+		{ToAccountID: account2.ID, Amount: 10},
+		{ToAccountID: account3.ID, Amount: account1.Balance * 2},
+		{ToAccountID: account3.ID, Amount: 20},
+	}
+
+	_, err := store.BatchTransferTxn(context.Background(), BatchTransferTxnParams{
+		FromAccountID: account1.ID,
+		Transfers:     transfers,
+		AllOrNothing:  true,
+	})
+	var batchErr *BatchTransferError
+	require.ErrorAs(t, err, &batchErr)
+	require.Equal(t, 1, batchErr.Index)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	require.Equal(t, account1.Balance, getBalance(account1.ID))
+	require.Equal(t, account2.Balance, getBalance(account2.ID))
+	require.Equal(t, account3.Balance, getBalance(account3.ID))
+
+	result, err := store.BatchTransferTxn(context.Background(), BatchTransferTxnParams{
+		FromAccountID: account1.ID,
+		Transfers:     transfers,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Results, 3)
+
+	require.NoError(t, result.Results[0].Err)
+	require.ErrorIs(t, result.Results[1].Err, ErrInsufficientFunds)
+	require.Empty(t, result.Results[1].Transfer)
+	require.NoError(t, result.Results[2].Err)
+
+	fees := result.Results[0].Transfer.Fee.Amount + result.Results[2].Transfer.Fee.Amount
+	require.Equal(t, account1.Balance-30-fees, result.Results[2].Transfer.FromAccount.Balance)
+	require.Equal(t, account1.Balance-30-fees, getBalance(account1.ID))
+	require.Equal(t, account2.Balance+10, getBalance(account2.ID))
+	require.Equal(t, account3.Balance+20, getBalance(account3.ID))
+}
+
+func TestBatchTransferTxnDeadlock(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomTestAccount(t)
+	account2 := createRandomTestAccountIn(t, account1.Currency)
+	account3 := createRandomTestAccountIn(t, account1.Currency)
+
+	// batches sending to each other's accounts in opposite orders
+	n := 6
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		from, first, second := account1, account2, account3
+		if i%2 == 1 {
+			from, first, second = account3, account2, account1
+		}
+
+		go func() {
+			_, err := store.BatchTransferTxn(context.Background(), BatchTransferTxnParams{
+				FromAccountID: from.ID,
+				Transfers: []BatchTransfer{
+					{ToAccountID: first.ID, Amount: 1},
+					{ToAccountID: second.ID, Amount: 1},
+				},
+				AllOrNothing: true,
+			})
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+}
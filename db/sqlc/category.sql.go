@@ -0,0 +1,454 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: category.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const categorizeTransferEntries = `-- name: CategorizeTransferEntries :exec
+UPDATE entries e
+SET category_id = (
+    SELECT r.category_id
+    FROM category_rules r
+    JOIN accounts a ON a.owner = r.owner
+    WHERE a.id = e.account_id
+      AND (r.counterparty_account_id IS NULL OR r.counterparty_account_id =
+          CASE WHEN e.account_id = t.from_account_id THEN t.to_account_id ELSE t.from_account_id END)
+      AND (r.memo_keyword IS NULL OR strpos(lower(t.description), lower(r.memo_keyword)) > 0)
+      AND (r.min_amount IS NULL OR t.amount >= r.min_amount)
+      AND (r.max_amount IS NULL OR t.amount <= r.max_amount)
+    ORDER BY r.priority, r.id
+    LIMIT 1
+)
+FROM transfers t
+WHERE t.id = $1
+  AND e.transfer_id = t.id
+  AND e.account_id IN (t.from_account_id, t.to_account_id)
+`
+
+// Sets the category of both sides of a transfer from the first matching rule of each
+// account's owner. Every condition a rule sets must match.
+func (q *Queries) CategorizeTransferEntries(ctx context.Context, transferID int64) error {
+	_, err := q.db.ExecContext(ctx, categorizeTransferEntries, transferID)
+	return err
+}
+
+const createCategory = `-- name: CreateCategory :one
+INSERT INTO categories (
+    owner,
+    name
+) VALUES (
+    $1, $2
+) RETURNING id, owner, name, created_at
+`
+
+type CreateCategoryParams struct {
+	Owner string
+	Name  string
+}
+
+func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error) {
+	row := q.db.QueryRowContext(ctx, createCategory, arg.Owner, arg.Name)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createCategoryRule = `-- name: CreateCategoryRule :one
+INSERT INTO category_rules (
+    owner,
+    category_id,
+    counterparty_account_id,
+    memo_keyword,
+    min_amount,
+    max_amount,
+    priority
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, owner, category_id, counterparty_account_id, memo_keyword, min_amount, max_amount, priority, created_at
+`
+
+type CreateCategoryRuleParams struct {
+	Owner                 string
+	CategoryID            int64
+	CounterpartyAccountID sql.NullInt64
+	MemoKeyword           sql.NullString
+	MinAmount             sql.NullInt64
+	MaxAmount             sql.NullInt64
+	Priority              int32
+}
+
+func (q *Queries) CreateCategoryRule(ctx context.Context, arg CreateCategoryRuleParams) (CategoryRule, error) {
+	row := q.db.QueryRowContext(ctx, createCategoryRule,
+		arg.Owner,
+		arg.CategoryID,
+		arg.CounterpartyAccountID,
+		arg.MemoKeyword,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Priority,
+	)
+	var i CategoryRule
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.CategoryID,
+		&i.CounterpartyAccountID,
+		&i.MemoKeyword,
+		&i.MinAmount,
+		&i.MaxAmount,
+		&i.Priority,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteCategory = `-- name: DeleteCategory :exec
+DELETE FROM categories
+WHERE id = $1
+`
+
+func (q *Queries) DeleteCategory(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteCategory, id)
+	return err
+}
+
+const deleteCategoryRule = `-- name: DeleteCategoryRule :exec
+DELETE FROM category_rules
+WHERE id = $1
+`
+
+func (q *Queries) DeleteCategoryRule(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteCategoryRule, id)
+	return err
+}
+
+const getCategory = `-- name: GetCategory :one
+SELECT id, owner, name, created_at FROM categories
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetCategory(ctx context.Context, id int64) (Category, error) {
+	row := q.db.QueryRowContext(ctx, getCategory, id)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getCategoryRule = `-- name: GetCategoryRule :one
+SELECT id, owner, category_id, counterparty_account_id, memo_keyword, min_amount, max_amount, priority, created_at FROM category_rules
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetCategoryRule(ctx context.Context, id int64) (CategoryRule, error) {
+	row := q.db.QueryRowContext(ctx, getCategoryRule, id)
+	var i CategoryRule
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.CategoryID,
+		&i.CounterpartyAccountID,
+		&i.MemoKeyword,
+		&i.MinAmount,
+		&i.MaxAmount,
+		&i.Priority,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAccountEntries = `-- name: ListAccountEntries :many
+SELECT e.id, e.account_id, e.amount, e.created_at, e.transfer_id, e.journal_transaction_id, e.category_id, e.tags, COALESCE(c.name, '')::varchar AS category_name
+FROM entries e
+LEFT JOIN categories c ON c.id = e.category_id
+WHERE e.account_id = $1
+  AND ($2::bigint IS NULL OR e.category_id = $2)
+  AND ($3::varchar IS NULL OR $3 = ANY(e.tags))
+ORDER BY e.id DESC
+LIMIT $5
+OFFSET $4
+`
+
+type ListAccountEntriesParams struct {
+	AccountID  int64
+	CategoryID sql.NullInt64
+	Tag        sql.NullString
+	Offset     int32
+	Limit      int32
+}
+
+type ListAccountEntriesRow struct {
+	ID                   int64
+	AccountID            int64
+	Amount               int64
+	CreatedAt            time.Time
+	TransferID           sql.NullInt64
+	JournalTransactionID sql.NullInt64
+	CategoryID           sql.NullInt64
+	Tags                 []string
+	CategoryName         string
+}
+
+func (q *Queries) ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]ListAccountEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountEntries,
+		arg.AccountID,
+		arg.CategoryID,
+		arg.Tag,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAccountEntriesRow
+	for rows.Next() {
+		var i ListAccountEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.JournalTransactionID,
+			&i.CategoryID,
+			pq.Array(&i.Tags),
+			&i.CategoryName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategories = `-- name: ListCategories :many
+SELECT id, owner, name, created_at FROM categories
+WHERE owner = $1
+ORDER BY name
+`
+
+func (q *Queries) ListCategories(ctx context.Context, owner string) ([]Category, error) {
+	rows, err := q.db.QueryContext(ctx, listCategories, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Category
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Name,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategoryRules = `-- name: ListCategoryRules :many
+SELECT r.id, r.owner, r.category_id, r.counterparty_account_id, r.memo_keyword, r.min_amount, r.max_amount, r.priority, r.created_at, c.name AS category_name, a.number AS counterparty_account_number
+FROM category_rules r
+JOIN categories c ON c.id = r.category_id
+LEFT JOIN accounts a ON a.id = r.counterparty_account_id
+WHERE r.owner = $1
+ORDER BY r.priority, r.id
+`
+
+type ListCategoryRulesRow struct {
+	ID                        int64
+	Owner                     string
+	CategoryID                int64
+	CounterpartyAccountID     sql.NullInt64
+	MemoKeyword               sql.NullString
+	MinAmount                 sql.NullInt64
+	MaxAmount                 sql.NullInt64
+	Priority                  int32
+	CreatedAt                 time.Time
+	CategoryName              string
+	CounterpartyAccountNumber sql.NullString
+}
+
+func (q *Queries) ListCategoryRules(ctx context.Context, owner string) ([]ListCategoryRulesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCategoryRules, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCategoryRulesRow
+	for rows.Next() {
+		var i ListCategoryRulesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.CategoryID,
+			&i.CounterpartyAccountID,
+			&i.MemoKeyword,
+			&i.MinAmount,
+			&i.MaxAmount,
+			&i.Priority,
+			&i.CreatedAt,
+			&i.CategoryName,
+			&i.CounterpartyAccountNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategorySpending = `-- name: ListCategorySpending :many
+SELECT
+    e.category_id,
+    COALESCE(c.name, '')::varchar AS category_name,
+    (-SUM(e.amount))::bigint AS spent,
+    COUNT(*) AS entries
+FROM entries e
+LEFT JOIN categories c ON c.id = e.category_id
+WHERE e.account_id = $1
+  AND e.amount < 0
+  AND e.created_at >= $2
+  AND e.created_at < $3
+GROUP BY e.category_id, c.name
+ORDER BY spent DESC, category_name
+`
+
+type ListCategorySpendingParams struct {
+	AccountID int64
+	FromTime  time.Time
+	ToTime    time.Time
+}
+
+type ListCategorySpendingRow struct {
+	CategoryID   sql.NullInt64
+	CategoryName string
+	Spent        int64
+	Entries      int64
+}
+
+// Debits of the account over [from_time, to_time) by category, fees included.
+// Uncategorized entries are grouped under a null category_id.
+func (q *Queries) ListCategorySpending(ctx context.Context, arg ListCategorySpendingParams) ([]ListCategorySpendingRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCategorySpending, arg.AccountID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCategorySpendingRow
+	for rows.Next() {
+		var i ListCategorySpendingRow
+		if err := rows.Scan(
+			&i.CategoryID,
+			&i.CategoryName,
+			&i.Spent,
+			&i.Entries,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateEntryCategory = `-- name: UpdateEntryCategory :one
+UPDATE entries
+SET category_id = $2
+WHERE id = $1
+RETURNING id, account_id, amount, created_at, transfer_id, journal_transaction_id, category_id, tags
+`
+
+type UpdateEntryCategoryParams struct {
+	ID         int64
+	CategoryID sql.NullInt64
+}
+
+func (q *Queries) UpdateEntryCategory(ctx context.Context, arg UpdateEntryCategoryParams) (Entry, error) {
+	row := q.db.QueryRowContext(ctx, updateEntryCategory, arg.ID, arg.CategoryID)
+	var i Entry
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.TransferID,
+		&i.JournalTransactionID,
+		&i.CategoryID,
+		pq.Array(&i.Tags),
+	)
+	return i, err
+}
+
+const updateEntryTags = `-- name: UpdateEntryTags :one
+UPDATE entries
+SET tags = $2
+WHERE id = $1
+RETURNING id, account_id, amount, created_at, transfer_id, journal_transaction_id, category_id, tags
+`
+
+type UpdateEntryTagsParams struct {
+	ID   int64
+	Tags []string
+}
+
+func (q *Queries) UpdateEntryTags(ctx context.Context, arg UpdateEntryTagsParams) (Entry, error) {
+	row := q.db.QueryRowContext(ctx, updateEntryTags, arg.ID, pq.Array(arg.Tags))
+	var i Entry
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.TransferID,
+		&i.JournalTransactionID,
+		&i.CategoryID,
+		pq.Array(&i.Tags),
+	)
+	return i, err
+}
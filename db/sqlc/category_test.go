@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategorizeTransferEntries(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomTestAccount(t)
+	account2 := createRandomTestAccountIn(t, account1.Currency)
+
+	groceries, err := testQueries.CreateCategory(context.Background(), CreateCategoryParams{
+		Owner: account1.Owner,
+		Name:  "groceries",
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.CreateCategory(context.Background(), CreateCategoryParams{
+		Owner: account1.Owner,
+		Name:  "groceries",
+	})
+	require.Equal(t, "categories_owner_name_key", ConstraintName(err))
+
+	coffee, err := testQueries.CreateCategory(context.Background(), CreateCategoryParams{
+		Owner: account1.Owner,
+		Name:  "coffee",
+	})
+	require.NoError(t, err)
+
+	// the keyword rule is tried first but only matches small amounts
+	_, err = testQueries.CreateCategoryRule(context.Background(), CreateCategoryRuleParams{
+		Owner:       account1.Owner,
+		CategoryID:  coffee.ID,
+		MemoKeyword: sql.NullString{String: "Latte", Valid: true},
+		MaxAmount:   sql.NullInt64{Int64: 10, Valid: true},
+		Priority:    0,
+	})
+	require.NoError(t, err)
+	_, err = testQueries.CreateCategoryRule(context.Background(), CreateCategoryRuleParams{
+		Owner:                 account1.Owner,
+		CategoryID:            groceries.ID,
+		CounterpartyAccountID: sql.NullInt64{Int64: account2.ID, Valid: true},
+		Priority:              1,
+	})
+	require.NoError(t, err)
+
+	transfer := func(amount int64, description string) TransferTxnResult {
+		result, err := store.TransferTxn(context.Background(), TransferTxnParam{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+			Description:   description,
+		})
+		require.NoError(t, err)
+		return result
+	}
+	category := func(entry Entry) sql.NullInt64 {
+		entry, err := testQueries.GetEntry(context.Background(), entry.ID)
+		require.NoError(t, err)
+		return entry.CategoryID
+	}
+
+	small := transfer(5, "morning latte")
+	require.Equal(t, sql.NullInt64{Int64: coffee.ID, Valid: true}, category(small.FromEntry))
+	// the receiver has no rules
+	require.False(t, category(small.ToEntry).Valid)
+
+	large := transfer(50, "latte machine")
+	require.Equal(t, sql.NullInt64{Int64: groceries.ID, Valid: true}, category(large.FromEntry))
+
+	entries, err := testQueries.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID:  account1.ID,
+		CategoryID: sql.NullInt64{Int64: coffee.ID, Valid: true},
+		Limit:      5,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, small.FromEntry.ID, entries[0].ID)
+	require.Equal(t, "coffee", entries[0].CategoryName)
+
+	// deleting a category uncategorizes its entries and drops its rules
+	require.NoError(t, testQueries.DeleteCategory(context.Background(), coffee.ID))
+	require.False(t, category(small.FromEntry).Valid)
+
+	rules, err := testQueries.ListCategoryRules(context.Background(), account1.Owner)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, groceries.ID, rules[0].CategoryID)
+	require.Equal(t, account2.Number, rules[0].CounterpartyAccountNumber.String)
+}
+
+func TestListCategorySpending(t *testing.T) {
+	account := createRandomTestAccount(t)
+
+	rent, err := testQueries.CreateCategory(context.Background(), CreateCategoryParams{
+		Owner: account.Owner,
+		Name:  "rent",
+	})
+	require.NoError(t, err)
+
+	entry1 := addTestEntry(t, account, -100)
+	entry2 := addTestEntry(t, account, -50)
+	addTestEntry(t, account, -20)
+	addTestEntry(t, account, 500)
+
+	for _, entry := range []Entry{entry1, entry2} {
+		_, err := testQueries.UpdateEntryCategory(context.Background(), UpdateEntryCategoryParams{
+			ID:         entry.ID,
+			CategoryID: sql.NullInt64{Int64: rent.ID, Valid: true},
+		})
+		require.NoError(t, err)
+	}
+
+	tagged, err := testQueries.UpdateEntryTags(context.Background(), UpdateEntryTagsParams{
+		ID:   entry2.ID,
+		Tags: []string{"home", "monthly"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"home", "monthly"}, tagged.Tags)
+
+	rows, err := testQueries.ListCategorySpending(context.Background(), ListCategorySpendingParams{
+		AccountID: account.ID,
+		FromTime:  time.Now().Add(-time.Hour),
+		ToTime:    time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	require.Equal(t, sql.NullInt64{Int64: rent.ID, Valid: true}, rows[0].CategoryID)
+	require.Equal(t, int64(150), rows[0].Spent)
+	require.Equal(t, int64(2), rows[0].Entries)
+	require.False(t, rows[1].CategoryID.Valid)
+	require.Equal(t, int64(20), rows[1].Spent)
+
+	entries, err := testQueries.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID: account.ID,
+		Tag:       sql.NullString{String: "monthly", Valid: true},
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, entry2.ID, entries[0].ID)
+}
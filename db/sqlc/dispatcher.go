@@ -0,0 +1,184 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	defaultDispatchBatchSize    = 100
+	defaultDispatchPollInterval = time.Second
+	defaultDispatchMaxAttempts  = 10
+	defaultPublishTimeout       = 10 * time.Second
+	maxDispatchBackoff          = 10 * time.Minute
+	maxOutboxErrorLength        = 500
+)
+
+// DispatcherConfig tunes a Dispatcher, zero values fall back to the defaults
+type DispatcherConfig struct {
+	BatchSize      int32
+	PollInterval   time.Duration
+	MaxAttempts    int32
+	PublishTimeout time.Duration
+}
+
+// Dispatcher publishes outbox events written by committed transactions.
+// Delivery is at-least-once: an event is marked processed only after the
+// publisher accepted it, so a crash in between publishes it again.
+// Events still failing after MaxAttempts are moved to outbox_dead_letters.
+// Secret payload fields, such as verification codes, are removed from the
+// event before it is marked processed or dead-lettered.
+type Dispatcher struct {
+	store     *SQLStore
+	publisher Publisher
+	config    DispatcherConfig
+	now       func() time.Time
+}
+
+func NewDispatcher(db *sql.DB, publisher Publisher, config DispatcherConfig) *Dispatcher {
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultDispatchBatchSize
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultDispatchPollInterval
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaultDispatchMaxAttempts
+	}
+	if config.PublishTimeout <= 0 {
+		config.PublishTimeout = defaultPublishTimeout
+	}
+
+	return &Dispatcher{
+		store:     &SQLStore{db: db, Queries: New(db)},
+		publisher: publisher,
+		config:    config,
+		now:       time.Now,
+	}
+}
+
+// Start dispatches until ctx is cancelled
+func (dispatcher *Dispatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(dispatcher.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := dispatcher.DispatchOnce(ctx)
+			if err != nil {
+				log.Printf("outbox dispatcher: %s", err)
+			}
+			if err != nil || n < int(dispatcher.config.BatchSize) {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce publishes one batch of due events and reports how many were claimed.
+// Events are claimed with SKIP LOCKED and stay locked until the batch commits,
+// so several dispatchers can run side by side without publishing the same event concurrently.
+func (dispatcher *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	var claimed int
+
+	err := dispatcher.store.execTxn(ctx, func(q *Queries) error {
+		events, err := q.ClaimOutboxEvents(ctx, dispatcher.config.BatchSize)
+		if err != nil {
+			return fmt.Errorf("cannot claim events: %w", err)
+		}
+
+		for _, event := range events {
+			if err := dispatcher.publish(ctx, event); err != nil {
+				if err := dispatcher.retry(ctx, q, event, err); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := redactEvent(ctx, q, event); err != nil {
+				return err
+			}
+			if err := q.MarkOutboxEventProcessed(ctx, event.ID); err != nil {
+				return err
+			}
+		}
+
+		claimed = len(events)
+		return nil
+	})
+
+	return claimed, err
+}
+
+func (dispatcher *Dispatcher) publish(ctx context.Context, event Outbox) error {
+	ctx, cancel := context.WithTimeout(ctx, dispatcher.config.PublishTimeout)
+	defer cancel()
+
+	return dispatcher.publisher.Publish(ctx, event)
+}
+
+// retry schedules the next attempt, or dead-letters the event once it ran out of attempts
+func (dispatcher *Dispatcher) retry(ctx context.Context, q *Queries, event Outbox, publishErr error) error {
+	attempts := event.Attempts + 1
+	lastError := publishErr.Error()
+	if len(lastError) > maxOutboxErrorLength {
+		lastError = lastError[:maxOutboxErrorLength]
+	}
+
+	if attempts >= dispatcher.config.MaxAttempts {
+		log.Printf("outbox dispatcher: dead-lettering event %d after %d attempts: %s", event.ID, attempts, lastError)
+
+		if err := redactEvent(ctx, q, event); err != nil {
+			return err
+		}
+		err := q.DeadLetterOutboxEvent(ctx, DeadLetterOutboxEventParams{
+			ID:        event.ID,
+			Attempts:  attempts,
+			LastError: lastError,
+		})
+		if err != nil {
+			return err
+		}
+		return q.MarkOutboxEventProcessed(ctx, event.ID)
+	}
+
+	return q.RescheduleOutboxEvent(ctx, RescheduleOutboxEventParams{
+		ID:            event.ID,
+		Attempts:      attempts,
+		NextAttemptAt: dispatcher.now().Add(DispatchBackoff(attempts)),
+		LastError:     lastError,
+	})
+}
+
+// redactEvent removes the secret fields of the event's topic from its payload
+func redactEvent(ctx context.Context, q *Queries, event Outbox) error {
+	fields, ok := secretEventFields[event.Topic]
+	if !ok {
+		return nil
+	}
+	return q.RedactOutboxEvent(ctx, RedactOutboxEventParams{
+		ID:     event.ID,
+		Fields: fields,
+	})
+}
+
+// DispatchBackoff returns the delay before the next publish attempt: 1s, 2s, 4s, ... capped at 10m
+func DispatchBackoff(attempts int32) time.Duration {
+	delay := time.Second
+	for i := int32(1); i < attempts && delay < maxDispatchBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxDispatchBackoff {
+		delay = maxDispatchBackoff
+	}
+	return delay
+}
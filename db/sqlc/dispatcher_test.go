@@ -0,0 +1,207 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func dispatchAll(t *testing.T, dispatcher *Dispatcher) {
+	for {
+		n, err := dispatcher.DispatchOnce(context.Background())
+		require.NoError(t, err)
+		if n == 0 {
+			return
+		}
+	}
+}
+
+func TestDispatcherPublishesCommittedEvents(t *testing.T) {
+	store := NewStore(testDB)
+	publisher := &MemoryPublisher{}
+	dispatcher := NewDispatcher(testDB, publisher, DispatcherConfig{})
+
+	account1 := createRandomTestAccount(t)
+	account2 := createRandomTestAccountIn(t, account1.Currency)
+
+	result, err := store.TransferTxn(context.Background(), TransferTxnParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// rolled back transfers must not produce events
+	_, err = store.TransferTxn(context.Background(), TransferTxnParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	dispatchAll(t, dispatcher)
+	// processed events are not published again
+	dispatchAll(t, dispatcher)
+
+	owners := map[string]string{}
+	for _, event := range publisher.Events() {
+		var payload TransferEvent
+		require.NoError(t, json.Unmarshal(event.Payload, &payload))
+		if payload.FromAccount != account1.Number {
+			continue
+		}
+
+		require.Equal(t, result.Transfer.ID, payload.TransferID)
+		require.Equal(t, account2.Number, payload.ToAccount)
+		require.Equal(t, int64(10), payload.Amount)
+		require.NotContains(t, owners, event.Topic)
+		owners[event.Topic] = event.Key
+	}
+
+	require.Equal(t, map[string]string{
+		EventTransferCreated:  account1.Owner,
+		EventTransferReceived: account2.Owner,
+	}, owners)
+}
+
+func TestTransferEventsReachCoOwners(t *testing.T) {
+	store := NewStore(testDB)
+	publisher := &MemoryPublisher{}
+	dispatcher := NewDispatcher(testDB, publisher, DispatcherConfig{})
+	dispatchAll(t, dispatcher)
+
+	from := createRandomTestAccount(t)
+	to := createRandomTestAccountIn(t, from.Currency)
+	addMember := func(role string, accept bool) User {
+		user := createRandomTestUser(t)
+		member, err := testQueries.CreateAccountMember(context.Background(), CreateAccountMemberParams{
+			AccountID: from.ID,
+			Username:  user.Username,
+			Role:      role,
+			InvitedBy: from.Owner,
+		})
+		require.NoError(t, err)
+		if accept {
+			_, err = testQueries.AcceptAccountInvitation(context.Background(), AcceptAccountInvitationParams{
+				ID:       member.ID,
+				Username: user.Username,
+			})
+			require.NoError(t, err)
+		}
+		return user
+	}
+	coOwner := addMember(MemberCoOwner, true)
+	addMember(MemberCoOwner, false)
+	addMember(MemberViewer, true)
+
+	result, err := store.TransferTxn(context.Background(), TransferTxnParam{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        1,
+	})
+	require.NoError(t, err)
+	dispatchAll(t, dispatcher)
+
+	keys := map[string][]string{}
+	for _, event := range publisher.Events() {
+		var payload TransferEvent
+		require.NoError(t, json.Unmarshal(event.Payload, &payload))
+		if payload.TransferID == result.Transfer.ID {
+			keys[event.Topic] = append(keys[event.Topic], event.Key)
+		}
+	}
+
+	require.Equal(t, map[string][]string{
+		EventTransferCreated:  {from.Owner, coOwner.Username},
+		EventTransferReceived: {to.Owner},
+	}, keys)
+}
+
+func TestDispatcherDeadLettersFailingEvents(t *testing.T) {
+	publisher := &MemoryPublisher{}
+	dispatcher := NewDispatcher(testDB, publisher, DispatcherConfig{MaxAttempts: 2})
+	// retries are scheduled in the past so they are due immediately
+	dispatcher.now = func() time.Time { return time.Now().Add(-time.Hour) }
+	dispatchAll(t, dispatcher)
+	published := len(publisher.Events())
+
+	user := createRandomTestUser(t)
+	event, err := testQueries.EnqueueEvent(context.Background(), EventTransferCreated, user.Username, TransferEvent{TransferID: 1})
+	require.NoError(t, err)
+
+	publisher.FailWith(errors.New("broker unavailable"))
+
+	n, err := dispatcher.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	retried, err := testQueries.GetOutboxEvent(context.Background(), event.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), retried.Attempts)
+	require.Equal(t, "broker unavailable", retried.LastError)
+	require.False(t, retried.ProcessedAt.Valid)
+
+	dispatchAll(t, dispatcher)
+
+	deadLettered, err := testQueries.GetOutboxEvent(context.Background(), event.ID)
+	require.NoError(t, err)
+	require.True(t, deadLettered.ProcessedAt.Valid)
+
+	deadLetters, err := testQueries.ListOutboxDeadLetters(context.Background(), ListOutboxDeadLettersParams{
+		Limit:  1000,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+
+	var deadLetter OutboxDeadLetter
+	for _, d := range deadLetters {
+		if d.EventID == event.ID {
+			deadLetter = d
+		}
+	}
+	require.Equal(t, event.Topic, deadLetter.Topic)
+	require.Equal(t, event.Key, deadLetter.Key)
+	require.Equal(t, int32(2), deadLetter.Attempts)
+	require.Equal(t, "broker unavailable", deadLetter.LastError)
+	require.Len(t, publisher.Events(), published)
+}
+
+func TestDispatcherRedactsVerificationCodes(t *testing.T) {
+	store := NewStore(testDB)
+	publisher := &MemoryPublisher{}
+	dispatcher := NewDispatcher(testDB, publisher, DispatcherConfig{})
+	dispatchAll(t, dispatcher)
+
+	user := createRandomTestUser(t)
+	_, err := store.RequestEmailVerificationTxn(context.Background(), user.Username)
+	require.NoError(t, err)
+	dispatchAll(t, dispatcher)
+
+	var published Outbox
+	for _, event := range publisher.Events() {
+		if event.Topic == EventEmailVerification && event.Key == user.Username {
+			published = event
+		}
+	}
+	var payload EmailVerificationEvent
+	require.NoError(t, json.Unmarshal(published.Payload, &payload))
+	require.NotEmpty(t, payload.Code)
+
+	stored, err := testQueries.GetOutboxEvent(context.Background(), published.ID)
+	require.NoError(t, err)
+	require.True(t, stored.ProcessedAt.Valid)
+	var redacted EmailVerificationEvent
+	require.NoError(t, json.Unmarshal(stored.Payload, &redacted))
+	require.Empty(t, redacted.Code)
+	require.Equal(t, user.Email, redacted.Email)
+}
+
+func TestDispatchBackoff(t *testing.T) {
+	require.Equal(t, time.Second, DispatchBackoff(1))
+	require.Equal(t, 4*time.Second, DispatchBackoff(3))
+	require.Equal(t, maxDispatchBackoff, DispatchBackoff(30))
+}
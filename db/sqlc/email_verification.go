@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EmailVerificationTTL is how long a verification code can be used for
+const EmailVerificationTTL = 24 * time.Hour
+
+// EmailVerificationEvent is the payload of user.email_verification, for the mail
+// service to send the code to Email
+type EmailVerificationEvent struct {
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RequestEmailVerificationTxn creates a verification code for the user's email and
+// enqueues it for the mail service. email_verifications only stores its hash, the
+// outbox event holds the code until it is dispatched. Earlier codes stay valid until
+// they expire.
+func (store *SQLStore) RequestEmailVerificationTxn(ctx context.Context, username string) (EmailVerification, error) {
+	var result EmailVerification
+
+	err := store.execTxn(ctx, func(q *Queries) error {
+		user, err := q.GetUser(ctx, username)
+		if err != nil {
+			return err
+		}
+		if user.EmailVerified {
+			return ErrEmailAlreadyVerified
+		}
+
+		code, err := newVerificationCode()
+		if err != nil {
+			return err
+		}
+
+		result, err = q.CreateEmailVerification(ctx, CreateEmailVerificationParams{
+			Username:  user.Username,
+			Email:     user.Email,
+			CodeHash:  hashVerificationCode(code),
+			ExpiresAt: time.Now().Add(EmailVerificationTTL),
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.EnqueueEvent(ctx, EventEmailVerification, user.Username, EmailVerificationEvent{
+			Username:  user.Username,
+			Email:     user.Email,
+			Code:      code,
+			ExpiresAt: result.ExpiresAt,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+type VerifyEmailTxnParams struct {
+	Username string `json:"username"`
+	Code     string `json:"code"`
+}
+
+// VerifyEmailTxn marks the user's email verified with a code sent to it and uses the
+// code up. Codes sent to an address the user has changed since are refused.
+func (store *SQLStore) VerifyEmailTxn(ctx context.Context, args VerifyEmailTxnParams) (User, error) {
+	var result User
+
+	err := store.execTxn(ctx, func(q *Queries) error {
+		verification, err := q.UseEmailVerification(ctx, UseEmailVerificationParams{
+			Username: args.Username,
+			CodeHash: hashVerificationCode(args.Code),
+		})
+		if err != nil {
+			return err
+		}
+
+		result, err = q.VerifyUserEmail(ctx, VerifyUserEmailParams{
+			Username: verification.Username,
+			Email:    verification.Email,
+		})
+		return err
+	})
+	if errors.Is(err, ErrRecordNotFound) {
+		return result, ErrInvalidVerificationCode
+	}
+
+	return result, err
+}
+
+// newVerificationCode returns a random code, long enough that it can't be guessed
+func newVerificationCode() (string, error) {
+	code := make([]byte, 16)
+	if _, err := rand.Read(code); err != nil {
+		return "", fmt.Errorf("cannot generate verification code: %w", err)
+	}
+	return hex.EncodeToString(code), nil
+}
+
+func hashVerificationCode(code string) string {
+	hash := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(hash[:])
+}
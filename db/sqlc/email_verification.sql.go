@@ -0,0 +1,81 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: email_verification.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createEmailVerification = `-- name: CreateEmailVerification :one
+INSERT INTO email_verifications (
+    username,
+    email,
+    code_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING id, username, email, code_hash, used_at, expires_at, created_at
+`
+
+type CreateEmailVerificationParams struct {
+	Username  string
+	Email     string
+	CodeHash  string
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateEmailVerification(ctx context.Context, arg CreateEmailVerificationParams) (EmailVerification, error) {
+	row := q.db.QueryRowContext(ctx, createEmailVerification,
+		arg.Username,
+		arg.Email,
+		arg.CodeHash,
+		arg.ExpiresAt,
+	)
+	var i EmailVerification
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.CodeHash,
+		&i.UsedAt,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useEmailVerification = `-- name: UseEmailVerification :one
+UPDATE email_verifications
+SET used_at = now()
+WHERE username = $1
+  AND code_hash = $2
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING id, username, email, code_hash, used_at, expires_at, created_at
+`
+
+type UseEmailVerificationParams struct {
+	Username string
+	CodeHash string
+}
+
+// Marks an unused, unexpired code as used, so each code verifies at most once.
+func (q *Queries) UseEmailVerification(ctx context.Context, arg UseEmailVerificationParams) (EmailVerification, error) {
+	row := q.db.QueryRowContext(ctx, useEmailVerification, arg.Username, arg.CodeHash)
+	var i EmailVerification
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.CodeHash,
+		&i.UsedAt,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
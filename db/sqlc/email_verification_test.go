@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createTestEmailVerification(t *testing.T, user User, code string, expiresAt time.Time) EmailVerification {
+	verification, err := testQueries.CreateEmailVerification(context.Background(), CreateEmailVerificationParams{
+		Username:  user.Username,
+		Email:     user.Email,
+		CodeHash:  hashVerificationCode(code),
+		ExpiresAt: expiresAt,
+	})
+	require.NoError(t, err)
+	return verification
+}
+
+func TestRequestEmailVerificationTxn(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomTestUser(t)
+
+	verification, err := store.RequestEmailVerificationTxn(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, verification.Username)
+	require.Equal(t, user.Email, verification.Email)
+	require.Len(t, verification.CodeHash, 64)
+	require.False(t, verification.UsedAt.Valid)
+	require.WithinDuration(t, time.Now().Add(EmailVerificationTTL), verification.ExpiresAt, time.Minute)
+
+	_, err = testQueries.VerifyUserEmail(context.Background(), VerifyUserEmailParams{
+		Username: user.Username,
+		Email:    user.Email,
+	})
+	require.NoError(t, err)
+
+	_, err = store.RequestEmailVerificationTxn(context.Background(), user.Username)
+	require.ErrorIs(t, err, ErrEmailAlreadyVerified)
+}
+
+func TestVerifyEmailTxn(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomTestUser(t)
+	code, err := newVerificationCode()
+	require.NoError(t, err)
+
+	createTestEmailVerification(t, user, code, time.Now().Add(-time.Minute))
+	_, err = store.VerifyEmailTxn(context.Background(), VerifyEmailTxnParams{Username: user.Username, Code: code})
+	require.ErrorIs(t, err, ErrInvalidVerificationCode)
+
+	createTestEmailVerification(t, user, code, time.Now().Add(time.Hour))
+
+	// codes only verify the user they were sent to
+	other := createRandomTestUser(t)
+	_, err = store.VerifyEmailTxn(context.Background(), VerifyEmailTxnParams{Username: other.Username, Code: code})
+	require.ErrorIs(t, err, ErrInvalidVerificationCode)
+
+	verified, err := store.VerifyEmailTxn(context.Background(), VerifyEmailTxnParams{Username: user.Username, Code: code})
+	require.NoError(t, err)
+	require.True(t, verified.EmailVerified)
+
+	// each code verifies once
+	_, err = store.VerifyEmailTxn(context.Background(), VerifyEmailTxnParams{Username: user.Username, Code: code})
+	require.ErrorIs(t, err, ErrInvalidVerificationCode)
+}
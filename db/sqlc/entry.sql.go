@@ -7,38 +7,57 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
     account_id,
-    amount
+    amount,
+    transfer_id,
+    journal_transaction_id
 ) VALUES (
     $1,
-    $2
+    $2,
+    $3,
+    $4
 )
-RETURNING id, account_id, amount, created_at
+RETURNING id, account_id, amount, created_at, transfer_id, journal_transaction_id, category_id, tags
 `
 
 type CreateEntryParams struct {
-	AccountID int64
-	Amount    int64
+	AccountID            int64
+	Amount               int64
+	TransferID           sql.NullInt64
+	JournalTransactionID sql.NullInt64
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.TransferID,
+		arg.JournalTransactionID,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.JournalTransactionID,
+		&i.CategoryID,
+		pq.Array(&i.Tags),
 	)
 	return i, err
 }
 
 const getEntries = `-- name: GetEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, journal_transaction_id, category_id, tags FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
@@ -65,6 +84,10 @@ func (q *Queries) GetEntries(ctx context.Context, arg GetEntriesParams) ([]Entry
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.JournalTransactionID,
+			&i.CategoryID,
+			pq.Array(&i.Tags),
 		); err != nil {
 			return nil, err
 		}
@@ -80,7 +103,7 @@ func (q *Queries) GetEntries(ctx context.Context, arg GetEntriesParams) ([]Entry
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, journal_transaction_id, category_id, tags FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -92,6 +115,116 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.JournalTransactionID,
+		&i.CategoryID,
+		pq.Array(&i.Tags),
 	)
 	return i, err
 }
+
+const getStatementBalances = `-- name: GetStatementBalances :one
+SELECT
+    (a.balance - COALESCE(SUM(e.amount) FILTER (WHERE e.created_at >= $1), 0))::bigint AS opening_balance,
+    (a.balance - COALESCE(SUM(e.amount) FILTER (WHERE e.created_at >= $2), 0))::bigint AS closing_balance
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+WHERE a.id = $3
+GROUP BY a.id
+`
+
+type GetStatementBalancesParams struct {
+	FromTime  time.Time
+	ToTime    time.Time
+	AccountID int64
+}
+
+type GetStatementBalancesRow struct {
+	OpeningBalance int64
+	ClosingBalance int64
+}
+
+func (q *Queries) GetStatementBalances(ctx context.Context, arg GetStatementBalancesParams) (GetStatementBalancesRow, error) {
+	row := q.db.QueryRowContext(ctx, getStatementBalances, arg.FromTime, arg.ToTime, arg.AccountID)
+	var i GetStatementBalancesRow
+	err := row.Scan(&i.OpeningBalance, &i.ClosingBalance)
+	return i, err
+}
+
+const listStatementLines = `-- name: ListStatementLines :many
+SELECT
+    e.id,
+    e.amount,
+    e.created_at,
+    e.transfer_id,
+    COALESCE(t.description, '')::varchar AS description,
+    COALESCE(t.reference, '')::varchar AS reference,
+    c.number AS counterparty_number,
+    c.owner AS counterparty_owner
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN accounts c ON c.id = CASE WHEN t.from_account_id = e.account_id THEN t.to_account_id ELSE t.from_account_id END
+WHERE e.account_id = $1
+    AND e.created_at >= $2
+    AND e.created_at < $3
+    AND e.id > $4
+ORDER BY e.id
+LIMIT $5
+`
+
+type ListStatementLinesParams struct {
+	AccountID int64
+	FromTime  time.Time
+	ToTime    time.Time
+	AfterID   int64
+	MaxLines  int32
+}
+
+type ListStatementLinesRow struct {
+	ID                 int64
+	Amount             int64
+	CreatedAt          time.Time
+	TransferID         sql.NullInt64
+	Description        string
+	Reference          string
+	CounterpartyNumber sql.NullString
+	CounterpartyOwner  sql.NullString
+}
+
+func (q *Queries) ListStatementLines(ctx context.Context, arg ListStatementLinesParams) ([]ListStatementLinesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listStatementLines,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.AfterID,
+		arg.MaxLines,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListStatementLinesRow
+	for rows.Next() {
+		var i ListStatementLinesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.Description,
+			&i.Reference,
+			&i.CounterpartyNumber,
+			&i.CounterpartyOwner,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
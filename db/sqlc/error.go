@@ -0,0 +1,70 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+const (
+	ForeignKeyViolation = "foreign_key_violation"
+	UniqueViolation     = "unique_violation"
+
+	// AccountNumberKey is violated when a random account number is already taken
+	AccountNumberKey = "accounts_number_key"
+)
+
+var (
+	ErrRecordNotFound = sql.ErrNoRows
+	// ErrInsufficientFunds is returned when a transfer, hold or fee would take the available
+	// balance of an account below the overdraft of its product
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrUnbalancedJournal is returned when postings don't sum to zero in every currency
+	ErrUnbalancedJournal = errors.New("journal transaction postings don't sum to zero")
+	// ErrLimitExceeded is matched by the *LimitError of a transfer or hold over a limit of
+	// the sender's tier or product
+	ErrLimitExceeded = errors.New("transfer limit exceeded")
+	// ErrHoldNotPending is returned when capturing or voiding a settled hold
+	ErrHoldNotPending = errors.New("hold is no longer pending")
+	// ErrHoldExpired is returned when capturing or voiding a hold the expirer hasn't swept yet
+	ErrHoldExpired = errors.New("hold has expired")
+	// ErrCaptureExceedsHold is returned when capturing more than the held amount
+	ErrCaptureExceedsHold = errors.New("capture amount exceeds the hold")
+	// ErrSenderNotAllowed is returned when the member sending from an account was removed
+	// from it while their request was in flight
+	ErrSenderNotAllowed = errors.New("user may no longer send from the account")
+	// ErrPayeeCoolingOff is matched by the *CoolingOffError of a transfer or hold above the
+	// cooling-off limit to a payee the sender saved recently
+	ErrPayeeCoolingOff = errors.New("payee is still cooling off")
+	// ErrOwnAccountsOnly is returned when the sender's product doesn't send to other owners
+	ErrOwnAccountsOnly = errors.New("account product only allows transfers to the owner's accounts")
+
+	// ErrPaymentRequestNotPending is returned when responding to a settled payment request
+	ErrPaymentRequestNotPending = errors.New("payment request is no longer pending")
+	// ErrPaymentRequestExpired is returned when paying a request the expirer hasn't swept yet
+	ErrPaymentRequestExpired = errors.New("payment request has expired")
+
+	// ErrEmailAlreadyVerified is returned when requesting a code for a verified email
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+	// ErrInvalidVerificationCode is returned for unknown, used or expired codes
+	ErrInvalidVerificationCode = errors.New("verification code is invalid or has expired")
+)
+
+// ErrorCode returns the postgres condition name of err, or "" when err doesn't come from postgres
+func ErrorCode(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code.Name()
+	}
+	return ""
+}
+
+// ConstraintName returns the name of the constraint violated by err, if any
+func ConstraintName(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Constraint
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Event types written to the outbox
+const (
+	EventTransferCreated  = "transfer.created"
+	EventTransferReceived = "transfer.received"
+)
+
+// EventEmailVerification carries a verification code for the mail service. Clients
+// can't subscribe to it, webhooks only get EventTypes.
+const EventEmailVerification = "user.email_verification"
+
+// secretEventFields are removed from the payload of an event once it was published or
+// dead-lettered, so they only stay in the outbox until the publisher has them
+var secretEventFields = map[string][]string{
+	EventEmailVerification: {"code"},
+}
+
+// EventTypes lists the event types clients can subscribe to
+var EventTypes = []string{EventTransferCreated, EventTransferReceived}
+
+func IsEventType(eventType string) bool {
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// TransferEvent is the payload of transfer.created and transfer.received
+type TransferEvent struct {
+	TransferID  int64     `json:"transfer_id"`
+	FromAccount string    `json:"from_account"`
+	ToAccount   string    `json:"to_account"`
+	Amount      int64     `json:"amount"`
+	Currency    string    `json:"currency"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// EnqueueEvent writes an event to the outbox. Called on the Queries of a
+// transaction, the event is only dispatched if that transaction commits.
+func (q *Queries) EnqueueEvent(ctx context.Context, topic string, key string, payload any) (Outbox, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Outbox{}, fmt.Errorf("cannot encode %s event: %w", topic, err)
+	}
+
+	return q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		Topic:   topic,
+		Key:     key,
+		Payload: data,
+	})
+}
+
+func enqueueTransferEvents(ctx context.Context, q *Queries, result TransferTxnResult) error {
+	event := TransferEvent{
+		TransferID:  result.Transfer.ID,
+		FromAccount: result.FromAccount.Number,
+		ToAccount:   result.ToAccount.Number,
+		Amount:      result.Transfer.Amount,
+		Currency:    result.FromAccount.Currency,
+		Description: result.Transfer.Description,
+		CreatedAt:   result.Transfer.CreatedAt,
+	}
+
+	if err := enqueueAccountEvent(ctx, q, EventTransferCreated, result.FromAccount, event); err != nil {
+		return err
+	}
+	return enqueueAccountEvent(ctx, q, EventTransferReceived, result.ToAccount, event)
+}
+
+// enqueueAccountEvent writes one event per owner and active co-owner of the account,
+// keyed to their username so each of their webhooks gets it
+func enqueueAccountEvent(ctx context.Context, q *Queries, topic string, account Account, payload any) error {
+	coOwners, err := q.ListActiveCoOwners(ctx, account.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, username := range append([]string{account.Owner}, coOwners...) {
+		if _, err := q.EnqueueEvent(ctx, topic, username, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
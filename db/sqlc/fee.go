@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"simple-bank/utils"
+)
+
+// Fee rule kinds
+const (
+	FeeFlat       = "flat"
+	FeePercentage = "percentage"
+	FeeTiered     = "tiered"
+)
+
+// SystemUsername owns the bank's own accounts, such as fee revenue
+const SystemUsername = "simple-bank"
+
+const basisPointsPerUnit = 10000
+
+// FeeTier is a band of a tiered fee rule, UpTo 0 is unbounded
+type FeeTier struct {
+	UpTo        int64 `json:"up_to"`
+	FlatFee     int64 `json:"flat_fee"`
+	BasisPoints int32 `json:"basis_points"`
+}
+
+// FeeQuote is the fee charged on a transfer and the rule that set it.
+// RuleID is 0 when no rule applies.
+type FeeQuote struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	RuleID   int64  `json:"rule_id"`
+}
+
+// Matches reports whether the rule applies to a transfer of amount
+func (rule FeeRule) Matches(amount int64, crossUser bool) bool {
+	if rule.CrossUserOnly && !crossUser {
+		return false
+	}
+	return amount >= rule.MinAmount
+}
+
+// Compute returns the fee of the rule for a transfer of amount, within its min and max caps
+func (rule FeeRule) Compute(amount int64) (int64, error) {
+	var fee int64
+
+	switch rule.Kind {
+	case FeeFlat:
+		fee = rule.FlatFee
+	case FeePercentage:
+		fee = rule.FlatFee + percentOf(amount, rule.BasisPoints)
+	case FeeTiered:
+		var tiers []FeeTier
+		if err := json.Unmarshal(rule.Tiers, &tiers); err != nil {
+			return 0, fmt.Errorf("fee rule %d has invalid tiers: %w", rule.ID, err)
+		}
+		for _, tier := range tiers {
+			if tier.UpTo == 0 || amount <= tier.UpTo {
+				fee = tier.FlatFee + percentOf(amount, tier.BasisPoints)
+				break
+			}
+		}
+	default:
+		return 0, fmt.Errorf("fee rule %d has unknown kind %q", rule.ID, rule.Kind)
+	}
+
+	if fee < rule.MinFee {
+		fee = rule.MinFee
+	}
+	if rule.MaxFee > 0 && fee > rule.MaxFee {
+		fee = rule.MaxFee
+	}
+	return fee, nil
+}
+
+// percentOf returns basisPoints of amount, rounded half up
+func percentOf(amount int64, basisPoints int32) int64 {
+	return (amount*int64(basisPoints) + basisPointsPerUnit/2) / basisPointsPerUnit
+}
+
+// QuoteFee returns the fee of the first rule matching the transfer.
+// rules must be ordered by priority, as ListActiveFeeRules returns them.
+func QuoteFee(rules []FeeRule, amount int64, crossUser bool) (FeeQuote, error) {
+	for _, rule := range rules {
+		if !rule.Matches(amount, crossUser) {
+			continue
+		}
+
+		fee, err := rule.Compute(amount)
+		if err != nil {
+			return FeeQuote{}, err
+		}
+		return FeeQuote{Amount: fee, RuleID: rule.ID}, nil
+	}
+
+	return FeeQuote{}, nil
+}
+
+// QuoteTransferFee returns the fee charged for moving amount between two accounts
+// in the currency of the sender
+func QuoteTransferFee(ctx context.Context, q Querier, from Account, to Account, amount int64) (FeeQuote, error) {
+	rules, err := q.ListActiveFeeRules(ctx, ListActiveFeeRulesParams{
+		Currency: from.Currency,
+		Product:  sql.NullString{String: from.Product, Valid: true},
+	})
+	if err != nil {
+		return FeeQuote{}, fmt.Errorf("cannot list fee rules: %w", err)
+	}
+
+	quote, err := QuoteFee(rules, amount, from.Owner != to.Owner)
+	if err != nil {
+		return FeeQuote{}, err
+	}
+
+	quote.Currency = from.Currency
+	return quote, nil
+}
+
+// revenueAccount returns the system account fees in currency are credited to,
+// opening it the first time a fee is charged in that currency
+func revenueAccount(ctx context.Context, q *Queries, currency string) (Account, error) {
+	return systemAccount(ctx, q, SystemUsername, currency)
+}
+
+// systemAccount returns the account of the system user owner in currency, opening it
+// the first time it's needed
+func systemAccount(ctx context.Context, q *Queries, owner string, currency string) (Account, error) {
+	account, err := q.GetAccountByOwnerCurrency(ctx, GetAccountByOwnerCurrencyParams{
+		Owner:    owner,
+		Currency: currency,
+	})
+	if !errors.Is(err, ErrRecordNotFound) {
+		return account, err
+	}
+
+	number, err := utils.NewAccountNumber()
+	if err != nil {
+		return account, err
+	}
+
+	err = q.CreateSystemAccount(ctx, CreateSystemAccountParams{
+		Owner:    owner,
+		Currency: currency,
+		Number:   number,
+	})
+	if err != nil {
+		return account, err
+	}
+
+	return q.GetAccountByOwnerCurrency(ctx, GetAccountByOwnerCurrencyParams{
+		Owner:    owner,
+		Currency: currency,
+	})
+}
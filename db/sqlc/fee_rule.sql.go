@@ -0,0 +1,145 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: fee_rule.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+const createFeeRule = `-- name: CreateFeeRule :one
+INSERT INTO fee_rules (
+    name,
+    currency,
+    kind,
+    priority,
+    cross_user_only,
+    min_amount,
+    flat_fee,
+    basis_points,
+    tiers,
+    min_fee,
+    max_fee,
+    product
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+RETURNING id, name, currency, kind, priority, cross_user_only, min_amount, flat_fee, basis_points, tiers, min_fee, max_fee, active, created_at, product
+`
+
+type CreateFeeRuleParams struct {
+	Name          string
+	Currency      string
+	Kind          string
+	Priority      int32
+	CrossUserOnly bool
+	MinAmount     int64
+	FlatFee       int64
+	BasisPoints   int32
+	Tiers         json.RawMessage
+	MinFee        int64
+	MaxFee        int64
+	Product       sql.NullString
+}
+
+func (q *Queries) CreateFeeRule(ctx context.Context, arg CreateFeeRuleParams) (FeeRule, error) {
+	row := q.db.QueryRowContext(ctx, createFeeRule,
+		arg.Name,
+		arg.Currency,
+		arg.Kind,
+		arg.Priority,
+		arg.CrossUserOnly,
+		arg.MinAmount,
+		arg.FlatFee,
+		arg.BasisPoints,
+		arg.Tiers,
+		arg.MinFee,
+		arg.MaxFee,
+		arg.Product,
+	)
+	var i FeeRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Currency,
+		&i.Kind,
+		&i.Priority,
+		&i.CrossUserOnly,
+		&i.MinAmount,
+		&i.FlatFee,
+		&i.BasisPoints,
+		&i.Tiers,
+		&i.MinFee,
+		&i.MaxFee,
+		&i.Active,
+		&i.CreatedAt,
+		&i.Product,
+	)
+	return i, err
+}
+
+const deactivateFeeRule = `-- name: DeactivateFeeRule :exec
+UPDATE fee_rules
+SET active = false
+WHERE id = $1
+`
+
+func (q *Queries) DeactivateFeeRule(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deactivateFeeRule, id)
+	return err
+}
+
+const listActiveFeeRules = `-- name: ListActiveFeeRules :many
+SELECT id, name, currency, kind, priority, cross_user_only, min_amount, flat_fee, basis_points, tiers, min_fee, max_fee, active, created_at, product FROM fee_rules
+WHERE currency = $1 AND (product IS NULL OR product = $2) AND active
+ORDER BY priority, id
+`
+
+type ListActiveFeeRulesParams struct {
+	Currency string
+	Product  sql.NullString
+}
+
+// The active rules of the currency that apply to senders of the product.
+func (q *Queries) ListActiveFeeRules(ctx context.Context, arg ListActiveFeeRulesParams) ([]FeeRule, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveFeeRules, arg.Currency, arg.Product)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FeeRule
+	for rows.Next() {
+		var i FeeRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Currency,
+			&i.Kind,
+			&i.Priority,
+			&i.CrossUserOnly,
+			&i.MinAmount,
+			&i.FlatFee,
+			&i.BasisPoints,
+			&i.Tiers,
+			&i.MinFee,
+			&i.MaxFee,
+			&i.Active,
+			&i.CreatedAt,
+			&i.Product,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
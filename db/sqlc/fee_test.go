@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeRuleCompute(t *testing.T) {
+	tiers := json.RawMessage(`[
+		{"up_to": 10000, "flat_fee": 10, "basis_points": 0},
+		{"up_to": 100000, "flat_fee": 0, "basis_points": 50},
+		{"up_to": 0, "flat_fee": 0, "basis_points": 25}
+	]`)
+
+	testCases := []struct {
+		name   string
+		rule   FeeRule
+		amount int64
+		fee    int64
+	}{
+		{name: "Flat", rule: FeeRule{Kind: FeeFlat, FlatFee: 30}, amount: 1000, fee: 30},
+		{name: "Percentage", rule: FeeRule{Kind: FeePercentage, BasisPoints: 125}, amount: 1000, fee: 13},
+		{name: "PercentagePlusFlat", rule: FeeRule{Kind: FeePercentage, FlatFee: 5, BasisPoints: 100}, amount: 1000, fee: 15},
+		{name: "MinFee", rule: FeeRule{Kind: FeePercentage, BasisPoints: 100, MinFee: 50}, amount: 1000, fee: 50},
+		{name: "MaxFee", rule: FeeRule{Kind: FeePercentage, BasisPoints: 100, MaxFee: 500}, amount: 1000000, fee: 500},
+		{name: "FirstTier", rule: FeeRule{Kind: FeeTiered, Tiers: tiers}, amount: 10000, fee: 10},
+		{name: "SecondTier", rule: FeeRule{Kind: FeeTiered, Tiers: tiers}, amount: 20000, fee: 100},
+		{name: "UnboundedTier", rule: FeeRule{Kind: FeeTiered, Tiers: tiers}, amount: 400000, fee: 1000},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fee, err := tc.rule.Compute(tc.amount)
+			require.NoError(t, err)
+			require.Equal(t, tc.fee, fee)
+		})
+	}
+
+	_, err := FeeRule{Kind: "waived"}.Compute(1000)
+	require.Error(t, err)
+}
+
+func TestQuoteFee(t *testing.T) {
+	rules := []FeeRule{
+		{ID: 1, Kind: FeeFlat, FlatFee: 100, MinAmount: 5000},
+		{ID: 2, Kind: FeeFlat, FlatFee: 10, CrossUserOnly: true},
+	}
+
+	testCases := []struct {
+		name      string
+		amount    int64
+		crossUser bool
+		quote     FeeQuote
+	}{
+		{name: "AboveThreshold", amount: 5000, quote: FeeQuote{Amount: 100, RuleID: 1}},
+		{name: "CrossUser", amount: 100, crossUser: true, quote: FeeQuote{Amount: 10, RuleID: 2}},
+		{name: "Free", amount: 100, quote: FeeQuote{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quote, err := QuoteFee(rules, tc.amount, tc.crossUser)
+			require.NoError(t, err)
+			require.Equal(t, tc.quote, quote)
+		})
+	}
+}
+
+func TestTransferTxnFee(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomTestAccount(t)
+	account2 := createRandomTestAccountIn(t, account1.Currency)
+
+	rule, err := testQueries.CreateFeeRule(context.Background(), CreateFeeRuleParams{
+		Name:          "cross user",
+		Currency:      account1.Currency,
+		Kind:          FeeFlat,
+		Priority:      -1,
+		CrossUserOnly: true,
+		FlatFee:       3,
+		Tiers:         json.RawMessage("[]"),
+	})
+	require.NoError(t, err)
+	// other transfer tests expect no fee
+	t.Cleanup(func() {
+		require.NoError(t, testQueries.DeactivateFeeRule(context.Background(), rule.ID))
+	})
+
+	revenueBefore, err := revenueAccount(context.Background(), testQueries, account1.Currency)
+	require.NoError(t, err)
+	require.Equal(t, SystemUsername, revenueBefore.Owner)
+
+	result, err := store.TransferTxn(context.Background(), TransferTxnParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, FeeQuote{Amount: 3, Currency: account1.Currency, RuleID: rule.ID}, result.Fee)
+	require.Equal(t, int64(3), result.Transfer.Fee)
+	require.Equal(t, int64(-13), result.FromEntry.Amount)
+	require.Equal(t, int64(10), result.ToEntry.Amount)
+	require.Equal(t, account1.Balance-13, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+10, result.ToAccount.Balance)
+
+	require.Equal(t, revenueBefore.ID, result.FeeEntry.AccountID)
+	require.Equal(t, int64(3), result.FeeEntry.Amount)
+	require.Equal(t, result.FromEntry.JournalTransactionID, result.FeeEntry.JournalTransactionID)
+
+	revenueAfter, err := testQueries.GetAccount(context.Background(), revenueBefore.ID)
+	require.NoError(t, err)
+	require.Equal(t, revenueBefore.Balance+3, revenueAfter.Balance)
+
+	// the fee counts towards the funds needed
+	_, err = store.TransferTxn(context.Background(), TransferTxnParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        result.FromAccount.Balance - 2,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
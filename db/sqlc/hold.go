@@ -0,0 +1,306 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sort"
+	"time"
+)
+
+// Hold statuses
+const (
+	HoldPending  = "pending"
+	HoldCaptured = "captured"
+	HoldVoided   = "voided"
+	HoldExpired  = "expired"
+)
+
+const (
+	defaultExpireBatchSize    = 100
+	defaultExpirePollInterval = time.Minute
+)
+
+// AvailableBalance is what the account can still spend: its balance minus pending holds
+func (account Account) AvailableBalance() int64 {
+	return account.Balance - account.HeldBalance
+}
+
+// Reserved is what the hold takes off the available balance: its amount and fee
+func (hold Hold) Reserved() int64 {
+	return hold.Amount + hold.Fee
+}
+
+type PlaceHoldTxnParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	// Sender is the user placing the hold, when set their membership is checked again
+	Sender string `json:"sender"`
+	// CoolingOff is applied as for TransferTxn
+	CoolingOff PayeeCoolingOff `json:"cooling_off"`
+}
+
+type PlaceHoldTxnResult struct {
+	Hold    Hold     `json:"hold"`
+	Account Account  `json:"account"`
+	Fee     FeeQuote `json:"fee"`
+}
+
+// PlaceHoldTxn reserves amount and the transfer fee on an account until the hold is
+// captured, voided or expires. The ledger balance is untouched, only the available
+// balance goes down, and the new held balance is sent on AccountChangesChannel once it
+// commits. The hold goes through the recipient and limit checks of TransferTxn, and
+// counts towards the limits until it is settled, so capturing it doesn't check them
+// or the payee cooling-off again.
+func (store *SQLStore) PlaceHoldTxn(ctx context.Context, args PlaceHoldTxnParams) (PlaceHoldTxnResult, error) {
+	var result PlaceHoldTxnResult
+
+	err := store.execTxn(ctx, func(q *Queries) error {
+		from, err := q.GetAccount(ctx, args.FromAccountID)
+		if err != nil {
+			return err
+		}
+		to, err := q.GetAccount(ctx, args.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkSender(ctx, q, from, args.Sender, args.Amount); err != nil {
+			return err
+		}
+		if err := checkTransferRecipient(ctx, q, from, to); err != nil {
+			return err
+		}
+		if err := CheckPayeeCoolingOff(ctx, q, sender(from, args.Sender), to, args.Amount, args.CoolingOff); err != nil {
+			return err
+		}
+		// the limit lock is taken before the account row is
+		if err := checkTransferLimits(ctx, q, from, args.Amount); err != nil {
+			return err
+		}
+
+		result.Fee, err = QuoteTransferFee(ctx, q, from, to, args.Amount)
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			ID:     args.FromAccountID,
+			Amount: args.Amount + result.Fee.Amount,
+		})
+		if err != nil {
+			return err
+		}
+		if err := checkOverdraft(ctx, q, result.Account); err != nil {
+			return err
+		}
+
+		result.Hold, err = q.CreateHold(ctx, CreateHoldParams{
+			AccountID:   args.FromAccountID,
+			ToAccountID: args.ToAccountID,
+			Amount:      args.Amount,
+			Fee:         result.Fee.Amount,
+			ExpiresAt:   args.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		return publishAccountChange(ctx, q, result.Account, Entry{})
+	})
+
+	return result, err
+}
+
+type CaptureHoldTxnParams struct {
+	HoldID int64 `json:"hold_id"`
+	// Amount to capture, the whole hold when 0
+	Amount int64 `json:"amount"`
+	// Sender is set when the payer captures, their membership is checked again
+	Sender string `json:"sender"`
+}
+
+type CaptureHoldTxnResult struct {
+	Hold     Hold              `json:"hold"`
+	Transfer TransferTxnResult `json:"transfer"`
+}
+
+// CaptureHoldTxn settles a pending hold with a transfer of up to the held amount,
+// the rest of the hold and its reserved fee are released. The transfer is booked with
+// the fees and events of TransferTxn; the limits were checked when the hold was placed.
+func (store *SQLStore) CaptureHoldTxn(ctx context.Context, args CaptureHoldTxnParams) (CaptureHoldTxnResult, error) {
+	var result CaptureHoldTxnResult
+
+	err := store.execTxn(ctx, func(q *Queries) error {
+		hold, err := lockPendingHold(ctx, q, args.HoldID)
+		if err != nil {
+			return err
+		}
+
+		amount := args.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount < 0 || amount > hold.Amount {
+			return ErrCaptureExceedsHold
+		}
+
+		result.Transfer, err = transfer(ctx, q, TransferTxnParam{
+			FromAccountID: hold.AccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        amount,
+			Sender:        args.Sender,
+		}, &hold)
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.SettleHold(ctx, SettleHoldParams{
+			ID:             hold.ID,
+			Status:         HoldCaptured,
+			CapturedAmount: amount,
+			TransferID:     sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+
+	return result, journalError(err)
+}
+
+// VoidHoldTxn cancels a pending hold, releasing the whole amount and fee
+func (store *SQLStore) VoidHoldTxn(ctx context.Context, holdID int64) (Hold, error) {
+	var result Hold
+
+	err := store.execTxn(ctx, func(q *Queries) error {
+		hold, err := lockPendingHold(ctx, q, holdID)
+		if err != nil {
+			return err
+		}
+		account, err := q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			ID:     hold.AccountID,
+			Amount: -hold.Reserved(),
+		})
+		if err != nil {
+			return err
+		}
+
+		result, err = q.SettleHold(ctx, SettleHoldParams{
+			ID:     hold.ID,
+			Status: HoldVoided,
+		})
+		if err != nil {
+			return err
+		}
+
+		return publishAccountChange(ctx, q, account, Entry{})
+	})
+
+	return result, err
+}
+
+// ExpireHoldsTxn expires up to maxHolds holds past their expiry and releases their amounts
+func (store *SQLStore) ExpireHoldsTxn(ctx context.Context, maxHolds int32) ([]Hold, error) {
+	var holds []Hold
+
+	err := store.execTxn(ctx, func(q *Queries) error {
+		var err error
+		holds, err = q.ExpireHolds(ctx, maxHolds)
+		if err != nil {
+			return err
+		}
+
+		// released in account ID order, like balance updates, so expiry can't deadlock with transfers
+		held := map[int64]int64{}
+		for _, hold := range holds {
+			held[hold.AccountID] += hold.Reserved()
+		}
+		accountIDs := make([]int64, 0, len(held))
+		for id := range held {
+			accountIDs = append(accountIDs, id)
+		}
+		sort.Slice(accountIDs, func(i, j int) bool { return accountIDs[i] < accountIDs[j] })
+
+		for _, id := range accountIDs {
+			account, err := q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+				ID:     id,
+				Amount: -held[id],
+			})
+			if err != nil {
+				return err
+			}
+			if err := publishAccountChange(ctx, q, account, Entry{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return holds, err
+}
+
+// lockPendingHold locks a hold that can still be captured or voided
+func lockPendingHold(ctx context.Context, q *Queries, holdID int64) (Hold, error) {
+	hold, err := q.GetHoldForUpdate(ctx, holdID)
+	if err != nil {
+		return hold, err
+	}
+	if hold.Status != HoldPending {
+		return hold, ErrHoldNotPending
+	}
+	// the expirer may not have swept it yet
+	if !hold.ExpiresAt.After(time.Now()) {
+		return hold, ErrHoldExpired
+	}
+	return hold, nil
+}
+
+// HoldExpirer expires pending holds once their time is up
+type HoldExpirer struct {
+	store        Store
+	BatchSize    int32
+	PollInterval time.Duration
+}
+
+func NewHoldExpirer(store Store) *HoldExpirer {
+	return &HoldExpirer{
+		store:        store,
+		BatchSize:    defaultExpireBatchSize,
+		PollInterval: defaultExpirePollInterval,
+	}
+}
+
+// Start expires holds until ctx is cancelled
+func (expirer *HoldExpirer) Start(ctx context.Context) {
+	ticker := time.NewTicker(expirer.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := expirer.RunOnce(ctx); err != nil {
+			log.Printf("hold expirer: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce expires every hold past its expiry and reports how many were expired
+func (expirer *HoldExpirer) RunOnce(ctx context.Context) (int, error) {
+	expired := 0
+	for {
+		holds, err := expirer.store.ExpireHoldsTxn(ctx, expirer.BatchSize)
+		if err != nil {
+			return expired, err
+		}
+
+		expired += len(holds)
+		if len(holds) < int(expirer.BatchSize) {
+			return expired, nil
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: hold.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const createHold = `-- name: CreateHold :one
+INSERT INTO holds (
+    account_id,
+    to_account_id,
+    amount,
+    fee,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING id, account_id, to_account_id, amount, captured_amount, status, transfer_id, expires_at, settled_at, created_at, fee
+`
+
+type CreateHoldParams struct {
+	AccountID   int64
+	ToAccountID int64
+	Amount      int64
+	Fee         int64
+	ExpiresAt   time.Time
+}
+
+func (q *Queries) CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error) {
+	row := q.db.QueryRowContext(ctx, createHold,
+		arg.AccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Fee,
+		arg.ExpiresAt,
+	)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CapturedAmount,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiresAt,
+		&i.SettledAt,
+		&i.CreatedAt,
+		&i.Fee,
+	)
+	return i, err
+}
+
+const expireHolds = `-- name: ExpireHolds :many
+UPDATE holds
+SET status = 'expired', settled_at = now()
+WHERE id IN (
+    SELECT h.id FROM holds h
+    WHERE h.status = 'pending' AND h.expires_at <= now()
+    ORDER BY h.id
+    LIMIT $1
+    FOR NO KEY UPDATE SKIP LOCKED
+)
+RETURNING id, account_id, to_account_id, amount, captured_amount, status, transfer_id, expires_at, settled_at, created_at, fee
+`
+
+// Expires up to max_holds pending holds past their expiry. Holds locked by a
+// capture or a void in progress are skipped.
+func (q *Queries) ExpireHolds(ctx context.Context, maxHolds int32) ([]Hold, error) {
+	rows, err := q.db.QueryContext(ctx, expireHolds, maxHolds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Hold
+	for rows.Next() {
+		var i Hold
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CapturedAmount,
+			&i.Status,
+			&i.TransferID,
+			&i.ExpiresAt,
+			&i.SettledAt,
+			&i.CreatedAt,
+			&i.Fee,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getHold = `-- name: GetHold :one
+SELECT id, account_id, to_account_id, amount, captured_amount, status, transfer_id, expires_at, settled_at, created_at, fee FROM holds
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetHold(ctx context.Context, id int64) (Hold, error) {
+	row := q.db.QueryRowContext(ctx, getHold, id)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CapturedAmount,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiresAt,
+		&i.SettledAt,
+		&i.CreatedAt,
+		&i.Fee,
+	)
+	return i, err
+}
+
+const getHoldForUpdate = `-- name: GetHoldForUpdate :one
+SELECT id, account_id, to_account_id, amount, captured_amount, status, transfer_id, expires_at, settled_at, created_at, fee FROM holds
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetHoldForUpdate(ctx context.Context, id int64) (Hold, error) {
+	row := q.db.QueryRowContext(ctx, getHoldForUpdate, id)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CapturedAmount,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiresAt,
+		&i.SettledAt,
+		&i.CreatedAt,
+		&i.Fee,
+	)
+	return i, err
+}
+
+const settleHold = `-- name: SettleHold :one
+UPDATE holds
+SET
+    status = $1,
+    captured_amount = $2,
+    transfer_id = $3,
+    settled_at = now()
+WHERE id = $4
+RETURNING id, account_id, to_account_id, amount, captured_amount, status, transfer_id, expires_at, settled_at, created_at, fee
+`
+
+type SettleHoldParams struct {
+	Status         string
+	CapturedAmount int64
+	TransferID     sql.NullInt64
+	ID             int64
+}
+
+func (q *Queries) SettleHold(ctx context.Context, arg SettleHoldParams) (Hold, error) {
+	row := q.db.QueryRowContext(ctx, settleHold,
+		arg.Status,
+		arg.CapturedAmount,
+		arg.TransferID,
+		arg.ID,
+	)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CapturedAmount,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiresAt,
+		&i.SettledAt,
+		&i.CreatedAt,
+		&i.Fee,
+	)
+	return i, err
+}
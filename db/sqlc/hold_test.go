@@ -0,0 +1,180 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func placeTestHold(t *testing.T, store Store, from, to Account, amount int64, expiresAt time.Time) Hold {
+	result, err := store.PlaceHoldTxn(context.Background(), PlaceHoldTxnParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		ExpiresAt:     expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, HoldPending, result.Hold.Status)
+	require.Equal(t, result.Fee.Amount, result.Hold.Fee)
+	require.Equal(t, from.Balance, result.Account.Balance)
+	return result.Hold
+}
+
+func TestHoldLifecycle(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomTestAccount(t)
+	account2 := createRandomTestAccountIn(t, account1.Currency)
+	expiresAt := time.Now().Add(time.Hour)
+
+	captured := placeTestHold(t, store, account1, account2, 30, expiresAt)
+	voided := placeTestHold(t, store, account1, account2, 20, expiresAt)
+
+	account, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, account.Balance)
+	require.Equal(t, account1.Balance-50, account.AvailableBalance())
+
+	// more than what's left available
+	_, err = store.PlaceHoldTxn(context.Background(), PlaceHoldTxnParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account.AvailableBalance() + 1,
+		ExpiresAt:     expiresAt,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.CaptureHoldTxn(context.Background(), CaptureHoldTxnParams{HoldID: captured.ID, Amount: 31})
+	require.ErrorIs(t, err, ErrCaptureExceedsHold)
+
+	capture, err := store.CaptureHoldTxn(context.Background(), CaptureHoldTxnParams{HoldID: captured.ID, Amount: 25})
+	require.NoError(t, err)
+	require.Equal(t, HoldCaptured, capture.Hold.Status)
+	require.Equal(t, int64(25), capture.Hold.CapturedAmount)
+	require.Equal(t, capture.Transfer.Transfer.ID, capture.Hold.TransferID.Int64)
+	require.Equal(t, int64(25), capture.Transfer.Transfer.Amount)
+	// the uncaptured 5 are released along with the hold
+	fee := capture.Transfer.Fee.Amount
+	require.Equal(t, account1.Balance-25-fee, capture.Transfer.FromAccount.Balance)
+	require.Equal(t, int64(20), capture.Transfer.FromAccount.HeldBalance)
+	require.Equal(t, account2.Balance+25, capture.Transfer.ToAccount.Balance)
+
+	_, err = store.CaptureHoldTxn(context.Background(), CaptureHoldTxnParams{HoldID: captured.ID})
+	require.ErrorIs(t, err, ErrHoldNotPending)
+
+	hold, err := store.VoidHoldTxn(context.Background(), voided.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldVoided, hold.Status)
+	require.True(t, hold.SettledAt.Valid)
+
+	_, err = store.VoidHoldTxn(context.Background(), voided.ID)
+	require.ErrorIs(t, err, ErrHoldNotPending)
+
+	account, err = store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Zero(t, account.HeldBalance)
+	require.Equal(t, account1.Balance-25-fee, account.Balance)
+}
+
+func TestHoldExpirer(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomTestAccount(t)
+	account2 := createRandomTestAccountIn(t, account1.Currency)
+
+	expired := placeTestHold(t, store, account1, account2, 10, time.Now().Add(-time.Second))
+	pending := placeTestHold(t, store, account1, account2, 15, time.Now().Add(time.Hour))
+
+	_, err := store.CaptureHoldTxn(context.Background(), CaptureHoldTxnParams{HoldID: expired.ID})
+	require.ErrorIs(t, err, ErrHoldExpired)
+
+	expirer := NewHoldExpirer(store)
+	_, err = expirer.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	hold, err := store.GetHold(context.Background(), expired.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldExpired, hold.Status)
+
+	hold, err = store.GetHold(context.Background(), pending.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldPending, hold.Status)
+
+	account, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(15), account.HeldBalance)
+	require.Equal(t, account1.Balance, account.Balance)
+}
+
+func TestPlaceHoldTxnFeeAndLimits(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomTestAccount(t)
+	account2 := createRandomTestAccountIn(t, account1.Currency)
+	expiresAt := time.Now().Add(time.Hour)
+
+	rule, err := testQueries.CreateFeeRule(context.Background(), CreateFeeRuleParams{
+		Name:          "cross user",
+		Currency:      account1.Currency,
+		Kind:          FeeFlat,
+		Priority:      -1,
+		CrossUserOnly: true,
+		FlatFee:       3,
+		Tiers:         json.RawMessage("[]"),
+	})
+	require.NoError(t, err)
+	// other hold tests expect no fee
+	t.Cleanup(func() {
+		require.NoError(t, testQueries.DeactivateFeeRule(context.Background(), rule.ID))
+	})
+
+	tier := "test-" + account1.Owner
+	_, err = testQueries.UpdateUserTier(context.Background(), UpdateUserTierParams{
+		Username: account1.Owner,
+		Tier:     tier,
+	})
+	require.NoError(t, err)
+	_, err = testQueries.UpsertTransferLimit(context.Background(), UpsertTransferLimitParams{
+		Tier:      tier,
+		Currency:  account1.Currency,
+		MaxPerDay: 50,
+	})
+	require.NoError(t, err)
+
+	// the fee is reserved along with the amount
+	hold := placeTestHold(t, store, account1, account2, 40, expiresAt)
+	require.Equal(t, int64(3), hold.Fee)
+
+	account, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(43), account.HeldBalance)
+
+	place := func(amount int64) error {
+		_, err := store.PlaceHoldTxn(context.Background(), PlaceHoldTxnParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+			ExpiresAt:     expiresAt,
+		})
+		return err
+	}
+
+	// the pending hold uses up the daily limit
+	var limitErr *LimitError
+	require.ErrorAs(t, place(20), &limitErr)
+	require.Equal(t, LimitDaily, limitErr.Kind)
+	require.Equal(t, int64(40), limitErr.Used)
+
+	// capturing it isn't checked again, and its transfer takes over the usage
+	capture, err := store.CaptureHoldTxn(context.Background(), CaptureHoldTxnParams{HoldID: hold.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), capture.Transfer.Fee.Amount)
+	require.Zero(t, capture.Transfer.FromAccount.HeldBalance)
+	require.Equal(t, account1.Balance-43, capture.Transfer.FromAccount.Balance)
+
+	require.ErrorAs(t, place(20), &limitErr)
+	require.Equal(t, int64(40), limitErr.Used)
+}
@@ -0,0 +1,265 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+)
+
+// InterestUsername owns the interest expense accounts interest is paid from, one per
+// currency. Their balance goes negative by what the bank paid out.
+const InterestUsername = "simple-bank-interest"
+
+const (
+	// interest accrues on actual/365: a day earns 1/365 of the annual rate, leap years too
+	interestDaysPerYear = 365
+	// accruals are kept in millionths of the minor unit and rounded when posted
+	microsPerUnit = 1_000_000
+
+	defaultInterestBatchSize    = 500
+	defaultInterestPollInterval = time.Hour
+	defaultInterestDelay        = 10 * time.Minute
+	defaultInterestCatchUpDays  = 7
+)
+
+// overdrawable reports whether debits may leave the account negative
+func (account Account) overdrawable() bool {
+	return account.Owner == InterestUsername
+}
+
+// InterestReference is the journal reference of the transaction paying an account's
+// interest for the month starting on month
+func InterestReference(accountID int64, month time.Time) string {
+	return "interest:" + strconv.FormatInt(accountID, 10) + ":" + month.Format("2006-01")
+}
+
+// DailyInterestMicros returns a day of interest at rateBps a year on balance, in
+// millionths of the minor unit rounded half to even. Balances at or below 0 earn nothing.
+func DailyInterestMicros(balance int64, rateBps int32) int64 {
+	if balance <= 0 || rateBps <= 0 {
+		return 0
+	}
+
+	// balance * rate / 365 in micros, split so balance * rate needn't fit in an int64
+	perUnit := int64(rateBps) * (microsPerUnit / basisPointsPerUnit)
+	whole, rest := balance/interestDaysPerYear, balance%interestDaysPerYear
+	return whole*perUnit + divRoundHalfEven(rest*perUnit, interestDaysPerYear)
+}
+
+// microsToMinorUnits rounds an amount in millionths of the minor unit half to even
+func microsToMinorUnits(micros int64) int64 {
+	return divRoundHalfEven(micros, microsPerUnit)
+}
+
+// divRoundHalfEven divides n >= 0 by d > 0, ties going to the even quotient
+func divRoundHalfEven(n, d int64) int64 {
+	quotient, remainder := n/d, n%d
+	if 2*remainder > d || (2*remainder == d && quotient%2 == 1) {
+		quotient++
+	}
+	return quotient
+}
+
+type PostInterestTxnParams struct {
+	AccountID int64 `json:"account_id"`
+	// Month is the first day of the month to pay
+	Month time.Time `json:"month"`
+}
+
+type PostInterestTxnResult struct {
+	// Accruals are the days paid, none when the month was already paid
+	Accruals []InterestAccrual `json:"accruals"`
+	// Amount is the interest credited, 0 when the month rounded to nothing
+	Amount      int64              `json:"amount"`
+	Transaction JournalTransaction `json:"transaction"`
+	Account     Account            `json:"account"`
+}
+
+// PostInterestTxn pays the interest an account accrued over a month: the accruals are
+// summed and rounded half to even to minor units, then moved from the interest expense
+// account of the currency to the account in one journal transaction. Paying a month
+// twice is a no-op.
+func (store *SQLStore) PostInterestTxn(ctx context.Context, args PostInterestTxnParams) (PostInterestTxnResult, error) {
+	var result PostInterestTxnResult
+
+	err := store.execTxn(ctx, func(q *Queries) error {
+		var err error
+		result.Accruals, err = q.LockUnpostedInterest(ctx, LockUnpostedInterestParams{
+			AccountID: args.AccountID,
+			Month:     args.Month,
+		})
+		if err != nil || len(result.Accruals) == 0 {
+			return err
+		}
+
+		var micros int64
+		ids := make([]int64, len(result.Accruals))
+		for i, accrual := range result.Accruals {
+			micros += accrual.AmountMicros
+			ids[i] = accrual.ID
+		}
+		result.Amount = microsToMinorUnits(micros)
+
+		var journalID sql.NullInt64
+		if result.Amount > 0 {
+			account, err := q.GetAccount(ctx, args.AccountID)
+			if err != nil {
+				return err
+			}
+
+			expense, err := systemAccount(ctx, q, InterestUsername, account.Currency)
+			if err != nil {
+				return err
+			}
+
+			journal, err := postJournal(ctx, q, PostJournalTxnParams{
+				Type:      JournalInterest,
+				Reference: InterestReference(account.ID, args.Month),
+				Postings: []Posting{
+					{AccountID: expense.ID, Amount: -result.Amount},
+					{AccountID: account.ID, Amount: result.Amount},
+				},
+			}, sql.NullInt64{}, nil)
+			if err != nil {
+				return err
+			}
+
+			result.Transaction = journal.Transaction
+			result.Account = journal.Accounts[account.ID]
+			journalID = sql.NullInt64{Int64: journal.Transaction.ID, Valid: true}
+		}
+
+		return q.MarkInterestPosted(ctx, MarkInterestPostedParams{
+			JournalTransactionID: journalID,
+			Ids:                  ids,
+		})
+	})
+
+	return result, journalError(err)
+}
+
+// InterestAccruer accrues a day of interest on the end-of-day balance of every account
+// whose product pays interest, and pays each month's accruals once it is over. A day is
+// accrued Delay after it ended and the last CatchUpDays days are retried, so a job that
+// was down catches up. Accruing or paying twice is a no-op.
+type InterestAccruer struct {
+	store        Store
+	BatchSize    int32
+	PollInterval time.Duration
+	Delay        time.Duration
+	CatchUpDays  int
+	now          func() time.Time
+}
+
+func NewInterestAccruer(store Store) *InterestAccruer {
+	return &InterestAccruer{
+		store:        store,
+		BatchSize:    defaultInterestBatchSize,
+		PollInterval: defaultInterestPollInterval,
+		Delay:        defaultInterestDelay,
+		CatchUpDays:  defaultInterestCatchUpDays,
+		now:          time.Now,
+	}
+}
+
+// Start accrues and pays interest until ctx is cancelled
+func (accruer *InterestAccruer) Start(ctx context.Context) {
+	ticker := time.NewTicker(accruer.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := accruer.RunOnce(ctx); err != nil {
+			log.Printf("interest accruer: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce accrues the last settled days and pays the months they complete
+func (accruer *InterestAccruer) RunOnce(ctx context.Context) error {
+	now := accruer.now().Add(-accruer.Delay).UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	for days := accruer.CatchUpDays; days >= 1; days-- {
+		if _, err := accruer.Accrue(ctx, today.AddDate(0, 0, -days)); err != nil {
+			return err
+		}
+	}
+
+	_, err := accruer.Post(ctx, time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC))
+	return err
+}
+
+// Accrue records the interest earned on date by the accounts missing an accrual for it
+// and reports how many were recorded
+func (accruer *InterestAccruer) Accrue(ctx context.Context, date time.Time) (int, error) {
+	dayEnd := date.AddDate(0, 0, 1)
+
+	accrued := 0
+	for {
+		accounts, err := accruer.store.ListInterestAccrualCandidates(ctx, ListInterestAccrualCandidatesParams{
+			DayEnd:      dayEnd,
+			AccrualDate: date,
+			MaxAccounts: accruer.BatchSize,
+		})
+		if err != nil {
+			return accrued, fmt.Errorf("cannot list accounts to accrue on %s: %w", date.Format("2006-01-02"), err)
+		}
+
+		for _, account := range accounts {
+			err := accruer.store.CreateInterestAccrual(ctx, CreateInterestAccrualParams{
+				AccountID:       account.ID,
+				AccrualDate:     date,
+				Balance:         account.Balance,
+				InterestRateBps: account.InterestRateBps,
+				AmountMicros:    DailyInterestMicros(account.Balance, account.InterestRateBps),
+			})
+			if err != nil {
+				return accrued, fmt.Errorf("cannot accrue interest of account %d on %s: %w", account.ID, date.Format("2006-01-02"), err)
+			}
+		}
+
+		accrued += len(accounts)
+		if len(accounts) < int(accruer.BatchSize) {
+			return accrued, nil
+		}
+	}
+}
+
+// Post pays the interest of the months ending before before and reports how many
+// account months were paid
+func (accruer *InterestAccruer) Post(ctx context.Context, before time.Time) (int, error) {
+	posted := 0
+	for {
+		months, err := accruer.store.ListUnpostedInterestMonths(ctx, ListUnpostedInterestMonthsParams{
+			Before:    before,
+			MaxMonths: accruer.BatchSize,
+		})
+		if err != nil {
+			return posted, fmt.Errorf("cannot list interest to pay: %w", err)
+		}
+
+		for _, month := range months {
+			_, err := accruer.store.PostInterestTxn(ctx, PostInterestTxnParams{
+				AccountID: month.AccountID,
+				Month:     month.Month,
+			})
+			if err != nil {
+				return posted, fmt.Errorf("cannot pay interest of account %d for %s: %w", month.AccountID, month.Month.Format("2006-01"), err)
+			}
+		}
+
+		posted += len(months)
+		if len(months) < int(accruer.BatchSize) {
+			return posted, nil
+		}
+	}
+}
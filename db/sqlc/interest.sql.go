@@ -0,0 +1,250 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: interest.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const createInterestAccrual = `-- name: CreateInterestAccrual :exec
+INSERT INTO interest_accruals (
+    account_id,
+    accrual_date,
+    balance,
+    interest_rate_bps,
+    amount_micros
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT ON CONSTRAINT interest_accruals_account_date_key DO NOTHING
+`
+
+type CreateInterestAccrualParams struct {
+	AccountID       int64
+	AccrualDate     time.Time
+	Balance         int64
+	InterestRateBps int32
+	AmountMicros    int64
+}
+
+func (q *Queries) CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) error {
+	_, err := q.db.ExecContext(ctx, createInterestAccrual,
+		arg.AccountID,
+		arg.AccrualDate,
+		arg.Balance,
+		arg.InterestRateBps,
+		arg.AmountMicros,
+	)
+	return err
+}
+
+const listInterestAccrualCandidates = `-- name: ListInterestAccrualCandidates :many
+SELECT
+    a.id,
+    p.interest_rate_bps,
+    (a.balance - COALESCE((
+        SELECT sum(e.amount) FROM entries e
+        WHERE e.account_id = a.id AND e.created_at >= $1::timestamptz
+    ), 0))::bigint AS balance
+FROM accounts a
+JOIN account_products p ON p.code = a.product
+WHERE p.interest_rate_bps > 0
+    AND a.created_at < $1::timestamptz
+    AND NOT EXISTS (
+        SELECT 1 FROM interest_accruals i
+        WHERE i.account_id = a.id AND i.accrual_date = $2
+    )
+ORDER BY a.id
+LIMIT $3
+`
+
+type ListInterestAccrualCandidatesParams struct {
+	DayEnd      time.Time
+	AccrualDate time.Time
+	MaxAccounts int32
+}
+
+type ListInterestAccrualCandidatesRow struct {
+	ID              int64
+	InterestRateBps int32
+	Balance         int64
+}
+
+// Accounts earning interest that were open at day_end and have no accrual for
+// accrual_date yet, with their balance at day_end walked back from the current one.
+func (q *Queries) ListInterestAccrualCandidates(ctx context.Context, arg ListInterestAccrualCandidatesParams) ([]ListInterestAccrualCandidatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listInterestAccrualCandidates, arg.DayEnd, arg.AccrualDate, arg.MaxAccounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListInterestAccrualCandidatesRow
+	for rows.Next() {
+		var i ListInterestAccrualCandidatesRow
+		if err := rows.Scan(&i.ID, &i.InterestRateBps, &i.Balance); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInterestAccruals = `-- name: ListInterestAccruals :many
+SELECT id, account_id, accrual_date, balance, interest_rate_bps, amount_micros, journal_transaction_id, posted_at, created_at FROM interest_accruals
+WHERE account_id = $1
+ORDER BY accrual_date
+`
+
+func (q *Queries) ListInterestAccruals(ctx context.Context, accountID int64) ([]InterestAccrual, error) {
+	rows, err := q.db.QueryContext(ctx, listInterestAccruals, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InterestAccrual
+	for rows.Next() {
+		var i InterestAccrual
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.AccrualDate,
+			&i.Balance,
+			&i.InterestRateBps,
+			&i.AmountMicros,
+			&i.JournalTransactionID,
+			&i.PostedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnpostedInterestMonths = `-- name: ListUnpostedInterestMonths :many
+SELECT DISTINCT account_id, date_trunc('month', accrual_date)::date AS month
+FROM interest_accruals
+WHERE posted_at IS NULL
+    AND accrual_date < $1
+ORDER BY account_id, month
+LIMIT $2
+`
+
+type ListUnpostedInterestMonthsParams struct {
+	Before    time.Time
+	MaxMonths int32
+}
+
+type ListUnpostedInterestMonthsRow struct {
+	AccountID int64
+	Month     time.Time
+}
+
+// The months, per account, with accruals dated before the given day left to pay.
+func (q *Queries) ListUnpostedInterestMonths(ctx context.Context, arg ListUnpostedInterestMonthsParams) ([]ListUnpostedInterestMonthsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUnpostedInterestMonths, arg.Before, arg.MaxMonths)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUnpostedInterestMonthsRow
+	for rows.Next() {
+		var i ListUnpostedInterestMonthsRow
+		if err := rows.Scan(&i.AccountID, &i.Month); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockUnpostedInterest = `-- name: LockUnpostedInterest :many
+SELECT id, account_id, accrual_date, balance, interest_rate_bps, amount_micros, journal_transaction_id, posted_at, created_at FROM interest_accruals
+WHERE account_id = $1
+    AND accrual_date >= $2::date
+    AND accrual_date < ($2::date + interval '1 month')
+    AND posted_at IS NULL
+ORDER BY accrual_date
+FOR UPDATE
+`
+
+type LockUnpostedInterestParams struct {
+	AccountID int64
+	Month     time.Time
+}
+
+func (q *Queries) LockUnpostedInterest(ctx context.Context, arg LockUnpostedInterestParams) ([]InterestAccrual, error) {
+	rows, err := q.db.QueryContext(ctx, lockUnpostedInterest, arg.AccountID, arg.Month)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InterestAccrual
+	for rows.Next() {
+		var i InterestAccrual
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.AccrualDate,
+			&i.Balance,
+			&i.InterestRateBps,
+			&i.AmountMicros,
+			&i.JournalTransactionID,
+			&i.PostedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markInterestPosted = `-- name: MarkInterestPosted :exec
+UPDATE interest_accruals
+SET posted_at = now(),
+    journal_transaction_id = $1
+WHERE id = ANY($2::bigint[])
+`
+
+type MarkInterestPostedParams struct {
+	JournalTransactionID sql.NullInt64
+	Ids                  []int64
+}
+
+func (q *Queries) MarkInterestPosted(ctx context.Context, arg MarkInterestPostedParams) error {
+	_, err := q.db.ExecContext(ctx, markInterestPosted, arg.JournalTransactionID, pq.Array(arg.Ids))
+	return err
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"simple-bank/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDailyInterestMicros(t *testing.T) {
+	testCases := []struct {
+		name    string
+		balance int64
+		rateBps int32
+		micros  int64
+	}{
+		{name: "Whole", balance: 365_000, rateBps: 200, micros: 20_000_000},
+		{name: "Fraction", balance: 1_000, rateBps: 150, micros: 41_096},
+		{name: "Tiny", balance: 1, rateBps: 1, micros: 0},
+		{name: "Large", balance: 1_000_000_000_000, rateBps: 500, micros: 136_986_301_369_863},
+		{name: "Negative", balance: -500, rateBps: 200, micros: 0},
+		{name: "NoRate", balance: 500, rateBps: 0, micros: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.micros, DailyInterestMicros(tc.balance, tc.rateBps))
+		})
+	}
+}
+
+func TestMicrosToMinorUnits(t *testing.T) {
+	require.Equal(t, int64(2), microsToMinorUnits(2_500_000))
+	require.Equal(t, int64(4), microsToMinorUnits(3_500_000))
+	require.Equal(t, int64(3), microsToMinorUnits(2_500_001))
+	require.Equal(t, int64(2), microsToMinorUnits(2_499_999))
+	require.Equal(t, int64(0), microsToMinorUnits(499_999))
+}
+
+func TestInterestAccruer(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomTestUser(t)
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  3_650_000,
+		Currency: utils.USD,
+		Number:   utils.RandomAccountNumber(),
+		Product:  ProductSavings,
+	})
+	require.NoError(t, err)
+
+	product, err := testQueries.GetAccountProduct(context.Background(), ProductSavings)
+	require.NoError(t, err)
+	daily := DailyInterestMicros(account.Balance, product.InterestRateBps)
+	require.NotZero(t, daily)
+
+	// three days later, the run accrues the day the account opened and the two after it
+	accruer := NewInterestAccruer(store)
+	accruer.CatchUpDays = 3
+	accruer.Delay = 0
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	accruer.now = func() time.Time { return today.AddDate(0, 0, 3) }
+
+	require.NoError(t, accruer.RunOnce(context.Background()))
+	// accruing the same days again is a no-op
+	require.NoError(t, accruer.RunOnce(context.Background()))
+
+	accruals, err := testQueries.ListInterestAccruals(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, accruals, 3)
+	for i, accrual := range accruals {
+		require.True(t, today.AddDate(0, 0, i).Equal(accrual.AccrualDate))
+		require.Equal(t, account.Balance, accrual.Balance)
+		require.Equal(t, daily, accrual.AmountMicros)
+	}
+
+	// the month of those days, and the next one if they run over, are paid once over
+	month := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	_, err = accruer.Post(context.Background(), month.AddDate(0, 2, 0))
+	require.NoError(t, err)
+
+	accruals, err = testQueries.ListInterestAccruals(context.Background(), account.ID)
+	require.NoError(t, err)
+	var paid int64
+	for _, accrual := range accruals {
+		require.True(t, accrual.PostedAt.Valid)
+		paid += accrual.AmountMicros
+	}
+
+	updated, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+microsToMinorUnits(paid), updated.Balance)
+
+	expense, err := testQueries.GetAccountByOwnerCurrency(context.Background(), GetAccountByOwnerCurrencyParams{
+		Owner:    InterestUsername,
+		Currency: utils.USD,
+	})
+	require.NoError(t, err)
+	require.Negative(t, expense.Balance)
+
+	// paying again is a no-op
+	result, err := store.PostInterestTxn(context.Background(), PostInterestTxnParams{
+		AccountID: account.ID,
+		Month:     month,
+	})
+	require.NoError(t, err)
+	require.Empty(t, result.Accruals)
+}
@@ -0,0 +1,174 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// Journal transaction types
+const (
+	JournalTransfer = "transfer"
+	JournalDeposit  = "deposit"
+	JournalFee      = "fee"
+	JournalFX       = "fx"
+	JournalReversal = "reversal"
+	JournalInterest = "interest"
+)
+
+// journalBalancedConstraint is raised by the entries_journal_balanced trigger at commit
+const journalBalancedConstraint = "journal_transactions_balanced"
+
+// TransferReference is the journal reference of the transaction booking a transfer
+func TransferReference(transferID int64) string {
+	return "transfer:" + strconv.FormatInt(transferID, 10)
+}
+
+// Posting moves Amount in or out of an account, negative amounts are debits
+type Posting struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+}
+
+type PostJournalTxnParams struct {
+	Type      string          `json:"type"`
+	Reference string          `json:"reference"`
+	Metadata  json.RawMessage `json:"metadata"`
+	Postings  []Posting       `json:"postings"`
+}
+
+type PostJournalTxnResult struct {
+	Transaction JournalTransaction `json:"transaction"`
+	// Entries are in the order of the postings
+	Entries []Entry `json:"entries"`
+	// Accounts holds the updated accounts by ID
+	Accounts map[int64]Account `json:"accounts"`
+}
+
+// PostJournalTxn books a journal transaction: one entry per posting and the balance
+// updates, in a single transaction. Postings must sum to zero in every currency,
+// otherwise it fails with ErrUnbalancedJournal; an account left negative by a debit
+// beyond the overdraft of its product fails it with ErrInsufficientFunds, interest
+// expense accounts aside.
+func (store *SQLStore) PostJournalTxn(ctx context.Context, args PostJournalTxnParams) (PostJournalTxnResult, error) {
+	var result PostJournalTxnResult
+
+	err := store.execTxn(ctx, func(q *Queries) error {
+		var err error
+		result, err = postJournal(ctx, q, args, sql.NullInt64{}, nil)
+		return err
+	})
+
+	return result, journalError(err)
+}
+
+// postJournal books args on q. released holds amounts to take off the held balance of
+// posted accounts, so settling a hold locks its account in the same order as the postings.
+func postJournal(ctx context.Context, q *Queries, args PostJournalTxnParams, transferID sql.NullInt64, released map[int64]int64) (PostJournalTxnResult, error) {
+	result := PostJournalTxnResult{
+		Entries:  make([]Entry, len(args.Postings)),
+		Accounts: map[int64]Account{},
+	}
+	if len(args.Postings) == 0 {
+		return result, ErrUnbalancedJournal
+	}
+
+	metadata := args.Metadata
+	if len(metadata) == 0 {
+		metadata = json.RawMessage("{}")
+	}
+
+	var err error
+	result.Transaction, err = q.CreateJournalTransaction(ctx, CreateJournalTransactionParams{
+		Type:      args.Type,
+		Reference: args.Reference,
+		Metadata:  metadata,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	amounts := map[int64]int64{}
+	debited := map[int64]bool{}
+	for i, posting := range args.Postings {
+		result.Entries[i], err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:            posting.AccountID,
+			Amount:               posting.Amount,
+			TransferID:           transferID,
+			JournalTransactionID: sql.NullInt64{Int64: result.Transaction.ID, Valid: true},
+		})
+		if err != nil {
+			return result, err
+		}
+
+		amounts[posting.AccountID] += posting.Amount
+		if posting.Amount < 0 {
+			debited[posting.AccountID] = true
+		}
+	}
+
+	// balances are always updated in account ID order so concurrent
+	// journal transactions lock rows in the same order and can't deadlock
+	accountIDs := make([]int64, 0, len(amounts))
+	for id := range amounts {
+		accountIDs = append(accountIDs, id)
+	}
+	sort.Slice(accountIDs, func(i, j int) bool { return accountIDs[i] < accountIDs[j] })
+
+	totals := map[string]int64{}
+	for _, id := range accountIDs {
+		account, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     id,
+			Amount: amounts[id],
+		})
+		if err != nil {
+			return result, err
+		}
+
+		if released[id] != 0 {
+			account, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+				ID:     id,
+				Amount: -released[id],
+			})
+			if err != nil {
+				return result, err
+			}
+		}
+
+		result.Accounts[id] = account
+		totals[account.Currency] += amounts[id]
+	}
+
+	for _, total := range totals {
+		if total != 0 {
+			return result, ErrUnbalancedJournal
+		}
+	}
+
+	for _, id := range accountIDs {
+		if !debited[id] {
+			continue
+		}
+		if err := checkOverdraft(ctx, q, result.Accounts[id]); err != nil {
+			return result, err
+		}
+	}
+
+	for i, posting := range args.Postings {
+		if err := publishAccountChange(ctx, q, result.Accounts[posting.AccountID], result.Entries[i]); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// journalError reports the deferred balance check failing at commit as ErrUnbalancedJournal
+func journalError(err error) error {
+	if ConstraintName(err) == journalBalancedConstraint {
+		return ErrUnbalancedJournal
+	}
+	return err
+}
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostJournalTxn(t *testing.T) {
+	store := NewStore(testDB)
+
+	usd1 := createRandomTestAccountIn(t, "USD")
+	usd2 := createRandomTestAccountIn(t, "USD")
+	eur1 := createRandomTestAccountIn(t, "EUR")
+	eur2 := createRandomTestAccountIn(t, "EUR")
+
+	result, err := store.PostJournalTxn(context.Background(), PostJournalTxnParams{
+		Type:      JournalFX,
+		Reference: "fx-test",
+		Metadata:  json.RawMessage(`{"rate": "0.92"}`),
+		Postings: []Posting{
+			{AccountID: usd1.ID, Amount: -50},
+			{AccountID: usd2.ID, Amount: 50},
+			{AccountID: eur1.ID, Amount: -46},
+			{AccountID: eur2.ID, Amount: 46},
+		},
+	})
+	require.NoError(t, err)
+
+	require.NotZero(t, result.Transaction.ID)
+	require.Equal(t, JournalFX, result.Transaction.Type)
+	require.Equal(t, "fx-test", result.Transaction.Reference)
+	require.JSONEq(t, `{"rate": "0.92"}`, string(result.Transaction.Metadata))
+
+	require.Len(t, result.Entries, 4)
+	require.Equal(t, usd1.Balance-50, result.Accounts[usd1.ID].Balance)
+	require.Equal(t, usd2.Balance+50, result.Accounts[usd2.ID].Balance)
+	require.Equal(t, eur1.Balance-46, result.Accounts[eur1.ID].Balance)
+	require.Equal(t, eur2.Balance+46, result.Accounts[eur2.ID].Balance)
+
+	entries, err := store.ListJournalEntries(context.Background(), sql.NullInt64{Int64: result.Transaction.ID, Valid: true})
+	require.NoError(t, err)
+	require.Equal(t, result.Entries, entries)
+}
+
+func TestPostJournalTxnRejections(t *testing.T) {
+	store := NewStore(testDB)
+
+	usd1 := createRandomTestAccountIn(t, "USD")
+	usd2 := createRandomTestAccountIn(t, "USD")
+	eur := createRandomTestAccountIn(t, "EUR")
+
+	testCases := []struct {
+		name     string
+		postings []Posting
+		err      error
+	}{
+		{
+			name:     "NoPostings",
+			postings: nil,
+			err:      ErrUnbalancedJournal,
+		},
+		{
+			name:     "Unbalanced",
+			postings: []Posting{{AccountID: usd1.ID, Amount: -10}, {AccountID: usd2.ID, Amount: 9}},
+			err:      ErrUnbalancedJournal,
+		},
+		{
+			name:     "AcrossCurrencies",
+			postings: []Posting{{AccountID: usd1.ID, Amount: -10}, {AccountID: eur.ID, Amount: 10}},
+			err:      ErrUnbalancedJournal,
+		},
+		{
+			name:     "Overdraft",
+			postings: []Posting{{AccountID: usd1.ID, Amount: -usd1.Balance - 1}, {AccountID: usd2.ID, Amount: usd1.Balance + 1}},
+			err:      ErrInsufficientFunds,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := store.PostJournalTxn(context.Background(), PostJournalTxnParams{
+				Type:      JournalReversal,
+				Reference: "rejected",
+				Postings:  tc.postings,
+			})
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	// nothing was booked
+	account, err := testQueries.GetAccount(context.Background(), usd1.ID)
+	require.NoError(t, err)
+	require.Equal(t, usd1.Balance, account.Balance)
+}
+
+func TestJournalBalancedTrigger(t *testing.T) {
+	account := createRandomTestAccount(t)
+
+	transaction, err := testQueries.CreateJournalTransaction(context.Background(), CreateJournalTransactionParams{
+		Type:      JournalDeposit,
+		Reference: "unbalanced",
+		Metadata:  json.RawMessage("{}"),
+	})
+	require.NoError(t, err)
+
+	// a single posting can never balance, the check runs when the statement commits
+	_, err = testQueries.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID:            account.ID,
+		Amount:               10,
+		JournalTransactionID: sql.NullInt64{Int64: transaction.ID, Valid: true},
+	})
+	require.Error(t, err)
+	require.Equal(t, journalBalancedConstraint, ConstraintName(err))
+	require.ErrorIs(t, journalError(err), ErrUnbalancedJournal)
+}
+
+func TestTransferTxnJournal(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomTestAccount(t)
+	account2 := createRandomTestAccountIn(t, account1.Currency)
+
+	result, err := store.TransferTxn(context.Background(), TransferTxnParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	require.True(t, result.FromEntry.JournalTransactionID.Valid)
+	require.Equal(t, result.FromEntry.JournalTransactionID, result.ToEntry.JournalTransactionID)
+
+	transaction, err := store.GetJournalTransaction(context.Background(), result.FromEntry.JournalTransactionID.Int64)
+	require.NoError(t, err)
+	require.Equal(t, JournalTransfer, transaction.Type)
+	require.Equal(t, TransferReference(result.Transfer.ID), transaction.Reference)
+
+	entries, err := store.ListJournalEntries(context.Background(), result.FromEntry.JournalTransactionID)
+	require.NoError(t, err)
+	require.Equal(t, []Entry{result.FromEntry, result.ToEntry}, entries)
+}
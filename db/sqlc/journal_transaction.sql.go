@@ -0,0 +1,100 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: journal_transaction.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/lib/pq"
+)
+
+const createJournalTransaction = `-- name: CreateJournalTransaction :one
+INSERT INTO journal_transactions (
+    type,
+    reference,
+    metadata
+) VALUES (
+    $1, $2, $3
+)
+RETURNING id, type, reference, metadata, created_at
+`
+
+type CreateJournalTransactionParams struct {
+	Type      string
+	Reference string
+	Metadata  json.RawMessage
+}
+
+func (q *Queries) CreateJournalTransaction(ctx context.Context, arg CreateJournalTransactionParams) (JournalTransaction, error) {
+	row := q.db.QueryRowContext(ctx, createJournalTransaction, arg.Type, arg.Reference, arg.Metadata)
+	var i JournalTransaction
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Reference,
+		&i.Metadata,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getJournalTransaction = `-- name: GetJournalTransaction :one
+SELECT id, type, reference, metadata, created_at FROM journal_transactions
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetJournalTransaction(ctx context.Context, id int64) (JournalTransaction, error) {
+	row := q.db.QueryRowContext(ctx, getJournalTransaction, id)
+	var i JournalTransaction
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Reference,
+		&i.Metadata,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listJournalEntries = `-- name: ListJournalEntries :many
+SELECT id, account_id, amount, created_at, transfer_id, journal_transaction_id, category_id, tags FROM entries
+WHERE journal_transaction_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListJournalEntries(ctx context.Context, journalTransactionID sql.NullInt64) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listJournalEntries, journalTransactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Entry
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.JournalTransactionID,
+			&i.CategoryID,
+			pq.Array(&i.Tags),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
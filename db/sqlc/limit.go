@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// Transfer limit kinds
+const (
+	LimitPerTransfer      = "per_transfer"
+	LimitDaily            = "daily"
+	LimitRolling30Days    = "rolling_30_days"
+	LimitTransfersPerHour = "transfers_per_hour"
+	// LimitTransfersPerMonth is set by the product of the account rather than the tier
+	LimitTransfersPerMonth = "transfers_per_month"
+)
+
+// DefaultTier is the tier of new users
+const DefaultTier = "standard"
+
+// LimitError reports the limit a transfer would exceed. It matches ErrLimitExceeded.
+type LimitError struct {
+	Kind  string
+	Limit int64
+	Used  int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s limit of %d exceeded, %d already used", e.Kind, e.Limit, e.Used)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// Check returns a *LimitError for the first limit sending amount from the account
+// would exceed. Limits of 0 are unlimited.
+func (usage ListLimitUsageRow) Check(amount int64) error {
+	switch {
+	case usage.MaxPerTransfer > 0 && amount > usage.MaxPerTransfer:
+		return &LimitError{Kind: LimitPerTransfer, Limit: usage.MaxPerTransfer}
+	case usage.MaxPerDay > 0 && usage.SentToday+amount > usage.MaxPerDay:
+		return &LimitError{Kind: LimitDaily, Limit: usage.MaxPerDay, Used: usage.SentToday}
+	case usage.MaxPer30Days > 0 && usage.Sent30Days+amount > usage.MaxPer30Days:
+		return &LimitError{Kind: LimitRolling30Days, Limit: usage.MaxPer30Days, Used: usage.Sent30Days}
+	case usage.MaxTransfersPerHour > 0 && usage.TransfersLastHour+1 > usage.MaxTransfersPerHour:
+		return &LimitError{Kind: LimitTransfersPerHour, Limit: usage.MaxTransfersPerHour, Used: usage.TransfersLastHour}
+	case usage.MaxTransfersPerMonth > 0 && usage.TransfersThisMonth+1 > usage.MaxTransfersPerMonth:
+		return &LimitError{Kind: LimitTransfersPerMonth, Limit: usage.MaxTransfersPerMonth, Used: usage.TransfersThisMonth}
+	}
+	return nil
+}
+
+// checkTransferLimits holds the limit lock of the sending owner for the rest of the
+// transaction, so concurrent transfers from any of their accounts are checked one
+// after the other against usage that includes each other.
+func checkTransferLimits(ctx context.Context, q *Queries, from Account, amount int64) error {
+	if err := q.LockTransferLimits(ctx, from.Owner); err != nil {
+		return err
+	}
+
+	usages, err := q.ListLimitUsage(ctx, from.Owner)
+	if err != nil {
+		return err
+	}
+
+	for _, usage := range usages {
+		if usage.AccountID == from.ID {
+			return usage.Check(amount)
+		}
+	}
+	return fmt.Errorf("no limits for account %d", from.ID)
+}
@@ -0,0 +1,185 @@
+package db
+
+import (
+	"context"
+	"simple-bank/utils"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitUsageCheck(t *testing.T) {
+	usage := ListLimitUsageRow{
+		MaxPerTransfer:      1000,
+		MaxPerDay:           2000,
+		MaxPer30Days:        5000,
+		MaxTransfersPerHour: 3,
+		SentToday:           1500,
+		Sent30Days:          4000,
+		TransfersLastHour:   2,
+	}
+
+	testCases := []struct {
+		name   string
+		usage  func(usage ListLimitUsageRow) ListLimitUsageRow
+		amount int64
+		kind   string
+	}{
+		{
+			name:   "WithinLimits",
+			usage:  func(usage ListLimitUsageRow) ListLimitUsageRow { return usage },
+			amount: 500,
+		},
+		{
+			name:   "PerTransfer",
+			usage:  func(usage ListLimitUsageRow) ListLimitUsageRow { return usage },
+			amount: 1001,
+			kind:   LimitPerTransfer,
+		},
+		{
+			name:   "Daily",
+			usage:  func(usage ListLimitUsageRow) ListLimitUsageRow { return usage },
+			amount: 501,
+			kind:   LimitDaily,
+		},
+		{
+			name: "Rolling30Days",
+			usage: func(usage ListLimitUsageRow) ListLimitUsageRow {
+				usage.Sent30Days = 4800
+				return usage
+			},
+			amount: 300,
+			kind:   LimitRolling30Days,
+		},
+		{
+			name: "TransfersPerHour",
+			usage: func(usage ListLimitUsageRow) ListLimitUsageRow {
+				usage.TransfersLastHour = 3
+				return usage
+			},
+			amount: 1,
+			kind:   LimitTransfersPerHour,
+		},
+		{
+			name: "TransfersPerMonth",
+			usage: func(usage ListLimitUsageRow) ListLimitUsageRow {
+				usage.MaxTransfersPerMonth = 6
+				usage.TransfersThisMonth = 6
+				return usage
+			},
+			amount: 1,
+			kind:   LimitTransfersPerMonth,
+		},
+		{
+			name:   "Unlimited",
+			usage:  func(usage ListLimitUsageRow) ListLimitUsageRow { return ListLimitUsageRow{SentToday: 1 << 40} },
+			amount: 1 << 40,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.usage(usage).Check(tc.amount)
+			if tc.kind == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrLimitExceeded)
+			var limitErr *LimitError
+			require.ErrorAs(t, err, &limitErr)
+			require.Equal(t, tc.kind, limitErr.Kind)
+		})
+	}
+}
+
+func TestTransferTxnLimits(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomTestAccount(t)
+	account2 := createRandomTestAccountIn(t, account1.Currency)
+
+	// a tier of its own, so other tests aren't limited
+	tier := "test-" + account1.Owner
+	_, err := testQueries.UpdateUserTier(context.Background(), UpdateUserTierParams{
+		Username: account1.Owner,
+		Tier:     tier,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.UpsertTransferLimit(context.Background(), UpsertTransferLimitParams{
+		Tier:                tier,
+		Currency:            account1.Currency,
+		MaxPerTransfer:      50,
+		MaxPerDay:           60,
+		MaxTransfersPerHour: 10,
+	})
+	require.NoError(t, err)
+
+	transfer := func(amount int64) error {
+		_, err := store.TransferTxn(context.Background(), TransferTxnParam{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+		})
+		return err
+	}
+
+	var limitErr *LimitError
+	require.ErrorAs(t, transfer(51), &limitErr)
+	require.Equal(t, LimitPerTransfer, limitErr.Kind)
+
+	// concurrent transfers are checked one after the other: only one of them fits the day
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			errs <- transfer(40)
+		}()
+	}
+
+	var failed int
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			require.ErrorAs(t, err, &limitErr)
+			require.Equal(t, LimitDaily, limitErr.Kind)
+			require.Equal(t, int64(40), limitErr.Used)
+			failed++
+		}
+	}
+	require.Equal(t, 1, failed)
+
+	usages, err := testQueries.ListLimitUsage(context.Background(), account1.Owner)
+	require.NoError(t, err)
+	require.Len(t, usages, 1)
+	require.Equal(t, tier, usages[0].Tier)
+	require.Equal(t, int64(40), usages[0].SentToday)
+	require.Equal(t, int64(40), usages[0].Sent30Days)
+	require.Equal(t, int64(1), usages[0].TransfersLastHour)
+	require.Zero(t, usages[0].MaxPer30Days)
+
+	// another product in the same currency shares the headroom of the tier
+	business, err := store.CreateAccountTxn(context.Background(), CreateAccountParams{
+		Owner:    account1.Owner,
+		Balance:  100,
+		Currency: account1.Currency,
+		Number:   utils.RandomAccountNumber(),
+		Product:  ProductBusiness,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTxn(context.Background(), TransferTxnParam{
+		FromAccountID: business.ID,
+		ToAccountID:   account2.ID,
+		Amount:        30,
+	})
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LimitDaily, limitErr.Kind)
+	require.Equal(t, int64(40), limitErr.Used)
+
+	usages, err = testQueries.ListLimitUsage(context.Background(), account1.Owner)
+	require.NoError(t, err)
+	require.Len(t, usages, 2)
+	for _, usage := range usages {
+		require.Equal(t, int64(40), usage.SentToday)
+	}
+}
@@ -12,12 +12,14 @@ import (
 
 var testQueries *Queries
 var testDB *sql.DB
+var testDBSource string
 
 func TestMain(m *testing.M) {
 	config, err := utils.LoadConfig("./../../")
 	if err != nil {
 		log.Fatal("error loading the config", err)
 	}
+	testDBSource = config.DbSource
 	testDB, err = sql.Open(config.DbDriver, config.DbSource)
 
 	if err != nil {
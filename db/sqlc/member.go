@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"simple-bank/utils"
+)
+
+// Account member roles
+const (
+	// MemberOwner opened the account, there is exactly one per account
+	MemberOwner   = "owner"
+	MemberCoOwner = "co_owner"
+	MemberViewer  = "viewer"
+	// MemberSpender sends from the account up to the member's spend limit per transfer
+	MemberSpender = "spender"
+)
+
+// Account member statuses
+const (
+	MemberInvited = "invited"
+	MemberActive  = "active"
+)
+
+// OwnerMember is the membership the owner of record holds on account. It is always an
+// active owner, so checking the owner's access needs no lookup.
+func OwnerMember(account Account) AccountMember {
+	return AccountMember{
+		AccountID: account.ID,
+		Username:  account.Owner,
+		Role:      MemberOwner,
+		Status:    MemberActive,
+		InvitedBy: account.Owner,
+		CreatedAt: account.CreatedAt,
+	}
+}
+
+// CanSpend reports whether the member may send amount from the account in one transfer
+func (member AccountMember) CanSpend(amount int64) bool {
+	if member.Status != MemberActive {
+		return false
+	}
+
+	switch member.Role {
+	case MemberOwner, MemberCoOwner:
+		return true
+	case MemberSpender:
+		return amount <= member.SpendLimit
+	}
+	return false
+}
+
+// CanManage reports whether the member may invite and remove members
+func (member AccountMember) CanManage() bool {
+	return member.Status == MemberActive && (member.Role == MemberOwner || member.Role == MemberCoOwner)
+}
+
+// CanReceive reports whether the member may act on payments to the account, like
+// capturing or voiding the holds placed for it
+func (member AccountMember) CanReceive() bool {
+	return member.Status == MemberActive && member.Role != MemberViewer
+}
+
+// checkSender re-checks inside the transaction that username may still send amount
+// from account. The membership stays locked until commit, so a member removed while their
+// request was in flight either can't send or is only removed once the money has moved.
+func checkSender(ctx context.Context, q *Queries, account Account, username string, amount int64) error {
+	if username == "" || username == account.Owner {
+		return nil
+	}
+
+	member, err := q.LockActiveAccountMember(ctx, LockActiveAccountMemberParams{
+		AccountID: account.ID,
+		Username:  username,
+	})
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return ErrSenderNotAllowed
+		}
+		return err
+	}
+	if !member.CanSpend(amount) {
+		return ErrSenderNotAllowed
+	}
+	return nil
+}
+
+// CreateAccountTxn opens an account and makes its owner the first active member of it
+func (store *SQLStore) CreateAccountTxn(ctx context.Context, args CreateAccountParams) (Account, error) {
+	var account Account
+
+	err := store.execTxn(ctx, func(q *Queries) error {
+		var err error
+		account, err = q.CreateAccount(ctx, args)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateAccountOwner(ctx, CreateAccountOwnerParams{
+			AccountID: account.ID,
+			Username:  account.Owner,
+		})
+		return err
+	})
+
+	return account, err
+}
+
+// CreateAccountWithNewNumber opens an account with CreateAccountTxn under a random
+// number, drawing another one when it is already taken. Running out of attempts is
+// not reported as a unique violation, the caller didn't ask for the number.
+func CreateAccountWithNewNumber(ctx context.Context, store Store, args CreateAccountParams) (Account, error) {
+	for attempt := 1; attempt <= utils.AccountNumberAttempts; attempt++ {
+		number, err := utils.NewAccountNumber()
+		if err != nil {
+			return Account{}, err
+		}
+		args.Number = number
+
+		account, err := store.CreateAccountTxn(ctx, args)
+		if ConstraintName(err) != AccountNumberKey {
+			return account, err
+		}
+	}
+	return Account{}, fmt.Errorf("no free account number after %d attempts", utils.AccountNumberAttempts)
+}
@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"simple-bank/utils"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountMemberCanSpend(t *testing.T) {
+	testCases := []struct {
+		name   string
+		member AccountMember
+		amount int64
+		spend  bool
+		manage bool
+	}{
+		{name: "Owner", member: AccountMember{Role: MemberOwner, Status: MemberActive}, amount: 1 << 40, spend: true, manage: true},
+		{name: "CoOwner", member: AccountMember{Role: MemberCoOwner, Status: MemberActive}, amount: 1 << 40, spend: true, manage: true},
+		{name: "Viewer", member: AccountMember{Role: MemberViewer, Status: MemberActive}, amount: 1},
+		{name: "SpenderAtLimit", member: AccountMember{Role: MemberSpender, SpendLimit: 100, Status: MemberActive}, amount: 100, spend: true},
+		{name: "SpenderAboveLimit", member: AccountMember{Role: MemberSpender, SpendLimit: 100, Status: MemberActive}, amount: 101},
+		{name: "Invited", member: AccountMember{Role: MemberCoOwner, Status: MemberInvited}, amount: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.spend, tc.member.CanSpend(tc.amount))
+			require.Equal(t, tc.manage, tc.member.CanManage())
+		})
+	}
+}
+
+func TestAccountInvitation(t *testing.T) {
+	account := createRandomTestAccount(t)
+	invitee := createRandomTestUser(t)
+
+	owner, err := testQueries.GetActiveAccountMember(context.Background(), GetActiveAccountMemberParams{
+		AccountID: account.ID,
+		Username:  account.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, MemberOwner, owner.Role)
+
+	invited, err := testQueries.CreateAccountMember(context.Background(), CreateAccountMemberParams{
+		AccountID:  account.ID,
+		Username:   invitee.Username,
+		Role:       MemberSpender,
+		SpendLimit: 50,
+		InvitedBy:  account.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, MemberInvited, invited.Status)
+	require.False(t, invited.AcceptedAt.Valid)
+
+	// an invitation gives no access until accepted
+	_, err = testQueries.GetActiveAccountMember(context.Background(), GetActiveAccountMemberParams{
+		AccountID: account.ID,
+		Username:  invitee.Username,
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	invitations, err := testQueries.ListAccountInvitations(context.Background(), invitee.Username)
+	require.NoError(t, err)
+	require.Len(t, invitations, 1)
+	require.Equal(t, account.Number, invitations[0].AccountNumber)
+
+	// only the invitee can accept
+	_, err = testQueries.AcceptAccountInvitation(context.Background(), AcceptAccountInvitationParams{
+		ID:       invited.ID,
+		Username: account.Owner,
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	accepted, err := testQueries.AcceptAccountInvitation(context.Background(), AcceptAccountInvitationParams{
+		ID:       invited.ID,
+		Username: invitee.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, MemberActive, accepted.Status)
+	require.True(t, accepted.AcceptedAt.Valid)
+
+	// the shared account is now listed for the invitee
+	accounts, err := testQueries.ListAccount(context.Background(), ListAccountParams{
+		Username: invitee.Username,
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, account.ID, accounts[0].ID)
+
+	_, err = testQueries.CreateAccountMember(context.Background(), CreateAccountMemberParams{
+		AccountID: account.ID,
+		Username:  invitee.Username,
+		Role:      MemberViewer,
+		InvitedBy: account.Owner,
+	})
+	require.Equal(t, "account_members_account_username_key", ConstraintName(err))
+}
+
+func TestTransferTxnRemovedSender(t *testing.T) {
+	store := NewStore(testDB)
+	from := createTestAccountOf(t, createRandomTestUser(t).Username, utils.USD, ProductChecking, 1000)
+	to := createTestAccountOf(t, createRandomTestUser(t).Username, utils.USD, ProductChecking, 0)
+	spender := createRandomTestUser(t)
+
+	member, err := testQueries.CreateAccountMember(context.Background(), CreateAccountMemberParams{
+		AccountID:  from.ID,
+		Username:   spender.Username,
+		Role:       MemberSpender,
+		SpendLimit: 50,
+		InvitedBy:  from.Owner,
+	})
+	require.NoError(t, err)
+	_, err = testQueries.AcceptAccountInvitation(context.Background(), AcceptAccountInvitationParams{
+		ID:       member.ID,
+		Username: spender.Username,
+	})
+	require.NoError(t, err)
+
+	args := TransferTxnParam{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+		Sender:        spender.Username,
+	}
+	_, err = store.TransferTxn(context.Background(), args)
+	require.NoError(t, err)
+
+	// removed after the API checked the membership, before the transfer ran
+	require.NoError(t, testQueries.DeleteAccountMember(context.Background(), member.ID))
+	_, err = store.TransferTxn(context.Background(), args)
+	require.ErrorIs(t, err, ErrSenderNotAllowed)
+
+	args.Sender = from.Owner
+	_, err = store.TransferTxn(context.Background(), args)
+	require.NoError(t, err)
+}
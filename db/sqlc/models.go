@@ -5,6 +5,8 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,16 +18,228 @@ type Account struct {
 	Balance   int64
 	Currency  string
 	CreatedAt time.Time
+	Number    string
+	// sum of pending holds, the available balance is balance - held_balance
+	HeldBalance int64
+	Product     string
+}
+
+type AccountMember struct {
+	ID        int64
+	AccountID int64
+	Username  string
+	// owner, co_owner, viewer or spender
+	Role string
+	// most a spender can send in one transfer, unused for other roles
+	SpendLimit int64
+	// invited until the user accepts, then active
+	Status     string
+	InvitedBy  string
+	AcceptedAt sql.NullTime
+	CreatedAt  time.Time
+}
+
+type AccountProduct struct {
+	Code string
+	Name string
+	// annual interest rate in basis points, accrued daily on actual/365
+	InterestRateBps int32
+	CreatedAt       time.Time
+	// currencies accounts can be opened in, any supported one when empty
+	Currencies []string
+	// how far below 0 debits may take the available balance
+	OverdraftLimit int64
+	// outgoing transfers per UTC calendar month, 0 is unlimited
+	MaxTransfersPerMonth int32
+	// outgoing transfers may only go to accounts of the same owner
+	OwnAccountsOnly bool
+}
+
+type AccountStatement struct {
+	ID        int64
+	AccountID int64
+	// first day of the month the statement covers
+	Period         time.Time
+	StorageKey     string
+	Size           int64
+	OpeningBalance int64
+	ClosingBalance int64
+	CreatedAt      time.Time
+}
+
+type BalanceSnapshot struct {
+	ID        int64
+	AccountID int64
+	// balance including every entry created up to taken_at
+	Balance   int64
+	TakenAt   time.Time
+	CreatedAt time.Time
+}
+
+type Category struct {
+	ID        int64
+	Owner     string
+	Name      string
+	CreatedAt time.Time
+}
+
+type CategoryRule struct {
+	ID                    int64
+	Owner                 string
+	CategoryID            int64
+	CounterpartyAccountID sql.NullInt64
+	// matches transfers whose description contains it, case insensitive
+	MemoKeyword sql.NullString
+	MinAmount   sql.NullInt64
+	MaxAmount   sql.NullInt64
+	// the matching rule with the lowest priority sets the category
+	Priority  int32
+	CreatedAt time.Time
+}
+
+type EmailVerification struct {
+	ID       int64
+	Username string
+	// the address the code was sent to, verifying it fails once the user changes email
+	Email string
+	// sha256 of the code, the code itself is only sent by email
+	CodeHash  string
+	UsedAt    sql.NullTime
+	ExpiresAt time.Time
+	CreatedAt time.Time
 }
 
 type Entry struct {
 	ID        int64
 	AccountID int64
 	// can be negative or positive
-	Amount    int64
+	Amount               int64
+	CreatedAt            time.Time
+	TransferID           sql.NullInt64
+	JournalTransactionID sql.NullInt64
+	// set by the owner or by their category rules when booked
+	CategoryID sql.NullInt64
+	Tags       []string
+}
+
+type FeeRule struct {
+	ID       int64
+	Name     string
+	Currency string
+	// flat, percentage or tiered
+	Kind string
+	// the first matching rule, lowest priority first, sets the fee
+	Priority      int32
+	CrossUserOnly bool
+	// the rule applies to transfers of at least this amount
+	MinAmount   int64
+	FlatFee     int64
+	BasisPoints int32
+	// tiered rules: [{"up_to", "flat_fee", "basis_points"}], up_to 0 is unbounded
+	Tiers  json.RawMessage
+	MinFee int64
+	// 0 means uncapped
+	MaxFee    int64
+	Active    bool
+	CreatedAt time.Time
+	// the rule only applies to senders of this product, to all when null
+	Product sql.NullString
+}
+
+type Hold struct {
+	ID             int64
+	AccountID      int64
+	ToAccountID    int64
+	Amount         int64
+	CapturedAmount int64
+	// pending, captured, voided or expired
+	Status string
+	// the transfer a captured hold turned into
+	TransferID sql.NullInt64
+	ExpiresAt  time.Time
+	SettledAt  sql.NullTime
+	CreatedAt  time.Time
+	// the transfer fee reserved along with amount
+	Fee int64
+}
+
+type InterestAccrual struct {
+	ID          int64
+	AccountID   int64
+	AccrualDate time.Time
+	// end-of-day balance the interest was computed on
+	Balance         int64
+	InterestRateBps int32
+	// interest in millionths of the minor unit, rounded half to even
+	AmountMicros         int64
+	JournalTransactionID sql.NullInt64
+	// set once the month is paid out, journal_transaction_id is null when it rounded to 0
+	PostedAt  sql.NullTime
 	CreatedAt time.Time
 }
 
+type JournalTransaction struct {
+	ID int64
+	// transfer, deposit, fee, fx, reversal or interest
+	Type      string
+	Reference string
+	Metadata  json.RawMessage
+	CreatedAt time.Time
+}
+
+type Outbox struct {
+	ID    int64
+	Topic string
+	// routing key, the username for account and transfer events
+	Key           string
+	Payload       json.RawMessage
+	CreatedAt     time.Time
+	ProcessedAt   sql.NullTime
+	Attempts      int32
+	NextAttemptAt time.Time
+	LastError     string
+}
+
+type OutboxDeadLetter struct {
+	ID             int64
+	EventID        int64
+	Topic          string
+	Key            string
+	Payload        json.RawMessage
+	Attempts       int32
+	LastError      string
+	CreatedAt      time.Time
+	DeadLetteredAt time.Time
+}
+
+type Payee struct {
+	ID        int64
+	Owner     string
+	Nickname  string
+	AccountID int64
+	Currency  string
+	// large transfers to the payee are held back for a cooling-off period from here
+	CreatedAt time.Time
+}
+
+type PaymentRequest struct {
+	ID        int64
+	Requester string
+	// the requester account the payment goes to
+	ToAccountID int64
+	Payer       string
+	Amount      int64
+	Currency    string
+	Note        string
+	// pending, then paid, declined, cancelled or expired
+	Status string
+	// the transfer paying an accepted request
+	TransferID  sql.NullInt64
+	ExpiresAt   time.Time
+	RespondedAt sql.NullTime
+	CreatedAt   time.Time
+}
+
 type Session struct {
 	ID           uuid.UUID
 	Username     string
@@ -44,6 +258,28 @@ type Transfer struct {
 	// must be positive
 	Amount    int64
 	CreatedAt time.Time
+	Fee       int64
+	// memo shown to both sides of the transfer
+	Description string
+	// reference set by the sending client, searchable
+	Reference string
+	// free-form JSON object set by the sending client
+	Metadata json.RawMessage
+}
+
+// limits are shared by the accounts of an owner in the currency, 0 means unlimited
+type TransferLimit struct {
+	ID             int64
+	Tier           string
+	Currency       string
+	MaxPerTransfer int64
+	// total amount sent since midnight UTC
+	MaxPerDay int64
+	// total amount sent over the last 30 days
+	MaxPer30Days int64
+	// number of transfers sent over the last hour
+	MaxTransfersPerHour int64
+	CreatedAt           time.Time
 }
 
 type User struct {
@@ -53,4 +289,35 @@ type User struct {
 	Email             string
 	PasswordChangedAt time.Time
 	CreatedAt         time.Time
+	Tier              string
+	// user-chosen handle to receive transfers, sent to as @alias
+	Alias sql.NullString
+	// only verified emails resolve transfer recipients
+	EmailVerified bool
+}
+
+type Webhook struct {
+	ID        int64
+	Owner     string
+	Url       string
+	Secret    string
+	Events    []string
+	Active    bool
+	CreatedAt time.Time
+}
+
+type WebhookDelivery struct {
+	ID        int64
+	WebhookID int64
+	EventID   int64
+	EventType string
+	Payload   json.RawMessage
+	// pending, succeeded or failed
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	ResponseStatus sql.NullInt32
+	LastError      string
+	DeliveredAt    sql.NullTime
+	CreatedAt      time.Time
 }
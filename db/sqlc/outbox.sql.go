@@ -0,0 +1,234 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: outbox.sql
+
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const claimOutboxEvents = `-- name: ClaimOutboxEvents :many
+SELECT id, topic, key, payload, created_at, processed_at, attempts, next_attempt_at, last_error FROM outbox
+WHERE processed_at IS NULL AND next_attempt_at <= now()
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED
+`
+
+func (q *Queries) ClaimOutboxEvents(ctx context.Context, limit int32) ([]Outbox, error) {
+	rows, err := q.db.QueryContext(ctx, claimOutboxEvents, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Outbox
+	for rows.Next() {
+		var i Outbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.Topic,
+			&i.Key,
+			&i.Payload,
+			&i.CreatedAt,
+			&i.ProcessedAt,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastError,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createOutboxEvent = `-- name: CreateOutboxEvent :one
+INSERT INTO outbox (
+    topic,
+    key,
+    payload
+) VALUES (
+    $1, $2, $3
+) RETURNING id, topic, key, payload, created_at, processed_at, attempts, next_attempt_at, last_error
+`
+
+type CreateOutboxEventParams struct {
+	Topic   string
+	Key     string
+	Payload json.RawMessage
+}
+
+func (q *Queries) CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error) {
+	row := q.db.QueryRowContext(ctx, createOutboxEvent, arg.Topic, arg.Key, arg.Payload)
+	var i Outbox
+	err := row.Scan(
+		&i.ID,
+		&i.Topic,
+		&i.Key,
+		&i.Payload,
+		&i.CreatedAt,
+		&i.ProcessedAt,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.LastError,
+	)
+	return i, err
+}
+
+const deadLetterOutboxEvent = `-- name: DeadLetterOutboxEvent :exec
+INSERT INTO outbox_dead_letters (
+    event_id,
+    topic,
+    key,
+    payload,
+    attempts,
+    last_error,
+    created_at
+)
+SELECT o.id, o.topic, o.key, o.payload, $1::integer, $2::varchar, o.created_at
+FROM outbox o
+WHERE o.id = $3
+`
+
+type DeadLetterOutboxEventParams struct {
+	Attempts  int32
+	LastError string
+	ID        int64
+}
+
+func (q *Queries) DeadLetterOutboxEvent(ctx context.Context, arg DeadLetterOutboxEventParams) error {
+	_, err := q.db.ExecContext(ctx, deadLetterOutboxEvent, arg.Attempts, arg.LastError, arg.ID)
+	return err
+}
+
+const getOutboxEvent = `-- name: GetOutboxEvent :one
+SELECT id, topic, key, payload, created_at, processed_at, attempts, next_attempt_at, last_error FROM outbox
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetOutboxEvent(ctx context.Context, id int64) (Outbox, error) {
+	row := q.db.QueryRowContext(ctx, getOutboxEvent, id)
+	var i Outbox
+	err := row.Scan(
+		&i.ID,
+		&i.Topic,
+		&i.Key,
+		&i.Payload,
+		&i.CreatedAt,
+		&i.ProcessedAt,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.LastError,
+	)
+	return i, err
+}
+
+const listOutboxDeadLetters = `-- name: ListOutboxDeadLetters :many
+SELECT id, event_id, topic, key, payload, attempts, last_error, created_at, dead_lettered_at FROM outbox_dead_letters
+ORDER BY id DESC
+LIMIT $1
+OFFSET $2
+`
+
+type ListOutboxDeadLettersParams struct {
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) ListOutboxDeadLetters(ctx context.Context, arg ListOutboxDeadLettersParams) ([]OutboxDeadLetter, error) {
+	rows, err := q.db.QueryContext(ctx, listOutboxDeadLetters, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OutboxDeadLetter
+	for rows.Next() {
+		var i OutboxDeadLetter
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.Topic,
+			&i.Key,
+			&i.Payload,
+			&i.Attempts,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.DeadLetteredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markOutboxEventProcessed = `-- name: MarkOutboxEventProcessed :exec
+UPDATE outbox
+SET processed_at = now()
+WHERE id = $1
+`
+
+func (q *Queries) MarkOutboxEventProcessed(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, markOutboxEventProcessed, id)
+	return err
+}
+
+const redactOutboxEvent = `-- name: RedactOutboxEvent :exec
+UPDATE outbox
+SET payload = payload - $1::text[]
+WHERE id = $2
+`
+
+type RedactOutboxEventParams struct {
+	Fields []string
+	ID     int64
+}
+
+func (q *Queries) RedactOutboxEvent(ctx context.Context, arg RedactOutboxEventParams) error {
+	_, err := q.db.ExecContext(ctx, redactOutboxEvent, pq.Array(arg.Fields), arg.ID)
+	return err
+}
+
+const rescheduleOutboxEvent = `-- name: RescheduleOutboxEvent :exec
+UPDATE outbox
+SET
+    attempts = $2,
+    next_attempt_at = $3,
+    last_error = $4
+WHERE id = $1
+`
+
+type RescheduleOutboxEventParams struct {
+	ID            int64
+	Attempts      int32
+	NextAttemptAt time.Time
+	LastError     string
+}
+
+func (q *Queries) RescheduleOutboxEvent(ctx context.Context, arg RescheduleOutboxEventParams) error {
+	_, err := q.db.ExecContext(ctx, rescheduleOutboxEvent,
+		arg.ID,
+		arg.Attempts,
+		arg.NextAttemptAt,
+		arg.LastError,
+	)
+	return err
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PayeeCoolingOff holds back transfers above Limit to a payee for Period after it is
+// saved. A Period of 0 turns it off.
+type PayeeCoolingOff struct {
+	Period time.Duration `json:"period"`
+	Limit  int64         `json:"limit"`
+}
+
+// Until is when a payee saved at createdAt can receive any amount
+func (policy PayeeCoolingOff) Until(createdAt time.Time) time.Time {
+	return createdAt.Add(policy.Period)
+}
+
+// Applies reports whether amount is large enough for the cooling-off to matter, so
+// payees only need to be looked up then
+func (policy PayeeCoolingOff) Applies(amount int64) bool {
+	return policy.Period > 0 && amount > policy.Limit
+}
+
+// Check returns a *CoolingOffError when the payee is still cooling off and amount is
+// above the limit
+func (policy PayeeCoolingOff) Check(payeeID int64, createdAt time.Time, amount int64) error {
+	until := policy.Until(createdAt)
+	if !policy.Applies(amount) || !time.Now().Before(until) {
+		return nil
+	}
+	return &CoolingOffError{PayeeID: payeeID, Limit: policy.Limit, Until: until}
+}
+
+// CoolingOffError reports the payee a transfer was held back for. It matches ErrPayeeCoolingOff.
+type CoolingOffError struct {
+	PayeeID int64
+	Limit   int64
+	Until   time.Time
+}
+
+func (e *CoolingOffError) Error() string {
+	return fmt.Sprintf("payee [%d] can receive up to %d until %s", e.PayeeID, e.Limit, e.Until.Format(time.RFC3339))
+}
+
+func (e *CoolingOffError) Unwrap() error {
+	return ErrPayeeCoolingOff
+}
+
+// CheckPayeeCoolingOff applies policy to the payee sender saved for the to account, if
+// any, however the transfer addressed the account
+func CheckPayeeCoolingOff(ctx context.Context, q Querier, sender string, to Account, amount int64, policy PayeeCoolingOff) error {
+	if !policy.Applies(amount) {
+		return nil
+	}
+
+	payee, err := q.GetPayeeByAccount(ctx, GetPayeeByAccountParams{
+		Owner:     sender,
+		AccountID: to.ID,
+	})
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	return policy.Check(payee.ID, payee.CreatedAt, amount)
+}
@@ -0,0 +1,189 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: payee.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createPayee = `-- name: CreatePayee :one
+INSERT INTO payees (
+    owner,
+    nickname,
+    account_id,
+    currency
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id, owner, nickname, account_id, currency, created_at
+`
+
+type CreatePayeeParams struct {
+	Owner     string
+	Nickname  string
+	AccountID int64
+	Currency  string
+}
+
+func (q *Queries) CreatePayee(ctx context.Context, arg CreatePayeeParams) (Payee, error) {
+	row := q.db.QueryRowContext(ctx, createPayee,
+		arg.Owner,
+		arg.Nickname,
+		arg.AccountID,
+		arg.Currency,
+	)
+	var i Payee
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Nickname,
+		&i.AccountID,
+		&i.Currency,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deletePayee = `-- name: DeletePayee :exec
+DELETE FROM payees
+WHERE id = $1
+`
+
+func (q *Queries) DeletePayee(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deletePayee, id)
+	return err
+}
+
+const getPayee = `-- name: GetPayee :one
+SELECT p.id, p.owner, p.nickname, p.account_id, p.currency, p.created_at, a.number AS account_number
+FROM payees p
+JOIN accounts a ON a.id = p.account_id
+WHERE p.id = $1 LIMIT 1
+`
+
+type GetPayeeRow struct {
+	ID            int64
+	Owner         string
+	Nickname      string
+	AccountID     int64
+	Currency      string
+	CreatedAt     time.Time
+	AccountNumber string
+}
+
+func (q *Queries) GetPayee(ctx context.Context, id int64) (GetPayeeRow, error) {
+	row := q.db.QueryRowContext(ctx, getPayee, id)
+	var i GetPayeeRow
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Nickname,
+		&i.AccountID,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountNumber,
+	)
+	return i, err
+}
+
+const getPayeeByAccount = `-- name: GetPayeeByAccount :one
+SELECT id, owner, nickname, account_id, currency, created_at FROM payees
+WHERE owner = $1 AND account_id = $2 LIMIT 1
+`
+
+type GetPayeeByAccountParams struct {
+	Owner     string
+	AccountID int64
+}
+
+func (q *Queries) GetPayeeByAccount(ctx context.Context, arg GetPayeeByAccountParams) (Payee, error) {
+	row := q.db.QueryRowContext(ctx, getPayeeByAccount, arg.Owner, arg.AccountID)
+	var i Payee
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Nickname,
+		&i.AccountID,
+		&i.Currency,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listPayees = `-- name: ListPayees :many
+SELECT p.id, p.owner, p.nickname, p.account_id, p.currency, p.created_at, a.number AS account_number
+FROM payees p
+JOIN accounts a ON a.id = p.account_id
+WHERE p.owner = $1
+ORDER BY p.nickname, p.id
+`
+
+type ListPayeesRow struct {
+	ID            int64
+	Owner         string
+	Nickname      string
+	AccountID     int64
+	Currency      string
+	CreatedAt     time.Time
+	AccountNumber string
+}
+
+func (q *Queries) ListPayees(ctx context.Context, owner string) ([]ListPayeesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPayees, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPayeesRow
+	for rows.Next() {
+		var i ListPayeesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Nickname,
+			&i.AccountID,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.AccountNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updatePayeeNickname = `-- name: UpdatePayeeNickname :one
+UPDATE payees
+SET nickname = $2
+WHERE id = $1
+RETURNING id, owner, nickname, account_id, currency, created_at
+`
+
+type UpdatePayeeNicknameParams struct {
+	ID       int64
+	Nickname string
+}
+
+func (q *Queries) UpdatePayeeNickname(ctx context.Context, arg UpdatePayeeNicknameParams) (Payee, error) {
+	row := q.db.QueryRowContext(ctx, updatePayeeNickname, arg.ID, arg.Nickname)
+	var i Payee
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Nickname,
+		&i.AccountID,
+		&i.Currency,
+		&i.CreatedAt,
+	)
+	return i, err
+}
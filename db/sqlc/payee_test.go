@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"simple-bank/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayees(t *testing.T) {
+	owner := createRandomTestUser(t)
+	account1 := createRandomTestAccount(t)
+	account2 := createRandomTestAccount(t)
+
+	payee1, err := testQueries.CreatePayee(context.Background(), CreatePayeeParams{
+		Owner:     owner.Username,
+		Nickname:  "rent",
+		AccountID: account1.ID,
+		Currency:  account1.Currency,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, payee1.CreatedAt)
+
+	_, err = testQueries.CreatePayee(context.Background(), CreatePayeeParams{
+		Owner:     owner.Username,
+		Nickname:  "rent again",
+		AccountID: account1.ID,
+		Currency:  account1.Currency,
+	})
+	require.Equal(t, "payees_owner_account_key", ConstraintName(err))
+
+	payee2, err := testQueries.CreatePayee(context.Background(), CreatePayeeParams{
+		Owner:     owner.Username,
+		Nickname:  "groceries",
+		AccountID: account2.ID,
+		Currency:  account2.Currency,
+	})
+	require.NoError(t, err)
+
+	payees, err := testQueries.ListPayees(context.Background(), owner.Username)
+	require.NoError(t, err)
+	require.Len(t, payees, 2)
+	require.Equal(t, payee2.ID, payees[0].ID)
+	require.Equal(t, account2.Number, payees[0].AccountNumber)
+
+	_, err = testQueries.UpdatePayeeNickname(context.Background(), UpdatePayeeNicknameParams{
+		ID:       payee1.ID,
+		Nickname: "landlord",
+	})
+	require.NoError(t, err)
+
+	payee, err := testQueries.GetPayee(context.Background(), payee1.ID)
+	require.NoError(t, err)
+	require.Equal(t, "landlord", payee.Nickname)
+	require.Equal(t, account1.Number, payee.AccountNumber)
+
+	require.NoError(t, testQueries.DeletePayee(context.Background(), payee1.ID))
+	_, err = testQueries.GetPayee(context.Background(), payee1.ID)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestPayeeCoolingOffTxn(t *testing.T) {
+	store := NewStore(testDB)
+	coolingOff := PayeeCoolingOff{Period: time.Hour, Limit: 10}
+
+	from := createTestAccountOf(t, createRandomTestUser(t).Username, utils.USD, ProductChecking, 1000)
+	to := createTestAccountOf(t, createRandomTestUser(t).Username, utils.USD, ProductChecking, 0)
+	_, err := testQueries.CreatePayee(context.Background(), CreatePayeeParams{
+		Owner:     from.Owner,
+		Nickname:  "new",
+		AccountID: to.ID,
+		Currency:  to.Currency,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTxn(context.Background(), TransferTxnParam{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        coolingOff.Limit,
+		CoolingOff:    coolingOff,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTxn(context.Background(), TransferTxnParam{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        coolingOff.Limit + 1,
+		CoolingOff:    coolingOff,
+	})
+	var coolingOffErr *CoolingOffError
+	require.ErrorAs(t, err, &coolingOffErr)
+	require.ErrorIs(t, err, ErrPayeeCoolingOff)
+
+	_, err = store.PlaceHoldTxn(context.Background(), PlaceHoldTxnParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        coolingOff.Limit + 1,
+		ExpiresAt:     time.Now().Add(time.Hour),
+		CoolingOff:    coolingOff,
+	})
+	require.ErrorIs(t, err, ErrPayeeCoolingOff)
+
+	request := createTestPaymentRequest(t, to, from, time.Now().Add(time.Hour))
+	_, err = store.AcceptPaymentRequestTxn(context.Background(), AcceptPaymentRequestTxnParams{
+		RequestID:  request.ID,
+		CoolingOff: PayeeCoolingOff{Period: time.Hour, Limit: request.Amount - 1},
+	})
+	require.ErrorIs(t, err, ErrPayeeCoolingOff)
+
+	// another user's payee for the account doesn't hold the owner back
+	_, err = store.TransferTxn(context.Background(), TransferTxnParam{
+		FromAccountID: createTestAccountOf(t, createRandomTestUser(t).Username, utils.USD, ProductChecking, 1000).ID,
+		ToAccountID:   to.ID,
+		Amount:        coolingOff.Limit + 1,
+		CoolingOff:    coolingOff,
+	})
+	require.NoError(t, err)
+}
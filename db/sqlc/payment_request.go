@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+)
+
+// Payment request statuses, only pending requests move on to another status
+const (
+	PaymentRequestPending   = "pending"
+	PaymentRequestPaid      = "paid"
+	PaymentRequestDeclined  = "declined"
+	PaymentRequestCancelled = "cancelled"
+	PaymentRequestExpired   = "expired"
+)
+
+type AcceptPaymentRequestTxnParams struct {
+	RequestID int64 `json:"request_id"`
+	// CoolingOff is applied to the payee the payer saved for the requester's account
+	CoolingOff PayeeCoolingOff `json:"cooling_off"`
+}
+
+type AcceptPaymentRequestTxnResult struct {
+	Request  PaymentRequest    `json:"request"`
+	Transfer TransferTxnResult `json:"transfer"`
+}
+
+// AcceptPaymentRequestTxn pays a pending request from the payer's account in its
+// currency, with the same checks, fees and events as TransferTxn
+func (store *SQLStore) AcceptPaymentRequestTxn(ctx context.Context, args AcceptPaymentRequestTxnParams) (AcceptPaymentRequestTxnResult, error) {
+	var result AcceptPaymentRequestTxnResult
+
+	err := store.execTxn(ctx, func(q *Queries) error {
+		request, err := q.GetPaymentRequestForUpdate(ctx, args.RequestID)
+		if err != nil {
+			return err
+		}
+		if request.Status != PaymentRequestPending {
+			return ErrPaymentRequestNotPending
+		}
+		// the expirer may not have swept it yet
+		if !request.ExpiresAt.After(time.Now()) {
+			return ErrPaymentRequestExpired
+		}
+
+		from, err := q.GetAccountByOwnerCurrency(ctx, GetAccountByOwnerCurrencyParams{
+			Owner:    request.Payer,
+			Currency: request.Currency,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Transfer, err = transfer(ctx, q, TransferTxnParam{
+			FromAccountID: from.ID,
+			ToAccountID:   request.ToAccountID,
+			Amount:        request.Amount,
+			CoolingOff:    args.CoolingOff,
+		}, nil)
+		if err != nil {
+			return err
+		}
+
+		result.Request, err = q.RespondPaymentRequest(ctx, RespondPaymentRequestParams{
+			ID:         request.ID,
+			Status:     PaymentRequestPaid,
+			TransferID: sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+
+	return result, journalError(err)
+}
+
+// PaymentRequestExpirer expires pending payment requests once their time is up
+type PaymentRequestExpirer struct {
+	querier      Querier
+	PollInterval time.Duration
+}
+
+func NewPaymentRequestExpirer(querier Querier) *PaymentRequestExpirer {
+	return &PaymentRequestExpirer{
+		querier:      querier,
+		PollInterval: defaultExpirePollInterval,
+	}
+}
+
+// Start expires payment requests until ctx is cancelled
+func (expirer *PaymentRequestExpirer) Start(ctx context.Context) {
+	ticker := time.NewTicker(expirer.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := expirer.querier.ExpirePaymentRequests(ctx); err != nil {
+			log.Printf("payment request expirer: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,337 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: payment_request.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const createPaymentRequest = `-- name: CreatePaymentRequest :one
+INSERT INTO payment_requests (
+    requester,
+    to_account_id,
+    payer,
+    amount,
+    currency,
+    note,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, requester, to_account_id, payer, amount, currency, note, status, transfer_id, expires_at, responded_at, created_at
+`
+
+type CreatePaymentRequestParams struct {
+	Requester   string
+	ToAccountID int64
+	Payer       string
+	Amount      int64
+	Currency    string
+	Note        string
+	ExpiresAt   time.Time
+}
+
+func (q *Queries) CreatePaymentRequest(ctx context.Context, arg CreatePaymentRequestParams) (PaymentRequest, error) {
+	row := q.db.QueryRowContext(ctx, createPaymentRequest,
+		arg.Requester,
+		arg.ToAccountID,
+		arg.Payer,
+		arg.Amount,
+		arg.Currency,
+		arg.Note,
+		arg.ExpiresAt,
+	)
+	var i PaymentRequest
+	err := row.Scan(
+		&i.ID,
+		&i.Requester,
+		&i.ToAccountID,
+		&i.Payer,
+		&i.Amount,
+		&i.Currency,
+		&i.Note,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiresAt,
+		&i.RespondedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const expirePaymentRequests = `-- name: ExpirePaymentRequests :execrows
+UPDATE payment_requests
+SET status = 'expired', responded_at = now()
+WHERE status = 'pending' AND expires_at <= now()
+`
+
+func (q *Queries) ExpirePaymentRequests(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, expirePaymentRequests)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getPaymentRequest = `-- name: GetPaymentRequest :one
+SELECT r.id, r.requester, r.to_account_id, r.payer, r.amount, r.currency, r.note, r.status, r.transfer_id, r.expires_at, r.responded_at, r.created_at, a.number AS to_account_number
+FROM payment_requests r
+JOIN accounts a ON a.id = r.to_account_id
+WHERE r.id = $1 LIMIT 1
+`
+
+type GetPaymentRequestRow struct {
+	ID              int64
+	Requester       string
+	ToAccountID     int64
+	Payer           string
+	Amount          int64
+	Currency        string
+	Note            string
+	Status          string
+	TransferID      sql.NullInt64
+	ExpiresAt       time.Time
+	RespondedAt     sql.NullTime
+	CreatedAt       time.Time
+	ToAccountNumber string
+}
+
+func (q *Queries) GetPaymentRequest(ctx context.Context, id int64) (GetPaymentRequestRow, error) {
+	row := q.db.QueryRowContext(ctx, getPaymentRequest, id)
+	var i GetPaymentRequestRow
+	err := row.Scan(
+		&i.ID,
+		&i.Requester,
+		&i.ToAccountID,
+		&i.Payer,
+		&i.Amount,
+		&i.Currency,
+		&i.Note,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiresAt,
+		&i.RespondedAt,
+		&i.CreatedAt,
+		&i.ToAccountNumber,
+	)
+	return i, err
+}
+
+const getPaymentRequestForUpdate = `-- name: GetPaymentRequestForUpdate :one
+SELECT id, requester, to_account_id, payer, amount, currency, note, status, transfer_id, expires_at, responded_at, created_at FROM payment_requests
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetPaymentRequestForUpdate(ctx context.Context, id int64) (PaymentRequest, error) {
+	row := q.db.QueryRowContext(ctx, getPaymentRequestForUpdate, id)
+	var i PaymentRequest
+	err := row.Scan(
+		&i.ID,
+		&i.Requester,
+		&i.ToAccountID,
+		&i.Payer,
+		&i.Amount,
+		&i.Currency,
+		&i.Note,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiresAt,
+		&i.RespondedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listIncomingPaymentRequests = `-- name: ListIncomingPaymentRequests :many
+SELECT r.id, r.requester, r.to_account_id, r.payer, r.amount, r.currency, r.note, r.status, r.transfer_id, r.expires_at, r.responded_at, r.created_at, a.number AS to_account_number
+FROM payment_requests r
+JOIN accounts a ON a.id = r.to_account_id
+WHERE r.payer = $1
+  AND ($2::varchar IS NULL OR r.status = $2)
+ORDER BY r.created_at DESC, r.id DESC
+LIMIT $4
+OFFSET $3
+`
+
+type ListIncomingPaymentRequestsParams struct {
+	Payer  string
+	Status sql.NullString
+	Offset int32
+	Limit  int32
+}
+
+type ListIncomingPaymentRequestsRow struct {
+	ID              int64
+	Requester       string
+	ToAccountID     int64
+	Payer           string
+	Amount          int64
+	Currency        string
+	Note            string
+	Status          string
+	TransferID      sql.NullInt64
+	ExpiresAt       time.Time
+	RespondedAt     sql.NullTime
+	CreatedAt       time.Time
+	ToAccountNumber string
+}
+
+func (q *Queries) ListIncomingPaymentRequests(ctx context.Context, arg ListIncomingPaymentRequestsParams) ([]ListIncomingPaymentRequestsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listIncomingPaymentRequests,
+		arg.Payer,
+		arg.Status,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListIncomingPaymentRequestsRow
+	for rows.Next() {
+		var i ListIncomingPaymentRequestsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Requester,
+			&i.ToAccountID,
+			&i.Payer,
+			&i.Amount,
+			&i.Currency,
+			&i.Note,
+			&i.Status,
+			&i.TransferID,
+			&i.ExpiresAt,
+			&i.RespondedAt,
+			&i.CreatedAt,
+			&i.ToAccountNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOutgoingPaymentRequests = `-- name: ListOutgoingPaymentRequests :many
+SELECT r.id, r.requester, r.to_account_id, r.payer, r.amount, r.currency, r.note, r.status, r.transfer_id, r.expires_at, r.responded_at, r.created_at, a.number AS to_account_number
+FROM payment_requests r
+JOIN accounts a ON a.id = r.to_account_id
+WHERE r.requester = $1
+  AND ($2::varchar IS NULL OR r.status = $2)
+ORDER BY r.created_at DESC, r.id DESC
+LIMIT $4
+OFFSET $3
+`
+
+type ListOutgoingPaymentRequestsParams struct {
+	Requester string
+	Status    sql.NullString
+	Offset    int32
+	Limit     int32
+}
+
+type ListOutgoingPaymentRequestsRow struct {
+	ID              int64
+	Requester       string
+	ToAccountID     int64
+	Payer           string
+	Amount          int64
+	Currency        string
+	Note            string
+	Status          string
+	TransferID      sql.NullInt64
+	ExpiresAt       time.Time
+	RespondedAt     sql.NullTime
+	CreatedAt       time.Time
+	ToAccountNumber string
+}
+
+func (q *Queries) ListOutgoingPaymentRequests(ctx context.Context, arg ListOutgoingPaymentRequestsParams) ([]ListOutgoingPaymentRequestsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOutgoingPaymentRequests,
+		arg.Requester,
+		arg.Status,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOutgoingPaymentRequestsRow
+	for rows.Next() {
+		var i ListOutgoingPaymentRequestsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Requester,
+			&i.ToAccountID,
+			&i.Payer,
+			&i.Amount,
+			&i.Currency,
+			&i.Note,
+			&i.Status,
+			&i.TransferID,
+			&i.ExpiresAt,
+			&i.RespondedAt,
+			&i.CreatedAt,
+			&i.ToAccountNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const respondPaymentRequest = `-- name: RespondPaymentRequest :one
+UPDATE payment_requests
+SET
+    status = $1,
+    transfer_id = $2,
+    responded_at = now()
+WHERE id = $3 AND status = 'pending'
+RETURNING id, requester, to_account_id, payer, amount, currency, note, status, transfer_id, expires_at, responded_at, created_at
+`
+
+type RespondPaymentRequestParams struct {
+	Status     string
+	TransferID sql.NullInt64
+	ID         int64
+}
+
+// Moves a pending request to its final status, no rows when it isn't pending anymore.
+func (q *Queries) RespondPaymentRequest(ctx context.Context, arg RespondPaymentRequestParams) (PaymentRequest, error) {
+	row := q.db.QueryRowContext(ctx, respondPaymentRequest, arg.Status, arg.TransferID, arg.ID)
+	var i PaymentRequest
+	err := row.Scan(
+		&i.ID,
+		&i.Requester,
+		&i.ToAccountID,
+		&i.Payer,
+		&i.Amount,
+		&i.Currency,
+		&i.Note,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiresAt,
+		&i.RespondedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
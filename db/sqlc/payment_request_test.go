@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createTestPaymentRequest(t *testing.T, to Account, payer Account, expiresAt time.Time) PaymentRequest {
+	request, err := testQueries.CreatePaymentRequest(context.Background(), CreatePaymentRequestParams{
+		Requester:   to.Owner,
+		ToAccountID: to.ID,
+		Payer:       payer.Owner,
+		Amount:      25,
+		Currency:    to.Currency,
+		Note:        "lunch",
+		ExpiresAt:   expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestPending, request.Status)
+	return request
+}
+
+func TestAcceptPaymentRequestTxn(t *testing.T) {
+	store := NewStore(testDB)
+
+	requester := createRandomTestAccount(t)
+	payer := createRandomTestAccountIn(t, requester.Currency)
+	request := createTestPaymentRequest(t, requester, payer, time.Now().Add(time.Hour))
+
+	result, err := store.AcceptPaymentRequestTxn(context.Background(), AcceptPaymentRequestTxnParams{RequestID: request.ID})
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestPaid, result.Request.Status)
+	require.True(t, result.Request.RespondedAt.Valid)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Request.TransferID.Int64)
+	require.Equal(t, payer.ID, result.Transfer.FromAccount.ID)
+	require.Equal(t, requester.Balance+25, result.Transfer.ToAccount.Balance)
+
+	_, err = store.AcceptPaymentRequestTxn(context.Background(), AcceptPaymentRequestTxnParams{RequestID: request.ID})
+	require.ErrorIs(t, err, ErrPaymentRequestNotPending)
+
+	_, err = testQueries.RespondPaymentRequest(context.Background(), RespondPaymentRequestParams{
+		ID:     request.ID,
+		Status: PaymentRequestDeclined,
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestExpirePaymentRequests(t *testing.T) {
+	store := NewStore(testDB)
+
+	requester := createRandomTestAccount(t)
+	payer := createRandomTestAccountIn(t, requester.Currency)
+	request := createTestPaymentRequest(t, requester, payer, time.Now().Add(-time.Second))
+
+	_, err := store.AcceptPaymentRequestTxn(context.Background(), AcceptPaymentRequestTxnParams{RequestID: request.ID})
+	require.ErrorIs(t, err, ErrPaymentRequestExpired)
+
+	expired, err := testQueries.ExpirePaymentRequests(context.Background())
+	require.NoError(t, err)
+	require.NotZero(t, expired)
+
+	row, err := testQueries.GetPaymentRequest(context.Background(), request.ID)
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestExpired, row.Status)
+	require.Equal(t, requester.Number, row.ToAccountNumber)
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"slices"
+)
+
+// Account products
+const (
+	ProductChecking = "checking"
+	ProductSavings  = "savings"
+	ProductBusiness = "business"
+)
+
+// AllowsCurrency reports whether accounts of the product can be opened in currency
+func (product AccountProduct) AllowsCurrency(currency string) bool {
+	return len(product.Currencies) == 0 || slices.Contains(product.Currencies, currency)
+}
+
+// checkOverdraft fails with ErrInsufficientFunds when the available balance of account
+// is below what its product lets it be overdrawn by
+func checkOverdraft(ctx context.Context, q *Queries, account Account) error {
+	available := account.AvailableBalance()
+	if available >= 0 || account.overdrawable() {
+		return nil
+	}
+
+	product, err := q.GetAccountProduct(ctx, account.Product)
+	if err != nil {
+		return err
+	}
+	if available < -product.OverdraftLimit {
+		return ErrInsufficientFunds
+	}
+	return nil
+}
+
+// checkTransferRecipient fails with ErrOwnAccountsOnly when the product of from only
+// sends to accounts of its owner and to belongs to someone else
+func checkTransferRecipient(ctx context.Context, q *Queries, from Account, to Account) error {
+	if from.Owner == to.Owner {
+		return nil
+	}
+
+	product, err := q.GetAccountProduct(ctx, from.Product)
+	if err != nil {
+		return err
+	}
+	if product.OwnAccountsOnly {
+		return ErrOwnAccountsOnly
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"simple-bank/utils"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowsCurrency(t *testing.T) {
+	require.True(t, AccountProduct{}.AllowsCurrency(utils.INR))
+	require.True(t, AccountProduct{Currencies: []string{utils.USD, utils.EUR}}.AllowsCurrency(utils.EUR))
+	require.False(t, AccountProduct{Currencies: []string{utils.USD, utils.EUR}}.AllowsCurrency(utils.INR))
+}
+
+// createTestAccountOf opens an account of product for owner, with balance
+func createTestAccountOf(t *testing.T, owner string, currency string, product string, balance int64) Account {
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    owner,
+		Balance:  balance,
+		Currency: currency,
+		Number:   utils.RandomAccountNumber(),
+		Product:  product,
+	})
+	require.NoError(t, err)
+	require.Equal(t, product, account.Product)
+	return account
+}
+
+func TestOneAccountPerProduct(t *testing.T) {
+	checking := createRandomTestAccount(t)
+	createTestAccountOf(t, checking.Owner, checking.Currency, ProductSavings, 0)
+
+	_, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    checking.Owner,
+		Currency: checking.Currency,
+		Number:   utils.RandomAccountNumber(),
+		Product:  ProductSavings,
+	})
+	require.Equal(t, UniqueViolation, ErrorCode(err))
+	require.Equal(t, "owner_currency_product_key", ConstraintName(err))
+
+	// the checking account stays the one recipients are paid into
+	account, err := testQueries.GetAccountByOwnerCurrency(context.Background(), GetAccountByOwnerCurrencyParams{
+		Owner:    checking.Owner,
+		Currency: checking.Currency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, checking.ID, account.ID)
+}
+
+func TestSavingsOwnAccountsOnly(t *testing.T) {
+	store := NewStore(testDB)
+
+	checking := createRandomTestAccount(t)
+	savings := createTestAccountOf(t, checking.Owner, checking.Currency, ProductSavings, 100)
+	other := createRandomTestAccountIn(t, checking.Currency)
+
+	_, err := store.TransferTxn(context.Background(), TransferTxnParam{
+		FromAccountID: savings.ID,
+		ToAccountID:   other.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrOwnAccountsOnly)
+
+	_, err = store.TransferTxn(context.Background(), TransferTxnParam{
+		FromAccountID: savings.ID,
+		ToAccountID:   checking.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+}
+
+func TestOverdraft(t *testing.T) {
+	store := NewStore(testDB)
+
+	product, err := testQueries.CreateAccountProduct(context.Background(), CreateAccountProductParams{
+		Code:           "overdraft-" + utils.RandomString(8),
+		Name:           "Overdraft",
+		Currencies:     []string{},
+		OverdraftLimit: 50,
+	})
+	require.NoError(t, err)
+
+	to := createRandomTestAccount(t)
+	from := createTestAccountOf(t, createRandomTestUser(t).Username, to.Currency, product.Code, 10)
+
+	// down to the overdraft limit and not a unit further
+	result, err := store.TransferTxn(context.Background(), TransferTxnParam{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        60,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(-50), result.FromAccount.Balance)
+
+	_, err = store.TransferTxn(context.Background(), TransferTxnParam{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+// Headers set by HTTPPublisher
+const (
+	EventIDHeader    = "Idempotency-Key"
+	EventTopicHeader = "X-Event-Topic"
+	EventKeyHeader   = "X-Event-Key"
+)
+
+// Publisher hands outbox events to a consumer. Delivery is at-least-once,
+// so the same event may be published more than once and must be safe to repeat.
+type Publisher interface {
+	Publish(ctx context.Context, event Outbox) error
+}
+
+// MultiPublisher publishes every event to each of its publishers in order
+type MultiPublisher []Publisher
+
+func (publishers MultiPublisher) Publish(ctx context.Context, event Outbox) error {
+	for _, publisher := range publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MemoryPublisher records published events, for tests
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []Outbox
+	err    error
+}
+
+func (publisher *MemoryPublisher) Publish(ctx context.Context, event Outbox) error {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	if publisher.err != nil {
+		return publisher.err
+	}
+	publisher.events = append(publisher.events, event)
+	return nil
+}
+
+// Events returns the events published so far
+func (publisher *MemoryPublisher) Events() []Outbox {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	return append([]Outbox(nil), publisher.events...)
+}
+
+// FailWith makes every following Publish return err, nil restores success
+func (publisher *MemoryPublisher) FailWith(err error) {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	publisher.err = err
+}
+
+// HTTPPublisher POSTs each event payload to <URL>/<topic>, the subject-per-topic
+// layout used by NATS and Kafka HTTP bridges. The event ID is sent as the
+// idempotency key so brokers that deduplicate can drop redeliveries.
+type HTTPPublisher struct {
+	URL    string
+	client *http.Client
+}
+
+func NewHTTPPublisher(url string) *HTTPPublisher {
+	return &HTTPPublisher{
+		URL:    url,
+		client: &http.Client{Timeout: defaultPublishTimeout},
+	}
+}
+
+func (publisher *HTTPPublisher) Publish(ctx context.Context, event Outbox) error {
+	endpoint, err := url.JoinPath(publisher.URL, event.Topic)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(event.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventIDHeader, strconv.FormatInt(event.ID, 10))
+	req.Header.Set(EventTopicHeader, event.Topic)
+	req.Header.Set(EventKeyHeader, event.Key)
+
+	res, err := publisher.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("broker responded with %s", res.Status)
+	}
+	return nil
+}
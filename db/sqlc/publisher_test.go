@@ -0,0 +1,40 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPPublisher(t *testing.T) {
+	event := Outbox{
+		ID:      42,
+		Topic:   EventTransferCreated,
+		Key:     "alice",
+		Payload: json.RawMessage(`{"transfer_id":3}`),
+	}
+
+	status := http.StatusAccepted
+	broker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, "/events/"+event.Topic, r.URL.Path)
+		require.Equal(t, "42", r.Header.Get(EventIDHeader))
+		require.Equal(t, event.Topic, r.Header.Get(EventTopicHeader))
+		require.Equal(t, event.Key, r.Header.Get(EventKeyHeader))
+		require.JSONEq(t, string(event.Payload), string(body))
+		w.WriteHeader(status)
+	}))
+	defer broker.Close()
+
+	publisher := NewHTTPPublisher(broker.URL + "/events")
+	require.NoError(t, publisher.Publish(context.Background(), event))
+
+	status = http.StatusServiceUnavailable
+	require.Error(t, publisher.Publish(context.Background(), event))
+}
@@ -6,28 +6,168 @@ package db
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AcceptAccountInvitation(ctx context.Context, arg AcceptAccountInvitationParams) (AccountMember, error)
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddAccountHeldBalance(ctx context.Context, arg AddAccountHeldBalanceParams) (Account, error)
+	// Sets the category of both sides of a transfer from the first matching rule of each
+	// account's owner. Every condition a rule sets must match.
+	CategorizeTransferEntries(ctx context.Context, transferID int64) error
+	ClaimOutboxEvents(ctx context.Context, limit int32) ([]Outbox, error)
+	// pushes next_attempt_at past the lease so concurrent workers skip the
+	// claimed deliveries until this one has reported the outcome
+	ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]WebhookDelivery, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountMember(ctx context.Context, arg CreateAccountMemberParams) (AccountMember, error)
+	// The owner is active from the start, as the account was opened by them.
+	CreateAccountOwner(ctx context.Context, arg CreateAccountOwnerParams) (AccountMember, error)
+	CreateAccountProduct(ctx context.Context, arg CreateAccountProductParams) (AccountProduct, error)
+	CreateAccountStatement(ctx context.Context, arg CreateAccountStatementParams) error
+	// Snapshots the balance at taken_at of up to max_accounts accounts that don't
+	// have one yet, walking entries back from the current balance.
+	CreateBalanceSnapshots(ctx context.Context, arg CreateBalanceSnapshotsParams) ([]BalanceSnapshot, error)
+	CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error)
+	CreateCategoryRule(ctx context.Context, arg CreateCategoryRuleParams) (CategoryRule, error)
+	CreateEmailVerification(ctx context.Context, arg CreateEmailVerificationParams) (EmailVerification, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateFeeRule(ctx context.Context, arg CreateFeeRuleParams) (FeeRule, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) error
+	CreateJournalTransaction(ctx context.Context, arg CreateJournalTransactionParams) (JournalTransaction, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error)
+	CreatePayee(ctx context.Context, arg CreatePayeeParams) (Payee, error)
+	CreatePaymentRequest(ctx context.Context, arg CreatePaymentRequestParams) (PaymentRequest, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateSystemAccount(ctx context.Context, arg CreateSystemAccountParams) error
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error
+	DeactivateFeeRule(ctx context.Context, id int64) error
+	DeadLetterOutboxEvent(ctx context.Context, arg DeadLetterOutboxEventParams) error
+	DeclineAccountInvitation(ctx context.Context, arg DeclineAccountInvitationParams) (AccountMember, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteAccountMember(ctx context.Context, id int64) error
+	DeleteCategory(ctx context.Context, id int64) error
+	DeleteCategoryRule(ctx context.Context, id int64) error
+	DeletePayee(ctx context.Context, id int64) error
+	DeleteWebhook(ctx context.Context, id int64) error
+	// Expires up to max_holds pending holds past their expiry. Holds locked by a
+	// capture or a void in progress are skipped.
+	ExpireHolds(ctx context.Context, maxHolds int32) ([]Hold, error)
+	ExpirePaymentRequests(ctx context.Context) (int64, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountByNumber(ctx context.Context, number string) (Account, error)
+	// The owner's checking account in the currency, or their oldest other one.
+	GetAccountByOwnerCurrency(ctx context.Context, arg GetAccountByOwnerCurrencyParams) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountMember(ctx context.Context, arg GetAccountMemberParams) (AccountMember, error)
+	GetAccountProduct(ctx context.Context, code string) (AccountProduct, error)
+	GetAccountStatement(ctx context.Context, arg GetAccountStatementParams) (AccountStatement, error)
+	GetActiveAccountMember(ctx context.Context, arg GetActiveAccountMemberParams) (AccountMember, error)
+	// Starts from the snapshot closest to at, or from the current balance, and
+	// applies the entries between that point and at.
+	GetBalanceAt(ctx context.Context, arg GetBalanceAtParams) (int64, error)
+	GetCategory(ctx context.Context, id int64) (Category, error)
+	GetCategoryRule(ctx context.Context, id int64) (CategoryRule, error)
 	GetEntries(ctx context.Context, arg GetEntriesParams) ([]Entry, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetJournalTransaction(ctx context.Context, id int64) (JournalTransaction, error)
+	GetOutboxEvent(ctx context.Context, id int64) (Outbox, error)
+	GetPayee(ctx context.Context, id int64) (GetPayeeRow, error)
+	GetPayeeByAccount(ctx context.Context, arg GetPayeeByAccountParams) (Payee, error)
+	GetPaymentRequest(ctx context.Context, id int64) (GetPaymentRequestRow, error)
+	GetPaymentRequestForUpdate(ctx context.Context, id int64) (PaymentRequest, error)
+	// Resolves a transfer recipient by exactly one of username, verified email or alias.
+	GetRecipient(ctx context.Context, arg GetRecipientParams) (User, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetStatementBalances(ctx context.Context, arg GetStatementBalancesParams) (GetStatementBalancesRow, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
 	GetTransfers(ctx context.Context, arg GetTransfersParams) ([]Transfer, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetWebhook(ctx context.Context, id int64) (Webhook, error)
+	// The accounts the user is an active member of, in any role.
 	ListAccount(ctx context.Context, arg ListAccountParams) ([]Account, error)
+	// Inflow, outflow, net and entry count of the account per UTC day, week or month over
+	// [from_time, to_time). Buckets without entries are zero.
+	ListAccountActivity(ctx context.Context, arg ListAccountActivityParams) ([]ListAccountActivityRow, error)
+	ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]ListAccountEntriesRow, error)
+	// The pending invitations of the user, with the account they were invited to.
+	ListAccountInvitations(ctx context.Context, username string) ([]ListAccountInvitationsRow, error)
+	ListAccountMembers(ctx context.Context, accountID int64) ([]AccountMember, error)
+	ListAccountProducts(ctx context.Context) ([]AccountProduct, error)
+	ListAccountStatements(ctx context.Context, arg ListAccountStatementsParams) ([]AccountStatement, error)
+	ListAccountTransfers(ctx context.Context, arg ListAccountTransfersParams) ([]ListAccountTransfersRow, error)
+	ListAccountsByNumbers(ctx context.Context, numbers []string) ([]Account, error)
+	ListAccountsMissingStatement(ctx context.Context, arg ListAccountsMissingStatementParams) ([]Account, error)
+	ListActiveCoOwners(ctx context.Context, accountID int64) ([]string, error)
+	// The active rules of the currency that apply to senders of the product.
+	ListActiveFeeRules(ctx context.Context, arg ListActiveFeeRulesParams) ([]FeeRule, error)
+	ListCategories(ctx context.Context, owner string) ([]Category, error)
+	ListCategoryRules(ctx context.Context, owner string) ([]ListCategoryRulesRow, error)
+	// Debits of the account over [from_time, to_time) by category, fees included.
+	// Uncategorized entries are grouped under a null category_id.
+	ListCategorySpending(ctx context.Context, arg ListCategorySpendingParams) ([]ListCategorySpendingRow, error)
+	ListIncomingPaymentRequests(ctx context.Context, arg ListIncomingPaymentRequestsParams) ([]ListIncomingPaymentRequestsRow, error)
+	// Accounts earning interest that were open at day_end and have no accrual for
+	// accrual_date yet, with their balance at day_end walked back from the current one.
+	ListInterestAccrualCandidates(ctx context.Context, arg ListInterestAccrualCandidatesParams) ([]ListInterestAccrualCandidatesRow, error)
+	ListInterestAccruals(ctx context.Context, accountID int64) ([]InterestAccrual, error)
+	ListJournalEntries(ctx context.Context, journalTransactionID sql.NullInt64) ([]Entry, error)
+	// Limits of the owner's tier and of each account's product. Tier limits are shared by
+	// the owner's accounts in a currency, so what was sent since midnight UTC, over the
+	// last 30 days and over the last hour is summed over all of them; the transfers
+	// since the start of the UTC month are counted per account, like its product limit.
+	// Pending holds count as sent when they were placed, until they are captured as a
+	// transfer or released.
+	ListLimitUsage(ctx context.Context, owner string) ([]ListLimitUsageRow, error)
+	ListOutboxDeadLetters(ctx context.Context, arg ListOutboxDeadLettersParams) ([]OutboxDeadLetter, error)
+	ListOutgoingPaymentRequests(ctx context.Context, arg ListOutgoingPaymentRequestsParams) ([]ListOutgoingPaymentRequestsRow, error)
+	ListPayees(ctx context.Context, owner string) ([]ListPayeesRow, error)
+	ListStatementLines(ctx context.Context, arg ListStatementLinesParams) ([]ListStatementLinesRow, error)
+	ListSubscribedWebhooks(ctx context.Context, arg ListSubscribedWebhooksParams) ([]Webhook, error)
+	// The accounts the account exchanged the most money with over [from_time, to_time).
+	ListTopCounterparties(ctx context.Context, arg ListTopCounterpartiesParams) ([]ListTopCounterpartiesRow, error)
+	// The months, per account, with accruals dated before the given day left to pay.
+	ListUnpostedInterestMonths(ctx context.Context, arg ListUnpostedInterestMonthsParams) ([]ListUnpostedInterestMonthsRow, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhooks(ctx context.Context, owner string) ([]Webhook, error)
+	LockAccounts(ctx context.Context, ids []int64) ([]int64, error)
+	// Held until the sending transaction commits, so the member can't be removed meanwhile.
+	LockActiveAccountMember(ctx context.Context, arg LockActiveAccountMemberParams) (AccountMember, error)
+	// Serializes the limit checks of transfers sent by an owner, from any of their
+	// accounts, until the transaction ends. Taken before any account row lock; accepting
+	// a payment request locks the request first, but nothing waits for that row while
+	// holding this lock, so it can't cause deadlocks.
+	LockTransferLimits(ctx context.Context, owner string) error
+	LockUnpostedInterest(ctx context.Context, arg LockUnpostedInterestParams) ([]InterestAccrual, error)
+	MarkInterestPosted(ctx context.Context, arg MarkInterestPostedParams) error
+	MarkOutboxEventProcessed(ctx context.Context, id int64) error
+	NotifyAccountChange(ctx context.Context, arg NotifyAccountChangeParams) error
+	RedactOutboxEvent(ctx context.Context, arg RedactOutboxEventParams) error
+	RescheduleOutboxEvent(ctx context.Context, arg RescheduleOutboxEventParams) error
+	// Moves a pending request to its final status, no rows when it isn't pending anymore.
+	RespondPaymentRequest(ctx context.Context, arg RespondPaymentRequestParams) (PaymentRequest, error)
+	SettleHold(ctx context.Context, arg SettleHoldParams) (Hold, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateEntryCategory(ctx context.Context, arg UpdateEntryCategoryParams) (Entry, error)
+	UpdateEntryTags(ctx context.Context, arg UpdateEntryTagsParams) (Entry, error)
+	UpdatePayeeNickname(ctx context.Context, arg UpdatePayeeNicknameParams) (Payee, error)
+	UpdateUserAlias(ctx context.Context, arg UpdateUserAliasParams) (User, error)
+	UpdateUserTier(ctx context.Context, arg UpdateUserTierParams) (User, error)
+	UpdateWebhookDelivery(ctx context.Context, arg UpdateWebhookDeliveryParams) (WebhookDelivery, error)
+	UpsertTransferLimit(ctx context.Context, arg UpsertTransferLimitParams) (TransferLimit, error)
+	// Marks an unused, unexpired code as used, so each code verifies at most once.
+	UseEmailVerification(ctx context.Context, arg UseEmailVerificationParams) (EmailVerification, error)
+	// Only verifies the address the code was sent to, in case the user changed it since.
+	VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementTxn(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomTestAccount(t)
+	account2 := createRandomTestAccountIn(t, account1.Currency)
+
+	from := time.Now().Add(-time.Minute)
+	var transfers []TransferTxnResult
+	for _, amount := range []int64{10, 5} {
+		result, err := store.TransferTxn(context.Background(), TransferTxnParam{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+		})
+		require.NoError(t, err)
+		transfers = append(transfers, result)
+	}
+
+	var balances GetStatementBalancesRow
+	var lines []ListStatementLinesRow
+	err := store.StatementTxn(context.Background(), StatementTxnParams{
+		AccountID: account1.ID,
+		From:      from,
+		To:        time.Now().Add(time.Minute),
+	}, func(b GetStatementBalancesRow) error {
+		balances = b
+		return nil
+	}, func(line ListStatementLinesRow) error {
+		lines = append(lines, line)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, account1.Balance, balances.OpeningBalance)
+	require.Equal(t, account1.Balance-15, balances.ClosingBalance)
+	require.Len(t, lines, 2)
+
+	for i, line := range lines {
+		require.Equal(t, transfers[i].FromEntry.ID, line.ID)
+		require.Equal(t, -transfers[i].Transfer.Amount, line.Amount)
+		require.Equal(t, transfers[i].Transfer.ID, line.TransferID.Int64)
+		require.Equal(t, account2.Number, line.CounterpartyNumber.String)
+		require.Equal(t, account2.Owner, line.CounterpartyOwner.String)
+	}
+}
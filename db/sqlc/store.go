@@ -3,13 +3,27 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // Store provides all the function to execute db queries and transactions
 type Store interface {
 	Querier
 	TransferTxn(ctx context.Context, args TransferTxnParam) (TransferTxnResult, error)
+	PostJournalTxn(ctx context.Context, args PostJournalTxnParams) (PostJournalTxnResult, error)
+	StatementTxn(ctx context.Context, args StatementTxnParams, header func(GetStatementBalancesRow) error, line func(ListStatementLinesRow) error) error
+	PlaceHoldTxn(ctx context.Context, args PlaceHoldTxnParams) (PlaceHoldTxnResult, error)
+	CaptureHoldTxn(ctx context.Context, args CaptureHoldTxnParams) (CaptureHoldTxnResult, error)
+	VoidHoldTxn(ctx context.Context, holdID int64) (Hold, error)
+	ExpireHoldsTxn(ctx context.Context, maxHolds int32) ([]Hold, error)
+	AcceptPaymentRequestTxn(ctx context.Context, args AcceptPaymentRequestTxnParams) (AcceptPaymentRequestTxnResult, error)
+	BatchTransferTxn(ctx context.Context, args BatchTransferTxnParams) (BatchTransferTxnResult, error)
+	PostInterestTxn(ctx context.Context, args PostInterestTxnParams) (PostInterestTxnResult, error)
+	CreateAccountTxn(ctx context.Context, args CreateAccountParams) (Account, error)
+	RequestEmailVerificationTxn(ctx context.Context, username string) (EmailVerification, error)
+	VerifyEmailTxn(ctx context.Context, args VerifyEmailTxnParams) (User, error)
 }
 
 // SQLStore provides all the function to execute SQL queries and transactions
@@ -27,7 +41,11 @@ func NewStore(db *sql.DB) Store {
 }
 
 func (store *SQLStore) execTxn(ctx context.Context, fn func(*Queries) error) error {
-	txn, err := store.db.BeginTx(ctx, nil)
+	return store.execTxnWithOptions(ctx, nil, fn)
+}
+
+func (store *SQLStore) execTxnWithOptions(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	txn, err := store.db.BeginTx(ctx, opts)
 
 	if err != nil {
 		return err
@@ -47,9 +65,17 @@ func (store *SQLStore) execTxn(ctx context.Context, fn func(*Queries) error) err
 }
 
 type TransferTxnParam struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Description   string `json:"description"`
+	Reference     string `json:"reference"`
+	// Metadata is a JSON object, an empty one when nil
+	Metadata json.RawMessage `json:"metadata"`
+	// Sender is the user sending from the account, when set their membership is checked again
+	Sender string `json:"sender"`
+	// CoolingOff is applied to the payee the sender, or the owner, saved for the recipient
+	CoolingOff PayeeCoolingOff `json:"cooling_off"`
 }
 
 type TransferTxnResult struct {
@@ -58,84 +84,191 @@ type TransferTxnResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	Fee         FeeQuote `json:"fee"`
+	// FeeEntry credits the fee to the revenue account, it is empty when no fee is charged
+	FeeEntry Entry `json:"fee_entry"`
+}
+
+// sender is the user sending from the account, its owner unless a member was named
+func sender(from Account, username string) string {
+	if username == "" {
+		return from.Owner
+	}
+	return username
 }
 
-func getTransferParam(args TransferTxnParam) *CreateTransferParams {
+func getTransferParam(args TransferTxnParam, fee int64) *CreateTransferParams {
+	metadata := args.Metadata
+	if len(metadata) == 0 {
+		metadata = json.RawMessage("{}")
+	}
+
 	return &CreateTransferParams{
 		FromAccountID: args.FromAccountID,
 		ToAccountID:   args.ToAccountID,
 		Amount:        args.Amount,
+		Fee:           fee,
+		Description:   args.Description,
+		Reference:     args.Reference,
+		Metadata:      metadata,
 	}
 }
 
 // TransferTxn performs the transfer of amount between two accounts
-// It creates the transfer record, add account entries, and update the accounts' balance in a single transaction
+// It books the transfer as one journal transaction with an entry per account
+// and the fee of the active fee rules, debited from the sender on top of amount
+// Both entries are categorized by their owner's category rules
+// Transfer events are written to the outbox in the same transaction
+// Account changes are sent on AccountChangesChannel once it commits
 func (store *SQLStore) TransferTxn(ctx context.Context, args TransferTxnParam) (TransferTxnResult, error) {
 	var result TransferTxnResult
 
 	err := store.execTxn(ctx, func(q *Queries) error {
 		var err error
-		result.Transfer, err = q.CreateTransfer(ctx, *getTransferParam(args))
+		result, err = transfer(ctx, q, args, nil)
+		return err
+	})
 
-		if err != nil {
-			return err
+	return result, journalError(err)
+}
+
+// transfer is the body of TransferTxn, for transactions that end in a transfer.
+// hold is the pending hold a capture settles, or nil: its reservation is released
+// along with the postings, keeping the account row locks in ID order, and the limits it
+// was checked against when placed aren't checked again.
+func transfer(ctx context.Context, q *Queries, args TransferTxnParam, hold *Hold) (TransferTxnResult, error) {
+	var result TransferTxnResult
+
+	from, err := q.GetAccount(ctx, args.FromAccountID)
+	if err != nil {
+		return result, err
+	}
+	to, err := q.GetAccount(ctx, args.ToAccountID)
+	if err != nil {
+		return result, err
+	}
+
+	if err := checkSender(ctx, q, from, args.Sender, args.Amount); err != nil {
+		return result, err
+	}
+	if err := checkTransferRecipient(ctx, q, from, to); err != nil {
+		return result, err
+	}
+
+	// holds were checked against the limits and cooling-off when they were placed
+	var released map[int64]int64
+	if hold != nil {
+		released = map[int64]int64{hold.AccountID: hold.Reserved()}
+	} else {
+		if err := CheckPayeeCoolingOff(ctx, q, sender(from, args.Sender), to, args.Amount, args.CoolingOff); err != nil {
+			return result, err
+		}
+		if err := checkTransferLimits(ctx, q, from, args.Amount); err != nil {
+			return result, err
 		}
+	}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: args.FromAccountID,
-			Amount:    -args.Amount,
-		})
+	result.Fee, err = QuoteTransferFee(ctx, q, from, to, args.Amount)
+	if err != nil {
+		return result, err
+	}
 
+	result.Transfer, err = q.CreateTransfer(ctx, *getTransferParam(args, result.Fee.Amount))
+	if err != nil {
+		return result, err
+	}
+
+	postings := []Posting{
+		{AccountID: args.FromAccountID, Amount: -args.Amount - result.Fee.Amount},
+		{AccountID: args.ToAccountID, Amount: args.Amount},
+	}
+
+	if result.Fee.Amount > 0 {
+		revenue, err := revenueAccount(ctx, q, result.Fee.Currency)
 		if err != nil {
-			return err
+			return result, err
 		}
+		postings = append(postings, Posting{AccountID: revenue.ID, Amount: result.Fee.Amount})
+	}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: args.ToAccountID,
-			Amount:    args.Amount,
-		})
+	journal, err := postJournal(ctx, q, PostJournalTxnParams{
+		Type:      JournalTransfer,
+		Reference: TransferReference(result.Transfer.ID),
+		Postings:  postings,
+	}, sql.NullInt64{Int64: result.Transfer.ID, Valid: true}, released)
+	if err != nil {
+		return result, err
+	}
+
+	if err := q.CategorizeTransferEntries(ctx, result.Transfer.ID); err != nil {
+		return result, err
+	}
 
+	result.FromEntry, result.ToEntry = journal.Entries[0], journal.Entries[1]
+	result.FromAccount, result.ToAccount = journal.Accounts[args.FromAccountID], journal.Accounts[args.ToAccountID]
+	if result.Fee.Amount > 0 {
+		result.FeeEntry = journal.Entries[2]
+	}
+
+	return result, enqueueTransferEvents(ctx, q, result)
+}
+
+const statementPageSize = 500
+
+type StatementTxnParams struct {
+	AccountID int64
+	From      time.Time
+	To        time.Time
+}
+
+// StatementTxn reads the statement of an account for [From, To) from a single snapshot,
+// so the balances always agree with the lines. header receives the opening and closing
+// balances, then line is called for each entry in order. Lines are read in pages and
+// never held in memory all at once.
+func (store *SQLStore) StatementTxn(
+	ctx context.Context,
+	args StatementTxnParams,
+	header func(GetStatementBalancesRow) error,
+	line func(ListStatementLinesRow) error,
+) error {
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+
+	return store.execTxnWithOptions(ctx, opts, func(q *Queries) error {
+		balances, err := q.GetStatementBalances(ctx, GetStatementBalancesParams{
+			AccountID: args.AccountID,
+			FromTime:  args.From,
+			ToTime:    args.To,
+		})
 		if err != nil {
 			return err
 		}
-
-		if args.FromAccountID < args.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, args.FromAccountID, -args.Amount, args.ToAccountID, args.Amount)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, args.ToAccountID, args.Amount, args.FromAccountID, -args.Amount)
-		}
-		if err != nil {
+		if err := header(balances); err != nil {
 			return err
 		}
-		return nil
-	})
 
-	return result, err
-}
+		var afterID int64
+		for {
+			lines, err := q.ListStatementLines(ctx, ListStatementLinesParams{
+				AccountID: args.AccountID,
+				FromTime:  args.From,
+				ToTime:    args.To,
+				AfterID:   afterID,
+				MaxLines:  statementPageSize,
+			})
+			if err != nil {
+				return err
+			}
 
-func addMoney(
-	ctx context.Context,
-	q *Queries,
-	accountID1 int64,
-	amount1 int64,
-	accountID2 int64,
-	amount2 int64,
-) (fromAccount Account, toAccount Account, err error) {
-	fromAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID1,
-		Amount: amount1,
-	})
-	if err != nil {
-		return
-	}
+			for _, l := range lines {
+				if err := line(l); err != nil {
+					return err
+				}
+				afterID = l.ID
+			}
 
-	toAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID2,
-		Amount: amount2,
+			if len(lines) < statementPageSize {
+				return nil
+			}
+		}
 	})
-	if err != nil {
-		return
-	}
-
-	return
 }
@@ -12,7 +12,7 @@ func TestTransferTxn(t *testing.T) {
 	store := NewStore(testDB)
 
 	account1 := createRandomTestAccount(t)
-	account2 := createRandomTestAccount(t)
+	account2 := createRandomTestAccountIn(t, account1.Currency)
 	fmt.Println(">> before:", account1.Balance, account2.Balance)
 
 	// run n concurrent transfer transactions
@@ -115,7 +115,7 @@ func TestTransferTxnDeadlock(t *testing.T) {
 	store := NewStore(testDB)
 
 	account1 := createRandomTestAccount(t)
-	account2 := createRandomTestAccount(t)
+	account2 := createRandomTestAccountIn(t, account1.Currency)
 	fmt.Println(">> before:", account1.Balance, account2.Balance)
 
 	// run n concurrent transfer transactions
@@ -160,3 +160,25 @@ func TestTransferTxnDeadlock(t *testing.T) {
 	require.Equal(t, account1.Balance, updatedAccount1.Balance)
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
 }
+
+func TestTransferTxnInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomTestAccount(t)
+	account2 := createRandomTestAccountIn(t, account1.Currency)
+
+	_, err := store.TransferTxn(context.Background(), TransferTxnParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+
+	updatedAccount2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+}
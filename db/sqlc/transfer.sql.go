@@ -7,26 +7,45 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
     from_account_id,
     to_account_id,
-    amount
+    amount,
+    fee,
+    description,
+    reference,
+    metadata
 ) VALUES (
-    $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, from_account_id, to_account_id, amount, created_at, fee, description, reference, metadata
 `
 
 type CreateTransferParams struct {
 	FromAccountID int64
 	ToAccountID   int64
 	Amount        int64
+	Fee           int64
+	Description   string
+	Reference     string
+	Metadata      json.RawMessage
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Fee,
+		arg.Description,
+		arg.Reference,
+		arg.Metadata,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,12 +53,16 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Fee,
+		&i.Description,
+		&i.Reference,
+		&i.Metadata,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers 
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, description, reference, metadata FROM transfers 
 WHERE id = $1
 `
 
@@ -52,12 +75,16 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Fee,
+		&i.Description,
+		&i.Reference,
+		&i.Metadata,
 	)
 	return i, err
 }
 
 const getTransfers = `-- name: GetTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, description, reference, metadata FROM transfers
 WHERE 
     from_account_id = $1 OR 
     to_account_id = $2
@@ -93,6 +120,83 @@ func (q *Queries) GetTransfers(ctx context.Context, arg GetTransfersParams) ([]T
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Fee,
+			&i.Description,
+			&i.Reference,
+			&i.Metadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountTransfers = `-- name: ListAccountTransfers :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.fee, t.description, t.reference, t.metadata, f.number AS from_account_number, r.number AS to_account_number
+FROM transfers t
+JOIN accounts f ON f.id = t.from_account_id
+JOIN accounts r ON r.id = t.to_account_id
+WHERE (t.from_account_id = $1 OR t.to_account_id = $1)
+  AND ($2::varchar IS NULL OR t.reference = $2)
+ORDER BY t.id DESC
+LIMIT $4
+OFFSET $3
+`
+
+type ListAccountTransfersParams struct {
+	AccountID int64
+	Reference sql.NullString
+	Offset    int32
+	Limit     int32
+}
+
+type ListAccountTransfersRow struct {
+	ID                int64
+	FromAccountID     int64
+	ToAccountID       int64
+	Amount            int64
+	CreatedAt         time.Time
+	Fee               int64
+	Description       string
+	Reference         string
+	Metadata          json.RawMessage
+	FromAccountNumber string
+	ToAccountNumber   string
+}
+
+func (q *Queries) ListAccountTransfers(ctx context.Context, arg ListAccountTransfersParams) ([]ListAccountTransfersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountTransfers,
+		arg.AccountID,
+		arg.Reference,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAccountTransfersRow
+	for rows.Next() {
+		var i ListAccountTransfersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Fee,
+			&i.Description,
+			&i.Reference,
+			&i.Metadata,
+			&i.FromAccountNumber,
+			&i.ToAccountNumber,
 		); err != nil {
 			return nil, err
 		}
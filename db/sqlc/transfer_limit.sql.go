@@ -0,0 +1,188 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: transfer_limit.sql
+
+package db
+
+import (
+	"context"
+)
+
+const listLimitUsage = `-- name: ListLimitUsage :many
+SELECT
+    a.id AS account_id,
+    a.number,
+    a.currency,
+    a.product,
+    u.tier,
+    COALESCE(l.max_per_transfer, 0)::bigint AS max_per_transfer,
+    COALESCE(l.max_per_day, 0)::bigint AS max_per_day,
+    COALESCE(l.max_per_30_days, 0)::bigint AS max_per_30_days,
+    COALESCE(l.max_transfers_per_hour, 0)::bigint AS max_transfers_per_hour,
+    p.max_transfers_per_month::bigint AS max_transfers_per_month,
+    COALESCE(s.sent_today, 0)::bigint AS sent_today,
+    COALESCE(s.sent_30_days, 0)::bigint AS sent_30_days,
+    COALESCE(s.transfers_last_hour, 0)::bigint AS transfers_last_hour,
+    COALESCE(m.transfers_this_month, 0)::bigint AS transfers_this_month
+FROM accounts a
+JOIN users u ON u.username = a.owner
+JOIN account_products p ON p.code = a.product
+LEFT JOIN transfer_limits l ON l.tier = u.tier AND l.currency = a.currency
+LEFT JOIN LATERAL (
+    SELECT
+        sum(u.amount) FILTER (WHERE u.created_at >= date_trunc('day', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC') AS sent_today,
+        sum(u.amount) AS sent_30_days,
+        count(*) FILTER (WHERE u.created_at >= now() - interval '1 hour') AS transfers_last_hour
+    FROM (
+        SELECT t.amount, t.created_at
+        FROM transfers t
+        JOIN accounts o ON o.id = t.from_account_id
+        WHERE o.owner = a.owner AND o.currency = a.currency
+            AND t.created_at >= now() - interval '30 days'
+        UNION ALL
+        SELECT h.amount, h.created_at
+        FROM holds h
+        JOIN accounts o ON o.id = h.account_id
+        WHERE o.owner = a.owner AND o.currency = a.currency
+            AND h.status = 'pending' AND h.created_at >= now() - interval '30 days'
+    ) u
+) s ON true
+LEFT JOIN LATERAL (
+    SELECT
+        (SELECT count(*) FROM transfers t
+         WHERE t.from_account_id = a.id
+            AND t.created_at >= date_trunc('month', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC')
+        + (SELECT count(*) FROM holds h
+           WHERE h.account_id = a.id AND h.status = 'pending'
+            AND h.created_at >= date_trunc('month', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC')
+        AS transfers_this_month
+) m ON true
+WHERE a.owner = $1
+ORDER BY a.currency, a.id
+`
+
+type ListLimitUsageRow struct {
+	AccountID            int64
+	Number               string
+	Currency             string
+	Product              string
+	Tier                 string
+	MaxPerTransfer       int64
+	MaxPerDay            int64
+	MaxPer30Days         int64
+	MaxTransfersPerHour  int64
+	MaxTransfersPerMonth int64
+	SentToday            int64
+	Sent30Days           int64
+	TransfersLastHour    int64
+	TransfersThisMonth   int64
+}
+
+// Limits of the owner's tier and of each account's product. Tier limits are shared by
+// the owner's accounts in a currency, so what was sent since midnight UTC, over the
+// last 30 days and over the last hour is summed over all of them; the transfers
+// since the start of the UTC month are counted per account, like its product limit.
+// Pending holds count as sent when they were placed, until they are captured as a
+// transfer or released.
+func (q *Queries) ListLimitUsage(ctx context.Context, owner string) ([]ListLimitUsageRow, error) {
+	rows, err := q.db.QueryContext(ctx, listLimitUsage, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLimitUsageRow
+	for rows.Next() {
+		var i ListLimitUsageRow
+		if err := rows.Scan(
+			&i.AccountID,
+			&i.Number,
+			&i.Currency,
+			&i.Product,
+			&i.Tier,
+			&i.MaxPerTransfer,
+			&i.MaxPerDay,
+			&i.MaxPer30Days,
+			&i.MaxTransfersPerHour,
+			&i.MaxTransfersPerMonth,
+			&i.SentToday,
+			&i.Sent30Days,
+			&i.TransfersLastHour,
+			&i.TransfersThisMonth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockTransferLimits = `-- name: LockTransferLimits :exec
+SELECT pg_advisory_xact_lock(hashtext($1::text))
+`
+
+// Serializes the limit checks of transfers sent by an owner, from any of their
+// accounts, until the transaction ends. Taken before any account row lock; accepting
+// a payment request locks the request first, but nothing waits for that row while
+// holding this lock, so it can't cause deadlocks.
+func (q *Queries) LockTransferLimits(ctx context.Context, owner string) error {
+	_, err := q.db.ExecContext(ctx, lockTransferLimits, owner)
+	return err
+}
+
+const upsertTransferLimit = `-- name: UpsertTransferLimit :one
+INSERT INTO transfer_limits (
+    tier,
+    currency,
+    max_per_transfer,
+    max_per_day,
+    max_per_30_days,
+    max_transfers_per_hour
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (tier, currency) DO UPDATE SET
+    max_per_transfer = EXCLUDED.max_per_transfer,
+    max_per_day = EXCLUDED.max_per_day,
+    max_per_30_days = EXCLUDED.max_per_30_days,
+    max_transfers_per_hour = EXCLUDED.max_transfers_per_hour
+RETURNING id, tier, currency, max_per_transfer, max_per_day, max_per_30_days, max_transfers_per_hour, created_at
+`
+
+type UpsertTransferLimitParams struct {
+	Tier                string
+	Currency            string
+	MaxPerTransfer      int64
+	MaxPerDay           int64
+	MaxPer30Days        int64
+	MaxTransfersPerHour int64
+}
+
+func (q *Queries) UpsertTransferLimit(ctx context.Context, arg UpsertTransferLimitParams) (TransferLimit, error) {
+	row := q.db.QueryRowContext(ctx, upsertTransferLimit,
+		arg.Tier,
+		arg.Currency,
+		arg.MaxPerTransfer,
+		arg.MaxPerDay,
+		arg.MaxPer30Days,
+		arg.MaxTransfersPerHour,
+	)
+	var i TransferLimit
+	err := row.Scan(
+		&i.ID,
+		&i.Tier,
+		&i.Currency,
+		&i.MaxPerTransfer,
+		&i.MaxPerDay,
+		&i.MaxPer30Days,
+		&i.MaxTransfersPerHour,
+		&i.CreatedAt,
+	)
+	return i, err
+}
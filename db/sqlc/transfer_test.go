@@ -2,6 +2,8 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"simple-bank/utils"
 	"testing"
 
@@ -14,6 +16,7 @@ func createRandomTestTransfer(fromAccount, toAccount, amount int64) (Transfer, e
 		FromAccountID: fromAccount,
 		ToAccountID:   toAccount,
 		Amount:        amount,
+		Metadata:      json.RawMessage("{}"),
 	}
 
 	transfer, err := testQueries.CreateTransfer(context.Background(), args)
@@ -82,3 +85,54 @@ func TestGetTransfers(t *testing.T) {
 	}
 
 }
+
+func TestListAccountTransfersByReference(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomTestAccount(t)
+	account2 := createRandomTestAccountIn(t, account1.Currency)
+	reference := "INV-" + utils.RandomString(8)
+
+	result, err := store.TransferTxn(context.Background(), TransferTxnParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Description:   "March rent",
+		Reference:     reference,
+		Metadata:      json.RawMessage(`{"order_id": 42}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "March rent", result.Transfer.Description)
+	require.Equal(t, reference, result.Transfer.Reference)
+	require.JSONEq(t, `{"order_id": 42}`, string(result.Transfer.Metadata))
+
+	_, err = store.TransferTxn(context.Background(), TransferTxnParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// both sides find the transfer by its reference
+	for _, account := range []Account{account1, account2} {
+		transfers, err := testQueries.ListAccountTransfers(context.Background(), ListAccountTransfersParams{
+			AccountID: account.ID,
+			Reference: sql.NullString{String: reference, Valid: true},
+			Limit:     10,
+		})
+		require.NoError(t, err)
+		require.Len(t, transfers, 1)
+		require.Equal(t, result.Transfer.ID, transfers[0].ID)
+		require.Equal(t, account1.Number, transfers[0].FromAccountNumber)
+		require.Equal(t, account2.Number, transfers[0].ToAccountNumber)
+	}
+
+	transfers, err := testQueries.ListAccountTransfers(context.Background(), ListAccountTransfersParams{
+		AccountID: account1.ID,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+	require.Empty(t, transfers[0].Reference)
+	require.JSONEq(t, `{}`, string(transfers[0].Metadata))
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"simple-bank/utils"
 	"testing"
 	"time"
@@ -29,6 +30,9 @@ func createRandomTestUser(t *testing.T) User {
 	require.Equal(t, args.FullName, user.FullName)
 	require.Equal(t, args.HashedPassword, user.HashedPassword)
 	require.Equal(t, args.Email, user.Email)
+	require.Equal(t, DefaultTier, user.Tier)
+	require.False(t, user.Alias.Valid)
+	require.False(t, user.EmailVerified)
 
 	require.True(t, user.PasswordChangedAt.IsZero())
 	require.NotZero(t, user.CreatedAt)
@@ -54,3 +58,41 @@ func TestGetUser(t *testing.T) {
 	require.WithinDuration(t, user1.PasswordChangedAt, user2.PasswordChangedAt, time.Second)
 	require.WithinDuration(t, user1.CreatedAt, user2.CreatedAt, time.Second)
 }
+
+func TestGetRecipient(t *testing.T) {
+	user := createRandomTestUser(t)
+
+	alias := sql.NullString{String: user.Username, Valid: true}
+	_, err := testQueries.UpdateUserAlias(context.Background(), UpdateUserAliasParams{
+		Username: user.Username,
+		Alias:    alias,
+	})
+	require.NoError(t, err)
+
+	recipient, err := testQueries.GetRecipient(context.Background(), GetRecipientParams{Alias: alias})
+	require.NoError(t, err)
+	require.Equal(t, user.Username, recipient.Username)
+
+	// unverified emails don't resolve
+	email := sql.NullString{String: user.Email, Valid: true}
+	_, err = testQueries.GetRecipient(context.Background(), GetRecipientParams{Email: email})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	_, err = testQueries.VerifyUserEmail(context.Background(), VerifyUserEmailParams{
+		Username: user.Username,
+		Email:    user.Email,
+	})
+	require.NoError(t, err)
+
+	recipient, err = testQueries.GetRecipient(context.Background(), GetRecipientParams{Email: email})
+	require.NoError(t, err)
+	require.Equal(t, user.Username, recipient.Username)
+
+	// aliases are unique
+	other := createRandomTestUser(t)
+	_, err = testQueries.UpdateUserAlias(context.Background(), UpdateUserAliasParams{
+		Username: other.Username,
+		Alias:    alias,
+	})
+	require.Equal(t, UniqueViolation, ErrorCode(err))
+}
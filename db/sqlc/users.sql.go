@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 const createUser = `-- name: CreateUser :one
@@ -18,7 +19,7 @@ INSERT INTO users (
 ) VALUES (
     $1, $2, $3, $4
 ) 
-RETURNING username, hashed_password, full_name, email, password_changed_at, created_at
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, tier, alias, email_verified
 `
 
 type CreateUserParams struct {
@@ -43,12 +44,47 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Tier,
+		&i.Alias,
+		&i.EmailVerified,
+	)
+	return i, err
+}
+
+const getRecipient = `-- name: GetRecipient :one
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, tier, alias, email_verified FROM users
+WHERE username = $1
+   OR (email = $2 AND email_verified)
+   OR alias = $3
+LIMIT 1
+`
+
+type GetRecipientParams struct {
+	Username sql.NullString
+	Email    sql.NullString
+	Alias    sql.NullString
+}
+
+// Resolves a transfer recipient by exactly one of username, verified email or alias.
+func (q *Queries) GetRecipient(ctx context.Context, arg GetRecipientParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, getRecipient, arg.Username, arg.Email, arg.Alias)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Tier,
+		&i.Alias,
+		&i.EmailVerified,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, full_name, email, password_changed_at, created_at FROM users
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, tier, alias, email_verified FROM users
 WHERE username = $1 LIMIT 1
 `
 
@@ -62,6 +98,97 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Tier,
+		&i.Alias,
+		&i.EmailVerified,
+	)
+	return i, err
+}
+
+const updateUserAlias = `-- name: UpdateUserAlias :one
+UPDATE users
+SET alias = $1
+WHERE username = $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, tier, alias, email_verified
+`
+
+type UpdateUserAliasParams struct {
+	Alias    sql.NullString
+	Username string
+}
+
+func (q *Queries) UpdateUserAlias(ctx context.Context, arg UpdateUserAliasParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserAlias, arg.Alias, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Tier,
+		&i.Alias,
+		&i.EmailVerified,
+	)
+	return i, err
+}
+
+const updateUserTier = `-- name: UpdateUserTier :one
+UPDATE users
+SET tier = $2
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, tier, alias, email_verified
+`
+
+type UpdateUserTierParams struct {
+	Username string
+	Tier     string
+}
+
+func (q *Queries) UpdateUserTier(ctx context.Context, arg UpdateUserTierParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserTier, arg.Username, arg.Tier)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Tier,
+		&i.Alias,
+		&i.EmailVerified,
+	)
+	return i, err
+}
+
+const verifyUserEmail = `-- name: VerifyUserEmail :one
+UPDATE users
+SET email_verified = true
+WHERE username = $1 AND email = $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, tier, alias, email_verified
+`
+
+type VerifyUserEmailParams struct {
+	Username string
+	Email    string
+}
+
+// Only verifies the address the code was sent to, in case the user changed it since.
+func (q *Queries) VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, verifyUserEmail, arg.Username, arg.Email)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Tier,
+		&i.Alias,
+		&i.EmailVerified,
 	)
 	return i, err
 }
@@ -0,0 +1,347 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: webhook.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const claimWebhookDeliveries = `-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET next_attempt_at = $1
+WHERE id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= now()
+    ORDER BY next_attempt_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at, response_status, last_error, delivered_at, created_at
+`
+
+type ClaimWebhookDeliveriesParams struct {
+	LeaseUntil    time.Time
+	MaxDeliveries int32
+}
+
+// pushes next_attempt_at past the lease so concurrent workers skip the
+// claimed deliveries until this one has reported the outcome
+func (q *Queries) ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, claimWebhookDeliveries, arg.LeaseUntil, arg.MaxDeliveries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.DeliveredAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createWebhook = `-- name: CreateWebhook :one
+INSERT INTO webhooks (
+    owner,
+    url,
+    secret,
+    events
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id, owner, url, secret, events, active, created_at
+`
+
+type CreateWebhookParams struct {
+	Owner  string
+	Url    string
+	Secret string
+	Events []string
+}
+
+func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error) {
+	row := q.db.QueryRowContext(ctx, createWebhook,
+		arg.Owner,
+		arg.Url,
+		arg.Secret,
+		pq.Array(arg.Events),
+	)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Url,
+		&i.Secret,
+		pq.Array(&i.Events),
+		&i.Active,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (
+    webhook_id,
+    event_id,
+    event_type,
+    payload
+) VALUES (
+    $1, $2, $3, $4
+) ON CONFLICT (webhook_id, event_id) DO NOTHING
+`
+
+type CreateWebhookDeliveryParams struct {
+	WebhookID int64
+	EventID   int64
+	EventType string
+	Payload   json.RawMessage
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, createWebhookDelivery,
+		arg.WebhookID,
+		arg.EventID,
+		arg.EventType,
+		arg.Payload,
+	)
+	return err
+}
+
+const deleteWebhook = `-- name: DeleteWebhook :exec
+DELETE FROM webhooks
+WHERE id = $1
+`
+
+func (q *Queries) DeleteWebhook(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteWebhook, id)
+	return err
+}
+
+const getWebhook = `-- name: GetWebhook :one
+SELECT id, owner, url, secret, events, active, created_at FROM webhooks
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetWebhook(ctx context.Context, id int64) (Webhook, error) {
+	row := q.db.QueryRowContext(ctx, getWebhook, id)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Url,
+		&i.Secret,
+		pq.Array(&i.Events),
+		&i.Active,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listSubscribedWebhooks = `-- name: ListSubscribedWebhooks :many
+SELECT id, owner, url, secret, events, active, created_at FROM webhooks
+WHERE owner = $1 AND active AND $2::varchar = ANY(events)
+ORDER BY id
+`
+
+type ListSubscribedWebhooksParams struct {
+	Owner     string
+	EventType string
+}
+
+func (q *Queries) ListSubscribedWebhooks(ctx context.Context, arg ListSubscribedWebhooksParams) ([]Webhook, error) {
+	rows, err := q.db.QueryContext(ctx, listSubscribedWebhooks, arg.Owner, arg.EventType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Url,
+			&i.Secret,
+			pq.Array(&i.Events),
+			&i.Active,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at, response_status, last_error, delivered_at, created_at FROM webhook_deliveries
+WHERE webhook_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListWebhookDeliveriesParams struct {
+	WebhookID int64
+	Limit     int32
+	Offset    int32
+}
+
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookDeliveries, arg.WebhookID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.DeliveredAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhooks = `-- name: ListWebhooks :many
+SELECT id, owner, url, secret, events, active, created_at FROM webhooks
+WHERE owner = $1
+ORDER BY id
+`
+
+func (q *Queries) ListWebhooks(ctx context.Context, owner string) ([]Webhook, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhooks, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Url,
+			&i.Secret,
+			pq.Array(&i.Events),
+			&i.Active,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateWebhookDelivery = `-- name: UpdateWebhookDelivery :one
+UPDATE webhook_deliveries
+SET
+    status = $2,
+    attempts = $3,
+    next_attempt_at = $4,
+    response_status = $5,
+    last_error = $6,
+    delivered_at = $7
+WHERE id = $1
+RETURNING id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at, response_status, last_error, delivered_at, created_at
+`
+
+type UpdateWebhookDeliveryParams struct {
+	ID             int64
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	ResponseStatus sql.NullInt32
+	LastError      string
+	DeliveredAt    sql.NullTime
+}
+
+func (q *Queries) UpdateWebhookDelivery(ctx context.Context, arg UpdateWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRowContext(ctx, updateWebhookDelivery,
+		arg.ID,
+		arg.Status,
+		arg.Attempts,
+		arg.NextAttemptAt,
+		arg.ResponseStatus,
+		arg.LastError,
+		arg.DeliveredAt,
+	)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.WebhookID,
+		&i.EventID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.ResponseStatus,
+		&i.LastError,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
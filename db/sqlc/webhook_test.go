@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"simple-bank/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomTestWebhook(t *testing.T, owner string, events ...string) Webhook {
+	args := CreateWebhookParams{
+		Owner:  owner,
+		Url:    "https://example.com/hooks/" + utils.RandomString(6),
+		Secret: utils.RandomString(32),
+		Events: events,
+	}
+
+	webhook, err := testQueries.CreateWebhook(context.Background(), args)
+	require.NoError(t, err)
+	require.NotZero(t, webhook.ID)
+	require.Equal(t, args.Owner, webhook.Owner)
+	require.Equal(t, args.Url, webhook.Url)
+	require.Equal(t, args.Events, webhook.Events)
+	require.True(t, webhook.Active)
+
+	return webhook
+}
+
+func TestClaimWebhookDeliveries(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomTestUser(t)
+	webhook := createRandomTestWebhook(t, user.Username, EventTransferCreated)
+
+	event, err := testQueries.EnqueueEvent(context.Background(), EventTransferCreated, user.Username, TransferEvent{TransferID: 1})
+	require.NoError(t, err)
+
+	err = store.CreateWebhookDelivery(context.Background(), CreateWebhookDeliveryParams{
+		WebhookID: webhook.ID,
+		EventID:   event.ID,
+		EventType: event.Topic,
+		Payload:   event.Payload,
+	})
+	require.NoError(t, err)
+
+	leaseUntil := time.Now().Add(time.Minute)
+	claimed, err := store.ClaimWebhookDeliveries(context.Background(), ClaimWebhookDeliveriesParams{
+		LeaseUntil:    leaseUntil,
+		MaxDeliveries: 1000,
+	})
+	require.NoError(t, err)
+
+	var delivery WebhookDelivery
+	for _, d := range claimed {
+		if d.WebhookID == webhook.ID {
+			delivery = d
+		}
+	}
+	require.NotZero(t, delivery.ID)
+	require.WithinDuration(t, leaseUntil, delivery.NextAttemptAt, time.Second)
+
+	// leased deliveries can't be claimed twice
+	claimed, err = store.ClaimWebhookDeliveries(context.Background(), ClaimWebhookDeliveriesParams{
+		LeaseUntil:    leaseUntil,
+		MaxDeliveries: 1000,
+	})
+	require.NoError(t, err)
+	for _, d := range claimed {
+		require.NotEqual(t, delivery.ID, d.ID)
+	}
+
+	updated, err := store.UpdateWebhookDelivery(context.Background(), UpdateWebhookDeliveryParams{
+		ID:             delivery.ID,
+		Status:         "succeeded",
+		Attempts:       1,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		ResponseStatus: sql.NullInt32{Int32: 200, Valid: true},
+		DeliveredAt:    sql.NullTime{Time: time.Now(), Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "succeeded", updated.Status)
+	require.Equal(t, int32(1), updated.Attempts)
+	require.True(t, updated.DeliveredAt.Valid)
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	user := createRandomTestUser(t)
+	webhook := createRandomTestWebhook(t, user.Username, EventTransferCreated)
+
+	err := testQueries.DeleteWebhook(context.Background(), webhook.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetWebhook(context.Background(), webhook.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -8,14 +8,32 @@ CREATE TABLE "users" (
   "full_name" varchar NOT NULL,
   "email" varchar UNIQUE NOT NULL,
   "password_changed_at" timestamptz NOT NULL DEFAULT '0001-01-01',
+  "tier" varchar NOT NULL DEFAULT 'standard',
+  "alias" varchar UNIQUE,
+  "email_verified" boolean NOT NULL DEFAULT false,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
 CREATE TABLE "accounts" (
   "id" bigserial PRIMARY KEY,
+  "number" varchar UNIQUE NOT NULL,
   "owner" varchar NOT NULL,
   "balance" bigint NOT NULL,
+  "held_balance" bigint NOT NULL DEFAULT 0,
   "currency" varchar NOT NULL,
+  "product" varchar NOT NULL DEFAULT 'checking',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "account_members" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "username" varchar NOT NULL,
+  "role" varchar NOT NULL,
+  "spend_limit" bigint NOT NULL DEFAULT 0,
+  "status" varchar NOT NULL DEFAULT 'invited',
+  "invited_by" varchar NOT NULL,
+  "accepted_at" timestamptz,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
@@ -23,6 +41,18 @@ CREATE TABLE "entries" (
   "id" bigserial PRIMARY KEY,
   "account_id" bigint NOT NULL,
   "amount" bigint NOT NULL,
+  "transfer_id" bigint,
+  "journal_transaction_id" bigint,
+  "category_id" bigint,
+  "tags" varchar[] NOT NULL DEFAULT '{}',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "journal_transactions" (
+  "id" bigserial PRIMARY KEY,
+  "type" varchar NOT NULL,
+  "reference" varchar NOT NULL,
+  "metadata" jsonb NOT NULL DEFAULT '{}',
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
@@ -31,6 +61,96 @@ CREATE TABLE "transfers" (
   "from_account_id" bigint NOT NULL,
   "to_account_id" bigint NOT NULL,
   "amount" bigint NOT NULL,
+  "fee" bigint NOT NULL DEFAULT 0,
+  "description" varchar NOT NULL DEFAULT '',
+  "reference" varchar NOT NULL DEFAULT '',
+  "metadata" jsonb NOT NULL DEFAULT '{}',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "payees" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "nickname" varchar NOT NULL,
+  "account_id" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "payment_requests" (
+  "id" bigserial PRIMARY KEY,
+  "requester" varchar NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "payer" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "note" varchar NOT NULL DEFAULT '',
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "transfer_id" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "responded_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "holds" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "fee" bigint NOT NULL DEFAULT 0,
+  "captured_amount" bigint NOT NULL DEFAULT 0,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "transfer_id" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "settled_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "categories" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "name" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "category_rules" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "category_id" bigint NOT NULL,
+  "counterparty_account_id" bigint,
+  "memo_keyword" varchar,
+  "min_amount" bigint,
+  "max_amount" bigint,
+  "priority" int NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "fee_rules" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "kind" varchar NOT NULL,
+  "priority" integer NOT NULL DEFAULT 0,
+  "cross_user_only" boolean NOT NULL DEFAULT false,
+  "min_amount" bigint NOT NULL DEFAULT 0,
+  "flat_fee" bigint NOT NULL DEFAULT 0,
+  "basis_points" integer NOT NULL DEFAULT 0,
+  "tiers" jsonb NOT NULL DEFAULT '[]',
+  "min_fee" bigint NOT NULL DEFAULT 0,
+  "max_fee" bigint NOT NULL DEFAULT 0,
+  "product" varchar,
+  "active" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "transfer_limits" (
+  "id" bigserial PRIMARY KEY,
+  "tier" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "max_per_transfer" bigint NOT NULL DEFAULT 0,
+  "max_per_day" bigint NOT NULL DEFAULT 0,
+  "max_per_30_days" bigint NOT NULL DEFAULT 0,
+  "max_transfers_per_hour" bigint NOT NULL DEFAULT 0,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
@@ -45,28 +165,337 @@ CREATE TABLE "sessions" (
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
+CREATE TABLE "email_verifications" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "email" varchar NOT NULL,
+  "code_hash" varchar NOT NULL,
+  "used_at" timestamptz,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "outbox" (
+  "id" bigserial PRIMARY KEY,
+  "topic" varchar NOT NULL,
+  "key" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "processed_at" timestamptz,
+  "attempts" integer NOT NULL DEFAULT 0,
+  "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+  "last_error" varchar NOT NULL DEFAULT ''
+);
+
+CREATE TABLE "outbox_dead_letters" (
+  "id" bigserial PRIMARY KEY,
+  "event_id" bigint UNIQUE NOT NULL,
+  "topic" varchar NOT NULL,
+  "key" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "attempts" integer NOT NULL,
+  "last_error" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL,
+  "dead_lettered_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhooks" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "url" varchar NOT NULL,
+  "secret" varchar NOT NULL,
+  "events" varchar[] NOT NULL,
+  "active" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_deliveries" (
+  "id" bigserial PRIMARY KEY,
+  "webhook_id" bigint NOT NULL,
+  "event_id" bigint NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "attempts" integer NOT NULL DEFAULT 0,
+  "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+  "response_status" integer,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "delivered_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "account_statements" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "period" date NOT NULL,
+  "storage_key" varchar NOT NULL,
+  "size" bigint NOT NULL,
+  "opening_balance" bigint NOT NULL,
+  "closing_balance" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "balance_snapshots" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "balance" bigint NOT NULL,
+  "taken_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "account_products" (
+  "code" varchar PRIMARY KEY,
+  "name" varchar NOT NULL,
+  "interest_rate_bps" integer NOT NULL DEFAULT 0,
+  "currencies" varchar[] NOT NULL DEFAULT '{}',
+  "overdraft_limit" bigint NOT NULL DEFAULT 0,
+  "max_transfers_per_month" integer NOT NULL DEFAULT 0,
+  "own_accounts_only" boolean NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "interest_accruals" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "interest_rate_bps" integer NOT NULL,
+  "amount_micros" bigint NOT NULL,
+  "journal_transaction_id" bigint,
+  "posted_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
 CREATE INDEX ON "accounts" ("owner");
 
-CREATE UNIQUE INDEX ON "accounts" ("owner", "currency");
+CREATE UNIQUE INDEX ON "accounts" ("owner", "currency", "product");
+
+CREATE UNIQUE INDEX ON "account_members" ("account_id", "username");
+
+CREATE INDEX ON "account_members" ("username", "status");
+
+CREATE INDEX ON "email_verifications" ("username", "code_hash");
 
 CREATE INDEX ON "entries" ("account_id");
 
+CREATE INDEX ON "entries" ("transfer_id");
+
+CREATE INDEX ON "entries" ("journal_transaction_id");
+
+CREATE INDEX ON "journal_transactions" ("reference");
+
+CREATE INDEX ON "fee_rules" ("currency", "priority");
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
+
+CREATE INDEX ON "transfers" ("to_account_id", "created_at");
+
+CREATE UNIQUE INDEX ON "transfer_limits" ("tier", "currency");
+
+CREATE INDEX ON "entries" ("account_id", "created_at");
+
 CREATE INDEX ON "transfers" ("from_account_id");
 
 CREATE INDEX ON "transfers" ("to_account_id");
 
 CREATE INDEX ON "transfers" ("from_account_id", "to_account_id");
 
+CREATE INDEX ON "transfers" ("from_account_id", "reference");
+
+CREATE INDEX ON "transfers" ("to_account_id", "reference");
+
+CREATE UNIQUE INDEX ON "payees" ("owner", "account_id");
+
+CREATE INDEX ON "payment_requests" ("requester", "created_at");
+
+CREATE INDEX ON "payment_requests" ("payer", "created_at");
+
+CREATE INDEX ON "payment_requests" ("status", "expires_at");
+
+CREATE INDEX ON "holds" ("account_id");
+
+CREATE INDEX ON "holds" ("status", "expires_at");
+
+CREATE INDEX ON "entries" ("category_id");
+
+CREATE UNIQUE INDEX ON "categories" ("owner", "name");
+
+CREATE INDEX ON "category_rules" ("owner", "priority");
+
+CREATE INDEX ON "webhooks" ("owner");
+
+CREATE UNIQUE INDEX ON "webhook_deliveries" ("webhook_id", "event_id");
+
+CREATE INDEX ON "webhook_deliveries" ("status", "next_attempt_at");
+
+CREATE UNIQUE INDEX ON "account_statements" ("account_id", "period");
+
+CREATE UNIQUE INDEX ON "balance_snapshots" ("account_id", "taken_at");
+
+CREATE UNIQUE INDEX ON "interest_accruals" ("account_id", "accrual_date");
+
+CREATE INDEX ON "interest_accruals" ("posted_at", "accrual_date");
+
+COMMENT ON COLUMN "users"."alias" IS 'user-chosen handle to receive transfers, sent to as @alias';
+
+COMMENT ON COLUMN "users"."email_verified" IS 'only verified emails resolve transfer recipients';
+
+COMMENT ON COLUMN "accounts"."number" IS 'public account number with check digits';
+
+COMMENT ON COLUMN "accounts"."held_balance" IS 'sum of pending holds, the available balance is balance - held_balance';
+
+COMMENT ON COLUMN "account_members"."role" IS 'owner, co_owner, viewer or spender';
+
+COMMENT ON COLUMN "account_members"."spend_limit" IS 'most a spender can send in one transfer, unused for other roles';
+
+COMMENT ON COLUMN "account_members"."status" IS 'invited until the user accepts, then active';
+
+COMMENT ON COLUMN "email_verifications"."email" IS 'the address the code was sent to, verifying it fails once the user changes email';
+
+COMMENT ON COLUMN "email_verifications"."code_hash" IS 'sha256 of the code, the code itself is only sent by email';
+
 COMMENT ON COLUMN "entries"."amount" IS 'can be negative or positive';
 
+COMMENT ON COLUMN "entries"."category_id" IS 'set by the owner or by their category rules when booked';
+
+COMMENT ON TABLE "journal_transactions" IS 'the entries of a journal transaction sum to zero per currency, checked by the entries_journal_balanced trigger';
+
+COMMENT ON COLUMN "journal_transactions"."type" IS 'transfer, deposit, fee, fx, reversal or interest';
+
 COMMENT ON COLUMN "transfers"."amount" IS 'must be positive';
 
+COMMENT ON COLUMN "transfers"."fee" IS 'debited from the sender on top of amount';
+
+COMMENT ON COLUMN "transfers"."description" IS 'memo shown to both sides of the transfer';
+
+COMMENT ON COLUMN "transfers"."reference" IS 'reference set by the sending client, searchable';
+
+COMMENT ON COLUMN "transfers"."metadata" IS 'free-form JSON object set by the sending client';
+
+COMMENT ON COLUMN "payees"."created_at" IS 'large transfers to the payee are held back for a cooling-off period from here';
+
+COMMENT ON COLUMN "payment_requests"."to_account_id" IS 'the requester account the payment goes to';
+
+COMMENT ON COLUMN "payment_requests"."status" IS 'pending, then paid, declined, cancelled or expired';
+
+COMMENT ON COLUMN "payment_requests"."transfer_id" IS 'the transfer paying an accepted request';
+
+COMMENT ON COLUMN "holds"."fee" IS 'the transfer fee reserved along with amount';
+
+COMMENT ON COLUMN "holds"."status" IS 'pending, captured, voided or expired';
+
+COMMENT ON COLUMN "holds"."transfer_id" IS 'the transfer a captured hold turned into';
+
+COMMENT ON COLUMN "category_rules"."memo_keyword" IS 'matches transfers whose description contains it, case insensitive';
+
+COMMENT ON COLUMN "category_rules"."priority" IS 'the matching rule with the lowest priority sets the category';
+
+COMMENT ON TABLE "transfer_limits" IS 'limits are shared by the accounts of an owner in the currency, 0 means unlimited';
+
+COMMENT ON COLUMN "transfer_limits"."max_per_day" IS 'total amount sent since midnight UTC';
+
+COMMENT ON COLUMN "transfer_limits"."max_per_30_days" IS 'total amount sent over the last 30 days';
+
+COMMENT ON COLUMN "transfer_limits"."max_transfers_per_hour" IS 'number of transfers sent over the last hour';
+
+COMMENT ON COLUMN "fee_rules"."kind" IS 'flat, percentage or tiered';
+
+COMMENT ON COLUMN "fee_rules"."priority" IS 'the first matching rule, lowest priority first, sets the fee';
+
+COMMENT ON COLUMN "fee_rules"."min_amount" IS 'the rule applies to transfers of at least this amount';
+
+COMMENT ON COLUMN "fee_rules"."tiers" IS 'tiered rules: [{"up_to", "flat_fee", "basis_points"}], up_to 0 is unbounded';
+
+COMMENT ON COLUMN "fee_rules"."max_fee" IS '0 means uncapped';
+
+COMMENT ON COLUMN "outbox"."key" IS 'routing key, the username for account and transfer events';
+
+COMMENT ON COLUMN "webhook_deliveries"."status" IS 'pending, succeeded or failed';
+
+COMMENT ON COLUMN "account_statements"."period" IS 'first day of the month the statement covers';
+
+COMMENT ON COLUMN "balance_snapshots"."balance" IS 'balance including every entry created up to taken_at';
+
+COMMENT ON COLUMN "account_products"."interest_rate_bps" IS 'annual interest rate in basis points, accrued daily on actual/365';
+
+COMMENT ON COLUMN "account_products"."currencies" IS 'currencies accounts can be opened in, any supported one when empty';
+
+COMMENT ON COLUMN "account_products"."overdraft_limit" IS 'how far below 0 debits may take the available balance';
+
+COMMENT ON COLUMN "account_products"."max_transfers_per_month" IS 'outgoing transfers per UTC calendar month, 0 is unlimited';
+
+COMMENT ON COLUMN "account_products"."own_accounts_only" IS 'outgoing transfers may only go to accounts of the same owner';
+
+COMMENT ON COLUMN "fee_rules"."product" IS 'the rule only applies to senders of this product, to all when null';
+
+COMMENT ON COLUMN "interest_accruals"."balance" IS 'end-of-day balance the interest was computed on';
+
+COMMENT ON COLUMN "interest_accruals"."amount_micros" IS 'interest in millionths of the minor unit, rounded half to even';
+
+COMMENT ON COLUMN "interest_accruals"."posted_at" IS 'set once the month is paid out, journal_transaction_id is null when it rounded to 0';
+
 ALTER TABLE "accounts" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
 
 ALTER TABLE "entries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
 
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("journal_transaction_id") REFERENCES "journal_transactions" ("id");
+
 ALTER TABLE "transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
 
 ALTER TABLE "transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
 
+ALTER TABLE "payees" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("requester") REFERENCES "users" ("username");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("payer") REFERENCES "users" ("username");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "categories" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "category_rules" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "category_rules" ADD FOREIGN KEY ("counterparty_account_id") REFERENCES "accounts" ("id");
+
 ALTER TABLE "sessions" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "webhooks" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("webhook_id") REFERENCES "webhooks" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "account_statements" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "balance_snapshots" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("product") REFERENCES "account_products" ("code");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("username");
+
+ALTER TABLE "fee_rules" ADD FOREIGN KEY ("product") REFERENCES "account_products" ("code");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("journal_transaction_id") REFERENCES "journal_transactions" ("id");
+
+ALTER TABLE "category_rules" ADD FOREIGN KEY ("category_id") REFERENCES "categories" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("category_id") REFERENCES "categories" ("id") ON DELETE SET NULL;
+
+ALTER TABLE "email_verifications" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
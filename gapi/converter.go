@@ -0,0 +1,63 @@
+package gapi
+
+import (
+	"encoding/json"
+	db "simple-bank/db/sqlc"
+	"simple-bank/pb"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func convertUser(user db.User) *pb.User {
+	return &pb.User{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
+		CreatedAt:         timestamppb.New(user.CreatedAt),
+	}
+}
+
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Number:    account.Number,
+		Owner:     account.Owner,
+		Balance:   account.Balance,
+		Currency:  account.Currency,
+		CreatedAt: timestamppb.New(account.CreatedAt),
+	}
+}
+
+// convertTransfer refers to the accounts by number, internal IDs aren't exposed
+func convertTransfer(transfer db.Transfer, fromAccount db.Account, toAccount db.Account) *pb.Transfer {
+	return &pb.Transfer{
+		Id:          transfer.ID,
+		FromAccount: fromAccount.Number,
+		ToAccount:   toAccount.Number,
+		Amount:      transfer.Amount,
+		CreatedAt:   timestamppb.New(transfer.CreatedAt),
+		Description: transfer.Description,
+		Reference:   transfer.Reference,
+		Metadata:    convertMetadata(transfer.Metadata),
+	}
+}
+
+// convertMetadata turns the stored JSON object of a transfer into a Struct, nil when empty
+func convertMetadata(metadata json.RawMessage) *structpb.Struct {
+	object := &structpb.Struct{}
+	if err := protojson.Unmarshal(metadata, object); err != nil || len(object.GetFields()) == 0 {
+		return nil
+	}
+	return object
+}
+
+func convertEntry(entry db.Entry, account db.Account) *pb.Entry {
+	return &pb.Entry{
+		Id:        entry.ID,
+		Account:   account.Number,
+		Amount:    entry.Amount,
+		CreatedAt: timestamppb.New(entry.CreatedAt),
+	}
+}
@@ -0,0 +1,49 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	db "simple-bank/db/sqlc"
+	"simple-bank/token"
+	"simple-bank/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := utils.Config{
+		TokenSymmetricKey:   utils.RandomString(32),
+		ExpiryTokenDuration: time.Minute,
+	}
+
+	server, err := NewServer(config, store)
+	require.NoError(t, err)
+
+	return server
+}
+
+func newContextWithBearerToken(t *testing.T, tokenMaker token.Maker, username string, duration time.Duration) context.Context {
+	accessToken, _, err := tokenMaker.CreateToken(username, duration)
+	require.NoError(t, err)
+
+	bearerToken := fmt.Sprintf("%s %s", authorizationBearer, accessToken)
+	md := metadata.MD{
+		authorizationHeader: []string{bearerToken},
+	}
+
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func randomAccount(owner string) db.Account {
+	return db.Account{
+		ID:        utils.RandomInt(1, 1000),
+		Number:    utils.RandomAccountNumber(),
+		Owner:     owner,
+		Balance:   utils.RandomMoney(),
+		Currency:  utils.RandomCurrency(),
+		CreatedAt: time.Now().UTC(),
+	}
+}
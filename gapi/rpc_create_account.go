@@ -0,0 +1,76 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "simple-bank/db/sqlc"
+	"simple-bank/pb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if err := validateCurrency(req.GetCurrency()); err != nil {
+		violations := []*errdetails.BadRequest_FieldViolation{fieldViolation("currency", err)}
+		return nil, invalidArgumentError(violations)
+	}
+
+	code := req.GetProduct()
+	if code == "" {
+		code = db.ProductChecking
+	}
+	product, err := server.getAccountProduct(ctx, code, req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	args := db.CreateAccountParams{
+		Owner:    authPayload.Username,
+		Balance:  0,
+		Currency: req.GetCurrency(),
+		Product:  product.Code,
+	}
+
+	account, err := db.CreateAccountWithNewNumber(ctx, server.store, args)
+	if err != nil {
+		switch {
+		case db.ConstraintName(err) == "owner_currency_product_key":
+			return nil, status.Errorf(codes.AlreadyExists, "a %s account in %s already exists", product.Code, req.GetCurrency())
+		case db.ErrorCode(err) == db.ForeignKeyViolation:
+			return nil, status.Errorf(codes.FailedPrecondition, "user %s doesn't exist", authPayload.Username)
+		}
+		return nil, internalError("failed to create account: %s", err)
+	}
+
+	res := &pb.CreateAccountResponse{
+		Account: convertAccount(account),
+	}
+	return res, nil
+}
+
+// getAccountProduct loads the product an account is opened with, as the REST API does
+func (server *Server) getAccountProduct(ctx context.Context, code string, currency string) (db.AccountProduct, error) {
+	product, err := server.store.GetAccountProduct(ctx, code)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			violations := []*errdetails.BadRequest_FieldViolation{fieldViolation("product", fmt.Errorf("product [%s] not found", code))}
+			return product, invalidArgumentError(violations)
+		}
+		return product, internalError("failed to get account product: %s", err)
+	}
+
+	if !product.AllowsCurrency(currency) {
+		violations := []*errdetails.BadRequest_FieldViolation{fieldViolation("product", fmt.Errorf("product [%s] isn't offered in %s", code, currency))}
+		return product, invalidArgumentError(violations)
+	}
+
+	return product, nil
+}
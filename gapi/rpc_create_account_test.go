@@ -0,0 +1,136 @@
+package gapi
+
+import (
+	mockdb "simple-bank/db/mock"
+	db "simple-bank/db/sqlc"
+	"simple-bank/pb"
+	"simple-bank/utils"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateAccountAPI(t *testing.T) {
+	owner := utils.RandomOwner()
+	account := randomAccount(owner)
+	account.Currency = utils.USD
+	account.Product = db.ProductSavings
+
+	savings := db.AccountProduct{Code: db.ProductSavings, Currencies: []string{utils.USD}}
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateAccountRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.CreateAccountResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.CreateAccountRequest{Currency: utils.USD, Product: db.ProductSavings},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountProduct(gomock.Any(), gomock.Eq(db.ProductSavings)).Times(1).Return(savings, nil)
+				store.EXPECT().
+					CreateAccountTxn(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, args db.CreateAccountParams) (db.Account, error) {
+						require.Equal(t, owner, args.Owner)
+						require.Equal(t, db.ProductSavings, args.Product)
+						require.True(t, utils.ValidAccountNumber(args.Number))
+						return account, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.Number, res.GetAccount().GetNumber())
+			},
+		},
+		{
+			name: "DefaultsToChecking",
+			req:  &pb.CreateAccountRequest{Currency: utils.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountProduct(gomock.Any(), gomock.Eq(db.ProductChecking)).Times(1).Return(db.AccountProduct{Code: db.ProductChecking}, nil)
+				store.EXPECT().CreateAccountTxn(gomock.Any(), gomock.Any()).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "AccountNumberTaken",
+			req:  &pb.CreateAccountRequest{Currency: utils.USD, Product: db.ProductSavings},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountProduct(gomock.Any(), gomock.Eq(db.ProductSavings)).Times(1).Return(savings, nil)
+				gomock.InOrder(
+					store.EXPECT().
+						CreateAccountTxn(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.Account{}, &pq.Error{Code: "23505", Constraint: db.AccountNumberKey}),
+					store.EXPECT().CreateAccountTxn(gomock.Any(), gomock.Any()).Times(1).Return(account, nil),
+				)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.Number, res.GetAccount().GetNumber())
+			},
+		},
+		{
+			name: "ProductNotFound",
+			req:  &pb.CreateAccountRequest{Currency: utils.USD, Product: "gold"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountProduct(gomock.Any(), gomock.Eq("gold")).Times(1).Return(db.AccountProduct{}, db.ErrRecordNotFound)
+				store.EXPECT().CreateAccountTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "ProductCurrencyNotOffered",
+			req:  &pb.CreateAccountRequest{Currency: utils.EUR, Product: db.ProductSavings},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountProduct(gomock.Any(), gomock.Eq(db.ProductSavings)).Times(1).Return(savings, nil)
+				store.EXPECT().CreateAccountTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "AlreadyExists",
+			req:  &pb.CreateAccountRequest{Currency: utils.USD, Product: db.ProductSavings},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountProduct(gomock.Any(), gomock.Eq(db.ProductSavings)).Times(1).Return(savings, nil)
+				store.EXPECT().
+					CreateAccountTxn(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, &pq.Error{Code: "23505", Constraint: "owner_currency_product_key"})
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.Equal(t, codes.AlreadyExists, status.Code(err))
+				require.Contains(t, status.Convert(err).Message(), db.ProductSavings)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, owner, time.Minute)
+			res, err := server.CreateAccount(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
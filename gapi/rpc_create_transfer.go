@@ -0,0 +1,138 @@
+package gapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	db "simple-bank/db/sqlc"
+	"simple-bank/pb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateCreateTransferRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	var metadata json.RawMessage
+	if req.GetMetadata() != nil {
+		if metadata, err = protojson.Marshal(req.GetMetadata()); err != nil {
+			return nil, internalError("failed to encode metadata: %s", err)
+		}
+	}
+
+	fromAccount, member, err := server.getUserAccount(ctx, req.GetFromAccount(), authPayload.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	if !member.CanSpend(req.GetAmount()) {
+		return nil, status.Errorf(codes.PermissionDenied, "%s of account [%s] can't send %d from it", member.Role, req.GetFromAccount(), req.GetAmount())
+	}
+
+	if err := checkCurrency(fromAccount, req.GetCurrency()); err != nil {
+		return nil, err
+	}
+
+	toAccount, err := server.validAccount(ctx, req.GetToAccount(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := server.store.TransferTxn(ctx, db.TransferTxnParam{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        req.GetAmount(),
+		Description:   req.GetDescription(),
+		Reference:     req.GetReference(),
+		Metadata:      metadata,
+		Sender:        authPayload.Username,
+		CoolingOff:    server.payeeCoolingOff(),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrSenderNotAllowed) {
+			return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrLimitExceeded) || errors.Is(err, db.ErrOwnAccountsOnly) || errors.Is(err, db.ErrPayeeCoolingOff) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, internalError("failed to transfer: %s", err)
+	}
+
+	res := &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer, result.FromAccount, result.ToAccount),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry, result.FromAccount),
+		ToEntry:     convertEntry(result.ToEntry, result.ToAccount),
+	}
+	return res, nil
+}
+
+// payeeCoolingOff is the cooling-off policy transfers are made with, as on the REST API
+func (server *Server) payeeCoolingOff() db.PayeeCoolingOff {
+	return db.PayeeCoolingOff{
+		Period: server.config.PayeeCoolingOffPeriod,
+		Limit:  server.config.PayeeCoolingOffLimit,
+	}
+}
+
+func (server *Server) validAccount(ctx context.Context, number string, currency string) (db.Account, error) {
+	account, err := server.store.GetAccountByNumber(ctx, number)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return account, accountNotFoundError(number)
+		}
+		return account, internalError("failed to get account: %s", err)
+	}
+
+	return account, checkCurrency(account, currency)
+}
+
+func checkCurrency(account db.Account, currency string) error {
+	if account.Currency != currency {
+		return status.Errorf(codes.InvalidArgument, "account [%s] currency mismatch: %s vs %s", account.Number, account.Currency, currency)
+	}
+	return nil
+}
+
+func validateCreateTransferRequest(req *pb.CreateTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validateAccountNumber(req.GetFromAccount()); err != nil {
+		violations = append(violations, fieldViolation("from_account", err))
+	}
+
+	if err := validateAccountNumber(req.GetToAccount()); err != nil {
+		violations = append(violations, fieldViolation("to_account", err))
+	}
+
+	if err := validateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	if err := validatePositive(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := validateString(req.GetDescription(), 0, maxDescriptionLength); err != nil {
+		violations = append(violations, fieldViolation("description", err))
+	}
+
+	if err := validateString(req.GetReference(), 0, maxReferenceLength); err != nil {
+		violations = append(violations, fieldViolation("reference", err))
+	}
+
+	if err := validateMetadata(req.GetMetadata()); err != nil {
+		violations = append(violations, fieldViolation("metadata", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,300 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	mockdb "simple-bank/db/mock"
+	db "simple-bank/db/sqlc"
+	"simple-bank/pb"
+	"simple-bank/token"
+	"simple-bank/utils"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestCreateTransferAPI(t *testing.T) {
+	amount := int64(10)
+
+	account1 := randomAccount(utils.RandomOwner())
+	account2 := randomAccount(utils.RandomOwner())
+	account3 := randomAccount(utils.RandomOwner())
+	account2.ID = account1.ID + 1
+	account3.ID = account1.ID + 2
+
+	account1.Currency = utils.USD
+	account2.Currency = utils.USD
+	account3.Currency = utils.EUR
+
+	coolingOff := db.PayeeCoolingOff{Period: time.Hour, Limit: amount - 1}
+
+	metadata, err := structpb.NewStruct(map[string]any{"order": "42"})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateTransferRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, res *pb.CreateTransferResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.CreateTransferRequest{
+				FromAccount: account1.Number,
+				ToAccount:   account2.Number,
+				Currency:    utils.USD,
+				Amount:      amount,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(account2, nil)
+
+				arg := db.TransferTxnParam{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        amount,
+					Sender:        account1.Owner,
+					CoolingOff:    coolingOff,
+				}
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, account1.Owner, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, res)
+			},
+		},
+		{
+			name: "WithDetails",
+			req: &pb.CreateTransferRequest{
+				FromAccount: account1.Number,
+				ToAccount:   account2.Number,
+				Currency:    utils.USD,
+				Amount:      amount,
+				Description: "rent",
+				Reference:   "INV-7",
+				Metadata:    metadata,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					TransferTxn(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, args db.TransferTxnParam) (db.TransferTxnResult, error) {
+						require.Equal(t, "rent", args.Description)
+						require.Equal(t, "INV-7", args.Reference)
+						require.JSONEq(t, `{"order":"42"}`, string(args.Metadata))
+						return db.TransferTxnResult{Transfer: db.Transfer{
+							Amount:      args.Amount,
+							Description: args.Description,
+							Reference:   args.Reference,
+							Metadata:    args.Metadata,
+						}}, nil
+					})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, account1.Owner, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "rent", res.GetTransfer().GetDescription())
+				require.Equal(t, "INV-7", res.GetTransfer().GetReference())
+				require.Equal(t, "42", res.GetTransfer().GetMetadata().GetFields()["order"].GetStringValue())
+			},
+		},
+		{
+			name: "ReferenceTooLong",
+			req: &pb.CreateTransferRequest{
+				FromAccount: account1.Number,
+				ToAccount:   account2.Number,
+				Currency:    utils.USD,
+				Amount:      amount,
+				Reference:   utils.RandomString(65),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, account1.Owner, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "PayeeCoolingOff",
+			req: &pb.CreateTransferRequest{
+				FromAccount: account1.Number,
+				ToAccount:   account2.Number,
+				Currency:    utils.USD,
+				Amount:      amount,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					TransferTxn(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxnResult{}, coolingOff.Check(1, time.Now(), amount))
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, account1.Owner, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+				require.Nil(t, res)
+			},
+		},
+		{
+			name: "FromAccountOfAnotherUser",
+			req: &pb.CreateTransferRequest{
+				FromAccount: account1.Number,
+				ToAccount:   account2.Number,
+				Currency:    utils.USD,
+				Amount:      amount,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().GetActiveAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(0)
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, account2.Owner, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "ViewerOfAccount",
+			req: &pb.CreateTransferRequest{
+				FromAccount: account1.Number,
+				ToAccount:   account2.Number,
+				Currency:    utils.USD,
+				Amount:      amount,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					GetActiveAccountMember(gomock.Any(), gomock.Eq(db.GetActiveAccountMemberParams{AccountID: account1.ID, Username: account2.Owner})).
+					Times(1).
+					Return(db.AccountMember{AccountID: account1.ID, Username: account2.Owner, Role: db.MemberViewer, Status: db.MemberActive}, nil)
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, account2.Owner, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "ToAccountNotFound",
+			req: &pb.CreateTransferRequest{
+				FromAccount: account1.Number,
+				ToAccount:   account2.Number,
+				Currency:    utils.USD,
+				Amount:      amount,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.Number)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, account1.Owner, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			req: &pb.CreateTransferRequest{
+				FromAccount: account1.Number,
+				ToAccount:   account3.Number,
+				Currency:    utils.USD,
+				Amount:      amount,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.Number)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account3.Number)).Times(1).Return(account3, nil)
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, account1.Owner, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "NegativeAmount",
+			req: &pb.CreateTransferRequest{
+				FromAccount: account1.Number,
+				ToAccount:   account2.Number,
+				Currency:    utils.USD,
+				Amount:      -amount,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, account1.Owner, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidToAccountNumber",
+			req: &pb.CreateTransferRequest{
+				FromAccount: account1.Number,
+				ToAccount:   account2.Number[:len(account2.Number)-1] + "x",
+				Currency:    utils.USD,
+				Amount:      amount,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTxn(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, account1.Owner, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.PayeeCoolingOffPeriod = coolingOff.Period
+			server.config.PayeeCoolingOffLimit = coolingOff.Limit
+			ctx := tc.buildContext(t, server.tokenMaker)
+			res, err := server.CreateTransfer(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package gapi
+
+import (
+	"context"
+	db "simple-bank/db/sqlc"
+	"simple-bank/pb"
+	"simple-bank/utils"
+
+	"github.com/lib/pq"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	if violations := validateCreateUserRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	hashedPassword, err := utils.HashPassword(req.GetPassword())
+	if err != nil {
+		return nil, internalError("failed to hash password: %s", err)
+	}
+
+	args := db.CreateUserParams{
+		Username:       req.GetUsername(),
+		FullName:       req.GetFullName(),
+		HashedPassword: hashedPassword,
+		Email:          req.GetEmail(),
+	}
+
+	user, err := server.store.CreateUser(ctx, args)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				return nil, status.Errorf(codes.AlreadyExists, "username already exists: %s", err)
+			}
+		}
+		return nil, internalError("failed to create user: %s", err)
+	}
+
+	res := &pb.CreateUserResponse{
+		User: convertUser(user),
+	}
+	return res, nil
+}
+
+func validateCreateUserRequest(req *pb.CreateUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validateUsername(req.GetUsername()); err != nil {
+		violations = append(violations, fieldViolation("username", err))
+	}
+
+	if err := validatePassword(req.GetPassword()); err != nil {
+		violations = append(violations, fieldViolation("password", err))
+	}
+
+	if err := validateFullName(req.GetFullName()); err != nil {
+		violations = append(violations, fieldViolation("full_name", err))
+	}
+
+	if err := validateEmail(req.GetEmail()); err != nil {
+		violations = append(violations, fieldViolation("email", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	db "simple-bank/db/sqlc"
+	"simple-bank/pb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if err := validateAccountNumber(req.GetNumber()); err != nil {
+		violations := []*errdetails.BadRequest_FieldViolation{fieldViolation("number", err)}
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, _, err := server.getUserAccount(ctx, req.GetNumber(), authPayload.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pb.GetAccountResponse{
+		Account: convertAccount(account),
+	}
+	return res, nil
+}
+
+// getUserAccount loads an account username is an active member of, along with their
+// membership. Other accounts are reported exactly like missing ones so their existence
+// isn't disclosed.
+func (server *Server) getUserAccount(ctx context.Context, number string, username string) (db.Account, db.AccountMember, error) {
+	account, err := server.store.GetAccountByNumber(ctx, number)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return account, db.AccountMember{}, accountNotFoundError(number)
+		}
+		return account, db.AccountMember{}, internalError("failed to get account: %s", err)
+	}
+
+	if account.Owner == username {
+		return account, db.OwnerMember(account), nil
+	}
+
+	member, err := server.store.GetActiveAccountMember(ctx, db.GetActiveAccountMemberParams{
+		AccountID: account.ID,
+		Username:  username,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return db.Account{}, member, accountNotFoundError(number)
+		}
+		return db.Account{}, member, internalError("failed to get account member: %s", err)
+	}
+
+	return account, member, nil
+}
+
+func accountNotFoundError(number string) error {
+	return status.Errorf(codes.NotFound, "account [%s] not found", number)
+}
@@ -0,0 +1,52 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	db "simple-bank/db/sqlc"
+	"simple-bank/pb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := validateListAccountsRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	args := db.ListAccountParams{
+		Username: authPayload.Username,
+		Limit:    req.GetPageSize(),
+		Offset:   (req.GetPageId() - 1) * req.GetPageSize(),
+	}
+
+	accounts, err := server.store.ListAccount(ctx, args)
+	if err != nil {
+		return nil, internalError("failed to list accounts: %s", err)
+	}
+
+	res := &pb.ListAccountsResponse{
+		Accounts: make([]*pb.Account, 0, len(accounts)),
+	}
+	for _, account := range accounts {
+		res.Accounts = append(res.Accounts, convertAccount(account))
+	}
+	return res, nil
+}
+
+func validateListAccountsRequest(req *pb.ListAccountsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetPageId() < 1 {
+		violations = append(violations, fieldViolation("page_id", fmt.Errorf("must be at least 1")))
+	}
+
+	if req.GetPageSize() < 1 || req.GetPageSize() > 5 {
+		violations = append(violations, fieldViolation("page_size", fmt.Errorf("must be between 1 and 5")))
+	}
+
+	return violations
+}
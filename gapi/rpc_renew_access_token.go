@@ -0,0 +1,63 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"simple-bank/pb"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (server *Server) RenewAccessToken(ctx context.Context, req *pb.RenewAccessTokenRequest) (*pb.RenewAccessTokenResponse, error) {
+	if req.GetRefreshToken() == "" {
+		violations := []*errdetails.BadRequest_FieldViolation{
+			fieldViolation("refresh_token", errors.New("is required")),
+		}
+		return nil, invalidArgumentError(violations)
+	}
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.GetRefreshToken())
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	session, err := server.store.GetSession(ctx, refreshPayload.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "session not found")
+		}
+		return nil, internalError("failed to find session: %s", err)
+	}
+
+	if session.IsBlocked {
+		return nil, unauthenticatedError(errors.New("blocked session"))
+	}
+
+	if session.Username != refreshPayload.Username {
+		return nil, unauthenticatedError(errors.New("incorrect session user"))
+	}
+
+	if session.RefreshToken != req.GetRefreshToken() {
+		return nil, unauthenticatedError(errors.New("invalid token"))
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		return nil, unauthenticatedError(errors.New("token expired"))
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(refreshPayload.Username, server.config.ExpiryTokenDuration)
+	if err != nil {
+		return nil, internalError("failed to create access token: %s", err)
+	}
+
+	res := &pb.RenewAccessTokenResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: timestamppb.New(accessPayload.ExpiredAt),
+	}
+	return res, nil
+}
@@ -0,0 +1,35 @@
+package gapi
+
+import (
+	db "simple-bank/db/sqlc"
+	"simple-bank/pb"
+	"simple-bank/token"
+	"simple-bank/utils"
+)
+
+// Server serves gRPC requests for the banking services
+type Server struct {
+	pb.UnimplementedUserServiceServer
+	pb.UnimplementedTokenServiceServer
+	pb.UnimplementedAccountServiceServer
+	pb.UnimplementedTransferServiceServer
+	config     utils.Config
+	tokenMaker token.Maker
+	store      db.Store
+}
+
+// NewServer creates a new gRPC server on top of the given store
+func NewServer(config utils.Config, store db.Store) (*Server, error) {
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &Server{
+		config:     config,
+		store:      store,
+		tokenMaker: tokenMaker,
+	}
+
+	return server, nil
+}
@@ -0,0 +1,92 @@
+package gapi
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"simple-bank/utils"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Transfer details are held to the same limits as on the REST API
+const (
+	maxDescriptionLength = 140
+	maxReferenceLength   = 64
+	maxMetadataSize      = 4096
+)
+
+var isAlphanumeric = regexp.MustCompile(`^[a-zA-Z0-9]+$`).MatchString
+
+func validateString(value string, minLength int, maxLength int) error {
+	n := len(value)
+	if n < minLength || n > maxLength {
+		return fmt.Errorf("must contain from %d-%d characters", minLength, maxLength)
+	}
+	return nil
+}
+
+func validateUsername(value string) error {
+	if err := validateString(value, 1, 100); err != nil {
+		return err
+	}
+	if !isAlphanumeric(value) {
+		return fmt.Errorf("must contain only letters or digits")
+	}
+	return nil
+}
+
+func validatePassword(value string) error {
+	return validateString(value, 6, 100)
+}
+
+func validateFullName(value string) error {
+	return validateString(value, 1, 100)
+}
+
+func validateEmail(value string) error {
+	if err := validateString(value, 3, 200); err != nil {
+		return err
+	}
+	if _, err := mail.ParseAddress(value); err != nil {
+		return fmt.Errorf("is not a valid email address")
+	}
+	return nil
+}
+
+func validateCurrency(value string) error {
+	if !utils.IsSupportedCurrency(value) {
+		return fmt.Errorf("unsupported currency %q", value)
+	}
+	return nil
+}
+
+func validateAccountNumber(value string) error {
+	if !utils.ValidAccountNumber(value) {
+		return fmt.Errorf("is not a valid account number")
+	}
+	return nil
+}
+
+func validatePositive(value int64) error {
+	if value <= 0 {
+		return fmt.Errorf("must be a positive number")
+	}
+	return nil
+}
+
+// validateMetadata caps the encoded size of the metadata of a transfer
+func validateMetadata(value *structpb.Struct) error {
+	if value == nil {
+		return nil
+	}
+	data, err := protojson.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("is not valid JSON: %w", err)
+	}
+	if len(data) > maxMetadataSize {
+		return fmt.Errorf("must be at most %d bytes, got %d", maxMetadataSize, len(data))
+	}
+	return nil
+}
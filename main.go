@@ -1,13 +1,25 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
+	"net"
+	"net/http"
 	"simple-bank/api"
 	db "simple-bank/db/sqlc"
+	"simple-bank/gapi"
+	"simple-bank/pb"
+	"simple-bank/statement"
+	"simple-bank/storage"
 	"simple-bank/utils"
+	"simple-bank/webhook"
 
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	_ "github.com/lib/pq"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
 func main() {
@@ -23,7 +35,27 @@ func main() {
 	}
 
 	store := db.NewStore(conn)
-	server, err := api.NewServer(config, store)
+	blobs := storage.NewLocalStorage(config.BlobStoragePath)
+	go runGrpcServer(config, store)
+	go runGatewayServer(config, store)
+	go runOutboxDispatcher(config, conn, store)
+	go runWebhookWorker(store)
+	go runStatementJob(store, blobs)
+	go runBalanceSnapshotWriter(store)
+	go runHoldExpirer(store)
+	go runPaymentRequestExpirer(store)
+	go runInterestAccruer(store)
+	runGinServer(config, store, blobs)
+}
+
+func runGinServer(config utils.Config, store db.Store, blobs storage.Storage) {
+	accounts, err := db.NewAccountListener(config.DbSource)
+	if err != nil {
+		log.Fatal("cannot listen for account changes", err)
+	}
+	go accounts.Start(context.Background())
+
+	server, err := api.NewServer(config, store, accounts, blobs)
 	if err != nil {
 		log.Fatal("cannot create server")
 	}
@@ -33,3 +65,130 @@ func main() {
 		log.Fatal("cannot start server", err)
 	}
 }
+
+func runGrpcServer(config utils.Config, store db.Store) {
+	server, err := gapi.NewServer(config, store)
+	if err != nil {
+		log.Fatal("cannot create grpc server", err)
+	}
+
+	grpcServer := grpc.NewServer()
+	pb.RegisterUserServiceServer(grpcServer, server)
+	pb.RegisterTokenServiceServer(grpcServer, server)
+	pb.RegisterAccountServiceServer(grpcServer, server)
+	pb.RegisterTransferServiceServer(grpcServer, server)
+	reflection.Register(grpcServer)
+
+	listener, err := net.Listen("tcp", config.GrpcServerAddress)
+	if err != nil {
+		log.Fatal("cannot create grpc listener", err)
+	}
+
+	log.Printf("start gRPC server at %s", listener.Addr().String())
+	err = grpcServer.Serve(listener)
+	if err != nil {
+		log.Fatal("cannot start grpc server", err)
+	}
+}
+
+func runGatewayServer(config utils.Config, store db.Store) {
+	server, err := gapi.NewServer(config, store)
+	if err != nil {
+		log.Fatal("cannot create gateway server", err)
+	}
+
+	jsonOption := runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+		MarshalOptions: protojson.MarshalOptions{
+			UseProtoNames: true,
+		},
+		UnmarshalOptions: protojson.UnmarshalOptions{
+			DiscardUnknown: true,
+		},
+	})
+	grpcMux := runtime.NewServeMux(jsonOption)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := pb.RegisterUserServiceHandlerServer(ctx, grpcMux, server); err != nil {
+		log.Fatal("cannot register user service handler", err)
+	}
+	if err := pb.RegisterTokenServiceHandlerServer(ctx, grpcMux, server); err != nil {
+		log.Fatal("cannot register token service handler", err)
+	}
+	if err := pb.RegisterAccountServiceHandlerServer(ctx, grpcMux, server); err != nil {
+		log.Fatal("cannot register account service handler", err)
+	}
+	if err := pb.RegisterTransferServiceHandlerServer(ctx, grpcMux, server); err != nil {
+		log.Fatal("cannot register transfer service handler", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", grpcMux)
+
+	listener, err := net.Listen("tcp", config.GatewayServerAddress)
+	if err != nil {
+		log.Fatal("cannot create gateway listener", err)
+	}
+
+	log.Printf("start HTTP gateway server at %s", listener.Addr().String())
+	err = http.Serve(listener, mux)
+	if err != nil {
+		log.Fatal("cannot start HTTP gateway server", err)
+	}
+}
+
+func runOutboxDispatcher(config utils.Config, conn *sql.DB, store db.Store) {
+	var publisher db.Publisher = webhook.NewFanOut(store)
+	if config.EventBrokerURL != "" {
+		publisher = db.MultiPublisher{publisher, db.NewHTTPPublisher(config.EventBrokerURL)}
+	} else {
+		log.Printf("EVENT_BROKER_URL is not set, %s events are dropped and no verification emails are sent", db.EventEmailVerification)
+	}
+	dispatcher := db.NewDispatcher(conn, publisher, db.DispatcherConfig{})
+
+	log.Printf("start outbox dispatcher")
+	dispatcher.Start(context.Background())
+}
+
+func runWebhookWorker(store db.Store) {
+	worker := webhook.NewWorker(store)
+
+	log.Printf("start webhook worker")
+	worker.Start(context.Background())
+}
+
+func runStatementJob(store db.Store, blobs storage.Storage) {
+	job := statement.NewMonthlyJob(store, blobs)
+
+	log.Printf("start monthly statement job")
+	job.Start(context.Background())
+}
+
+func runBalanceSnapshotWriter(store db.Store) {
+	writer := db.NewSnapshotWriter(store)
+
+	log.Printf("start balance snapshot writer")
+	writer.Start(context.Background())
+}
+
+func runHoldExpirer(store db.Store) {
+	expirer := db.NewHoldExpirer(store)
+
+	log.Printf("start hold expirer")
+	expirer.Start(context.Background())
+}
+
+func runPaymentRequestExpirer(store db.Store) {
+	expirer := db.NewPaymentRequestExpirer(store)
+
+	log.Printf("start payment request expirer")
+	expirer.Start(context.Background())
+}
+
+func runInterestAccruer(store db.Store) {
+	accruer := db.NewInterestAccruer(store)
+
+	log.Printf("start interest accruer")
+	accruer.Start(context.Background())
+}
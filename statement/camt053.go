@@ -0,0 +1,165 @@
+package statement
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+)
+
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    int64  `xml:",chardata"`
+}
+
+type camtAccount struct {
+	ID string `xml:"Id>Othr>Id"`
+}
+
+type camtParty struct {
+	Name string `xml:"Pty>Nm,omitempty"`
+}
+
+type camtBalance struct {
+	Code     string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount   camtAmount `xml:"Amt"`
+	Credit   string     `xml:"CdtDbtInd"`
+	DateTime string     `xml:"Dt>DtTm"`
+}
+
+type camtRelatedParties struct {
+	Debtor          *camtParty   `xml:"Dbtr,omitempty"`
+	DebtorAccount   *camtAccount `xml:"DbtrAcct,omitempty"`
+	Creditor        *camtParty   `xml:"Cdtr,omitempty"`
+	CreditorAccount *camtAccount `xml:"CdtrAcct,omitempty"`
+}
+
+type camtTransactionDetails struct {
+	EndToEndID     string              `xml:"Refs>EndToEndId"`
+	RelatedParties *camtRelatedParties `xml:"RltdPties,omitempty"`
+	Information    string              `xml:"AddtlTxInf"`
+}
+
+type camtEntry struct {
+	Reference   string                 `xml:"NtryRef"`
+	Amount      camtAmount             `xml:"Amt"`
+	Credit      string                 `xml:"CdtDbtInd"`
+	Status      string                 `xml:"Sts>Cd"`
+	BookingDate string                 `xml:"BookgDt>DtTm"`
+	ValueDate   string                 `xml:"ValDt>DtTm"`
+	ServicerRef string                 `xml:"AcctSvcrRef"`
+	BankCode    string                 `xml:"BkTxCd>Prtry>Cd"`
+	Details     camtTransactionDetails `xml:"NtryDtls>TxDtls"`
+}
+
+// camt053Writer renders an ISO 20022 BankToCustomerStatement (camt.053.001.08)
+type camt053Writer struct {
+	stream *xmlStream
+	header Header
+}
+
+func newCamt053Writer(w io.Writer) *camt053Writer {
+	return &camt053Writer{stream: newXMLStream(w)}
+}
+
+func camtTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// creditDebit splits a signed amount into its absolute value and CRDT/DBIT indicator
+func creditDebit(amount int64) (int64, string) {
+	if amount < 0 {
+		return -amount, "DBIT"
+	}
+	return amount, "CRDT"
+}
+
+func (writer *camt053Writer) balance(code string, amount int64, at time.Time) camtBalance {
+	value, indicator := creditDebit(amount)
+	return camtBalance{
+		Code:     code,
+		Amount:   camtAmount{Currency: writer.header.Currency, Value: value},
+		Credit:   indicator,
+		DateTime: camtTime(at),
+	}
+}
+
+func (writer *camt053Writer) WriteHeader(header Header) error {
+	writer.header = header
+	stream := writer.stream
+	id := header.AccountNumber + "-" + header.From.UTC().Format("20060102") + "-" + header.To.UTC().Format("20060102")
+
+	stream.procInst("xml", `version="1.0" encoding="UTF-8"`)
+	stream.start("Document", xml.Attr{Name: xml.Name{Local: "xmlns"}, Value: camt053Namespace})
+	stream.start("BkToCstmrStmt")
+
+	stream.start("GrpHdr")
+	stream.element("MsgId", id)
+	stream.element("CreDtTm", camtTime(header.CreatedAt))
+	stream.end()
+
+	stream.start("Stmt")
+	stream.element("Id", id)
+	stream.element("CreDtTm", camtTime(header.CreatedAt))
+	stream.start("FrToDt")
+	stream.element("FrDtTm", camtTime(header.From))
+	stream.element("ToDtTm", camtTime(header.To))
+	stream.end()
+
+	stream.element("Acct", struct {
+		ID       string `xml:"Id>Othr>Id"`
+		Currency string `xml:"Ccy"`
+		Owner    string `xml:"Ownr>Nm"`
+	}{
+		ID:       header.AccountNumber,
+		Currency: header.Currency,
+		Owner:    header.Owner,
+	})
+	stream.element("Bal", writer.balance("OPBD", header.OpeningBalance, header.From))
+	stream.element("Bal", writer.balance("CLBD", header.ClosingBalance, header.To))
+
+	return stream.flush()
+}
+
+func (writer *camt053Writer) WriteLine(line Line) error {
+	value, indicator := creditDebit(line.Amount)
+	entry := camtEntry{
+		Reference:   strconv.FormatInt(line.EntryID, 10),
+		Amount:      camtAmount{Currency: writer.header.Currency, Value: value},
+		Credit:      indicator,
+		Status:      "BOOK",
+		BookingDate: camtTime(line.BookedAt),
+		ValueDate:   camtTime(line.BookedAt),
+		ServicerRef: strconv.FormatInt(line.EntryID, 10),
+		BankCode:    "TRANSFER",
+		Details: camtTransactionDetails{
+			EndToEndID:  "NOTPROVIDED",
+			Information: line.Description(),
+		},
+	}
+
+	if line.TransferID != 0 {
+		entry.Details.EndToEndID = strconv.FormatInt(line.TransferID, 10)
+		if line.Reference != "" {
+			entry.Details.EndToEndID = line.Reference
+		}
+		party := &camtParty{Name: line.CounterpartyOwner}
+		account := &camtAccount{ID: line.CounterpartyAccount}
+		if line.Amount < 0 {
+			entry.Details.RelatedParties = &camtRelatedParties{Creditor: party, CreditorAccount: account}
+		} else {
+			entry.Details.RelatedParties = &camtRelatedParties{Debtor: party, DebtorAccount: account}
+		}
+	} else {
+		entry.BankCode = "ENTRY"
+	}
+
+	writer.stream.element("Ntry", entry)
+	return writer.stream.err
+}
+
+func (writer *camt053Writer) Close() error {
+	return writer.stream.close()
+}
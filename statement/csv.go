@@ -0,0 +1,76 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// CSV row types, the opening and closing balances are rows of their own
+const (
+	csvOpeningBalance = "OPENING_BALANCE"
+	csvEntry          = "ENTRY"
+	csvClosingBalance = "CLOSING_BALANCE"
+)
+
+var csvColumns = []string{
+	"type", "booked_at", "entry_id", "transfer_id", "amount", "currency", "balance",
+	"counterparty_account", "counterparty_owner", "description",
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	header Header
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (writer *csvWriter) WriteHeader(header Header) error {
+	writer.header = header
+	if err := writer.w.Write(csvColumns); err != nil {
+		return err
+	}
+	return writer.writeBalance(csvOpeningBalance, header.From, header.OpeningBalance)
+}
+
+func (writer *csvWriter) WriteLine(line Line) error {
+	transferID := ""
+	if line.TransferID != 0 {
+		transferID = strconv.FormatInt(line.TransferID, 10)
+	}
+
+	return writer.w.Write([]string{
+		csvEntry,
+		line.BookedAt.UTC().Format(time.RFC3339),
+		strconv.FormatInt(line.EntryID, 10),
+		transferID,
+		strconv.FormatInt(line.Amount, 10),
+		writer.header.Currency,
+		strconv.FormatInt(line.Balance, 10),
+		line.CounterpartyAccount,
+		line.CounterpartyOwner,
+		line.Description(),
+	})
+}
+
+func (writer *csvWriter) Close() error {
+	if err := writer.writeBalance(csvClosingBalance, writer.header.To, writer.header.ClosingBalance); err != nil {
+		return err
+	}
+	writer.w.Flush()
+	return writer.w.Error()
+}
+
+func (writer *csvWriter) writeBalance(rowType string, at time.Time, balance int64) error {
+	return writer.w.Write([]string{
+		rowType,
+		at.UTC().Format(time.RFC3339),
+		"", "", "",
+		writer.header.Currency,
+		strconv.FormatInt(balance, 10),
+		"", "", "",
+	})
+}
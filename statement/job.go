@@ -0,0 +1,163 @@
+package statement
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	db "simple-bank/db/sqlc"
+	"simple-bank/storage"
+	"time"
+)
+
+const (
+	defaultJobBatchSize    = 100
+	defaultJobPollInterval = time.Hour
+)
+
+// MonthlyJob renders a PDF statement for every account once the month is over
+// and keeps it in blob storage. Accounts are picked up until their statement is
+// recorded, so a failed run is simply retried by the next one.
+type MonthlyJob struct {
+	store        db.Store
+	storage      storage.Storage
+	BatchSize    int32
+	PollInterval time.Duration
+	now          func() time.Time
+}
+
+func NewMonthlyJob(store db.Store, storage storage.Storage) *MonthlyJob {
+	return &MonthlyJob{
+		store:        store,
+		storage:      storage,
+		BatchSize:    defaultJobBatchSize,
+		PollInterval: defaultJobPollInterval,
+		now:          time.Now,
+	}
+}
+
+// MonthStart returns the first instant of the month t falls in, in UTC
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// StorageKey is where the PDF statement of an account for a month is kept
+func StorageKey(accountNumber string, period time.Time) string {
+	return fmt.Sprintf("statements/%s/%s.pdf", accountNumber, period.Format("2006-01"))
+}
+
+// Start runs the job until ctx is cancelled
+func (job *MonthlyJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(job.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := job.RunOnce(ctx); err != nil {
+			log.Printf("statement job: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce renders the statements of the previous month that don't exist yet.
+// Accounts that fail are logged and skipped until the next run.
+func (job *MonthlyJob) RunOnce(ctx context.Context) error {
+	periodEnd := MonthStart(job.now())
+	period := periodEnd.AddDate(0, -1, 0)
+
+	var afterID int64
+	for {
+		accounts, err := job.store.ListAccountsMissingStatement(ctx, db.ListAccountsMissingStatementParams{
+			Period:      period,
+			PeriodEnd:   periodEnd,
+			AfterID:     afterID,
+			MaxAccounts: job.BatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot list accounts: %w", err)
+		}
+
+		for _, account := range accounts {
+			if err := job.Generate(ctx, account, period); err != nil {
+				log.Printf("statement job: account %s: %s", account.Number, err)
+			}
+			afterID = account.ID
+		}
+
+		if len(accounts) < int(job.BatchSize) {
+			return nil
+		}
+	}
+}
+
+// Generate renders, stores and records the statement of the account for the month starting at period
+func (job *MonthlyJob) Generate(ctx context.Context, account db.Account, period time.Time) error {
+	owner, err := job.store.GetUser(ctx, account.Owner)
+	if err != nil {
+		return fmt.Errorf("cannot get owner: %w", err)
+	}
+
+	var buf bytes.Buffer
+	writer := newPDFWriter(&buf)
+	header := Header{
+		AccountNumber: account.Number,
+		Owner:         account.Owner,
+		OwnerName:     owner.FullName,
+		Currency:      account.Currency,
+		From:          period,
+		To:            period.AddDate(0, 1, 0),
+		CreatedAt:     job.now(),
+	}
+
+	var balance int64
+	err = job.store.StatementTxn(ctx, db.StatementTxnParams{
+		AccountID: account.ID,
+		From:      header.From,
+		To:        header.To,
+	}, func(balances db.GetStatementBalancesRow) error {
+		header.OpeningBalance = balances.OpeningBalance
+		header.ClosingBalance = balances.ClosingBalance
+		balance = balances.OpeningBalance
+		return writer.WriteHeader(header)
+	}, func(line db.ListStatementLinesRow) error {
+		balance += line.Amount
+		return writer.WriteLine(Line{
+			EntryID:             line.ID,
+			TransferID:          line.TransferID.Int64,
+			BookedAt:            line.CreatedAt,
+			Amount:              line.Amount,
+			Balance:             balance,
+			CounterpartyAccount: line.CounterpartyNumber.String,
+			CounterpartyOwner:   line.CounterpartyOwner.String,
+			Memo:                line.Description,
+			Reference:           line.Reference,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("cannot read statement: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("cannot render statement: %w", err)
+	}
+
+	key := StorageKey(account.Number, period)
+	size := int64(buf.Len())
+	if err := job.storage.Put(ctx, key, &buf); err != nil {
+		return fmt.Errorf("cannot store statement: %w", err)
+	}
+
+	return job.store.CreateAccountStatement(ctx, db.CreateAccountStatementParams{
+		AccountID:      account.ID,
+		Period:         period,
+		StorageKey:     key,
+		Size:           size,
+		OpeningBalance: header.OpeningBalance,
+		ClosingBalance: header.ClosingBalance,
+	})
+}
@@ -0,0 +1,75 @@
+package statement
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	mockdb "simple-bank/db/mock"
+	db "simple-bank/db/sqlc"
+	"simple-bank/storage"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonthlyJob(t *testing.T) {
+	now := time.Date(2026, 2, 3, 4, 5, 6, 0, time.UTC)
+	period := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	accounts := []db.Account{
+		{ID: 1, Number: "SB23000012345678", Owner: "alice", Currency: "USD", Balance: 70},
+		{ID: 2, Number: "SB61000087654321", Owner: "bob", Currency: "EUR", Balance: 5},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListAccountsMissingStatement(gomock.Any(), gomock.Eq(db.ListAccountsMissingStatementParams{
+		Period:      period,
+		PeriodEnd:   period.AddDate(0, 1, 0),
+		MaxAccounts: defaultJobBatchSize,
+	})).Times(1).Return(accounts, nil)
+
+	store.EXPECT().GetUser(gomock.Any(), "alice").Times(1).Return(db.User{Username: "alice", FullName: "Alice Liddell"}, nil)
+	store.EXPECT().GetUser(gomock.Any(), "bob").Times(1).Return(db.User{}, errors.New("connection reset"))
+
+	store.EXPECT().StatementTxn(gomock.Any(), gomock.Eq(db.StatementTxnParams{
+		AccountID: 1,
+		From:      period,
+		To:        period.AddDate(0, 1, 0),
+	}), gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, _ db.StatementTxnParams, header func(db.GetStatementBalancesRow) error, line func(db.ListStatementLinesRow) error) error {
+			if err := header(db.GetStatementBalancesRow{OpeningBalance: 100, ClosingBalance: 70}); err != nil {
+				return err
+			}
+			return line(db.ListStatementLinesRow{ID: 11, Amount: -30, CreatedAt: period.Add(time.Hour)})
+		})
+
+	store.EXPECT().CreateAccountStatement(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, args db.CreateAccountStatementParams) error {
+			require.Equal(t, int64(1), args.AccountID)
+			require.Equal(t, period, args.Period)
+			require.Equal(t, "statements/SB23000012345678/2026-01.pdf", args.StorageKey)
+			require.Equal(t, int64(100), args.OpeningBalance)
+			require.Equal(t, int64(70), args.ClosingBalance)
+			require.NotZero(t, args.Size)
+			return nil
+		})
+
+	blobs := storage.NewMemoryStorage()
+	job := NewMonthlyJob(store, blobs)
+	job.now = func() time.Time { return now }
+	require.NoError(t, job.RunOnce(context.Background()))
+
+	blob, err := blobs.Get(context.Background(), StorageKey(accounts[0].Number, period))
+	require.NoError(t, err)
+	data, err := io.ReadAll(blob)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(data, []byte("%PDF-")))
+
+	_, err = blobs.Get(context.Background(), StorageKey(accounts[1].Number, period))
+	require.ErrorIs(t, err, storage.ErrNotFound)
+}
@@ -0,0 +1,120 @@
+package statement
+
+import (
+	"io"
+	"strconv"
+	"time"
+)
+
+const ofxTimeFormat = "20060102150405"
+
+type ofxBankAccount struct {
+	BankID      string `xml:"BANKID"`
+	AccountID   string `xml:"ACCTID"`
+	AccountType string `xml:"ACCTTYPE"`
+}
+
+type ofxTransaction struct {
+	Type         string          `xml:"TRNTYPE"`
+	Posted       string          `xml:"DTPOSTED"`
+	Amount       int64           `xml:"TRNAMT"`
+	ID           string          `xml:"FITID"`
+	Name         string          `xml:"NAME,omitempty"`
+	Counterparty *ofxBankAccount `xml:"BANKACCTTO,omitempty"`
+	Memo         string          `xml:"MEMO"`
+}
+
+// ofxWriter renders an OFX 2.2 bank statement. OFX has no opening balance,
+// the closing balance is reported as the ledger balance.
+type ofxWriter struct {
+	stream *xmlStream
+	header Header
+}
+
+func newOFXWriter(w io.Writer) *ofxWriter {
+	return &ofxWriter{stream: newXMLStream(w)}
+}
+
+func ofxTime(t time.Time) string {
+	return t.UTC().Format(ofxTimeFormat) + "[0:GMT]"
+}
+
+func ofxStatus(stream *xmlStream) {
+	stream.start("STATUS")
+	stream.element("CODE", 0)
+	stream.element("SEVERITY", "INFO")
+	stream.end()
+}
+
+func (writer *ofxWriter) WriteHeader(header Header) error {
+	writer.header = header
+	stream := writer.stream
+
+	stream.procInst("xml", `version="1.0" encoding="UTF-8" standalone="no"`)
+	stream.procInst("OFX", `OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"`)
+	stream.start("OFX")
+
+	stream.start("SIGNONMSGSRSV1")
+	stream.start("SONRS")
+	ofxStatus(stream)
+	stream.element("DTSERVER", ofxTime(header.CreatedAt))
+	stream.element("LANGUAGE", "ENG")
+	stream.end()
+	stream.end()
+
+	stream.start("BANKMSGSRSV1")
+	stream.start("STMTTRNRS")
+	stream.element("TRNUID", 0)
+	ofxStatus(stream)
+	stream.start("STMTRS")
+	stream.element("CURDEF", header.Currency)
+	stream.element("BANKACCTFROM", ofxBankAccount{
+		BankID:      BankID,
+		AccountID:   header.AccountNumber,
+		AccountType: "CHECKING",
+	})
+	stream.start("BANKTRANLIST")
+	stream.element("DTSTART", ofxTime(header.From))
+	stream.element("DTEND", ofxTime(header.To))
+
+	return stream.flush()
+}
+
+func (writer *ofxWriter) WriteLine(line Line) error {
+	transaction := ofxTransaction{
+		Type:   "CREDIT",
+		Posted: ofxTime(line.BookedAt),
+		Amount: line.Amount,
+		ID:     strconv.FormatInt(line.EntryID, 10),
+		Name:   line.CounterpartyOwner,
+		Memo:   line.Description(),
+	}
+	if line.Amount < 0 {
+		transaction.Type = "DEBIT"
+	}
+	if line.CounterpartyAccount != "" {
+		transaction.Counterparty = &ofxBankAccount{
+			BankID:      BankID,
+			AccountID:   line.CounterpartyAccount,
+			AccountType: "CHECKING",
+		}
+	}
+
+	writer.stream.element("STMTTRN", transaction)
+	return writer.stream.err
+}
+
+func (writer *ofxWriter) Close() error {
+	stream := writer.stream
+
+	stream.end() // BANKTRANLIST
+	stream.element("LEDGERBAL", struct {
+		Amount int64  `xml:"BALAMT"`
+		AsOf   string `xml:"DTASOF"`
+	}{
+		Amount: writer.header.ClosingBalance,
+		AsOf:   ofxTime(writer.header.To),
+	})
+
+	return stream.close()
+}
@@ -0,0 +1,103 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	pdfDateFormat  = "2006-01-02"
+	pdfLineHeight  = 6.0
+	pdfDateWidth   = 28.0
+	pdfTextWidth   = 82.0
+	pdfAmountWidth = 40.0
+)
+
+// pdfWriter renders a printable statement. Unlike the other formats the
+// document is assembled in memory and only written out by Close.
+type pdfWriter struct {
+	w         io.Writer
+	pdf       *gofpdf.Fpdf
+	translate func(string) string
+	header    Header
+}
+
+func newPDFWriter(w io.Writer) *pdfWriter {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	return &pdfWriter{
+		w:         w,
+		pdf:       pdf,
+		translate: pdf.UnicodeTranslatorFromDescriptor(""),
+	}
+}
+
+func (writer *pdfWriter) amount(amount int64) string {
+	return strconv.FormatInt(amount, 10) + " " + writer.header.Currency
+}
+
+func (writer *pdfWriter) WriteHeader(header Header) error {
+	writer.header = header
+	pdf := writer.pdf
+
+	pdf.SetCreationDate(header.CreatedAt)
+	pdf.SetTitle("Statement "+header.AccountNumber, true)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, "Account statement", "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 11)
+	to := header.To.AddDate(0, 0, -1)
+	for _, row := range [][2]string{
+		{"Account holder", writer.translate(header.OwnerName)},
+		{"Account", header.AccountNumber},
+		{"Currency", header.Currency},
+		{"Period", header.From.UTC().Format(pdfDateFormat) + " to " + to.UTC().Format(pdfDateFormat)},
+		{"Opening balance", writer.amount(header.OpeningBalance)},
+		{"Closing balance", writer.amount(header.ClosingBalance)},
+	} {
+		pdf.CellFormat(45, pdfLineHeight, row[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, pdfLineHeight, row[1], "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(pdfLineHeight)
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(230, 230, 230)
+	pdf.CellFormat(pdfDateWidth, pdfLineHeight, "Date", "B", 0, "L", true, 0, "")
+	pdf.CellFormat(pdfTextWidth, pdfLineHeight, "Description", "B", 0, "L", true, 0, "")
+	pdf.CellFormat(pdfAmountWidth, pdfLineHeight, "Amount", "B", 0, "R", true, 0, "")
+	pdf.CellFormat(pdfAmountWidth, pdfLineHeight, "Balance", "B", 1, "R", true, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+
+	return pdf.Error()
+}
+
+func (writer *pdfWriter) WriteLine(line Line) error {
+	pdf := writer.pdf
+
+	description := line.Description()
+	if line.CounterpartyOwner != "" {
+		description = fmt.Sprintf("%s (%s)", description, line.CounterpartyOwner)
+	}
+
+	pdf.CellFormat(pdfDateWidth, pdfLineHeight, line.BookedAt.UTC().Format(pdfDateFormat), "", 0, "L", false, 0, "")
+	pdf.CellFormat(pdfTextWidth, pdfLineHeight, writer.translate(description), "", 0, "L", false, 0, "")
+	pdf.CellFormat(pdfAmountWidth, pdfLineHeight, writer.amount(line.Amount), "", 0, "R", false, 0, "")
+	pdf.CellFormat(pdfAmountWidth, pdfLineHeight, writer.amount(line.Balance), "", 1, "R", false, 0, "")
+
+	return pdf.Error()
+}
+
+func (writer *pdfWriter) Close() error {
+	pdf := writer.pdf
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(pdfDateWidth+pdfTextWidth+pdfAmountWidth, pdfLineHeight, "Closing balance", "T", 0, "L", false, 0, "")
+	pdf.CellFormat(pdfAmountWidth, pdfLineHeight, writer.amount(writer.header.ClosingBalance), "T", 1, "R", false, 0, "")
+
+	return pdf.Output(writer.w)
+}
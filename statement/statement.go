@@ -0,0 +1,113 @@
+// Package statement renders account statements in formats accounting tools import.
+// Writers are fed line by line so statements of any size can be streamed.
+package statement
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Supported formats
+const (
+	FormatCSV     = "csv"
+	FormatOFX     = "ofx"
+	FormatCamt053 = "camt053"
+	FormatPDF     = "pdf"
+)
+
+// Formats lists the supported formats
+var Formats = []string{FormatCSV, FormatOFX, FormatCamt053, FormatPDF}
+
+// BankID identifies this bank in OFX and camt.053 documents
+const BankID = "SIMPLEBANK"
+
+// Header describes the statement, it is written before any line
+type Header struct {
+	AccountNumber  string
+	Owner          string
+	OwnerName      string
+	Currency       string
+	From           time.Time
+	To             time.Time
+	OpeningBalance int64
+	ClosingBalance int64
+	CreatedAt      time.Time
+}
+
+// Line is a single booked entry. Counterparty, Memo and Reference are empty for
+// entries that don't belong to a transfer.
+type Line struct {
+	EntryID             int64
+	TransferID          int64
+	BookedAt            time.Time
+	Amount              int64
+	Balance             int64
+	CounterpartyAccount string
+	CounterpartyOwner   string
+	// Memo is the description the transfer was sent with
+	Memo      string
+	Reference string
+}
+
+// Description summarizes the line for formats with a free text field
+func (line Line) Description() string {
+	var description string
+	switch {
+	case line.TransferID == 0:
+		return "Entry " + strconv.FormatInt(line.EntryID, 10)
+	case line.Amount < 0:
+		description = fmt.Sprintf("Transfer %d to %s", line.TransferID, line.CounterpartyAccount)
+	default:
+		description = fmt.Sprintf("Transfer %d from %s", line.TransferID, line.CounterpartyAccount)
+	}
+
+	if line.Memo != "" {
+		description += ": " + line.Memo
+	}
+	return description
+}
+
+// Writer renders a statement: WriteHeader once, WriteLine per entry, then Close
+type Writer interface {
+	WriteHeader(header Header) error
+	WriteLine(line Line) error
+	Close() error
+}
+
+// NewWriter returns a writer rendering the given format to w
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatOFX:
+		return newOFXWriter(w), nil
+	case FormatCamt053:
+		return newCamt053Writer(w), nil
+	case FormatPDF:
+		return newPDFWriter(w), nil
+	}
+	return nil, fmt.Errorf("unsupported statement format %q", format)
+}
+
+// ContentType returns the media type documents of the format are served with
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatOFX:
+		return "application/x-ofx"
+	case FormatPDF:
+		return "application/pdf"
+	}
+	return "application/xml"
+}
+
+// FileExtension returns the usual extension of documents in the format
+func FileExtension(format string) string {
+	if format == FormatCamt053 {
+		return "xml"
+	}
+	return format
+}
@@ -0,0 +1,155 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testFrom   = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	testTo     = time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	testHeader = Header{
+		AccountNumber:  "SB23000012345678",
+		Owner:          "alice",
+		Currency:       "USD",
+		From:           testFrom,
+		To:             testTo,
+		OpeningBalance: 100,
+		ClosingBalance: 70,
+		CreatedAt:      testTo,
+	}
+	testLines = []Line{
+		{
+			EntryID:             11,
+			TransferID:          5,
+			BookedAt:            testFrom.Add(time.Hour),
+			Amount:              -50,
+			Balance:             50,
+			CounterpartyAccount: "SB61000087654321",
+			CounterpartyOwner:   "bob",
+		},
+		{
+			EntryID:             14,
+			TransferID:          7,
+			BookedAt:            testFrom.Add(2 * time.Hour),
+			Amount:              20,
+			Balance:             70,
+			CounterpartyAccount: "SB61000087654321",
+			CounterpartyOwner:   "bob",
+			Memo:                "Rent",
+			Reference:           "INV-7",
+		},
+	}
+)
+
+func render(t *testing.T, format string) []byte {
+	var buf bytes.Buffer
+	writer, err := NewWriter(format, &buf)
+	require.NoError(t, err)
+
+	require.NoError(t, writer.WriteHeader(testHeader))
+	for _, line := range testLines {
+		require.NoError(t, writer.WriteLine(line))
+	}
+	require.NoError(t, writer.Close())
+
+	return buf.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	records, err := csv.NewReader(bytes.NewReader(render(t, FormatCSV))).ReadAll()
+	require.NoError(t, err)
+
+	require.Equal(t, csvColumns, records[0])
+	require.Equal(t, []string{csvOpeningBalance, "2026-01-01T00:00:00Z", "", "", "", "USD", "100", "", "", ""}, records[1])
+	require.Equal(t, []string{csvEntry, "2026-01-01T01:00:00Z", "11", "5", "-50", "USD", "50", "SB61000087654321", "bob", "Transfer 5 to SB61000087654321"}, records[2])
+	require.Equal(t, []string{csvEntry, "2026-01-01T02:00:00Z", "14", "7", "20", "USD", "70", "SB61000087654321", "bob", "Transfer 7 from SB61000087654321: Rent"}, records[3])
+	require.Equal(t, []string{csvClosingBalance, "2026-02-01T00:00:00Z", "", "", "", "USD", "70", "", "", ""}, records[4])
+	require.Len(t, records, 5)
+}
+
+func TestOFX(t *testing.T) {
+	data := render(t, FormatOFX)
+	require.True(t, strings.HasPrefix(string(data), `<?xml version="1.0" encoding="UTF-8" standalone="no"?>`))
+	require.Contains(t, string(data), `<?OFX OFXHEADER="200" VERSION="220"`)
+
+	var document struct {
+		Statement struct {
+			Currency     string           `xml:"CURDEF"`
+			Account      ofxBankAccount   `xml:"BANKACCTFROM"`
+			Start        string           `xml:"BANKTRANLIST>DTSTART"`
+			Transactions []ofxTransaction `xml:"BANKTRANLIST>STMTTRN"`
+			Balance      int64            `xml:"LEDGERBAL>BALAMT"`
+		} `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS"`
+	}
+	require.NoError(t, xml.Unmarshal(data, &document))
+
+	statement := document.Statement
+	require.Equal(t, "USD", statement.Currency)
+	require.Equal(t, testHeader.AccountNumber, statement.Account.AccountID)
+	require.Equal(t, "20260101000000[0:GMT]", statement.Start)
+	require.Equal(t, int64(70), statement.Balance)
+	require.Len(t, statement.Transactions, 2)
+
+	debit := statement.Transactions[0]
+	require.Equal(t, "DEBIT", debit.Type)
+	require.Equal(t, int64(-50), debit.Amount)
+	require.Equal(t, "11", debit.ID)
+	require.Equal(t, "bob", debit.Name)
+	require.Equal(t, "SB61000087654321", debit.Counterparty.AccountID)
+	require.Equal(t, "CREDIT", statement.Transactions[1].Type)
+}
+
+func TestCamt053(t *testing.T) {
+	data := render(t, FormatCamt053)
+
+	var document struct {
+		XMLName   xml.Name
+		Statement struct {
+			Account  string        `xml:"Acct>Id>Othr>Id"`
+			Balances []camtBalance `xml:"Bal"`
+			Entries  []camtEntry   `xml:"Ntry"`
+		} `xml:"BkToCstmrStmt>Stmt"`
+	}
+	require.NoError(t, xml.Unmarshal(data, &document))
+	require.Equal(t, camt053Namespace, document.XMLName.Space)
+
+	statement := document.Statement
+	require.Equal(t, testHeader.AccountNumber, statement.Account)
+	require.Len(t, statement.Balances, 2)
+	require.Equal(t, "OPBD", statement.Balances[0].Code)
+	require.Equal(t, int64(100), statement.Balances[0].Amount.Value)
+	require.Equal(t, "CLBD", statement.Balances[1].Code)
+	require.Equal(t, int64(70), statement.Balances[1].Amount.Value)
+
+	require.Len(t, statement.Entries, 2)
+	debit := statement.Entries[0]
+	require.Equal(t, int64(50), debit.Amount.Value)
+	require.Equal(t, "USD", debit.Amount.Currency)
+	require.Equal(t, "DBIT", debit.Credit)
+	require.Equal(t, "5", debit.Details.EndToEndID)
+	require.Equal(t, "bob", debit.Details.RelatedParties.Creditor.Name)
+	require.Equal(t, "SB61000087654321", debit.Details.RelatedParties.CreditorAccount.ID)
+
+	credit := statement.Entries[1]
+	require.Equal(t, "CRDT", credit.Credit)
+	require.Equal(t, "INV-7", credit.Details.EndToEndID)
+	require.Equal(t, "bob", credit.Details.RelatedParties.Debtor.Name)
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	_, err := NewWriter("xlsx", &bytes.Buffer{})
+	require.Error(t, err)
+}
+
+func TestPDF(t *testing.T) {
+	data := render(t, FormatPDF)
+	require.True(t, bytes.HasPrefix(data, []byte("%PDF-")))
+	require.True(t, bytes.Contains(data, []byte("%%EOF")))
+}
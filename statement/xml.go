@@ -0,0 +1,66 @@
+package statement
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// xmlStream writes an XML document token by token. The first error sticks and
+// is reported by flush, so callers can write a whole section before checking.
+type xmlStream struct {
+	enc  *xml.Encoder
+	open []xml.Name
+	err  error
+}
+
+func newXMLStream(w io.Writer) *xmlStream {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return &xmlStream{enc: enc}
+}
+
+// procInst writes a processing instruction such as the XML declaration
+func (stream *xmlStream) procInst(target string, inst string) {
+	if stream.err == nil {
+		stream.err = stream.enc.EncodeToken(xml.ProcInst{Target: target, Inst: []byte(inst)})
+	}
+}
+
+func (stream *xmlStream) start(name string, attrs ...xml.Attr) {
+	if stream.err != nil {
+		return
+	}
+	start := xml.StartElement{Name: xml.Name{Local: name}, Attr: attrs}
+	stream.err = stream.enc.EncodeToken(start)
+	stream.open = append(stream.open, start.Name)
+}
+
+func (stream *xmlStream) end() {
+	if stream.err != nil || len(stream.open) == 0 {
+		return
+	}
+	name := stream.open[len(stream.open)-1]
+	stream.open = stream.open[:len(stream.open)-1]
+	stream.err = stream.enc.EncodeToken(xml.EndElement{Name: name})
+}
+
+func (stream *xmlStream) element(name string, value any) {
+	if stream.err == nil {
+		stream.err = stream.enc.EncodeElement(value, xml.StartElement{Name: xml.Name{Local: name}})
+	}
+}
+
+func (stream *xmlStream) flush() error {
+	if stream.err == nil {
+		stream.err = stream.enc.Flush()
+	}
+	return stream.err
+}
+
+// close ends every open element
+func (stream *xmlStream) close() error {
+	for len(stream.open) > 0 && stream.err == nil {
+		stream.end()
+	}
+	return stream.flush()
+}
@@ -0,0 +1,112 @@
+// Package storage keeps generated documents such as PDF statements in a blob store
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned by Get for keys that were never stored
+var ErrNotFound = errors.New("blob not found")
+
+// Storage stores blobs under slash separated keys such as "statements/SB.../2026-01.pdf"
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// LocalStorage keeps blobs as files under a root directory
+type LocalStorage struct {
+	root string
+}
+
+func NewLocalStorage(root string) *LocalStorage {
+	return &LocalStorage{root: root}
+}
+
+// path maps a key into the root, rejecting keys that would escape it
+func (storage *LocalStorage) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" || strings.Contains(key, "..") {
+		return "", errors.New("invalid blob key " + key)
+	}
+	return filepath.Join(storage.root, filepath.FromSlash(clean)), nil
+}
+
+// Put writes to a temporary file first so readers never see a partial blob
+func (storage *LocalStorage) Put(ctx context.Context, key string, r io.Reader) error {
+	path, err := storage.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func (storage *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := storage.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// MemoryStorage keeps blobs in memory, for tests
+type MemoryStorage struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{blobs: map[string][]byte{}}
+}
+
+func (storage *MemoryStorage) Put(ctx context.Context, key string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	storage.blobs[key] = data
+	return nil
+}
+
+func (storage *MemoryStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+
+	data, ok := storage.blobs[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
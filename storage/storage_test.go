@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testStorage(t *testing.T, storage Storage) {
+	ctx := context.Background()
+
+	_, err := storage.Get(ctx, "statements/missing.pdf")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, storage.Put(ctx, "statements/SB1/2026-01.pdf", strings.NewReader("first")))
+	require.NoError(t, storage.Put(ctx, "statements/SB1/2026-01.pdf", strings.NewReader("second")))
+
+	blob, err := storage.Get(ctx, "statements/SB1/2026-01.pdf")
+	require.NoError(t, err)
+	defer blob.Close()
+
+	data, err := io.ReadAll(blob)
+	require.NoError(t, err)
+	require.Equal(t, "second", string(data))
+}
+
+func TestLocalStorage(t *testing.T) {
+	storage := NewLocalStorage(t.TempDir())
+	testStorage(t, storage)
+
+	err := storage.Put(context.Background(), "../escape", strings.NewReader("x"))
+	require.Error(t, err)
+}
+
+func TestMemoryStorage(t *testing.T) {
+	testStorage(t, NewMemoryStorage())
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Account numbers follow the IBAN layout: a two letter prefix, two check
+// digits and a 12 digit random body, e.g. SB23000012345678.
+const (
+	AccountNumberPrefix = "SB"
+	accountNumberDigits = 12
+	accountNumberLength = len(AccountNumberPrefix) + 2 + accountNumberDigits
+
+	// AccountNumberPattern describes the format, without the check digit validation
+	AccountNumberPattern = "^" + AccountNumberPrefix + "[0-9]{14}$"
+)
+
+// AccountNumberAttempts is how many random numbers opening an account tries before it
+// gives up on collisions with existing accounts
+const AccountNumberAttempts = 3
+
+var accountNumberRange = new(big.Int).Exp(big.NewInt(10), big.NewInt(accountNumberDigits), nil)
+
+// NewAccountNumber generates a random, non sequential account number
+func NewAccountNumber() (string, error) {
+	n, err := rand.Int(rand.Reader, accountNumberRange)
+	if err != nil {
+		return "", fmt.Errorf("cannot generate account number: %w", err)
+	}
+
+	return accountNumberWithBody(fmt.Sprintf("%0*d", accountNumberDigits, n)), nil
+}
+
+// ValidAccountNumber checks the format and the check digits of an account number
+func ValidAccountNumber(number string) bool {
+	if len(number) != accountNumberLength || !strings.HasPrefix(number, AccountNumberPrefix) {
+		return false
+	}
+
+	for _, c := range number[len(AccountNumberPrefix):] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	body := number[len(AccountNumberPrefix)+2:]
+	return mod97(body+AccountNumberPrefix+number[len(AccountNumberPrefix):len(AccountNumberPrefix)+2]) == 1
+}
+
+func accountNumberWithBody(body string) string {
+	check := 98 - mod97(body+AccountNumberPrefix+"00")
+	return fmt.Sprintf("%s%02d%s", AccountNumberPrefix, check, body)
+}
+
+// mod97 computes the ISO 7064 MOD 97-10 remainder, letters counting as 10..35
+func mod97(s string) int {
+	remainder := 0
+	for _, c := range s {
+		if c >= 'A' && c <= 'Z' {
+			remainder = (remainder*100 + int(c-'A'+10)) % 97
+			continue
+		}
+		remainder = (remainder*10 + int(c-'0')) % 97
+	}
+	return remainder
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAccountNumber(t *testing.T) {
+	number1, err := NewAccountNumber()
+	require.NoError(t, err)
+	require.Len(t, number1, accountNumberLength)
+	require.True(t, ValidAccountNumber(number1))
+
+	number2, err := NewAccountNumber()
+	require.NoError(t, err)
+	require.NotEqual(t, number1, number2)
+}
+
+func TestValidAccountNumber(t *testing.T) {
+	number := accountNumberWithBody("000000000001")
+
+	testCases := []struct {
+		name   string
+		number string
+		valid  bool
+	}{
+		{"OK", number, true},
+		{"WrongCheckDigits", number[:2] + swapDigit(number[2]) + number[3:], false},
+		{"MistypedDigit", number[:len(number)-1] + swapDigit(number[len(number)-1]), false},
+		{"WrongPrefix", "XX" + number[2:], false},
+		{"TooShort", number[:len(number)-1], false},
+		{"NotNumeric", number[:len(number)-1] + "A", false},
+		{"Empty", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.valid, ValidAccountNumber(tc.number))
+		})
+	}
+}
+
+func swapDigit(c byte) string {
+	return string('0' + (c-'0'+1)%10)
+}
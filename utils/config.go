@@ -10,9 +10,16 @@ type Config struct {
 	DbDriver             string        `mapstructure:"DB_DRIVER"`
 	DbSource             string        `mapstructure:"DB_SOURCE"`
 	ServerAddress        string        `mapstructure:"SERVER_ADDRESS"`
+	GrpcServerAddress    string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	GatewayServerAddress string        `mapstructure:"GATEWAY_SERVER_ADDRESS"`
 	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
 	ExpiryTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
 	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	EventBrokerURL       string        `mapstructure:"EVENT_BROKER_URL"`
+	BlobStoragePath      string        `mapstructure:"BLOB_STORAGE_PATH"`
+	// PayeeCoolingOffPeriod holds back transfers above PayeeCoolingOffLimit to new payees, 0 disables it
+	PayeeCoolingOffPeriod time.Duration `mapstructure:"PAYEE_COOLING_OFF_PERIOD"`
+	PayeeCoolingOffLimit  int64         `mapstructure:"PAYEE_COOLING_OFF_LIMIT"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
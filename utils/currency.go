@@ -6,6 +6,8 @@ const (
 	INR = "INR"
 )
 
+var SupportedCurrencies = []string{USD, EUR, INR}
+
 func IsSupportedCurrency(currency string) bool {
 	switch currency {
 	case USD, EUR, INR:
@@ -44,3 +44,7 @@ func RandomCurrency() string {
 func RandomEmail() string {
 	return fmt.Sprintf("%s@email.com", RandomString(6))
 }
+
+func RandomAccountNumber() string {
+	return accountNumberWithBody(fmt.Sprintf("%012d", RandomInt(0, 999999999999)))
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Ways to address a transfer recipient
+const (
+	RecipientUsername = "username"
+	RecipientEmail    = "email"
+	RecipientAlias    = "alias"
+)
+
+// AliasPattern is the format of user aliases, sent to with a leading @
+const AliasPattern = "^[a-z0-9_]{3,30}$"
+
+var aliasRegexp = regexp.MustCompile(AliasPattern)
+
+// ValidAlias checks an alias, without its @
+func ValidAlias(alias string) bool {
+	return aliasRegexp.MatchString(alias)
+}
+
+// ParseRecipient tells how a recipient is addressed: "@sam" is an alias, anything
+// else with an @ is an email and the rest are usernames. ok is false for a malformed alias.
+func ParseRecipient(to string) (kind string, value string, ok bool) {
+	switch {
+	case strings.HasPrefix(to, "@"):
+		alias := strings.TrimPrefix(to, "@")
+		return RecipientAlias, alias, ValidAlias(alias)
+	case strings.Contains(to, "@"):
+		return RecipientEmail, to, true
+	default:
+		return RecipientUsername, to, to != ""
+	}
+}
+
+// MaskName keeps the first letter of every word of a name, e.g. "Sam Taylor" becomes "S** T*****"
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		first, size := utf8.DecodeRuneInString(word)
+		words[i] = string(first) + strings.Repeat("*", utf8.RuneCountInString(word[size:]))
+	}
+	return strings.Join(words, " ")
+}
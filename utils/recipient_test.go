@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRecipient(t *testing.T) {
+	testCases := []struct {
+		to    string
+		kind  string
+		value string
+		ok    bool
+	}{
+		{to: "@sam_t", kind: RecipientAlias, value: "sam_t", ok: true},
+		{to: "@Sam", kind: RecipientAlias, value: "Sam", ok: false},
+		{to: "sam@example.com", kind: RecipientEmail, value: "sam@example.com", ok: true},
+		{to: "samtaylor", kind: RecipientUsername, value: "samtaylor", ok: true},
+		{to: "", kind: RecipientUsername, value: "", ok: false},
+	}
+
+	for _, tc := range testCases {
+		kind, value, ok := ParseRecipient(tc.to)
+		require.Equal(t, tc.kind, kind, tc.to)
+		require.Equal(t, tc.value, value, tc.to)
+		require.Equal(t, tc.ok, ok, tc.to)
+	}
+}
+
+func TestMaskName(t *testing.T) {
+	require.Equal(t, "S** T*****", MaskName("Sam Taylor"))
+	require.Equal(t, "Z**", MaskName(" Zoë "))
+	require.Equal(t, "", MaskName(""))
+}
@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// ErrInternalAddress is returned when an endpoint resolves to an address inside the
+// bank's network, webhooks must only reach the public internet
+var ErrInternalAddress = errors.New("webhook endpoint resolves to an internal address")
+
+// carrierNAT is the shared address space of RFC 6598, which net.IP doesn't treat as private
+var carrierNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// ValidURL reports whether raw is an https URL webhooks may be registered with. Hosts
+// given as internal IP addresses or localhost are refused here already, names are only
+// checked once they are resolved at delivery.
+func ValidURL(raw string) bool {
+	endpoint, err := url.Parse(raw)
+	if err != nil || endpoint.Scheme != "https" || endpoint.User != nil {
+		return false
+	}
+
+	host := endpoint.Hostname()
+	if host == "" || strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return checkAddress(ip) == nil
+	}
+	return true
+}
+
+// checkAddress refuses loopback, private, link-local and other addresses that aren't
+// publicly routable
+func checkAddress(ip net.IP) error {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || carrierNAT.Contains(ip) {
+		return fmt.Errorf("%w: %s", ErrInternalAddress, ip)
+	}
+	return nil
+}
+
+// newClient returns the client deliveries are sent with. Every address is checked
+// after DNS resolution, right before connecting, so a name can't be pointed at an
+// internal service, and redirects are returned as the response instead of followed.
+func newClient(check func(ip net.IP) error) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: deliveryTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil {
+				return fmt.Errorf("%w: %s", ErrInternalAddress, host)
+			}
+			return check(ip)
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would be the address dialled and checked, not the endpoint
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   deliveryTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	db "simple-bank/db/sqlc"
+)
+
+// FanOut is the outbox publisher that turns an event into one pending delivery
+// per webhook of the event's owner subscribed to its type. Deliveries are unique
+// per webhook and event, so publishing the same event twice is harmless.
+type FanOut struct {
+	store db.Querier
+}
+
+func NewFanOut(store db.Querier) *FanOut {
+	return &FanOut{store: store}
+}
+
+func (fanOut *FanOut) Publish(ctx context.Context, event db.Outbox) error {
+	if !db.IsEventType(event.Topic) {
+		return nil
+	}
+
+	webhooks, err := fanOut.store.ListSubscribedWebhooks(ctx, db.ListSubscribedWebhooksParams{
+		Owner:     event.Key,
+		EventType: event.Topic,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot list webhooks: %w", err)
+	}
+
+	for _, webhook := range webhooks {
+		err = fanOut.store.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+			WebhookID: webhook.ID,
+			EventID:   event.ID,
+			EventType: event.Topic,
+			Payload:   event.Payload,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot create delivery for webhook %d: %w", webhook.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	mockdb "simple-bank/db/mock"
+	db "simple-bank/db/sqlc"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFanOut(t *testing.T) {
+	event := db.Outbox{
+		ID:      42,
+		Topic:   db.EventTransferReceived,
+		Key:     "alice",
+		Payload: json.RawMessage(`{"transfer_id":3}`),
+	}
+	webhooks := []db.Webhook{{ID: 1}, {ID: 2}}
+
+	testCases := []struct {
+		name       string
+		event      db.Outbox
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name:  "OK",
+			event: event,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListSubscribedWebhooks(gomock.Any(), gomock.Eq(db.ListSubscribedWebhooksParams{
+					Owner:     event.Key,
+					EventType: event.Topic,
+				})).Times(1).Return(webhooks, nil)
+
+				for _, webhook := range webhooks {
+					store.EXPECT().CreateWebhookDelivery(gomock.Any(), gomock.Eq(db.CreateWebhookDeliveryParams{
+						WebhookID: webhook.ID,
+						EventID:   event.ID,
+						EventType: event.Topic,
+						Payload:   event.Payload,
+					})).Times(1).Return(nil)
+				}
+			},
+		},
+		{
+			name:  "NotAWebhookEvent",
+			event: db.Outbox{ID: 43, Topic: "ledger.closed", Key: "alice"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListSubscribedWebhooks(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateWebhookDelivery(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			require.NoError(t, NewFanOut(store).Publish(context.Background(), tc.event))
+		})
+	}
+}
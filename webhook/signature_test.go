@@ -0,0 +1,32 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignature(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+
+	body := []byte(`{"id":1,"type":"transfer.created"}`)
+	header := Sign(secret, time.Now(), body)
+
+	require.NoError(t, Verify(secret, header, body, time.Minute))
+	require.ErrorIs(t, Verify(secret, header, []byte(`{"id":2}`), time.Minute), ErrInvalidSignature)
+	require.ErrorIs(t, Verify("whsec_other", header, body, time.Minute), ErrInvalidSignature)
+	require.ErrorIs(t, Verify(secret, "v1=abc", body, time.Minute), ErrInvalidSignature)
+
+	old := Sign(secret, time.Now().Add(-time.Hour), body)
+	require.ErrorIs(t, Verify(secret, old, body, time.Minute), ErrExpiredSignature)
+	require.NoError(t, Verify(secret, old, body, 0))
+}
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, 30*time.Second, Backoff(1))
+	require.Equal(t, time.Minute, Backoff(2))
+	require.Equal(t, 4*time.Minute, Backoff(4))
+	require.Equal(t, maxBackoff, Backoff(30))
+}
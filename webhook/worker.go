@@ -0,0 +1,190 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	db "simple-bank/db/sqlc"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Delivery statuses stored in webhook_deliveries
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+const (
+	defaultBatchSize    = 50
+	defaultPollInterval = 5 * time.Second
+	defaultMaxAttempts  = 8
+	deliveryTimeout     = 10 * time.Second
+	maxBackoff          = 6 * time.Hour
+	maxErrorLength      = 500
+)
+
+// Event is the body POSTed to webhook endpoints
+type Event struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Worker delivers the pending webhook deliveries created by FanOut.
+// Failed deliveries are retried with exponential backoff until MaxAttempts.
+type Worker struct {
+	store        db.Store
+	client       *http.Client
+	BatchSize    int32
+	PollInterval time.Duration
+	MaxAttempts  int32
+	now          func() time.Time
+}
+
+func NewWorker(store db.Store) *Worker {
+	return &Worker{
+		store:        store,
+		client:       newClient(checkAddress),
+		BatchSize:    defaultBatchSize,
+		PollInterval: defaultPollInterval,
+		MaxAttempts:  defaultMaxAttempts,
+		now:          time.Now,
+	}
+}
+
+// Start polls until ctx is cancelled
+func (worker *Worker) Start(ctx context.Context) {
+	ticker := time.NewTicker(worker.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := worker.RunOnce(ctx); err != nil {
+			log.Printf("webhook worker: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce attempts every due delivery
+func (worker *Worker) RunOnce(ctx context.Context) error {
+	deliveries, err := worker.store.ClaimWebhookDeliveries(ctx, db.ClaimWebhookDeliveriesParams{
+		LeaseUntil:    worker.now().Add(deliveryTimeout * 3),
+		MaxDeliveries: worker.BatchSize,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot claim deliveries: %w", err)
+	}
+
+	for _, delivery := range deliveries {
+		if err := worker.deliver(ctx, delivery); err != nil {
+			log.Printf("webhook worker: delivery %d: %s", delivery.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (worker *Worker) deliver(ctx context.Context, delivery db.WebhookDelivery) error {
+	webhook, err := worker.store.GetWebhook(ctx, delivery.WebhookID)
+	if err != nil {
+		return fmt.Errorf("cannot get webhook: %w", err)
+	}
+
+	responseStatus, sendErr := worker.send(ctx, webhook, delivery)
+
+	args := db.UpdateWebhookDeliveryParams{
+		ID:             delivery.ID,
+		Status:         StatusSucceeded,
+		Attempts:       delivery.Attempts + 1,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		ResponseStatus: sql.NullInt32{Int32: int32(responseStatus), Valid: responseStatus != 0},
+	}
+
+	switch {
+	case sendErr == nil:
+		args.DeliveredAt = sql.NullTime{Time: worker.now(), Valid: true}
+	case args.Attempts >= worker.MaxAttempts:
+		args.Status = StatusFailed
+		args.LastError = truncate(sendErr.Error())
+	default:
+		args.Status = StatusPending
+		args.LastError = truncate(sendErr.Error())
+		args.NextAttemptAt = worker.now().Add(Backoff(args.Attempts))
+	}
+
+	_, err = worker.store.UpdateWebhookDelivery(ctx, args)
+	return err
+}
+
+// send POSTs the signed event and reports the response status, any non 2xx
+// response, redirects included, is treated as a failure
+func (worker *Worker) send(ctx context.Context, webhook db.Webhook, delivery db.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(Event{
+		ID:        delivery.EventID,
+		Type:      delivery.EventType,
+		CreatedAt: delivery.CreatedAt,
+		Data:      delivery.Payload,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// webhooks registered before https was required are never delivered in the clear
+	if !strings.HasPrefix(webhook.Url, "https://") {
+		return 0, fmt.Errorf("endpoint %q isn't an https URL", webhook.Url)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, worker.now(), body))
+
+	res, err := worker.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("endpoint responded with %s", res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+// Backoff returns the delay before the next attempt: 30s, 1m, 2m, ... capped at 6h
+func Backoff(attempts int32) time.Duration {
+	delay := 30 * time.Second
+	for i := int32(1); i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
+
+func truncate(s string) string {
+	if len(s) > maxErrorLength {
+		return s[:maxErrorLength]
+	}
+	return s
+}
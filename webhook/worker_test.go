@@ -0,0 +1,218 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simple-bank/db/mock"
+	db "simple-bank/db/sqlc"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerDelivers(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	webhook := db.Webhook{ID: 1, Owner: "alice", Secret: "whsec_test", Events: []string{db.EventTransferReceived}, Active: true}
+	delivery := db.WebhookDelivery{
+		ID:            7,
+		WebhookID:     webhook.ID,
+		EventID:       42,
+		EventType:     db.EventTransferReceived,
+		Payload:       json.RawMessage(`{"transfer_id":3}`),
+		Status:        StatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+
+	testCases := []struct {
+		name          string
+		attempts      int32
+		status        int
+		checkDelivery func(t *testing.T, args db.UpdateWebhookDeliveryParams)
+	}{
+		{
+			name:   "OK",
+			status: http.StatusNoContent,
+			checkDelivery: func(t *testing.T, args db.UpdateWebhookDeliveryParams) {
+				require.Equal(t, StatusSucceeded, args.Status)
+				require.Equal(t, int32(1), args.Attempts)
+				require.Equal(t, int32(http.StatusNoContent), args.ResponseStatus.Int32)
+				require.True(t, args.DeliveredAt.Valid)
+				require.Empty(t, args.LastError)
+			},
+		},
+		{
+			name:   "Retry",
+			status: http.StatusInternalServerError,
+			checkDelivery: func(t *testing.T, args db.UpdateWebhookDeliveryParams) {
+				require.Equal(t, StatusPending, args.Status)
+				require.Equal(t, int32(1), args.Attempts)
+				require.Equal(t, now.Add(Backoff(1)), args.NextAttemptAt)
+				require.False(t, args.DeliveredAt.Valid)
+				require.NotEmpty(t, args.LastError)
+			},
+		},
+		{
+			name:     "GiveUp",
+			attempts: defaultMaxAttempts - 1,
+			status:   http.StatusBadGateway,
+			checkDelivery: func(t *testing.T, args db.UpdateWebhookDeliveryParams) {
+				require.Equal(t, StatusFailed, args.Status)
+				require.Equal(t, int32(defaultMaxAttempts), args.Attempts)
+				require.Equal(t, int32(http.StatusBadGateway), args.ResponseStatus.Int32)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var received Event
+			endpoint := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.NoError(t, Verify(webhook.Secret, r.Header.Get(SignatureHeader), body, time.Minute))
+				require.Equal(t, delivery.EventType, r.Header.Get(EventHeader))
+				require.Equal(t, strconv.FormatInt(delivery.ID, 10), r.Header.Get(DeliveryHeader))
+				require.NoError(t, json.Unmarshal(body, &received))
+				w.WriteHeader(tc.status)
+			}))
+			defer endpoint.Close()
+
+			hook := webhook
+			hook.Url = endpoint.URL
+			claimed := delivery
+			claimed.Attempts = tc.attempts
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any()).Times(1).Return([]db.WebhookDelivery{claimed}, nil)
+			store.EXPECT().GetWebhook(gomock.Any(), hook.ID).Times(1).Return(hook, nil)
+			store.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).
+				DoAndReturn(func(_ context.Context, args db.UpdateWebhookDeliveryParams) (db.WebhookDelivery, error) {
+					require.Equal(t, delivery.ID, args.ID)
+					tc.checkDelivery(t, args)
+					return db.WebhookDelivery{}, nil
+				})
+
+			worker := NewWorker(store)
+			worker.client = testClient(endpoint)
+			worker.now = func() time.Time { return now }
+			require.NoError(t, worker.RunOnce(context.Background()))
+
+			require.Equal(t, delivery.EventID, received.ID)
+			require.Equal(t, delivery.EventType, received.Type)
+			require.JSONEq(t, string(delivery.Payload), string(received.Data))
+		})
+	}
+}
+
+func TestWorkerRefusesEndpoint(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	webhook := db.Webhook{ID: 1, Owner: "alice", Secret: "whsec_test", Events: []string{db.EventTransferReceived}, Active: true}
+	delivery := db.WebhookDelivery{
+		ID:            7,
+		WebhookID:     webhook.ID,
+		EventID:       42,
+		EventType:     db.EventTransferReceived,
+		Payload:       json.RawMessage(`{"transfer_id":3}`),
+		Status:        StatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+
+	testCases := []struct {
+		name      string
+		newServer func(handler http.Handler) *httptest.Server
+		handler   http.HandlerFunc
+		// trusted skips the address check, as if the endpoint were public
+		trusted     bool
+		checkResult func(t *testing.T, args db.UpdateWebhookDeliveryParams)
+	}{
+		{
+			name:      "InternalAddress",
+			newServer: httptest.NewTLSServer,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				t.Error("internal endpoint must not be reached")
+			},
+			checkResult: func(t *testing.T, args db.UpdateWebhookDeliveryParams) {
+				require.Contains(t, args.LastError, ErrInternalAddress.Error())
+				require.False(t, args.ResponseStatus.Valid)
+			},
+		},
+		{
+			name:      "Redirect",
+			newServer: httptest.NewTLSServer,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/internal" {
+					t.Error("redirect must not be followed")
+				}
+				http.Redirect(w, r, "/internal", http.StatusFound)
+			},
+			trusted: true,
+			checkResult: func(t *testing.T, args db.UpdateWebhookDeliveryParams) {
+				require.Equal(t, int32(http.StatusFound), args.ResponseStatus.Int32)
+			},
+		},
+		{
+			name:      "PlainHTTP",
+			newServer: httptest.NewServer,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				t.Error("plain http endpoint must not be reached")
+			},
+			trusted: true,
+			checkResult: func(t *testing.T, args db.UpdateWebhookDeliveryParams) {
+				require.Contains(t, args.LastError, "https")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint := tc.newServer(tc.handler)
+			defer endpoint.Close()
+
+			hook := webhook
+			hook.Url = endpoint.URL
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any()).Times(1).Return([]db.WebhookDelivery{delivery}, nil)
+			store.EXPECT().GetWebhook(gomock.Any(), hook.ID).Times(1).Return(hook, nil)
+			store.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).
+				DoAndReturn(func(_ context.Context, args db.UpdateWebhookDeliveryParams) (db.WebhookDelivery, error) {
+					require.Equal(t, StatusPending, args.Status)
+					require.False(t, args.DeliveredAt.Valid)
+					tc.checkResult(t, args)
+					return db.WebhookDelivery{}, nil
+				})
+
+			worker := NewWorker(store)
+			if tc.trusted {
+				worker.client = testClient(endpoint)
+			} else {
+				worker.client.Transport.(*http.Transport).TLSClientConfig = endpoint.Client().Transport.(*http.Transport).TLSClientConfig
+			}
+			worker.now = func() time.Time { return now }
+			require.NoError(t, worker.RunOnce(context.Background()))
+		})
+	}
+}
+
+// testClient sends deliveries to a local test server, trusting its certificate and
+// skipping the address check, which would refuse the loopback address
+func testClient(endpoint *httptest.Server) *http.Client {
+	client := newClient(func(net.IP) error { return nil })
+	client.Transport.(*http.Transport).TLSClientConfig = endpoint.Client().Transport.(*http.Transport).TLSClientConfig
+	return client
+}